@@ -4,13 +4,26 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/romanguyen/seman/internal/app"
+	"github.com/romanguyen/seman/internal/cli"
 	"github.com/romanguyen/seman/internal/storage"
 )
 
+const (
+	backendJSON   = "json"
+	backendSQLite = "sqlite"
+)
+
 func main() {
+	flags, args, err := parseGlobalFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
 	dataDir, err := dataDirectory()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error locating data directory: %v\n", err)
@@ -21,16 +34,147 @@ func main() {
 		os.Exit(1)
 	}
 
-	store := storage.NewJSONStore(filepath.Join(dataDir, "semester.json"))
+	lib, err := storage.OpenLibrary(dataDir, opener(flags.backend))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading semesters: %v\n", err)
+		os.Exit(1)
+	}
+	semester := lib.Current()
+	if flags.semester != "" {
+		var ok bool
+		if semester, ok = lib.Find(flags.semester); !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown semester %q (have: %s)\n", flags.semester, strings.Join(lib.Names(), ", "))
+			os.Exit(2)
+		}
+	}
+	store, err := lib.Open(semester.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening data store: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(run(lib, store, args))
+}
+
+func run(lib *storage.Library, store storage.Store, args []string) int {
+	defer lib.Close()
+	if cli.IsCommand(args) {
+		return cli.Run(args, store, os.Stdout, os.Stderr)
+	}
+
 	data, found, err := store.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading data: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
-	p := tea.NewProgram(app.NewModel(store, data, found), tea.WithAltScreen())
+	keymap, err := loadKeymap()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	model := app.NewModel(store, data, found).WithLibrary(lib).WithKeymap(keymap)
+	if dir, err := app.ThemesDir(); err == nil {
+		model = model.WithThemes(dir)
+	}
+	p := tea.NewProgram(model, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		return 1
+	}
+	return 0
+}
+
+// loadKeymap reads the key bindings of keys.toml in the configuration
+// directory, if there is one.
+func loadKeymap() (app.Keymap, error) {
+	path, err := app.KeymapPath()
+	if err != nil {
+		return app.Keymap{}, nil
+	}
+	keymap, err := app.LoadKeymap(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keymap, nil
+}
+
+// globalFlags are the options that apply to every command.
+type globalFlags struct {
+	backend  string
+	semester string
+}
+
+// parseGlobalFlags takes the options that apply to every command off the
+// front of args. The storage backend defaults to $SEMAN_STORE.
+func parseGlobalFlags(args []string) (globalFlags, []string, error) {
+	flags := globalFlags{backend: os.Getenv("SEMAN_STORE")}
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--store" || arg == "-store":
+			if len(args) < 2 {
+				return flags, nil, fmt.Errorf("--store needs a value (json or sqlite)")
+			}
+			flags.backend = args[1]
+			args = args[2:]
+		case strings.HasPrefix(arg, "--store="):
+			flags.backend = strings.TrimPrefix(arg, "--store=")
+			args = args[1:]
+		case arg == "--semester" || arg == "-semester":
+			if len(args) < 2 {
+				return flags, nil, fmt.Errorf("--semester needs a name or ID")
+			}
+			flags.semester = args[1]
+			args = args[2:]
+		case strings.HasPrefix(arg, "--semester="):
+			flags.semester = strings.TrimPrefix(arg, "--semester=")
+			args = args[1:]
+		default:
+			return flags, args, nil
+		}
+	}
+	return flags, args, nil
+}
+
+// opener returns how a semester's data file is opened with the requested
+// backend. Without an explicit choice the SQLite database is used once it
+// exists, so a migration sticks. Opening SQLite for the first time copies
+// the semester's JSON file into it.
+func opener(backend string) storage.Opener {
+	backend = strings.ToLower(strings.TrimSpace(backend))
+	return func(base string) (storage.Store, func() error, error) {
+		jsonPath := base + ".json"
+		dbPath := base + ".db"
+
+		choice := backend
+		if choice == "" {
+			choice = backendJSON
+			if _, err := os.Stat(dbPath); err == nil {
+				choice = backendSQLite
+			}
+		}
+
+		switch choice {
+		case backendJSON:
+			return storage.NewJSONStore(jsonPath), func() error { return nil }, nil
+		case backendSQLite:
+			if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+				return nil, nil, err
+			}
+			store, err := storage.OpenSQLite(dbPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			migrated, err := store.MigrateFromJSON(jsonPath)
+			if err != nil {
+				store.Close()
+				return nil, nil, fmt.Errorf("migrating %s: %w", jsonPath, err)
+			}
+			if migrated {
+				fmt.Fprintf(os.Stderr, "Migrated %s to %s\n", jsonPath, dbPath)
+			}
+			return store, store.Close, nil
+		}
+		return nil, nil, fmt.Errorf("unknown store %q (want json or sqlite)", backend)
 	}
 }
 
@@ -0,0 +1,385 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// An action is something the user can do: the keys run it and the command
+// palette lists it by name. An action of some tabs only applies there and
+// takes precedence over a global one (no tabs) bound to the same key; when,
+// if set, further limits it to some states of its tab. Actions that change
+// data are mutating, which an archived semester blocks.
+type action struct {
+	id       string
+	name     string
+	keys     []string
+	tabs     []int
+	when     func(m *Model) bool
+	mutating bool
+	run      func(m *Model) tea.Cmd
+}
+
+// do adapts a method without a command to an action's run.
+func do(f func(*Model)) func(*Model) tea.Cmd {
+	return func(m *Model) tea.Cmd {
+		f(m)
+		return nil
+	}
+}
+
+var filterTabs = []int{tabDashboard, tabExams, tabTodos, tabProjects, tabCalendar}
+
+// editorTabs are the tabs whose items can be edited in $EDITOR.
+var editorTabs = []int{tabSubjects, tabExams, tabTodos, tabProjects, tabGrades, tabTimetable}
+
+// defaultActions lists every action with its default keys: the global ones,
+// then those of each tab.
+func defaultActions() []action {
+	return []action{
+		{id: "quit", name: "Quit", keys: []string{"q", "ctrl+c"}, run: func(m *Model) tea.Cmd {
+			m.shutdownLofi()
+			return tea.Quit
+		}},
+		{id: "command-palette", name: "Command palette", keys: []string{":", "ctrl+p"}, run: do((*Model).openPalette)},
+		{id: "search", name: "Search", keys: []string{"/"}, run: do((*Model).openSearch)},
+		{id: "semesters", name: "Semesters", keys: []string{"m"}, run: do((*Model).openSemesters)},
+		{id: "undo", name: "Undo", keys: []string{"ctrl+z"}, mutating: true, run: do((*Model).undo)},
+		{id: "redo", name: "Redo", keys: []string{"ctrl+y"}, mutating: true, run: do((*Model).redo)},
+		{id: "history", name: "History (undo to any point)", keys: []string{"z"}, mutating: true, run: do((*Model).openHistory)},
+		{id: "add-subject", name: "Add subject", keys: []string{"s"}, mutating: true, run: do((*Model).openAddSubject)},
+		{id: "add-exam", name: "Add exam", keys: []string{"a"}, mutating: true, run: do((*Model).openAddExamWithFilter)},
+		{id: "add-project", name: "Add project", keys: []string{"p"}, mutating: true, run: do((*Model).openAddProject)},
+		{id: "edit", name: "Edit selected item", keys: []string{"e"}, mutating: true, run: do((*Model).openEditCurrent)},
+		{id: "delete", name: "Delete selected item", keys: []string{"d"}, mutating: true, run: do((*Model).queueDelete)},
+		{id: "global-view", name: "Toggle global view (all weeks)", keys: []string{"g"}, run: do((*Model).toggleGlobalView)},
+		{id: "previous-week", name: "Previous week", keys: []string{"left"}, run: do(func(m *Model) { m.shiftWeek(-1) })},
+		{id: "next-week", name: "Next week", keys: []string{"right"}, run: do(func(m *Model) { m.shiftWeek(1) })},
+		{id: "this-week", name: "Jump to this week", keys: []string{"t"}, run: do((*Model).jumpToCurrentWeek)},
+
+		{id: "filter", name: "Filter by subject", keys: []string{"f"}, tabs: filterTabs, run: do((*Model).openSubjectFilter)},
+		{id: "clear-filter", name: "Clear subject filter", keys: []string{"r"}, tabs: filterTabs, run: do(func(m *Model) {
+			m.subjectFilters = nil
+			m.refreshAllFilters()
+		})},
+
+		{id: "edit-in-editor", name: "Edit selected item (or subtasks) in $EDITOR", keys: []string{"ctrl+o"}, tabs: editorTabs, mutating: true, run: (*Model).editInEditor},
+
+		{id: "scroll-down", name: "Scroll todos down", keys: []string{"j", "down"}, tabs: []int{tabDashboard}, run: do(func(m *Model) { m.checklist.LineDown(1) })},
+		{id: "scroll-up", name: "Scroll todos up", keys: []string{"k", "up"}, tabs: []int{tabDashboard}, run: do(func(m *Model) { m.checklist.LineUp(1) })},
+		{id: "page-down", name: "Page todos down", keys: []string{"pgdown"}, tabs: []int{tabDashboard}, run: do(func(m *Model) { m.checklist.ViewDown() })},
+		{id: "page-up", name: "Page todos up", keys: []string{"pgup"}, tabs: []int{tabDashboard}, run: do(func(m *Model) { m.checklist.ViewUp() })},
+
+		{id: "down", name: "Move down", keys: []string{"j", "down"}, tabs: []int{tabSubjects}, run: do(func(m *Model) {
+			if m.subjectFocus {
+				m.moveResourceCursor(1)
+			} else {
+				m.moveSubjectCursor(1)
+			}
+		})},
+		{id: "up", name: "Move up", keys: []string{"k", "up"}, tabs: []int{tabSubjects}, run: do(func(m *Model) {
+			if m.subjectFocus {
+				m.moveResourceCursor(-1)
+			} else {
+				m.moveSubjectCursor(-1)
+			}
+		})},
+		{id: "open", name: "Edit subject (add one if there are none)", keys: []string{"enter"}, tabs: []int{tabSubjects}, when: func(m *Model) bool { return !m.subjectFocus }, mutating: true, run: do(func(m *Model) {
+			if len(m.subjects) == 0 {
+				m.openAddSubject()
+			} else {
+				m.openEditSubject()
+			}
+		})},
+		{id: "switch-focus", name: "Switch between subjects and resources", keys: []string{"tab"}, tabs: []int{tabSubjects}, run: do((*Model).toggleSubjectFocus)},
+		{id: "open-resource", name: "Open resource", keys: []string{"o", "enter"}, tabs: []int{tabSubjects}, when: func(m *Model) bool { return m.subjectFocus }, run: (*Model).openResource},
+		{id: "close-resources", name: "Back to subjects", keys: []string{"esc"}, tabs: []int{tabSubjects}, when: func(m *Model) bool { return m.subjectFocus }, run: do(func(m *Model) { m.subjectFocus = false })},
+		{id: "add-resource", name: "Add resource (link or file)", keys: []string{"l"}, tabs: []int{tabSubjects}, mutating: true, run: do((*Model).openAddResource)},
+		{id: "edit-notes", name: "Edit notes in $EDITOR", keys: []string{"n"}, tabs: []int{tabSubjects}, mutating: true, run: (*Model).editNotes},
+
+		{id: "down", name: "Next exam", keys: []string{"j", "down"}, tabs: []int{tabExams}, run: do(func(m *Model) { m.moveExamCursor(1) })},
+		{id: "up", name: "Previous exam", keys: []string{"k", "up"}, tabs: []int{tabExams}, run: do(func(m *Model) { m.moveExamCursor(-1) })},
+
+		{id: "down", name: "Next todo", keys: []string{"j", "down"}, tabs: []int{tabTodos}, run: do(func(m *Model) { m.moveChecklistCursor(1) })},
+		{id: "up", name: "Previous todo", keys: []string{"k", "up"}, tabs: []int{tabTodos}, run: do(func(m *Model) { m.moveChecklistCursor(-1) })},
+		{id: "page-down", name: "Page down", keys: []string{"pgdown"}, tabs: []int{tabTodos}, run: do(func(m *Model) { m.moveChecklistCursor(m.checklist.Height) })},
+		{id: "page-up", name: "Page up", keys: []string{"pgup"}, tabs: []int{tabTodos}, run: do(func(m *Model) { m.moveChecklistCursor(-m.checklist.Height) })},
+		{id: "add-todo", name: "Add todo", keys: []string{"n"}, tabs: []int{tabTodos}, mutating: true, run: do((*Model).openAddTodo)},
+		{id: "toggle", name: "Toggle todo done", keys: []string{" ", "enter", "x"}, tabs: []int{tabTodos}, mutating: true, run: do((*Model).toggleChecklistItem)},
+
+		{id: "down", name: "Move down", keys: []string{"j", "down"}, tabs: []int{tabProjects}, run: do(func(m *Model) {
+			if m.projectFocus {
+				m.movePlanCursor(1)
+			} else {
+				m.moveProjectCursor(1)
+			}
+		})},
+		{id: "up", name: "Move up", keys: []string{"k", "up"}, tabs: []int{tabProjects}, run: do(func(m *Model) {
+			if m.projectFocus {
+				m.movePlanCursor(-1)
+			} else {
+				m.moveProjectCursor(-1)
+			}
+		})},
+		{id: "switch-focus", name: "Switch between projects and plan", keys: []string{"tab"}, tabs: []int{tabProjects}, run: do((*Model).toggleProjectFocus)},
+		{id: "open-plan", name: "Open plan", keys: []string{"enter"}, tabs: []int{tabProjects}, when: func(m *Model) bool { return !m.projectFocus }, run: do((*Model).toggleProjectFocus)},
+		{id: "toggle", name: "Toggle subtask or milestone done", keys: []string{" ", "enter", "x"}, tabs: []int{tabProjects}, when: func(m *Model) bool { return m.projectFocus }, mutating: true, run: do((*Model).togglePlanItem)},
+		{id: "close-plan", name: "Back to projects", keys: []string{"esc"}, tabs: []int{tabProjects}, when: func(m *Model) bool { return m.projectFocus }, run: do(func(m *Model) { m.projectFocus = false })},
+		{id: "add-subtask", name: "Add subtask", keys: []string{"n"}, tabs: []int{tabProjects}, mutating: true, run: do((*Model).openAddSubtask)},
+		{id: "add-milestone", name: "Add milestone", keys: []string{"l"}, tabs: []int{tabProjects}, mutating: true, run: do((*Model).openAddMilestone)},
+		{id: "timeline", name: "Toggle timeline", keys: []string{"v"}, tabs: []int{tabProjects}, run: do(func(m *Model) { m.projectTimeline = !m.projectTimeline })},
+
+		{id: "down", name: "Next exam", keys: []string{"j", "down"}, tabs: []int{tabGrades}, run: do(func(m *Model) { m.moveGradeCursor(1) })},
+		{id: "up", name: "Previous exam", keys: []string{"k", "up"}, tabs: []int{tabGrades}, run: do(func(m *Model) { m.moveGradeCursor(-1) })},
+		{id: "record-result", name: "Record exam result", keys: []string{"enter"}, tabs: []int{tabGrades}, mutating: true, run: do((*Model).openExamResult)},
+		{id: "grading-scale", name: "Cycle grading scale", keys: []string{"c"}, tabs: []int{tabGrades}, mutating: true, run: do((*Model).cycleGradingScale)},
+
+		{id: "down", name: "Next class", keys: []string{"j", "down"}, tabs: []int{tabTimetable}, run: do(func(m *Model) { m.moveSessionCursor(1) })},
+		{id: "up", name: "Previous class", keys: []string{"k", "up"}, tabs: []int{tabTimetable}, run: do(func(m *Model) { m.moveSessionCursor(-1) })},
+		{id: "add-class", name: "Add class", keys: []string{"n"}, tabs: []int{tabTimetable}, mutating: true, run: do((*Model).openAddSession)},
+		{id: "open", name: "Edit class (add one if there are none)", keys: []string{"enter"}, tabs: []int{tabTimetable}, mutating: true, run: do(func(m *Model) {
+			if _, ok := m.selectedSession(); ok {
+				m.openEditSession()
+			} else {
+				m.openAddSession()
+			}
+		})},
+
+		{id: "previous-day", name: "Previous day", keys: []string{"left", "h"}, tabs: []int{tabCalendar}, run: do(func(m *Model) { m.moveCalendarDay(-1) })},
+		{id: "next-day", name: "Next day", keys: []string{"right", "l"}, tabs: []int{tabCalendar}, run: do(func(m *Model) { m.moveCalendarDay(1) })},
+		{id: "up", name: "Previous week (or agenda entry)", keys: []string{"up", "k"}, tabs: []int{tabCalendar}, run: do(func(m *Model) { m.moveCalendarVertical(-1) })},
+		{id: "down", name: "Next week (or agenda entry)", keys: []string{"down", "j"}, tabs: []int{tabCalendar}, run: do(func(m *Model) { m.moveCalendarVertical(1) })},
+		{id: "previous-month", name: "Previous month", keys: []string{"[", "pgup"}, tabs: []int{tabCalendar}, run: do(func(m *Model) { m.moveCalendarMonth(-1) })},
+		{id: "next-month", name: "Next month", keys: []string{"]", "pgdown"}, tabs: []int{tabCalendar}, run: do(func(m *Model) { m.moveCalendarMonth(1) })},
+		{id: "today", name: "Go to today", keys: []string{"t"}, tabs: []int{tabCalendar}, run: do((*Model).calendarToday)},
+		{id: "open-agenda", name: "Open day agenda", keys: []string{"enter", "e"}, tabs: []int{tabCalendar}, when: func(m *Model) bool { return !m.agendaOpen }, run: do((*Model).openAgenda)},
+		{id: "edit", name: "Edit agenda entry", keys: []string{"e", "enter"}, tabs: []int{tabCalendar}, when: func(m *Model) bool { return m.agendaOpen }, mutating: true, run: do((*Model).openEditCalendarEntry)},
+		{id: "close-agenda", name: "Close agenda", keys: []string{"esc"}, tabs: []int{tabCalendar}, when: func(m *Model) bool { return m.agendaOpen }, run: do(func(m *Model) { m.agendaOpen = false })},
+		{id: "add-exam", name: "Add exam on this day", keys: []string{"a"}, tabs: []int{tabCalendar}, mutating: true, run: do((*Model).openAddExamOnDay)},
+		{id: "add-project", name: "Add project due this day", keys: []string{"p"}, tabs: []int{tabCalendar}, mutating: true, run: do((*Model).openAddProjectOnDay)},
+		{id: "add-todo", name: "Add todo due this day", keys: []string{"n"}, tabs: []int{tabCalendar}, mutating: true, run: do((*Model).openAddTodoOnDay)},
+
+		{id: "theme", name: "Cycle theme", keys: []string{"t"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).cycleTheme)},
+		{id: "date-format", name: "Cycle date format", keys: []string{"f"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).cycleDateFormat)},
+		{id: "confirm-delete", name: "Toggle delete confirmation", keys: []string{"o"}, tabs: []int{tabSettings}, mutating: true, run: do(func(m *Model) {
+			m.confirmOn = !m.confirmOn
+			m.persist()
+		})},
+		{id: "week-span", name: "Cycle week span", keys: []string{"w"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).cycleWeekSpan)},
+		{id: "priority-rules", name: "Edit priority rules", keys: []string{"r"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).openPriorityRules)},
+		{id: "lofi", name: "Toggle lofi player", keys: []string{"l"}, tabs: []int{tabSettings}, mutating: true, run: (*Model).toggleLofiEnabled},
+		{id: "lofi-url", name: "Edit lofi playlist URL", keys: []string{"u"}, tabs: []int{tabSettings, tabLofi}, mutating: true, run: do((*Model).openEditLofiURL)},
+		{id: "export", name: "Export data", keys: []string{"x"}, tabs: []int{tabSettings}, run: do((*Model).openExportData)},
+		{id: "import", name: "Import data", keys: []string{"i"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).openImportData)},
+		{id: "backup", name: "Create backup", keys: []string{"b"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).createBackup)},
+		{id: "restore", name: "Restore backup", keys: []string{"v"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).openRestoreBackup)},
+		{id: "clear-all", name: "Clear all data", keys: []string{"c"}, tabs: []int{tabSettings}, mutating: true, run: do((*Model).queueClearAll)},
+
+		{id: "down", name: "Next track", keys: []string{"j", "down"}, tabs: []int{tabLofi}, run: do(func(m *Model) { m.moveLofiCursor(1) })},
+		{id: "up", name: "Previous track", keys: []string{"k", "up"}, tabs: []int{tabLofi}, run: do(func(m *Model) { m.moveLofiCursor(-1) })},
+		{id: "play", name: "Play selected track", keys: []string{"enter"}, tabs: []int{tabLofi}, run: func(m *Model) tea.Cmd { return m.playLofiAt(m.lofiCursor) }},
+		{id: "pause", name: "Pause / resume", keys: []string{" "}, tabs: []int{tabLofi}, run: (*Model).toggleLofiPlayPause},
+		{id: "next", name: "Next track", keys: []string{"n"}, tabs: []int{tabLofi}, run: (*Model).lofiNext},
+		{id: "previous", name: "Previous track", keys: []string{"b"}, tabs: []int{tabLofi}, run: (*Model).lofiPrev},
+		{id: "stop", name: "Stop", keys: []string{"x"}, tabs: []int{tabLofi}, run: do((*Model).lofiStop)},
+	}
+}
+
+// onTab reports whether the action applies on tab.
+func (a action) onTab(tab int) bool {
+	if a.tabs == nil {
+		return true
+	}
+	for _, t := range a.tabs {
+		if t == tab {
+			return true
+		}
+	}
+	return false
+}
+
+// applies reports whether the action can run in the current state.
+func (a action) applies(m *Model) bool {
+	return a.onTab(m.activeTab) && (a.when == nil || a.when(m))
+}
+
+// boundTo reports whether key is one of keys. A lowercase letter also
+// matches with shift.
+func boundTo(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key || len(k) == 1 && k >= "a" && k <= "z" && strings.ToLower(key) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// actionFor returns the action key runs: the active tab's own, or else a
+// global one.
+func (m *Model) actionFor(key string) (action, bool) {
+	var global *action
+	for i := range m.actions {
+		a := &m.actions[i]
+		if !a.applies(m) || !boundTo(m.keymap.keysFor(*a, m.activeTab), key) {
+			continue
+		}
+		if a.tabs != nil {
+			return *a, true
+		}
+		if global == nil {
+			global = a
+		}
+	}
+	if global == nil {
+		return action{}, false
+	}
+	return *global, true
+}
+
+// runAction runs a, unless it would change an archived semester.
+func (m *Model) runAction(a action) tea.Cmd {
+	if m.archived && a.mutating {
+		m.notice = strings.TrimSpace("This semester is archived (read-only)  " + m.actionHint("semesters", "Semesters"))
+		return nil
+	}
+	return a.run(m)
+}
+
+// keyLabel is how a key reads in hints, e.g. "Ctrl+Z" or "Space".
+func keyLabel(key string) string {
+	switch key {
+	case " ":
+		return "Space"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "pgup":
+		return "PgUp"
+	case "pgdown":
+		return "PgDn"
+	}
+	parts := strings.Split(key, "+")
+	for i, p := range parts {
+		if len(p) > 1 {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		} else {
+			parts[i] = strings.ToUpper(p)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// keysLabel lists the keys of an action as the palette shows them.
+func keysLabel(keys []string) string {
+	labels := make([]string, 0, len(keys))
+	for _, k := range keys {
+		labels = append(labels, keyLabel(k))
+	}
+	return strings.Join(labels, " / ")
+}
+
+// openPalette opens the command palette, which lists the actions that
+// apply on the active tab.
+func (m *Model) openPalette() {
+	fields := []formField{newFormField("Command", m.modalInputWidth(), false)}
+	fields[0].input.Placeholder = "type to filter"
+	m.openFormModal(modalPalette, "Commands", fields)
+	m.modalHint = "Type to filter · ↑↓ select · Enter run · Esc close"
+	m.paletteItems = m.paletteMatches("")
+	m.paletteCursor = 0
+}
+
+// paletteMatches lists the actions that apply and match query: the active
+// tab's first, then the global ones, best matches first.
+func (m *Model) paletteMatches(query string) []action {
+	words := strings.Fields(strings.ToLower(query))
+	type match struct {
+		action action
+		score  int
+	}
+	var matches []match
+	for _, a := range m.actions {
+		if a.id == "command-palette" || !a.applies(m) {
+			continue
+		}
+		score := 0
+		if len(words) > 0 {
+			var ok bool
+			if score, ok = fuzzyScore(words, a.name); !ok {
+				continue
+			}
+		}
+		matches = append(matches, match{a, score})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if gi, gj := matches[i].action.tabs == nil, matches[j].action.tabs == nil; gi != gj {
+			return gj
+		}
+		return matches[i].score > matches[j].score
+	})
+	items := make([]action, len(matches))
+	for i, match := range matches {
+		items[i] = match.action
+	}
+	return items
+}
+
+func (m Model) updatePaletteModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.closeModal()
+		return m, nil
+	case "enter":
+		if m.paletteCursor >= 0 && m.paletteCursor < len(m.paletteItems) {
+			a := m.paletteItems[m.paletteCursor]
+			m.closeModal()
+			cmd := m.runAction(a)
+			return m, cmd
+		}
+		return m, nil
+	case "up", "ctrl+p", "shift+tab":
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "tab":
+		if m.paletteCursor < len(m.paletteItems)-1 {
+			m.paletteCursor++
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.formFields[0].input, cmd = m.formFields[0].input.Update(key)
+	m.paletteItems = m.paletteMatches(m.formFields[0].input.Value())
+	m.paletteCursor = 0
+	return m, cmd
+}
+
+// paletteLabels returns the rows of the palette, name and keys, and the
+// group of each: the active tab's name or General.
+func (m Model) paletteLabels() ([]string, []string) {
+	tabName := ""
+	for _, item := range m.tabItems() {
+		if item.ID == m.activeTab {
+			tabName = item.Label
+		}
+	}
+	labels := make([]string, len(m.paletteItems))
+	groups := make([]string, len(m.paletteItems))
+	for i, a := range m.paletteItems {
+		labels[i] = fmt.Sprintf("%-44s %s", a.name, keysLabel(m.reachableKeys(a, m.activeTab)))
+		groups[i] = "General"
+		if a.tabs != nil {
+			groups[i] = tabName
+		}
+	}
+	return labels, groups
+}
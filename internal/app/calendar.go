@@ -0,0 +1,257 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// monthStart returns the first day of t's month.
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// calendarRange is the span of the month grid around the selected day:
+// whole weeks, Monday first, from the week of the 1st to that of the last.
+func (m Model) calendarRange() (time.Time, time.Time) {
+	first := monthStart(m.calendarDay)
+	start := weekStartOf(first)
+	end := weekStartOf(first.AddDate(0, 1, -1)).AddDate(0, 0, 7)
+	return start, end
+}
+
+// calendarEntries lists the exams, retakes, project deadlines and
+// milestones, and todos (recurring ones by occurrence) between from and to,
+// in date order. The subject filter applies as on the other tabs.
+func (m Model) calendarEntries(from, to time.Time) []models.CalendarEntry {
+	now := time.Now()
+	shown := func(subject string) bool {
+		return len(m.subjectFilters) == 0 || m.isFiltered(subject)
+	}
+	in := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+	var entries []models.CalendarEntry
+	add := func(stored string, e models.CalendarEntry) {
+		if in(e.Date) {
+			e.HasTime = !dates.IsDay(stored)
+			entries = append(entries, e)
+		}
+	}
+
+	for _, subject := range m.subjects {
+		if !shown(subject.Code) {
+			continue
+		}
+		for _, exam := range subject.Exams {
+			if date, ok := dates.Parse(exam.Date); ok {
+				add(exam.Date, models.CalendarEntry{
+					Kind:        models.CalendarExam,
+					Date:        date,
+					Title:       exam.Name,
+					SubjectCode: subject.Code,
+					Priority:    m.priorityRules.Resolve(exam.Priority, date, true, now),
+					ID:          exam.ID,
+				})
+			}
+			for _, retake := range exam.Retakes {
+				if date, ok := dates.Parse(retake); ok {
+					add(retake, models.CalendarEntry{
+						Kind:        models.CalendarRetake,
+						Date:        date,
+						Title:       exam.Name,
+						SubjectCode: subject.Code,
+						Priority:    m.priorityRules.Resolve(exam.Priority, date, true, now),
+						ID:          exam.ID,
+					})
+				}
+			}
+		}
+	}
+
+	for _, project := range m.projects {
+		if !shown(project.Subject) {
+			continue
+		}
+		if date, ok := dates.Parse(project.Due); ok {
+			add(project.Due, models.CalendarEntry{
+				Kind:        models.CalendarProject,
+				Date:        date,
+				Title:       project.Name,
+				SubjectCode: project.Subject,
+				Done:        strings.EqualFold(project.Status, "DONE"),
+				ID:          project.ID,
+			})
+		}
+		for _, ms := range project.Milestones {
+			if date, ok := dates.Parse(ms.Date); ok {
+				add(ms.Date, models.CalendarEntry{
+					Kind:        models.CalendarMilestone,
+					Date:        date,
+					Title:       project.Name + ": " + ms.Name,
+					SubjectCode: project.Subject,
+					Done:        ms.Done,
+					ID:          ms.ID,
+				})
+			}
+		}
+	}
+
+	for _, item := range m.checklistItems {
+		if !shown(item.Subject) {
+			continue
+		}
+		if _, _, ok := todoRecurrence(item); ok {
+			for _, day := range todoOccurrences(item, to) {
+				occ := todoOccurrence(item, day)
+				if date, ok := dates.Parse(occ.Due); ok {
+					add(occ.Due, models.CalendarEntry{
+						Kind:        models.CalendarTodo,
+						Date:        date,
+						Title:       item.Text,
+						SubjectCode: item.Subject,
+						Done:        occ.Done,
+						ID:          item.ID,
+						Day:         day,
+					})
+				}
+			}
+			continue
+		}
+		if date, ok := dates.Parse(item.Due); ok {
+			add(item.Due, models.CalendarEntry{
+				Kind:        models.CalendarTodo,
+				Date:        date,
+				Title:       item.Text,
+				SubjectCode: item.Subject,
+				Done:        item.Done,
+				ID:          item.ID,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entries[i].Kind < entries[j].Kind
+	})
+	return entries
+}
+
+// agendaEntries lists what happens on the selected day.
+func (m Model) agendaEntries() []models.CalendarEntry {
+	return m.calendarEntries(m.calendarDay, m.calendarDay.AddDate(0, 0, 1))
+}
+
+func (m *Model) moveCalendarDay(days int) {
+	m.calendarDay = m.calendarDay.AddDate(0, 0, days)
+	m.agendaCursor = 0
+}
+
+// moveCalendarMonth keeps the day of the month where it can, landing on the
+// last day of shorter months.
+func (m *Model) moveCalendarMonth(months int) {
+	first := monthStart(m.calendarDay).AddDate(0, months, 0)
+	day := m.calendarDay.Day()
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	m.calendarDay = first.AddDate(0, 0, day-1)
+	m.agendaCursor = 0
+}
+
+func (m *Model) moveAgendaCursor(delta int) {
+	n := len(m.agendaEntries())
+	m.agendaCursor += delta
+	if m.agendaCursor >= n {
+		m.agendaCursor = n - 1
+	}
+	if m.agendaCursor < 0 {
+		m.agendaCursor = 0
+	}
+}
+
+// moveCalendarVertical moves the selected day by weeks, or the agenda
+// cursor while the agenda is open.
+func (m *Model) moveCalendarVertical(delta int) {
+	if m.agendaOpen {
+		m.moveAgendaCursor(delta)
+	} else {
+		m.moveCalendarDay(delta * 7)
+	}
+}
+
+func (m *Model) calendarToday() {
+	m.calendarDay = dayOf(time.Now())
+	m.agendaCursor = 0
+}
+
+func (m *Model) openAgenda() {
+	m.agendaOpen = true
+	m.agendaCursor = 0
+}
+
+// The add forms of the Calendar tab pre-fill the selected day.
+
+func (m *Model) openAddExamOnDay() {
+	m.openAddExamWithFilter()
+	m.formFields[2].input.SetValue(m.dateFormat.Show(m.calendarDay))
+}
+
+func (m *Model) openAddProjectOnDay() {
+	m.openAddProject()
+	m.formFields[3].input.SetValue(m.dateFormat.Show(m.calendarDay))
+}
+
+func (m *Model) openAddTodoOnDay() {
+	m.openAddTodo()
+	m.formFields[2].input.SetValue(m.dateFormat.Show(m.calendarDay))
+}
+
+// openEditCalendarEntry opens the edit form of the agenda entry under the
+// cursor. A recurring todo's occurrence asks which occurrences to change,
+// as on the Todos tab.
+func (m *Model) openEditCalendarEntry() {
+	entries := m.agendaEntries()
+	if m.agendaCursor < 0 || m.agendaCursor >= len(entries) {
+		return
+	}
+	e := entries[m.agendaCursor]
+	switch e.Kind {
+	case models.CalendarExam, models.CalendarRetake:
+		si, ei := findExamByID(m.subjects, e.ID)
+		if si < 0 {
+			return
+		}
+		m.openEditExamItem(models.FlatExam{
+			SubjectCode: m.subjects[si].Code,
+			SubjectIdx:  si,
+			ExamIdx:     ei,
+			Exam:        m.subjects[si].Exams[ei],
+		})
+	case models.CalendarProject:
+		if pi := findProjectByID(m.projects, e.ID); pi >= 0 {
+			m.projectCursor = pi
+			m.openEditProject()
+		}
+	case models.CalendarMilestone:
+		for pi, project := range m.projects {
+			if mi := findMilestoneIndex(project.Milestones, e.ID); mi >= 0 {
+				m.projectCursor = pi
+				m.planCursor = len(project.Subtasks) + mi
+				m.openEditPlanItem()
+				return
+			}
+		}
+	case models.CalendarTodo:
+		if ti := findTodoByID(m.checklistItems, e.ID); ti >= 0 {
+			m.checklistCursor = ti
+			m.checklistDate = e.Day
+			m.openEditTodo()
+		}
+	}
+}
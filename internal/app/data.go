@@ -6,7 +6,7 @@ import (
 )
 
 func DefaultData() storage.SemesterData {
-	return storage.SemesterData{
+	data := storage.SemesterData{
 		Subjects: []models.SubjectItem{
 			{
 				Code: "PHY150",
@@ -65,29 +65,42 @@ func DefaultData() storage.SemesterData {
 			{Code: "MATH201", Name: "Calculus II"},
 		},
 		Projects: []models.ProjectItem{
-			{Name: "Database Design Project", Subject: "CS101", Due: "May 20, 2025", Status: "IN PROGRESS"},
+			{
+				Name: "Database Design Project", Subject: "CS101", Start: "Apr 21, 2025", Due: "May 20, 2025", Status: "IN PROGRESS", AutoStatus: true,
+				Subtasks: []models.Subtask{
+					{Text: "ER diagram", Done: true},
+					{Text: "Normalize schema", Done: true},
+					{Text: "Write queries"},
+					{Text: "Final report"},
+				},
+				Milestones: []models.Milestone{
+					{Name: "Schema review", Date: "May 6, 2025", Done: true},
+					{Name: "Demo", Date: "May 18, 2025"},
+				},
+			},
 			{Name: "Renaissance Art Essay", Subject: "HIST210", Due: "May 28, 2025", Status: "NOT STARTED"},
-			{Name: "Physics Lab Simulation", Subject: "PHY150", Due: "Jun 3, 2025", Status: "IN PROGRESS"},
-			{Name: "Calculus Portfolio", Subject: "MATH201", Due: "Jun 10, 2025", Status: "NOT STARTED"},
+			{Name: "Physics Lab Simulation", Subject: "PHY150", Start: "May 12, 2025", Due: "Jun 3, 2025", Status: "IN PROGRESS"},
+			{Name: "Calculus Portfolio", Subject: "MATH201", Start: "May 26, 2025", Due: "Jun 10, 2025", Status: "NOT STARTED"},
 			{Name: "English Literature Review", Subject: "ENG102", Due: "Jun 15, 2025", Status: "DONE"},
 			{Name: "Machine Learning Assignment", Subject: "CS101", Due: "Jun 18, 2025", Status: "NOT STARTED"},
 		},
 		Checklist: []models.ChecklistItem{
 			{Text: "Create flashcards for history", Done: true, Subject: "HIST210"},
 			{Text: "Attend study group session", Done: true},
-			{Text: "Mock exam practice", Done: false, Subject: "CS101"},
-			{Text: "Summarize key formulas", Done: false, Subject: "MATH201"},
-			{Text: "Review lectures 8-10", Done: false, Subject: "CS101"},
+			{Text: "Mock exam practice", Done: false, Due: "2026-01-13", Subject: "CS101"},
+			{Text: "Summarize key formulas", Done: false, Due: "2026-01-14", Subject: "MATH201"},
+			{Text: "Review lectures 8-10", Done: false, Due: "2026-01-12 18:00", Subject: "CS101"},
 			{Text: "Complete problem set 5", Done: true, Subject: "MATH201"},
 			{Text: "Read chapter 12-13", Done: false, Subject: "PHY150"},
 			{Text: "Outline essay", Done: false, Subject: "HIST210"},
 			{Text: "Practice pronunciation", Done: true, Subject: "ENG102"},
 			{Text: "Work on database project", Done: false, Subject: "CS101"},
-			{Text: "Group meeting for CS project", Done: false, Subject: "CS101"},
-			{Text: "Submit lab report", Done: true, Subject: "PHY150"},
+			{Text: "Group meeting for CS project", Done: false, Due: "2026-01-15 14:00", Subject: "CS101"},
+			{Text: "Submit lab report", Done: true, Due: "2026-01-12", Subject: "PHY150"},
 			{Text: "Study calculus theorems", Done: false, Subject: "MATH201"},
 			{Text: "Review weak areas", Done: false, Subject: "PHY150"},
 			{Text: "Prepare oral exam notes", Done: false, Subject: "HIST210"},
+			{Text: "Weekly problem sheet", Due: "2026-01-09 12:00", Subject: "MATH201", Repeat: "FREQ=WEEKLY;COUNT=12", DoneDates: []string{"2026-01-09"}},
 		},
 		WeeklyExams: []string{},
 		ConfirmOn:   true,
@@ -96,4 +109,7 @@ func DefaultData() storage.SemesterData {
 		LofiEnabled: false,
 		LofiURL:     "",
 	}
+	// The sample dates above are written the way they are typed.
+	storage.NormalizeDates(&data)
+	return data
 }
@@ -0,0 +1,442 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// dataCounts summarises how many items a SemesterData holds (or would gain
+// from a merge), for the import preview. A merge also lists the items and
+// counts those it skipped as already present.
+type dataCounts struct {
+	subjects   int
+	exams      int
+	projects   int
+	todos      int
+	items      []importItem
+	duplicates int
+}
+
+// importItem names an item of an import in the preview: a subject by code
+// and name, the rest by subject, name and date.
+type importItem struct {
+	kind    string
+	subject string
+	name    string
+	date    string
+}
+
+// importPreviewLimit is how many items the import preview lists for each
+// choice before summing up the rest.
+const importPreviewLimit = 6
+
+func (c dataCounts) String() string {
+	return strings.Join([]string{
+		plural(c.subjects, "subject"),
+		plural(c.exams, "exam"),
+		plural(c.projects, "project"),
+		plural(c.todos, "todo"),
+	}, " · ")
+}
+
+// plural renders a count with its noun, e.g. "1 exam" or "2 exams".
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+func countData(data storage.SemesterData) dataCounts {
+	c := dataCounts{
+		subjects: len(data.Subjects),
+		projects: len(data.Projects),
+		todos:    len(data.Checklist),
+	}
+	for _, s := range data.Subjects {
+		c.exams += len(s.Exams)
+	}
+	return c
+}
+
+func (m *Model) openExportData() {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Format", inputWidth, true),
+		newFormField("Path", inputWidth, true),
+	}
+	fields[0].input.SetValue("json")
+	fields[0].input.Placeholder = "json / ics"
+	fields[1].input.SetValue(defaultExportPath())
+	m.openFormModal(modalExportData, "Export Data", fields)
+}
+
+// submitExport writes the export in the chosen format. A path ending in .ics
+// always exports a calendar; choosing ics with the default .json path swaps
+// the extension.
+func (m *Model) submitExport(format, path string) error {
+	format = strings.ToLower(strings.TrimSpace(format))
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return fmt.Errorf("Path is required.")
+	}
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		format = "ics"
+	}
+	switch format {
+	case "", "json":
+		return m.exportDataTo(path)
+	case "ics", "ical", "icalendar":
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			path = strings.TrimSuffix(path, filepath.Ext(path)) + ".ics"
+		}
+		return m.exportCalendarTo(path)
+	}
+	return fmt.Errorf("Format must be json or ics.")
+}
+
+func (m *Model) openImportData() {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Path", inputWidth, true),
+		newFormField("Subject regex", inputWidth, false),
+	}
+	fields[0].input.Placeholder = "~/seman-export.json or calendar.ics"
+	pattern := m.icsPattern
+	if pattern == "" {
+		pattern = DefaultICSSubjectPattern
+	}
+	fields[1].input.SetValue(pattern)
+	fields[1].input.Placeholder = "for .ics files"
+	m.openFormModal(modalImportData, "Import Data", fields)
+}
+
+// exportDataTo writes the current data to path as JSON.
+func (m *Model) exportDataTo(path string) error {
+	path = expandPath(path)
+	if err := storage.WriteFile(path, m.exportData()); err != nil {
+		return fmt.Errorf("Export failed: %v", err)
+	}
+	m.notice = "Exported data to " + path
+	return nil
+}
+
+// exportCalendarTo writes exams, retakes, deadlines and todos to path as an
+// iCalendar file.
+func (m *Model) exportCalendarTo(path string) error {
+	path = expandPath(path)
+	if err := WriteICS(path, m.exportData(), time.Now()); err != nil {
+		return fmt.Errorf("Export failed: %v", err)
+	}
+	m.notice = "Exported calendar to " + path
+	return nil
+}
+
+// previewImport reads path and switches to the import preview modal.
+func (m *Model) previewImport(path string) error {
+	path = expandPath(path)
+	data, err := storage.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Cannot read %s: %v", filepath.Base(path), err)
+	}
+	m.openImportPreview(path, data)
+	return nil
+}
+
+func (m *Model) openImportPreview(source string, data storage.SemesterData) {
+	current := m.exportData()
+	_, added := mergeSemesterData(current, data)
+	_, dropped := mergeSemesterData(data, current)
+	_, incoming := mergeSemesterData(storage.SemesterData{}, data)
+
+	lines := []string{
+		"Source:  " + filepath.Base(source),
+		"",
+		"Current: " + countData(current).String(),
+		"File:    " + countData(data).String(),
+		"",
+		"[R] Replace — current items are discarded",
+	}
+	lines = append(lines, m.importItemLines("-", dropped.items)...)
+	lines = append(lines, m.importItemLines("+", incoming.items)...)
+	lines = append(lines, "", fmt.Sprintf("[M] Merge — adds %s", added))
+	lines = append(lines, m.importItemLines("+", added.items)...)
+	if added.duplicates > 0 {
+		lines = append(lines, fmt.Sprintf("          (%s skipped)", plural(added.duplicates, "duplicate")))
+	}
+
+	m.closeModal()
+	m.importData = data
+	m.importSource = source
+	m.modal = modalImportPreview
+	m.modalTitle = "Import Preview"
+	m.modalHint = "[R] Replace  [M] Merge  [Esc] Cancel"
+	m.modalError = strings.Join(lines, "\n")
+}
+
+// importItemLines lists items for the import preview, each after mark (+
+// for items added, - for items discarded), up to importPreviewLimit.
+func (m Model) importItemLines(mark string, items []importItem) []string {
+	var lines []string
+	for i, item := range items {
+		if i == importPreviewLimit {
+			lines = append(lines, fmt.Sprintf("  %s …and %d more", mark, len(items)-i))
+			break
+		}
+		line := fmt.Sprintf("  %s %-7s %-9s %s", mark, item.kind, item.subject, item.name)
+		if item.date != "" {
+			line += " · " + m.dateFormat.ShowStored(item.date)
+		}
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return lines
+}
+
+func (m Model) updateImportPreviewModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "n", "N":
+		m.closeModal()
+	case "r", "R":
+		m.applyImport(false)
+		m.closeModal()
+	case "m", "M":
+		m.applyImport(true)
+		m.closeModal()
+	}
+	return m, nil
+}
+
+// applyImport replaces or merges the pending import into the current data.
+// Settings such as theme and week span are never taken from the file.
+func (m *Model) applyImport(merge bool) {
+	data := m.importData
+	if merge {
+		data, _ = mergeSemesterData(m.exportData(), m.importData)
+	}
+	m.subjects = data.Subjects
+	m.projects = data.Projects
+	m.checklistItems = data.Checklist
+	m.weeklyExams = data.WeeklyExams
+	m.clampCursors()
+	m.sortExamsByPriority()
+	m.sortProjectsByStatus()
+	m.sortChecklistByDone()
+	m.refreshAllFilters()
+	verb := "Replaced data with"
+	if merge {
+		verb = "Merged"
+	}
+	label := verb + " " + filepath.Base(m.importSource)
+	m.changeLabel = label
+	if m.persist() {
+		m.notice = label + "  " + m.actionHint("undo", "Undo")
+	}
+}
+
+func (m *Model) createBackup() {
+	b, ok := m.store.(storage.Backuper)
+	if !ok {
+		m.notice = "Backups are not supported by this storage backend."
+		return
+	}
+	path, err := b.Backup(m.exportData())
+	if err != nil {
+		m.notice = "Backup failed: " + err.Error()
+		return
+	}
+	m.notice = "Backup saved to " + path
+}
+
+func (m *Model) openRestoreBackup() {
+	b, ok := m.store.(storage.Backuper)
+	if !ok {
+		m.notice = "Backups are not supported by this storage backend."
+		return
+	}
+	backups, err := b.Backups()
+	if err != nil {
+		m.notice = "Cannot list backups: " + err.Error()
+		return
+	}
+	m.backups = backups
+	m.backupCursor = 0
+	m.modal = modalRestoreBackup
+	m.modalTitle = "Restore from Backup"
+	m.modalHint = "↑↓ navigate · Enter preview · Esc cancel"
+	m.modalError = ""
+	m.formFields = nil
+}
+
+func (m Model) updateBackupPickerModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.closeModal()
+	case "j", "down":
+		if m.backupCursor < len(m.backups)-1 {
+			m.backupCursor++
+		}
+	case "k", "up":
+		if m.backupCursor > 0 {
+			m.backupCursor--
+		}
+	case "enter":
+		if m.backupCursor < 0 || m.backupCursor >= len(m.backups) {
+			return m, nil
+		}
+		path := m.backups[m.backupCursor].Path
+		data, err := storage.ReadFile(path)
+		if err != nil {
+			m.modalError = "Cannot read backup: " + err.Error()
+			return m, nil
+		}
+		m.openImportPreview(path, data)
+	}
+	return m, nil
+}
+
+func (m Model) backupLabels() []string {
+	labels := make([]string, len(m.backups))
+	for i, b := range m.backups {
+		labels[i] = b.Created.Format("Jan 2, 2006 @ 15:04:05") + "  " + filepath.Base(b.Path)
+	}
+	return labels
+}
+
+// mergeSemesterData adds items from incoming that are not already present in
+// base. Items match by ID or, for data from elsewhere, by content: subjects
+// by code, exams by name and date within a subject, projects by name,
+// subject and deadline, and todos by text, subject and due date; a subject
+// that is already present takes in the new exams. It returns the merged data
+// and the counts and list of what was added.
+func mergeSemesterData(base, incoming storage.SemesterData) (storage.SemesterData, dataCounts) {
+	var added dataCounts
+	out := base
+
+	out.Subjects = make([]models.SubjectItem, len(base.Subjects))
+	for i, s := range base.Subjects {
+		s.Exams = append([]models.ExamItem(nil), s.Exams...)
+		out.Subjects[i] = s
+	}
+	for _, in := range incoming.Subjects {
+		idx := findSubjectIndex(out.Subjects, in.Code)
+		if idx < 0 {
+			out.Subjects = append(out.Subjects, in)
+			added.subjects++
+			added.exams += len(in.Exams)
+			name := in.Name
+			if n := len(in.Exams); n > 0 {
+				name += " (" + plural(n, "exam") + ")"
+			}
+			added.items = append(added.items, importItem{kind: "subject", subject: in.Code, name: name})
+			continue
+		}
+		for _, exam := range in.Exams {
+			if hasExam(out.Subjects[idx].Exams, exam) {
+				added.duplicates++
+				continue
+			}
+			out.Subjects[idx].Exams = append(out.Subjects[idx].Exams, exam)
+			added.exams++
+			added.items = append(added.items, importItem{kind: "exam", subject: in.Code, name: exam.Name, date: exam.Date})
+		}
+	}
+
+	out.Projects = append([]models.ProjectItem(nil), base.Projects...)
+	for _, p := range incoming.Projects {
+		if hasProject(out.Projects, p) {
+			added.duplicates++
+			continue
+		}
+		out.Projects = append(out.Projects, p)
+		added.projects++
+		added.items = append(added.items, importItem{kind: "project", subject: p.Subject, name: p.Name, date: p.Due})
+	}
+
+	out.Checklist = append([]models.ChecklistItem(nil), base.Checklist...)
+	for _, item := range incoming.Checklist {
+		if hasTodo(out.Checklist, item) {
+			added.duplicates++
+			continue
+		}
+		out.Checklist = append(out.Checklist, item)
+		added.todos++
+		added.items = append(added.items, importItem{kind: "todo", subject: item.Subject, name: item.Text, date: item.Due})
+	}
+
+	out.WeeklyExams = append([]string(nil), base.WeeklyExams...)
+	for _, w := range incoming.WeeklyExams {
+		if !containsString(out.WeeklyExams, w) {
+			out.WeeklyExams = append(out.WeeklyExams, w)
+		}
+	}
+	storage.EnsureIDs(&out)
+	return out, added
+}
+
+func sameID(a, b string) bool {
+	return a != "" && a == b
+}
+
+func hasExam(items []models.ExamItem, exam models.ExamItem) bool {
+	for _, e := range items {
+		if sameID(e.ID, exam.ID) || strings.EqualFold(e.Name, exam.Name) && e.Date == exam.Date {
+			return true
+		}
+	}
+	return false
+}
+
+func hasProject(items []models.ProjectItem, project models.ProjectItem) bool {
+	for _, p := range items {
+		if sameID(p.ID, project.ID) ||
+			strings.EqualFold(p.Name, project.Name) && strings.EqualFold(p.Subject, project.Subject) && p.Due == project.Due {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTodo(items []models.ChecklistItem, item models.ChecklistItem) bool {
+	for _, c := range items {
+		if sameID(c.ID, item.ID) || c.Text == item.Text && strings.EqualFold(c.Subject, item.Subject) && c.Due == item.Due {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(items []string, value string) bool {
+	for _, item := range items {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultExportPath() string {
+	name := "seman-export-" + time.Now().Format("2006-01-02") + ".json"
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(home, name)
+}
+
+// expandPath resolves a leading ~ to the user's home directory.
+func expandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+	}
+	return path
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+func TestImportPreviewListsItems(t *testing.T) {
+	m := newTestModel(t, persistTestData())
+	m.dateFormat = dates.FormatOf("iso")
+	file := storage.SemesterData{
+		Subjects: []models.SubjectItem{
+			{ID: "s1", Code: "DB", Name: "Databases", Exams: []models.ExamItem{{ID: "e1", Name: "Final", Date: "2026-01-13"}}},
+			{ID: "s2", Code: "OS", Name: "Operating Systems", Exams: []models.ExamItem{{ID: "e2", Name: "Midterm"}}},
+		},
+		Projects:  []models.ProjectItem{{ID: "p1", Name: "Paper", Subject: "OS", Due: "2026-02-01"}},
+		Checklist: []models.ChecklistItem{{ID: "t1", Text: "Read", Due: "2026-10-18"}, {ID: "t2", Text: "Write"}},
+	}
+	m.openImportPreview("/tmp/export.json", file)
+
+	want := strings.Join([]string{
+		"Current: 1 subject · 0 exams · 0 projects · 1 todo",
+		"File:    2 subjects · 2 exams · 1 project · 2 todos",
+		"",
+		"[R] Replace — current items are discarded",
+		"  + subject DB        Databases (1 exam)",
+		"  + subject OS        Operating Systems (1 exam)",
+		"  + project OS        Paper · 2026-02-01",
+		"  + todo              Read · 2026-10-18",
+		"  + todo              Write",
+		"",
+		"[M] Merge — adds 1 subject · 2 exams · 1 project · 1 todo",
+		"  + exam    DB        Final · 2026-01-13",
+		"  + subject OS        Operating Systems (1 exam)",
+		"  + project OS        Paper · 2026-02-01",
+		"  + todo              Write",
+		// The todo t1; DB is merged into, not skipped.
+		"          (1 duplicate skipped)",
+	}, "\n")
+	if !strings.HasSuffix(m.modalError, want) {
+		t.Errorf("preview =\n%s\nwant it to end with\n%s", m.modalError, want)
+	}
+
+	// Replacing with a file that lacks the current items lists them.
+	m.openImportPreview("/tmp/other.json", storage.SemesterData{
+		Checklist: []models.ChecklistItem{{ID: "t3", Text: "Other"}},
+	})
+	for _, line := range []string{
+		"  - subject DB        Databases",
+		"  - todo              Read · 2026-10-18",
+		"  + todo              Other",
+	} {
+		if !strings.Contains(m.modalError, line+"\n") {
+			t.Errorf("preview =\n%s\nwant the line %q", m.modalError, line)
+		}
+	}
+}
+
+func TestImportPreviewLimit(t *testing.T) {
+	m := newTestModel(t, persistTestData())
+	var file storage.SemesterData
+	for i := 1; i <= importPreviewLimit+3; i++ {
+		file.Checklist = append(file.Checklist, models.ChecklistItem{Text: fmt.Sprintf("Task %d", i)})
+	}
+	m.openImportPreview("/tmp/export.json", file)
+	lines := strings.Split(m.modalError, "\n")
+	merge := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "[M] Merge") {
+			merge = i
+		}
+	}
+	if merge < 0 || len(lines) < merge+importPreviewLimit+2 {
+		t.Fatalf("preview =\n%s", m.modalError)
+	}
+	if got := lines[merge+importPreviewLimit]; !strings.HasSuffix(got, "Task 6") {
+		t.Errorf("last listed item = %q, want Task 6", got)
+	}
+	if got, want := lines[merge+importPreviewLimit+1], "  + …and 3 more"; got != want {
+		t.Errorf("summary = %q, want %q", got, want)
+	}
+}
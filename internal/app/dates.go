@@ -5,8 +5,42 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
 )
 
+// DateContext is what relative dates typed into a form or flag ("tomorrow",
+// "fri", "end of week") are resolved against: the current time and the
+// first day of the week on screen. A zero Week means the current week.
+type DateContext struct {
+	Now  time.Time
+	Week time.Time
+}
+
+// Today is the context for dates given outside the planner's week view.
+func Today() DateContext {
+	return DateContext{Now: time.Now()}
+}
+
+// reference is the day weekday names count from: today while the current
+// week is shown, else the Monday of the week on screen.
+func (dc DateContext) reference() time.Time {
+	today := dayOf(dc.Now)
+	if dc.Week.IsZero() {
+		return today
+	}
+	week := weekStartOf(dc.Week)
+	if week.Equal(weekStartOf(today)) {
+		return today
+	}
+	return week
+}
+
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
 func weekStartOf(t time.Time) time.Time {
 	year, month, day := t.Date()
 	start := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
@@ -22,50 +56,243 @@ func weekLabel(start time.Time, span int) string {
 		return "All Weeks"
 	}
 	if span <= 1 {
-		weekNum, _ := start.ISOWeek()
+		_, weekNum := start.ISOWeek()
 		end := start.AddDate(0, 0, 6)
 		return "Week " + strconv.Itoa(weekNum) + " - " + start.Format("Jan 2") + " - " + end.Format("Jan 2, 2006")
 	}
 	end := start.AddDate(0, 0, span*7-1)
-	startWeek, _ := start.ISOWeek()
-	endWeek, _ := end.ISOWeek()
+	_, startWeek := start.ISOWeek()
+	_, endWeek := end.ISOWeek()
 	return fmt.Sprintf("Weeks %d-%d - %s - %s", startWeek, endWeek, start.Format("Jan 2"), end.Format("Jan 2, 2006"))
 }
 
-func parseExamDate(value string) (time.Time, bool) {
-	value = strings.TrimSpace(value)
-	if value == "" {
+// parseDateInput reads a date typed by the user: any layout dates.Parse
+// accepts, or a relative date resolved against dc (see parseRelativeDate).
+func parseDateInput(value string, dc DateContext) (time.Time, bool) {
+	if t, ok := dates.Parse(value); ok {
+		return t, true
+	}
+	return parseRelativeDate(value, dc)
+}
+
+// parseRelativeDate reads dates such as "today", "tomorrow 10:00", "fri",
+// "next fri", "this mon 14:30", "in 2 weeks", "+3d", "-1w", "next week",
+// "end of week" and "end of month", optionally followed by a time ("9:30",
+// "@ 14:00", "at 3pm"). A time alone means today. Weekday names count from
+// the reference day of dc; everything else from today.
+func parseRelativeDate(value string, dc DateContext) (time.Time, bool) {
+	words := strings.Fields(strings.NewReplacer("@", " ", ",", " ").Replace(strings.ToLower(value)))
+	var kept []string
+	for i, w := range words {
+		if w == "at" || w == "the" {
+			continue
+		}
+		if _, err := strconv.Atoi(w); err == nil && i > 0 && words[i-1] == "at" {
+			w += ":00" // "at 10"
+		}
+		kept = append(kept, w)
+	}
+	words = kept
+	if len(words) == 0 {
 		return time.Time{}, false
 	}
-	layouts := []string{
-		"Jan 2, 2006 @ 15:04",
-		"Jan 2, 2006",
-		"2006-01-02 15:04",
-		"2006-01-02",
-		"2.1.2006 15:04",
-		"2.1.2006",
-		"02.01.2006 15:04",
-		"02.01.2006",
-		"2/1/2006 15:04",
-		"2/1/2006",
-		"02/01/2006 15:04",
-		"02/01/2006",
-	}
-	for _, layout := range layouts {
-		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
-			return t, true
+
+	hour, minute, timed := 0, 0, false
+	if n := len(words); n >= 2 && (words[n-1] == "am" || words[n-1] == "pm") {
+		words = append(words[:n-2], words[n-2]+words[n-1])
+	}
+	if h, m, ok := parseClockWord(words[len(words)-1]); ok {
+		hour, minute, timed = h, m, true
+		words = words[:len(words)-1]
+	} else if h, m, ok := parseClockWord(words[0]); ok && len(words) > 1 {
+		hour, minute, timed = h, m, true
+		words = words[1:]
+	}
+
+	today := dayOf(dc.Now)
+	ref := dc.reference()
+	var day time.Time
+	phrase := strings.Join(words, " ")
+	switch phrase {
+	case "":
+		if !timed {
+			return time.Time{}, false
+		}
+		day = today
+	case "today", "tod", "now":
+		day = today
+	case "tomorrow", "tmr", "tmrw", "tom":
+		day = today.AddDate(0, 0, 1)
+	case "yesterday":
+		day = today.AddDate(0, 0, -1)
+	case "next week":
+		day = weekStartOf(ref).AddDate(0, 0, 7)
+	case "end of week", "eow", "end of this week":
+		day = weekStartOf(ref).AddDate(0, 0, 6)
+	case "end of next week":
+		day = weekStartOf(ref).AddDate(0, 0, 13)
+	case "next month":
+		day = time.Date(ref.Year(), ref.Month()+1, 1, 0, 0, 0, 0, ref.Location())
+	case "end of month", "eom", "end of this month":
+		day = time.Date(ref.Year(), ref.Month()+1, 0, 0, 0, 0, 0, ref.Location())
+	default:
+		var ok bool
+		if day, ok = parseOffsetPhrase(words, today); !ok {
+			if day, ok = parseWeekdayPhrase(words, ref); !ok {
+				return time.Time{}, false
+			}
 		}
 	}
-	return time.Time{}, false
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location()), true
 }
 
-func parseTodoDate(value string) (time.Time, bool) {
-	value = strings.TrimSpace(value)
-	if value == "" {
+// parseOffsetPhrase reads "+3d", "-1w", "+2m", "in 3 days", "in a week" and
+// "in 2 months" as an offset from today.
+func parseOffsetPhrase(words []string, today time.Time) (time.Time, bool) {
+	var amount, unit string
+	switch {
+	case len(words) == 1 && (words[0][0] == '+' || words[0][0] == '-'):
+		i := 1
+		for i < len(words[0]) && words[0][i] >= '0' && words[0][i] <= '9' {
+			i++
+		}
+		amount, unit = words[0][:i], words[0][i:]
+	case len(words) == 2 && (words[0][0] == '+' || words[0][0] == '-'):
+		amount, unit = words[0], words[1]
+	case len(words) == 3 && words[0] == "in":
+		amount, unit = words[1], words[2]
+		if amount == "a" || amount == "one" {
+			amount = "1"
+		}
+	default:
 		return time.Time{}, false
 	}
-	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
-		return t, true
+	n, err := strconv.Atoi(strings.TrimPrefix(amount, "+"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	switch strings.TrimSuffix(unit, "s") {
+	case "d", "day":
+		return today.AddDate(0, 0, n), true
+	case "w", "week":
+		return today.AddDate(0, 0, 7*n), true
+	case "m", "month":
+		return today.AddDate(0, n, 0), true
 	}
 	return time.Time{}, false
 }
+
+// parseWeekdayPhrase reads "fri" (the first Friday from ref on), "this fri"
+// (the Friday of ref's week) and "next fri" (the Friday of the week after).
+func parseWeekdayPhrase(words []string, ref time.Time) (time.Time, bool) {
+	which := ""
+	if len(words) == 2 && (words[0] == "this" || words[0] == "next") {
+		which, words = words[0], words[1:]
+	}
+	if len(words) != 1 || len(words[0]) < 2 {
+		return time.Time{}, false
+	}
+	name, err := parseWeekday(words[0])
+	if err != nil {
+		return time.Time{}, false
+	}
+	offset := indexOfString(models.Weekdays, name)
+	week := weekStartOf(ref)
+	switch which {
+	case "this":
+		return week.AddDate(0, 0, offset), true
+	case "next":
+		return week.AddDate(0, 0, 7+offset), true
+	}
+	day := week.AddDate(0, 0, offset)
+	if day.Before(ref) {
+		day = day.AddDate(0, 0, 7)
+	}
+	return day, true
+}
+
+// parseClockWord reads a time of day: 14:30, 9.30, 9am, 3pm, 12:15pm or
+// noon. A bare number is not a time, so "in 2 weeks" keeps its 2.
+func parseClockWord(word string) (int, int, bool) {
+	if word == "noon" {
+		return 12, 0, true
+	}
+	suffix := ""
+	if strings.HasSuffix(word, "am") || strings.HasSuffix(word, "pm") {
+		suffix, word = word[len(word)-2:], word[:len(word)-2]
+	}
+	if suffix == "" && !strings.ContainsAny(word, ":.") {
+		return 0, 0, false
+	}
+	clock, ok := parseClockTime(word)
+	if !ok {
+		return 0, 0, false
+	}
+	h, _ := strconv.Atoi(clock[:2])
+	m, _ := strconv.Atoi(clock[3:])
+	if suffix != "" {
+		if h < 1 || h > 12 {
+			return 0, 0, false
+		}
+		h %= 12
+		if suffix == "pm" {
+			h += 12
+		}
+	}
+	return h, m, true
+}
+
+// inputIsDay reports whether t, read by parseDateInput from value, is a date
+// without a time. Values in a stored layout say so themselves, so that
+// "Jan 5, 2026 @ 00:00" stays a deadline at midnight; relative dates have a
+// time if one other than midnight was given.
+func inputIsDay(value string, t time.Time) bool {
+	if _, ok := dates.Parse(value); ok {
+		return dates.IsDay(value)
+	}
+	return dates.AllDay(t)
+}
+
+// storeInput renders t, read by parseDateInput from value, the way dates are
+// stored.
+func storeInput(value string, t time.Time) string {
+	if inputIsDay(value, t) {
+		return dates.StoreDay(t)
+	}
+	return dates.StoreTime(t)
+}
+
+// keepStored returns old, a stored date, if updated is the same date read
+// back from an edit form, so that editing another field of an item does not
+// move its date to the local offset.
+func keepStored(old, updated string) string {
+	a, okOld := dates.Parse(old)
+	b, okNew := dates.Parse(updated)
+	if okOld && okNew && a.Equal(b) && dates.IsDay(old) == dates.IsDay(updated) {
+		return old
+	}
+	return updated
+}
+
+// formatDatePreview is how the forms show the date t they read from value.
+func formatDatePreview(value string, t time.Time) string {
+	if inputIsDay(value, t) {
+		return t.Format("Mon Jan 2, 2006")
+	}
+	return t.Format("Mon Jan 2, 2006 15:04")
+}
+
+// splitDateList splits a comma-separated list of dates, keeping dates that
+// contain a comma themselves ("Jan 5, 2026") in one piece.
+func splitDateList(raw string) []string {
+	var out []string
+	for _, part := range splitCSV(strings.ReplaceAll(raw, ";", ",")) {
+		year, _, _ := strings.Cut(part, " ")
+		if _, err := strconv.Atoi(year); err == nil && len(year) == 4 && len(out) > 0 {
+			out[len(out)-1] += ", " + part
+			continue
+		}
+		out = append(out, part)
+	}
+	return out
+}
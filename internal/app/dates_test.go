@@ -0,0 +1,322 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+func TestStoreInput(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("CET", 60*60)
+	defer func() { time.Local = saved }()
+
+	dc := DateContext{Now: time.Date(2026, 10, 14, 9, 0, 0, 0, time.Local)}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"2026-10-20", "2026-10-20"},
+		{"Oct 20, 2026", "2026-10-20"},
+		{"Oct 20, 2026 @ 00:00", "2026-10-20T00:00:00+01:00"},
+		{"2026-10-20 14:30", "2026-10-20T14:30:00+01:00"},
+		{"2026-10-20T00:00:00-05:00", "2026-10-20T06:00:00+01:00"},
+		{"tomorrow", "2026-10-15"},
+		{"tomorrow 10:00", "2026-10-15T10:00:00+01:00"},
+		{"fri", "2026-10-16"},
+	}
+	for _, tt := range tests {
+		when, ok := parseDateInput(tt.value, dc)
+		if !ok {
+			t.Errorf("parseDateInput(%q) failed", tt.value)
+			continue
+		}
+		if got := storeInput(tt.value, when); got != tt.want {
+			t.Errorf("storeInput(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateExamKeepsStoredOffset(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("CET", 60*60)
+	defer func() { time.Local = saved }()
+
+	exam := models.ExamItem{ID: "e1", Name: "Final", Date: "2026-01-14T00:00:00+09:00", Retakes: []string{"2026-02-01T08:00:00Z"}}
+	df := dates.FormatOf("default")
+	dc := DateContext{Now: time.Date(2026, 1, 1, 9, 0, 0, 0, time.Local)}
+
+	// The form shows the dates in local time; saving it unchanged keeps them.
+	updated, err := UpdateExam(exam, models.PriorityRules{}, dc, "Final exam", df.ShowStored(exam.Date), df.ShowList(exam.Retakes), "", "")
+	if err != nil {
+		t.Fatalf("UpdateExam: %v", err)
+	}
+	if updated.Date != exam.Date || updated.Retakes[0] != exam.Retakes[0] {
+		t.Errorf("dates = %q, %q; want them unchanged", updated.Date, updated.Retakes)
+	}
+
+	// A changed date is stored in local time.
+	updated, err = UpdateExam(exam, models.PriorityRules{}, dc, "Final", "Jan 15, 2026 @ 10:00", "", "", "")
+	if err != nil {
+		t.Fatalf("UpdateExam: %v", err)
+	}
+	if updated.Date != "2026-01-15T10:00:00+01:00" {
+		t.Errorf("Date = %q, want 2026-01-15T10:00:00+01:00", updated.Date)
+	}
+}
+
+// clock reads "2026-10-14 09:00" or "2026-10-14" in the time zone of
+// withFixedZone.
+func clock(t *testing.T, value string) time.Time {
+	t.Helper()
+	when, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+	if err != nil {
+		when, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return when
+}
+
+// withFixedZone makes local time a fixed UTC+1 for the length of the test,
+// so that day arithmetic does not depend on where the tests run.
+func withFixedZone(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("CET", 60*60)
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func TestParseRelativeDate(t *testing.T) {
+	withFixedZone(t)
+	tests := []struct {
+		now   string // Wednesday 2026-10-14 09:00 when empty
+		week  string // the week on screen; empty: the current one
+		value string
+		want  string // empty: not a date
+	}{
+		{value: "today", want: "2026-10-14 00:00"},
+		{value: "Tomorrow", want: "2026-10-15 00:00"},
+		{value: "tmrw 10:00", want: "2026-10-15 10:00"},
+		{value: "tomorrow noon", want: "2026-10-15 12:00"},
+		{value: "tomorrow at 3pm", want: "2026-10-15 15:00"},
+		{value: "tomorrow 3 pm", want: "2026-10-15 15:00"},
+		{value: "tomorrow at 10", want: "2026-10-15 10:00"},
+		{value: "yesterday @ 9.30", want: "2026-10-13 09:30"},
+		{value: "14:30", want: "2026-10-14 14:30"},
+		{value: "noon", want: "2026-10-14 12:00"},
+		{value: "fri", want: "2026-10-16 00:00"},
+		{value: "wed", want: "2026-10-14 00:00"}, // today is Wednesday
+		{value: "mon", want: "2026-10-19 00:00"}, // Monday has passed
+		{value: "this mon 14:30", want: "2026-10-12 14:30"},
+		{value: "next fri 14:00", want: "2026-10-23 14:00"},
+		{value: "14:00 next fri", want: "2026-10-23 14:00"},
+		{value: "next fri, 2pm", want: "2026-10-23 14:00"},
+		{value: "in 3 days", want: "2026-10-17 00:00"},
+		{value: "in a week", want: "2026-10-21 00:00"},
+		{value: "in 2 weeks 9:00", want: "2026-10-28 09:00"},
+		{value: "+3d", want: "2026-10-17 00:00"},
+		{value: "-1w", want: "2026-10-07 00:00"},
+		{value: "+2 days", want: "2026-10-16 00:00"},
+		{value: "next week", want: "2026-10-19 00:00"},
+		{value: "end of week", want: "2026-10-18 00:00"},
+		{value: "end of next week", want: "2026-10-25 00:00"},
+		{value: "end of month", want: "2026-10-31 00:00"},
+		{value: "next month", want: "2026-11-01 00:00"},
+
+		// Month and year rollover.
+		{now: "2026-10-30 18:00", value: "tomorrow", want: "2026-10-31 00:00"},
+		{now: "2026-10-30 18:00", value: "in 3 days", want: "2026-11-02 00:00"},
+		{now: "2026-10-30 18:00", value: "next fri 14:00", want: "2026-11-06 14:00"},
+		{now: "2026-12-30 18:00", value: "fri", want: "2027-01-01 00:00"},
+		{now: "2026-12-30 18:00", value: "+1w", want: "2027-01-06 00:00"},
+		{now: "2026-12-30 18:00", value: "end of week", want: "2027-01-03 00:00"},
+		{now: "2026-12-30 18:00", value: "next month", want: "2027-01-01 00:00"},
+		{now: "2026-12-15 18:00", value: "+1m", want: "2027-01-15 00:00"},
+		{now: "2028-02-27 18:00", value: "in 2 days", want: "2028-02-29 00:00"},
+		{now: "2028-02-27 18:00", value: "end of month", want: "2028-02-29 00:00"},
+
+		// Weekday names count from the week on screen, everything else
+		// from today.
+		{week: "2026-11-02", value: "fri", want: "2026-11-06 00:00"},
+		{week: "2026-11-02", value: "next mon", want: "2026-11-09 00:00"},
+		{week: "2026-11-02", value: "end of week", want: "2026-11-08 00:00"},
+		{week: "2026-11-02", value: "tomorrow", want: "2026-10-15 00:00"},
+		{week: "2026-11-02", value: "in 3 days", want: "2026-10-17 00:00"},
+		{week: "2026-10-12", value: "mon", want: "2026-10-19 00:00"},
+
+		{value: ""},
+		{value: "at"},
+		{value: "someday"},
+		{value: "in 3"},
+		{value: "in three days"},
+		{value: "in 3 years"},
+		{value: "+3"},
+		{value: "3"},
+		{value: "next"},
+		{value: "next fri fri"},
+		{value: "t"},
+		{value: "tomorrow 25:00"},
+		{value: "tomorrow 13pm"},
+		{value: "tomorrow 9:5"},
+	}
+	for _, tt := range tests {
+		now := "2026-10-14 09:00"
+		if tt.now != "" {
+			now = tt.now
+		}
+		dc := DateContext{Now: clock(t, now)}
+		if tt.week != "" {
+			dc.Week = clock(t, tt.week)
+		}
+		got, ok := parseRelativeDate(tt.value, dc)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseRelativeDate(%q) at %s = %v, want no date", tt.value, now, got)
+			}
+			continue
+		}
+		if !ok || !got.Equal(clock(t, tt.want)) {
+			t.Errorf("parseRelativeDate(%q) at %s (week %q) = %v, %v; want %s", tt.value, now, tt.week, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParseOffsetPhrase(t *testing.T) {
+	withFixedZone(t)
+	today := clock(t, "2026-01-31")
+	tests := []struct {
+		phrase string
+		want   string // empty: not an offset
+	}{
+		{"+0d", "2026-01-31"},
+		{"+1d", "2026-02-01"},
+		{"-31d", "2025-12-31"},
+		{"+2w", "2026-02-14"},
+		{"-1m", "2025-12-31"},
+		{"+11m", "2026-12-31"},
+		{"+3 days", "2026-02-03"},
+		{"+ 3 days", ""}, // a lone sign
+		{"- 1 week", ""},
+		{"in 1 day", "2026-02-01"},
+		{"in one week", "2026-02-07"},
+		{"in 12 weeks", "2026-04-25"},
+		{"in 1 months", "2026-03-03"}, // January 31 plus a month overflows
+		{"in a fortnight", ""},
+		{"in 2", ""},
+		{"+d", ""},
+		{"3d", ""},
+		{"in -2 days", "2026-01-29"},
+		{"in 2 hours", ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseOffsetPhrase(strings.Fields(tt.phrase), today)
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseOffsetPhrase(%q) = %v, want no offset", tt.phrase, got)
+			}
+			continue
+		}
+		if !ok || !got.Equal(clock(t, tt.want)) {
+			t.Errorf("parseOffsetPhrase(%q) = %v, %v; want %s", tt.phrase, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParseWeekdayPhrase(t *testing.T) {
+	withFixedZone(t)
+	tests := []struct {
+		ref    string
+		phrase string
+		want   string // empty: not a weekday
+	}{
+		{"2026-10-14", "wed", "2026-10-14"},
+		{"2026-10-14", "tuesday", "2026-10-20"},
+		{"2026-10-14", "th", "2026-10-15"},
+		{"2026-10-14", "su", "2026-10-18"},
+		{"2026-10-14", "this mon", "2026-10-12"},
+		{"2026-10-14", "this sun", "2026-10-18"},
+		{"2026-10-14", "next wed", "2026-10-21"},
+		{"2026-10-18", "next mon", "2026-10-19"}, // from a Sunday
+		{"2026-10-18", "mon", "2026-10-19"},
+		{"2026-12-28", "next fri", "2027-01-08"},
+		{"2026-12-31", "sat", "2027-01-02"},
+		{"2026-10-14", "t", ""},
+		{"2026-10-14", "last fri", ""},
+		{"2026-10-14", "next", ""},
+		{"2026-10-14", "fri fri", ""},
+		{"2026-10-14", "friyay", ""},
+	}
+	for _, tt := range tests {
+		got, ok := parseWeekdayPhrase(strings.Fields(tt.phrase), clock(t, tt.ref))
+		if tt.want == "" {
+			if ok {
+				t.Errorf("parseWeekdayPhrase(%q) from %s = %v, want no day", tt.phrase, tt.ref, got)
+			}
+			continue
+		}
+		if !ok || !got.Equal(clock(t, tt.want)) {
+			t.Errorf("parseWeekdayPhrase(%q) from %s = %v, %v; want %s", tt.phrase, tt.ref, got, ok, tt.want)
+		}
+	}
+}
+
+func TestParseClockWord(t *testing.T) {
+	tests := []struct {
+		word         string
+		hour, minute int
+		ok           bool
+	}{
+		{"14:30", 14, 30, true},
+		{"9:05", 9, 5, true},
+		{"9.30", 9, 30, true},
+		{"0:00", 0, 0, true},
+		{"23:59", 23, 59, true},
+		{"noon", 12, 0, true},
+		{"9am", 9, 0, true},
+		{"3pm", 15, 0, true},
+		{"12am", 0, 0, true},
+		{"12pm", 12, 0, true},
+		{"12:15pm", 12, 15, true},
+		{"11:45pm", 23, 45, true},
+		{"14", 0, 0, false}, // a bare number is a count, not a time
+		{"24:00", 0, 0, false},
+		{"9:60", 0, 0, false},
+		{"9:5", 0, 0, false},
+		{"0am", 0, 0, false},
+		{"13pm", 0, 0, false},
+		{"pm", 0, 0, false},
+		{"midnight", 0, 0, false},
+	}
+	for _, tt := range tests {
+		h, m, ok := parseClockWord(tt.word)
+		if h != tt.hour || m != tt.minute || ok != tt.ok {
+			t.Errorf("parseClockWord(%q) = %d, %d, %v; want %d, %d, %v", tt.word, h, m, ok, tt.hour, tt.minute, tt.ok)
+		}
+	}
+}
+
+func TestWeekLabel(t *testing.T) {
+	withFixedZone(t)
+	tests := []struct {
+		start string
+		span  int
+		want  string
+	}{
+		{"2026-10-12", 1, "Week 42 - Oct 12 - Oct 18, 2026"},
+		{"2026-10-12", 0, "Week 42 - Oct 12 - Oct 18, 2026"},
+		{"2026-12-28", 1, "Week 53 - Dec 28 - Jan 3, 2027"},
+		{"2027-01-04", 1, "Week 1 - Jan 4 - Jan 10, 2027"},
+		{"2026-10-12", 3, "Weeks 42-44 - Oct 12 - Nov 1, 2026"},
+		{"2026-12-21", 3, "Weeks 52-1 - Dec 21 - Jan 10, 2027"},
+		{"2026-10-12", -1, "All Weeks"},
+	}
+	for _, tt := range tests {
+		if got := weekLabel(clock(t, tt.start), tt.span); got != tt.want {
+			t.Errorf("weekLabel(%s, %d) = %q, want %q", tt.start, tt.span, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// An open form can be edited in $EDITOR as a file: a front matter with a
+// "field: value" line per field, then the body, which is the form's text
+// field if it has one (the task of a todo or subtask). The body may be
+// wrapped over several lines, but they are joined into one: the text field
+// holds a single line, so line breaks are not kept. The values are put back
+// into the form and submitted as if typed, so they pass the same checks;
+// errors are shown in the form. The subtasks of a project are edited as a
+// whole, as a markdown checklist.
+
+// bodyFields are the labels of the form fields edited as the file's body.
+var bodyFields = map[string]bool{"Task": true}
+
+// editorMsg reports that the editor started on a form or plan file exited.
+type editorMsg struct {
+	path string
+	err  error
+}
+
+// editInEditor opens the selected item of the tab in $EDITOR: its edit
+// form, or the subtasks of the project whose plan is focused.
+func (m *Model) editInEditor() tea.Cmd {
+	if m.activeTab == tabProjects && m.projectFocus {
+		return m.openPlanEditor()
+	}
+	m.openEditCurrent()
+	if m.modal == modalNone || len(m.formFields) == 0 {
+		// Nothing selected, or a picker comes first (the scope of an
+		// occurrence); its form takes Ctrl+O too.
+		return nil
+	}
+	m.editorStandalone = true
+	return m.launchEditor(formDocument(m.modalTitle, m.formFields))
+}
+
+// launchEditor writes text to a temporary file and suspends the program
+// while $EDITOR edits it.
+func (m *Model) launchEditor(text string) tea.Cmd {
+	if m.editorText != "" {
+		// Back to the file that failed, rather than losing what was typed.
+		text = m.editorText
+	}
+	path, err := writeTempFile("seman-*.md", text)
+	if err != nil {
+		m.modalError = "Cannot start the editor: " + err.Error()
+		return nil
+	}
+	m.editorOriginal = text
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return editorMsg{path: path, err: err}
+	})
+}
+
+// handleEditor applies the file the editor left to the form or plan it was
+// started on.
+func (m *Model) handleEditor(msg editorMsg) tea.Cmd {
+	defer os.Remove(msg.path)
+	standalone := m.editorStandalone
+	m.editorStandalone = false
+	if m.modal == modalNone {
+		return nil
+	}
+	if msg.err != nil {
+		m.modalError = "The editor failed: " + msg.err.Error()
+		return nil
+	}
+	raw, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.modalError = "Cannot read the file: " + err.Error()
+		return nil
+	}
+	text := strings.ReplaceAll(string(raw), "\r\n", "\n")
+	if strings.TrimSpace(text) == "" || text == m.editorOriginal {
+		m.editorText = ""
+		if standalone || m.modal == modalEditPlanList {
+			m.closeModal()
+			m.notice = "Nothing changed."
+		}
+		return nil
+	}
+	if m.modal == modalEditPlanList {
+		return m.applyPlanList(text)
+	}
+	values, err := parseFormDocument(text, m.formFields)
+	if err != nil {
+		m.editorText = text
+		m.modalError = err.Error() + "  Ctrl+O to fix it in the editor."
+		return nil
+	}
+	m.editorText = ""
+	for i, value := range values {
+		input := &m.formFields[i].input
+		if input.CharLimit > 0 && len([]rune(value)) > input.CharLimit {
+			input.CharLimit = 0
+		}
+		input.SetValue(value)
+	}
+	return m.submitFormModal()
+}
+
+// fieldKey is the front matter key of a form field, e.g. exam_name.
+func fieldKey(label string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(label), " ", "_"))
+}
+
+// formDocument writes the fields of a form as a file to edit: the text field,
+// if any, as the body and the rest in the front matter, each after a
+// comment with its placeholder.
+func formDocument(title string, fields []formField) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "# %s. Save and quit to apply; empty the file to cancel.\n", title)
+	body := ""
+	for _, f := range fields {
+		if bodyFields[f.label] {
+			body = f.input.Value()
+			fmt.Fprintf(&b, "# The %s goes below the front matter; its lines are joined into one.\n", strings.ToLower(f.label))
+			continue
+		}
+		if f.input.Placeholder != "" {
+			fmt.Fprintf(&b, "# %s\n", f.input.Placeholder)
+		}
+		fmt.Fprintf(&b, "%s: %s\n", fieldKey(f.label), quoteValue(f.input.Value()))
+	}
+	b.WriteString("---\n")
+	if body != "" {
+		b.WriteString(body + "\n")
+	}
+	return b.String()
+}
+
+// quoteValue quotes values that would not read back as they are.
+func quoteValue(value string) string {
+	if value != strings.TrimSpace(value) || strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// parseFormDocument reads the values of fields back from a file written by
+// formDocument. Fields the file leaves out keep their value; the lines of
+// the body are joined with spaces, as the fields are single lines.
+func parseFormDocument(text string, fields []formField) ([]string, error) {
+	values := make([]string, len(fields))
+	bodyIdx := -1
+	for i, f := range fields {
+		values[i] = f.input.Value()
+		if bodyFields[f.label] {
+			bodyIdx = i
+		}
+	}
+	lines := strings.Split(text, "\n")
+	n := 0
+	for n < len(lines) && strings.TrimSpace(lines[n]) == "" {
+		n++
+	}
+	if n == len(lines) || strings.TrimSpace(lines[n]) != "---" {
+		return nil, fmt.Errorf("Line %d: the file must start with the front matter, between --- lines.", n+1)
+	}
+	seen := map[int]bool{}
+	closed := false
+	for n++; n < len(lines); n++ {
+		line := strings.TrimSpace(lines[n])
+		if line == "---" {
+			closed = true
+			n++
+			break
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("Line %d: expected \"field: value\", got %q.", n+1, line)
+		}
+		idx := -1
+		for i, f := range fields {
+			if fieldKey(f.label) == fieldKey(key) {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return nil, fmt.Errorf("Line %d: %s is not a field of this form.", n+1, strings.TrimSpace(key))
+		}
+		if seen[idx] {
+			return nil, fmt.Errorf("Line %d: %s is given twice.", n+1, strings.TrimSpace(key))
+		}
+		seen[idx] = true
+		value, err := unquoteValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %s has a bad quoted value.", n+1, strings.TrimSpace(key))
+		}
+		values[idx] = value
+	}
+	if !closed {
+		return nil, fmt.Errorf("The front matter is not closed by a --- line.")
+	}
+	body := strings.Join(strings.Fields(strings.Join(lines[n:], "\n")), " ")
+	switch {
+	case bodyIdx >= 0 && !seen[bodyIdx]:
+		values[bodyIdx] = body
+	case body != "" && bodyIdx >= 0:
+		return nil, fmt.Errorf("Line %d: the %s is given in the front matter already.", firstTextLine(lines, n)+1, strings.ToLower(fields[bodyIdx].label))
+	case body != "":
+		return nil, fmt.Errorf("Line %d: this form has no text; put every field in the front matter.", firstTextLine(lines, n)+1)
+	}
+	return values, nil
+}
+
+func unquoteValue(value string) (string, error) {
+	switch {
+	case len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"':
+		return strconv.Unquote(value)
+	case len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'':
+		return strings.ReplaceAll(value[1:len(value)-1], "''", "'"), nil
+	}
+	return value, nil
+}
+
+// firstTextLine returns the index of the first line from n on that is not
+// blank.
+func firstTextLine(lines []string, n int) int {
+	for ; n < len(lines); n++ {
+		if strings.TrimSpace(lines[n]) != "" {
+			return n
+		}
+	}
+	return n
+}
+
+// openPlanEditor edits the subtasks of the selected project in $EDITOR.
+func (m *Model) openPlanEditor() tea.Cmd {
+	pi, ok := m.selectedProject()
+	if !ok {
+		return nil
+	}
+	project := m.projects[pi]
+	m.closeModal()
+	m.modal = modalEditPlanList
+	m.modalTitle = "Edit Subtasks (" + project.Name + ")"
+	m.modalHint = "Ctrl+O back to the editor · Esc discard"
+	m.editProjectID = project.ID
+	return m.launchEditor(planDocument(project))
+}
+
+func (m Model) updatePlanListModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.closeModal()
+	case "ctrl+o":
+		pi := findProjectByID(m.projects, m.editProjectID)
+		if pi < 0 {
+			m.closeModal()
+			return m, nil
+		}
+		return m, m.launchEditor(planDocument(m.projects[pi]))
+	}
+	return m, nil
+}
+
+// planDocument writes the subtasks of a project as a markdown checklist.
+func planDocument(project models.ProjectItem) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Subtasks of %s, one per line: \"- [ ] task\", or \"- [x] task\" when done.\n", project.Name)
+	b.WriteString("# Add, delete or reorder lines to change the list; empty the file to cancel.\n")
+	for _, s := range project.Subtasks {
+		box := "[ ]"
+		if s.Done {
+			box = "[x]"
+		}
+		fmt.Fprintf(&b, "- %s %s\n", box, s.Text)
+	}
+	return b.String()
+}
+
+// parsePlanList reads the subtasks back from a checklist written by
+// planDocument. Subtasks keep their IDs as long as their text is the same.
+func parsePlanList(text string, current []models.Subtask) ([]models.Subtask, error) {
+	used := make([]bool, len(current))
+	var subtasks []models.Subtask
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		item, ok := strings.CutPrefix(line, "- ")
+		if !ok {
+			item, ok = strings.CutPrefix(line, "* ")
+		}
+		if !ok {
+			return nil, fmt.Errorf("Line %d: expected a list item like \"- [ ] task\", got %q.", n+1, line)
+		}
+		done := false
+		switch {
+		case strings.HasPrefix(item, "[ ]"):
+			item = item[3:]
+		case strings.HasPrefix(item, "[x]"), strings.HasPrefix(item, "[X]"):
+			item, done = item[3:], true
+		}
+		subtask, err := BuildSubtask(item)
+		if err != nil {
+			return nil, fmt.Errorf("Line %d: %s", n+1, err)
+		}
+		for i, s := range current {
+			if !used[i] && s.Text == subtask.Text {
+				used[i] = true
+				subtask.ID = s.ID
+				break
+			}
+		}
+		subtask.Done = done
+		subtasks = append(subtasks, subtask)
+	}
+	return subtasks, nil
+}
+
+// applyPlanList replaces the subtasks of the project being edited with the
+// checklist the editor left, or reports what is wrong with it.
+func (m *Model) applyPlanList(text string) tea.Cmd {
+	pi := findProjectByID(m.projects, m.editProjectID)
+	if pi < 0 {
+		m.closeModal()
+		return nil
+	}
+	subtasks, err := parsePlanList(text, m.projects[pi].Subtasks)
+	if err != nil {
+		m.editorText = text
+		m.modalError = err.Error()
+		return nil
+	}
+	m.closeModal()
+	m.projects[pi].Subtasks = subtasks
+	m.notice = fmt.Sprintf("Updated the subtasks of %s.", m.projects[pi].Name)
+	m.projectChanged(pi)
+	m.persist()
+	return nil
+}
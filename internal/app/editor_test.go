@@ -0,0 +1,262 @@
+package app
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// testFields returns form fields with the given labels and values.
+func testFields(labels []string, values ...string) []formField {
+	fields := make([]formField, len(labels))
+	for i, label := range labels {
+		fields[i] = newFormField(label, 40, false)
+		if i < len(values) {
+			fields[i].input.SetValue(values[i])
+		}
+	}
+	return fields
+}
+
+var (
+	todoLabels = []string{"Task", "Subject", "Due", "Repeat"}
+	examLabels = []string{"Exam Name", "Date", "Retakes", "Weight"}
+)
+
+func TestFormDocumentRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		values []string
+	}{
+		{"todo", todoLabels, []string{"Write up the lab report", "DB", "Jan 14, 2026 @ 18:00", "weekly on Mon"}},
+		{"empty values", todoLabels, []string{"Read", "", "", ""}},
+		{"no body", examLabels, []string{"Final: written", "2026-12-10", "Jan 14, 2027, Feb 2, 2027", "40"}},
+		{"spaces and quotes", examLabels, []string{"  padded  ", `"quoted"`, "'single'", `back\slash`}},
+		{"comment and separator look-alikes", examLabels, []string{"# not a comment", "---", "a: b: c", "it's"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := formDocument("Edit", testFields(tt.labels, tt.values...))
+			// Parse into a form holding other values, so that nothing is
+			// kept by accident.
+			got, err := parseFormDocument(text, testFields(tt.labels, "x", "x", "x", "x"))
+			if err != nil {
+				t.Fatalf("parseFormDocument: %v\n%s", err, text)
+			}
+			if !reflect.DeepEqual(got, tt.values) {
+				t.Errorf("values = %q, want %q\n%s", got, tt.values, text)
+			}
+		})
+	}
+}
+
+func TestParseFormDocument(t *testing.T) {
+	fields := testFields(todoLabels, "Read", "DB", "tomorrow", "")
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "fields left out keep their value",
+			text: "---\ndue: fri\n---\nRead\n",
+			want: []string{"Read", "DB", "fri", ""},
+		},
+		{
+			name: "the lines of the body are joined",
+			text: "\n---\n---\nWrite up the lab report:\n  results and figures\n\nand a discussion.\n",
+			want: []string{"Write up the lab report: results and figures and a discussion.", "DB", "tomorrow", ""},
+		},
+		{
+			name: "keys match labels loosely",
+			text: "---\n  Subject :OS\nREPEAT: weekly\n---\n",
+			want: []string{"", "OS", "tomorrow", "weekly"},
+		},
+		{
+			name: "the task in the front matter",
+			text: "---\ntask: Skim\n---\n\n",
+			want: []string{"Skim", "DB", "tomorrow", ""},
+		},
+		{
+			name: "quoted values",
+			text: "---\nsubject: \" DB \"\ndue: 'it''s'\n---\nRead\n",
+			want: []string{"Read", " DB ", "it's", ""},
+		},
+		{
+			name: "a carriage return is trimmed",
+			text: "---\nsubject: DB\r\n---\nRead\n",
+			want: []string{"Read", "DB", "tomorrow", ""},
+		},
+	}
+	for _, tt := range tests {
+		got, err := parseFormDocument(tt.text, fields)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: values = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseFormDocumentErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels []string
+		text   string
+		want   string
+	}{
+		{"no front matter", todoLabels, "Read\n", "Line 1: the file must start with the front matter, between --- lines."},
+		{"blank lines first", todoLabels, "\n\nsubject: DB\n", "Line 3: the file must start with the front matter"},
+		{"unclosed", todoLabels, "---\nsubject: DB\n", "The front matter is not closed by a --- line."},
+		{"no colon", todoLabels, "---\nsubject DB\n---\n", `Line 2: expected "field: value", got "subject DB".`},
+		{"unknown field", todoLabels, "---\n# a comment\ncolour: red\n---\n", "Line 3: colour is not a field of this form."},
+		{"field twice", todoLabels, "---\nsubject: A\nSubject: B\n---\n", "Line 3: Subject is given twice."},
+		{"bad quotes", todoLabels, "---\nsubject: \"a\\q\"\n---\n", "Line 2: subject has a bad quoted value."},
+		{"task twice", todoLabels, "---\ntask: Read\n---\n\nSkim\n", "Line 5: the task is given in the front matter already."},
+		{"text in a form without", examLabels, "---\ndate: fri\n---\nFinal\n", "Line 4: this form has no text; put every field in the front matter."},
+	}
+	for _, tt := range tests {
+		_, err := parseFormDocument(tt.text, testFields(tt.labels))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+// editorResult writes text as the file the editor left and hands it to the
+// model.
+func editorResult(t *testing.T, m *Model, text string) {
+	t.Helper()
+	path, err := writeTempFile("seman-test-*.md", text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.handleEditor(editorMsg{path: path})
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the file was left behind: %v", err)
+	}
+}
+
+func TestEditorErrorsShowInTheForm(t *testing.T) {
+	m := newTestModel(t, persistTestData())
+	m.checklistCursor = 0
+	m.openEditTodo()
+	if m.modal != modalEditTodo {
+		t.Fatalf("modal = %v, want the todo form", m.modal)
+	}
+	original := formDocument(m.modalTitle, m.formFields)
+	m.editorOriginal = original
+
+	bad := strings.Replace(original, "subject:", "colour:", 1)
+	editorResult(t, &m, bad)
+	if m.modal != modalEditTodo {
+		t.Fatal("the form closed on a bad file")
+	}
+	line := strings.Count(bad[:strings.Index(bad, "colour:")], "\n") + 1
+	want := "Line " + strconv.Itoa(line) + ": colour is not a field of this form.  Ctrl+O to fix it in the editor."
+	if m.modalError != want {
+		t.Errorf("modalError = %q, want %q", m.modalError, want)
+	}
+	if m.editorText != bad {
+		t.Error("the bad file is not kept for Ctrl+O")
+	}
+
+	// A mistake the form finds is shown the same way.
+	editorResult(t, &m, strings.Replace(original, "Read", "", 1))
+	if m.modal != modalEditTodo || m.modalError != "Task is required." {
+		t.Errorf("modal = %v, modalError = %q; want the form open with the task error", m.modal, m.modalError)
+	}
+
+	editorResult(t, &m, strings.Replace(original, "Read\n", "Read the\nwhole chapter\n", 1))
+	if m.modal != modalNone || m.modalError != "" {
+		t.Fatalf("modal = %v, modalError = %q; want the form submitted", m.modal, m.modalError)
+	}
+	if got := m.checklistItems[0].Text; got != "Read the whole chapter" {
+		t.Errorf("todo = %q, want the lines joined", got)
+	}
+	if m.editorText != "" {
+		t.Error("editorText is kept after a good file")
+	}
+}
+
+func TestPlanListRoundTrip(t *testing.T) {
+	project := models.ProjectItem{Name: "Report", Subtasks: []models.Subtask{
+		{ID: "a", Text: "Outline", Done: true},
+		{ID: "b", Text: "Draft"},
+		{ID: "c", Text: "Draft"},
+	}}
+	got, err := parsePlanList(planDocument(project), project.Subtasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, project.Subtasks) {
+		t.Errorf("subtasks = %+v, want %+v", got, project.Subtasks)
+	}
+
+	// Reordered, one renamed, one added: IDs follow the text.
+	text := "- [X] Draft\n* [ ] Outline v2\n\n- Proofread\n- [ ] Draft\n"
+	got, err = parsePlanList(text, project.Subtasks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 4 || got[0].ID != "b" || !got[0].Done || got[3].ID != "c" || got[3].Done {
+		t.Errorf("subtasks = %+v", got)
+	}
+	for _, s := range got[1:3] {
+		if s.ID == "" || s.ID == "a" || s.Done {
+			t.Errorf("new subtask = %+v, want a fresh ID and not done", s)
+		}
+	}
+	if got[1].Text != "Outline v2" || got[2].Text != "Proofread" {
+		t.Errorf("texts = %q, %q", got[1].Text, got[2].Text)
+	}
+}
+
+func TestParsePlanListErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"- [ ] Outline\nDraft\n", `Line 2: expected a list item like "- [ ] task", got "Draft".`},
+		{"# comment\n\n- [x]\n", "Line 3: Task is required."},
+		{"-[ ] Outline\n", "Line 1: expected a list item"},
+	}
+	for _, tt := range tests {
+		_, err := parsePlanList(tt.text, nil)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parsePlanList(%q) = %v, want %q", tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestPlanListErrorShowsInTheModal(t *testing.T) {
+	data := persistTestData()
+	data.Projects = []models.ProjectItem{{ID: "p1", Name: "Report", Subject: "DB", Due: "2026-11-20",
+		Status: models.StatusNotStarted, Subtasks: []models.Subtask{{ID: "a", Text: "Outline"}}}}
+	m := newTestModel(t, data)
+	m.modal = modalEditPlanList
+	m.editProjectID = "p1"
+
+	editorResult(t, &m, "- [ ] Outline\nDraft\n")
+	if m.modal != modalEditPlanList || !strings.HasPrefix(m.modalError, "Line 2: expected a list item") {
+		t.Errorf("modal = %v, modalError = %q; want the error in the open modal", m.modal, m.modalError)
+	}
+	if len(m.projects[0].Subtasks) != 1 {
+		t.Errorf("subtasks = %+v, want them unchanged", m.projects[0].Subtasks)
+	}
+
+	editorResult(t, &m, "- [x] Outline\n- [ ] Draft\n")
+	if m.modal != modalNone {
+		t.Errorf("modal = %v, want it closed", m.modal)
+	}
+	if got := m.projects[0].Subtasks; len(got) != 2 || got[0].ID != "a" || !got[0].Done {
+		t.Errorf("subtasks = %+v", got)
+	}
+}
@@ -0,0 +1,280 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// parseCredits reads a subject's credits; blank means none.
+func parseCredits(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("Credits must be a number like 6 or 2.5.")
+	}
+	return v, nil
+}
+
+// parseWeight reads an exam's share of the subject grade; blank means an
+// equal share.
+func parseWeight(raw string) (float64, error) {
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), "%")
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil || v < 0 || v > 100 {
+		return 0, fmt.Errorf("Weight must be a percentage from 0 to 100.")
+	}
+	return v, nil
+}
+
+// FormatNumber drops the trailing zeros of credits, weights and points.
+func FormatNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parsePoints reads "45/50"; a bare number is out of 100.
+func parsePoints(raw string) (float64, float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, 0, nil
+	}
+	got, max, hasMax := strings.Cut(raw, "/")
+	points, err := strconv.ParseFloat(strings.TrimSpace(got), 64)
+	if err != nil || points < 0 {
+		return 0, 0, fmt.Errorf("Points must look like 45/50.")
+	}
+	maxPoints := 100.0
+	if hasMax {
+		maxPoints, err = strconv.ParseFloat(strings.TrimSpace(max), 64)
+		if err != nil || maxPoints <= 0 {
+			return 0, 0, fmt.Errorf("Points must look like 45/50.")
+		}
+	}
+	if points > maxPoints {
+		return 0, 0, fmt.Errorf("Points cannot exceed the maximum.")
+	}
+	return points, maxPoints, nil
+}
+
+// parsePassed reads a yes/no answer; ok is false for a blank one.
+func parsePassed(raw string) (passed, ok bool, err error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "":
+		return false, false, nil
+	case "y", "yes", "p", "pass", "passed", "true":
+		return true, true, nil
+	case "n", "no", "f", "fail", "failed", "false":
+		return false, true, nil
+	}
+	return false, false, fmt.Errorf("Passed must be yes or no.")
+}
+
+// parseAttempt reads a 1-based attempt number (1 is the original date, 2 the
+// first retake) and returns it 0-based.
+func parseAttempt(exam models.ExamItem, raw string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || n < 1 || n > len(exam.Retakes)+1 {
+		if len(exam.Retakes) == 0 {
+			return 0, fmt.Errorf("Attempt must be 1; the exam has no retakes.")
+		}
+		return 0, fmt.Errorf("Attempt must be 1 to %d (1 is the original date).", len(exam.Retakes)+1)
+	}
+	return n - 1, nil
+}
+
+// SetExamResult validates a result for one attempt of exam and records it,
+// replacing any earlier result for that attempt. Leaving grade, points and
+// passed all blank removes the attempt's result. Without an explicit answer,
+// passed follows from the grade or points.
+func SetExamResult(exam models.ExamItem, scale models.GradingScale, attempt, grade, points, passed string) (models.ExamItem, error) {
+	n, err := parseAttempt(exam, attempt)
+	if err != nil {
+		return exam, err
+	}
+	result := models.ExamResult{Attempt: n, Grade: strings.TrimSpace(grade)}
+	if result.Points, result.MaxPoints, err = parsePoints(points); err != nil {
+		return exam, err
+	}
+	pass, hasPass, err := parsePassed(passed)
+	if err != nil {
+		return exam, err
+	}
+
+	results := make([]models.ExamResult, 0, len(exam.Results)+1)
+	for _, r := range exam.Results {
+		if r.Attempt != n {
+			results = append(results, r)
+		}
+	}
+	if result.Grade == "" && result.MaxPoints == 0 && !hasPass {
+		exam.Results = results
+		return exam, nil
+	}
+
+	if result.Grade != "" {
+		if _, ok := scale.Percent(result.Grade); !ok {
+			return exam, fmt.Errorf("Grade must be one of %s.", scaleGrades(scale))
+		}
+		result.Scale = scale.Name
+		if len(scale.Bands) > 0 {
+			// Store the grade as the scale spells it.
+			for _, b := range scale.Bands {
+				if strings.EqualFold(b.Grade, result.Grade) {
+					result.Grade = b.Grade
+				}
+			}
+		}
+	}
+	if hasPass {
+		result.Passed = pass
+	} else if p, ok := result.Percent(scale); ok {
+		result.Passed = scale.Passes(p)
+	}
+
+	results = append(results, result)
+	sort.Slice(results, func(i, j int) bool { return results[i].Attempt < results[j].Attempt })
+	exam.Results = results
+	return exam, nil
+}
+
+// NextAttempt is the 0-based attempt a new result most likely belongs to: the
+// passed one if the exam was passed, otherwise the first attempt without a
+// result (or the last retake when all have one).
+func NextAttempt(exam models.ExamItem) int {
+	if final, ok := exam.FinalResult(); ok && final.Passed {
+		return final.Attempt
+	}
+	attempt := 0
+	for attempt < len(exam.Retakes) {
+		if _, ok := exam.Result(attempt); !ok {
+			break
+		}
+		attempt++
+	}
+	return attempt
+}
+
+// scaleGrades lists the grades of a scale for messages and placeholders.
+func scaleGrades(scale models.GradingScale) string {
+	if len(scale.Bands) == 0 {
+		return "0–100"
+	}
+	grades := make([]string, len(scale.Bands))
+	for i, b := range scale.Bands {
+		grades[i] = b.Grade
+	}
+	return strings.Join(grades, " / ")
+}
+
+// gradeRows lists every exam in subject order, which is the order of the
+// Grades tab.
+func (m Model) gradeRows() []models.FlatExam {
+	var rows []models.FlatExam
+	for si, s := range m.subjects {
+		for ei, exam := range s.Exams {
+			rows = append(rows, models.FlatExam{SubjectCode: s.Code, SubjectIdx: si, ExamIdx: ei, Exam: exam})
+		}
+	}
+	return rows
+}
+
+func (m *Model) moveGradeCursor(delta int) {
+	rows := m.gradeRows()
+	m.gradeCursor += delta
+	if m.gradeCursor >= len(rows) {
+		m.gradeCursor = len(rows) - 1
+	}
+	if m.gradeCursor < 0 {
+		m.gradeCursor = 0
+	}
+}
+
+func (m *Model) cycleGradingScale() {
+	names := models.GradingScaleNames
+	cur := 0
+	for i, n := range names {
+		if n == m.gradingScale {
+			cur = i
+			break
+		}
+	}
+	m.gradingScale = names[(cur+1)%len(names)]
+	if m.persist() {
+		m.notice = "Grading scale: " + m.gradingScale
+	}
+}
+
+// openExamResult opens the result form for the exam under the Grades tab
+// cursor, on the first attempt that has no result yet.
+func (m *Model) openExamResult() {
+	rows := m.gradeRows()
+	if m.gradeCursor < 0 || m.gradeCursor >= len(rows) {
+		return
+	}
+	flat := rows[m.gradeCursor]
+	exam := flat.Exam
+	attempt := NextAttempt(exam)
+
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Attempt", inputWidth, true),
+		newFormField("Grade", inputWidth, false),
+		newFormField("Points", inputWidth, false),
+		newFormField("Passed", inputWidth, false),
+	}
+	fields[0].input.SetValue(strconv.Itoa(attempt + 1))
+	fields[0].input.Placeholder = "1 = original date, 2 = first retake"
+	fields[1].input.Placeholder = scaleGrades(models.GradingScaleOf(m.gradingScale))
+	fields[2].input.Placeholder = "e.g. 45/50"
+	fields[3].input.Placeholder = "yes / no (blank: from grade)"
+	if r, ok := exam.Result(attempt); ok {
+		fields[1].input.SetValue(r.Grade)
+		if r.MaxPoints > 0 {
+			fields[2].input.SetValue(FormatNumber(r.Points) + "/" + FormatNumber(r.MaxPoints))
+		}
+		// Only an answer that the grade does not already imply is shown, so
+		// changing the grade re-derives it.
+		scale := models.GradingScaleOf(m.gradingScale)
+		if p, ok := r.Percent(scale); !ok || scale.Passes(p) != r.Passed {
+			fields[3].input.SetValue(yesNo(r.Passed))
+		}
+	}
+	m.editExamID = exam.ID
+	m.openFormModal(modalExamResult, "Result — "+exam.Name+" ("+flat.SubjectCode+")", fields)
+}
+
+func (m *Model) submitExamResult() error {
+	si, ei := findExamByID(m.subjects, m.editExamID)
+	if si < 0 {
+		return nil
+	}
+	exam, err := SetExamResult(m.subjects[si].Exams[ei], models.GradingScaleOf(m.gradingScale),
+		m.formFields[0].input.Value(),
+		m.formFields[1].input.Value(),
+		m.formFields[2].input.Value(),
+		m.formFields[3].input.Value(),
+	)
+	if err != nil {
+		return err
+	}
+	m.subjects[si].Exams[ei] = exam
+	m.refreshFlatExams()
+	m.persist()
+	return nil
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
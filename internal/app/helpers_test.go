@@ -0,0 +1,27 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// newTestModel returns a model of data kept in a JSON file in a temporary
+// directory.
+func newTestModel(t *testing.T, data storage.SemesterData) Model {
+	t.Helper()
+	store := storage.NewJSONStore(filepath.Join(t.TempDir(), "seman.json"))
+	return NewModel(store, data, true)
+}
+
+// mustDay reads a day such as 2026-10-05 as local midnight.
+func mustDay(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.ParseInLocation(semesterDateLayout, value, time.Local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return date
+}
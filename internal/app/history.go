@@ -0,0 +1,386 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// The kinds of item the undo history tracks. Settings and weekly exams are
+// one item each, without an ID.
+const (
+	historySubject  = "subject"
+	historyProject  = "project"
+	historyTodo     = "todo"
+	historyWeekly   = "weekly_exams"
+	historySettings = "settings"
+)
+
+var historyKinds = []string{historySubject, historyProject, historyTodo, historyWeekly, historySettings}
+
+// historyItem is an item as the history compares it: by ID and JSON.
+type historyItem struct {
+	id  string
+	raw json.RawMessage
+}
+
+// historyItems are the items of the data by kind, in order.
+type historyItems map[string][]historyItem
+
+// historyItemsOf splits data into the items the history tracks. The weeks
+// shown are left out: moving between weeks or to the global view is not a
+// change.
+func historyItemsOf(data storage.SemesterData) historyItems {
+	items := historyItems{}
+	add := func(kind, id string, v any) {
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		items[kind] = append(items[kind], historyItem{id: id, raw: raw})
+	}
+	for _, s := range data.Subjects {
+		add(historySubject, s.ID, s)
+	}
+	for _, p := range data.Projects {
+		add(historyProject, p.ID, p)
+	}
+	for _, c := range data.Checklist {
+		add(historyTodo, c.ID, c)
+	}
+	add(historyWeekly, "", data.WeeklyExams)
+	settings := data
+	settings.Subjects, settings.Projects, settings.Checklist, settings.WeeklyExams = nil, nil, nil, nil
+	settings.WeekStart, settings.WeekSpan, settings.SchemaVersion = "", 0, 0
+	add(historySettings, "", settings)
+	return items
+}
+
+// diffHistoryItems returns the changes that turn before into after. Items
+// that only moved are not changes: the lists are sorted anyway.
+func diffHistoryItems(before, after historyItems) []storage.ItemChange {
+	var changes []storage.ItemChange
+	for _, kind := range historyKinds {
+		for i, b := range before[kind] {
+			j := indexOfHistoryItem(after[kind], b.id)
+			switch {
+			case j < 0:
+				changes = append(changes, storage.ItemChange{Kind: kind, ID: b.id, Index: i, Before: b.raw})
+			case string(after[kind][j].raw) != string(b.raw):
+				changes = append(changes, storage.ItemChange{Kind: kind, ID: b.id, Index: i, Before: b.raw, After: after[kind][j].raw})
+			}
+		}
+		for j, a := range after[kind] {
+			if indexOfHistoryItem(before[kind], a.id) < 0 {
+				changes = append(changes, storage.ItemChange{Kind: kind, ID: a.id, Index: j, After: a.raw})
+			}
+		}
+	}
+	return changes
+}
+
+func indexOfHistoryItem(items []historyItem, id string) int {
+	for i, item := range items {
+		if item.id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply makes the changes of an entry, or takes them back when undo is set.
+// Items are found by ID, so data edited elsewhere in the meantime keeps what
+// the entry did not touch.
+func (items historyItems) apply(changes []storage.ItemChange, undo bool) {
+	for n := range changes {
+		c := changes[n]
+		if undo {
+			c = changes[len(changes)-1-n]
+		}
+		to := c.After
+		if undo {
+			to = c.Before
+		}
+		list := items[c.Kind]
+		i := indexOfHistoryItem(list, c.ID)
+		switch {
+		case to == nil:
+			if i >= 0 {
+				list = append(list[:i], list[i+1:]...)
+			}
+		case i >= 0:
+			list[i].raw = to
+		default:
+			at := c.Index
+			if at < 0 || at > len(list) {
+				at = len(list)
+			}
+			list = append(list[:at], append([]historyItem{{id: c.ID, raw: to}}, list[at:]...)...)
+		}
+		items[c.Kind] = list
+	}
+}
+
+// data puts the items back together; the weeks shown are taken from current.
+func (items historyItems) data(current storage.SemesterData) (storage.SemesterData, error) {
+	var data storage.SemesterData
+	if settings := items[historySettings]; len(settings) > 0 {
+		if err := json.Unmarshal(settings[0].raw, &data); err != nil {
+			return storage.SemesterData{}, err
+		}
+	}
+	data.SchemaVersion = current.SchemaVersion
+	data.WeekStart, data.WeekSpan = current.WeekStart, current.WeekSpan
+	data.Subjects, data.Projects, data.Checklist, data.WeeklyExams = nil, nil, nil, nil
+	for _, item := range items[historySubject] {
+		var s models.SubjectItem
+		if err := json.Unmarshal(item.raw, &s); err != nil {
+			return storage.SemesterData{}, err
+		}
+		data.Subjects = append(data.Subjects, s)
+	}
+	for _, item := range items[historyProject] {
+		var p models.ProjectItem
+		if err := json.Unmarshal(item.raw, &p); err != nil {
+			return storage.SemesterData{}, err
+		}
+		data.Projects = append(data.Projects, p)
+	}
+	for _, item := range items[historyTodo] {
+		var c models.ChecklistItem
+		if err := json.Unmarshal(item.raw, &c); err != nil {
+			return storage.SemesterData{}, err
+		}
+		data.Checklist = append(data.Checklist, c)
+	}
+	if weekly := items[historyWeekly]; len(weekly) > 0 {
+		if err := json.Unmarshal(weekly[0].raw, &data.WeeklyExams); err != nil {
+			return storage.SemesterData{}, err
+		}
+	}
+	return data, nil
+}
+
+// describeChanges labels an entry after its first change, e.g. "Deleted
+// exam Final Exam (PHY150)".
+func describeChanges(changes []storage.ItemChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	label := describeChange(changes[0])
+	if len(changes) > 1 {
+		label += fmt.Sprintf(" and %d more changes", len(changes)-1)
+	}
+	return label
+}
+
+func describeChange(c storage.ItemChange) string {
+	verb := "Edited"
+	switch {
+	case c.Before == nil:
+		verb = "Added"
+	case c.After == nil:
+		verb = "Deleted"
+	}
+	switch c.Kind {
+	case historySubject:
+		var before, after models.SubjectItem
+		decodeChange(c, &before, &after)
+		if verb == "Edited" {
+			if label := describeSubjectEdit(before, after); label != "" {
+				return label
+			}
+		}
+		s := after
+		if verb == "Deleted" {
+			s = before
+		}
+		return fmt.Sprintf("%s subject %s (%s)", verb, s.Name, s.Code)
+	case historyProject:
+		var before, after models.ProjectItem
+		decodeChange(c, &before, &after)
+		if verb == "Edited" {
+			if label := describeProjectEdit(before, after); label != "" {
+				return label
+			}
+		}
+		p := after
+		if verb == "Deleted" {
+			p = before
+		}
+		return fmt.Sprintf("%s project %s", verb, p.Name)
+	case historyTodo:
+		var before, after models.ChecklistItem
+		decodeChange(c, &before, &after)
+		switch {
+		case verb == "Deleted":
+			return "Deleted todo " + before.Text
+		case verb == "Added":
+			return "Added todo " + after.Text
+		case !before.Done && after.Done || len(after.DoneDates) > len(before.DoneDates):
+			return "Completed todo " + after.Text
+		case before.Done && !after.Done || len(after.DoneDates) < len(before.DoneDates):
+			return "Reopened todo " + after.Text
+		case len(after.Skipped) > len(before.Skipped):
+			return "Removed an occurrence of todo " + after.Text
+		}
+		return "Edited todo " + after.Text
+	case historyWeekly:
+		return "Changed weekly exams"
+	case historySettings:
+		var before, after storage.SemesterData
+		decodeChange(c, &before, &after)
+		return describeSettingsEdit(before, after)
+	}
+	return "Changed " + c.Kind
+}
+
+func decodeChange(c storage.ItemChange, before, after any) {
+	if c.Before != nil {
+		_ = json.Unmarshal(c.Before, before)
+	}
+	if c.After != nil {
+		_ = json.Unmarshal(c.After, after)
+	}
+}
+
+// describeSubjectEdit names the exam, class, resource or notes an edit of a
+// subject changed.
+func describeSubjectEdit(before, after models.SubjectItem) string {
+	of := fmt.Sprintf(" (%s)", after.Code)
+	for _, e := range before.Exams {
+		if findExamIndex(after.Exams, e.ID) < 0 {
+			return "Deleted exam " + e.Name + of
+		}
+	}
+	for _, e := range after.Exams {
+		i := findExamIndex(before.Exams, e.ID)
+		switch {
+		case i < 0:
+			return "Added exam " + e.Name + of
+		case len(e.Results) != len(before.Exams[i].Results):
+			return "Recorded result for " + e.Name + of
+		case !sameJSON(e, before.Exams[i]):
+			return "Edited exam " + e.Name + of
+		}
+	}
+	for _, s := range before.Sessions {
+		if findSessionIndex(after.Sessions, s.ID) < 0 {
+			return fmt.Sprintf("Deleted %s on %s%s", s.Type, s.Day, of)
+		}
+	}
+	for _, s := range after.Sessions {
+		i := findSessionIndex(before.Sessions, s.ID)
+		switch {
+		case i < 0:
+			return fmt.Sprintf("Added %s on %s%s", s.Type, s.Day, of)
+		case s != before.Sessions[i]:
+			return fmt.Sprintf("Edited %s on %s%s", s.Type, s.Day, of)
+		}
+	}
+	for _, r := range before.Resources {
+		if findResourceIndex(after.Resources, r.ID) < 0 {
+			return "Deleted resource " + r.Title + of
+		}
+	}
+	for _, r := range after.Resources {
+		i := findResourceIndex(before.Resources, r.ID)
+		switch {
+		case i < 0:
+			return "Added resource " + r.Title + of
+		case r != before.Resources[i]:
+			return "Edited resource " + r.Title + of
+		}
+	}
+	if before.Notes != after.Notes {
+		return "Edited notes" + of
+	}
+	return ""
+}
+
+// describeProjectEdit names the subtask or milestone an edit of a project
+// changed.
+func describeProjectEdit(before, after models.ProjectItem) string {
+	of := fmt.Sprintf(" (%s)", after.Name)
+	for _, t := range before.Subtasks {
+		if findSubtaskIndex(after.Subtasks, t.ID) < 0 {
+			return "Deleted subtask " + t.Text + of
+		}
+	}
+	for _, t := range after.Subtasks {
+		i := findSubtaskIndex(before.Subtasks, t.ID)
+		switch {
+		case i < 0:
+			return "Added subtask " + t.Text + of
+		case t.Done != before.Subtasks[i].Done:
+			return doneVerb(t.Done) + " subtask " + t.Text + of
+		case t != before.Subtasks[i]:
+			return "Edited subtask " + t.Text + of
+		}
+	}
+	for _, ms := range before.Milestones {
+		if findMilestoneIndex(after.Milestones, ms.ID) < 0 {
+			return "Deleted milestone " + ms.Name + of
+		}
+	}
+	for _, ms := range after.Milestones {
+		i := findMilestoneIndex(before.Milestones, ms.ID)
+		switch {
+		case i < 0:
+			return "Added milestone " + ms.Name + of
+		case ms.Done != before.Milestones[i].Done:
+			return doneVerb(ms.Done) + " milestone " + ms.Name + of
+		case ms != before.Milestones[i]:
+			return "Edited milestone " + ms.Name + of
+		}
+	}
+	if before.Status != after.Status && !after.AutoStatus {
+		return fmt.Sprintf("Set project %s to %s", after.Name, strings.ToUpper(after.Status))
+	}
+	return ""
+}
+
+func doneVerb(done bool) string {
+	if done {
+		return "Completed"
+	}
+	return "Reopened"
+}
+
+func describeSettingsEdit(before, after storage.SemesterData) string {
+	switch {
+	case before.Theme != after.Theme:
+		return "Changed theme to " + after.Theme
+	case before.DateFormat != after.DateFormat:
+		return "Changed date format"
+	case before.ConfirmOn != after.ConfirmOn:
+		return "Turned delete confirmation " + onOff(after.ConfirmOn)
+	case before.PriorityRules != after.PriorityRules:
+		return "Changed priority rules"
+	case before.LofiEnabled != after.LofiEnabled:
+		return "Turned the lofi player " + onOff(after.LofiEnabled)
+	case before.LofiURL != after.LofiURL:
+		return "Changed the lofi playlist"
+	case before.GradingScale != after.GradingScale:
+		return "Changed grading scale to " + after.GradingScale
+	case before.ICSSubjectPattern != after.ICSSubjectPattern:
+		return "Changed the calendar subject pattern"
+	}
+	return "Changed settings"
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
+func sameJSON(a, b any) bool {
+	ra, errA := json.Marshal(a)
+	rb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ra) == string(rb)
+}
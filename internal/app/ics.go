@@ -0,0 +1,163 @@
+package app
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/ical"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// ExportICS converts exams, their retakes, project deadlines and todos into
+// an iCalendar document. Entries whose dates cannot be parsed are skipped.
+// UIDs are the items' IDs, so re-exporting after renaming or rescheduling
+// updates the same calendar entries.
+func ExportICS(data storage.SemesterData, now time.Time) []byte {
+	rules := normalizePriorityRules(data.PriorityRules)
+	uids := uidSet{}
+	cal := ical.Calendar{Name: "seman"}
+
+	for _, subject := range data.Subjects {
+		for _, exam := range subject.Exams {
+			date, ok := dates.Parse(exam.Date)
+			if !ok {
+				continue
+			}
+			base := uids.next(exam.ID, "exam", subject.Code, exam.Name)
+			priority := icalPriority(rules.Resolve(exam.Priority, date, true, now))
+			cal.Events = append(cal.Events, ical.Event{
+				UID:         base,
+				Summary:     fmt.Sprintf("%s (%s)", exam.Name, subject.Code),
+				Description: subject.Name,
+				Categories:  []string{subject.Code},
+				Start:       date,
+				AllDay:      dates.IsDay(exam.Date),
+				Priority:    priority,
+			})
+			for i, retake := range exam.Retakes {
+				rdate, ok := dates.Parse(retake)
+				if !ok {
+					continue
+				}
+				cal.Events = append(cal.Events, ical.Event{
+					UID:         retakeUID(base, i),
+					Summary:     fmt.Sprintf("%s — Retake %d (%s)", exam.Name, i+1, subject.Code),
+					Description: subject.Name,
+					Categories:  []string{subject.Code},
+					Start:       rdate,
+					AllDay:      dates.IsDay(retake),
+					Priority:    priority,
+				})
+			}
+		}
+	}
+
+	for _, project := range data.Projects {
+		due, ok := dates.Parse(project.Due)
+		if !ok {
+			continue
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:         uids.next(project.ID, "project", project.Subject, project.Name),
+			Summary:     fmt.Sprintf("Due: %s (%s)", project.Name, project.Subject),
+			Description: "Status: " + project.Status,
+			Categories:  []string{project.Subject},
+			Start:       due,
+			AllDay:      dates.IsDay(project.Due),
+		})
+	}
+
+	for _, item := range data.Checklist {
+		// Todos without a due date are exported without one.
+		due, _ := dates.Parse(item.Due)
+		var categories []string
+		if item.Subject != "" {
+			categories = []string{item.Subject}
+		}
+		todo := ical.Todo{
+			UID:        uids.next(item.ID, "todo", item.Subject, item.Text, item.Due),
+			Summary:    item.Text,
+			Categories: categories,
+			Due:        due,
+			DueAllDay:  dates.IsDay(item.Due),
+			Done:       item.Done,
+		}
+		if rule, _, ok := todoRecurrence(item); ok {
+			todo.RRule = icalRRule(rule, dates.IsDay(item.Due))
+			for _, day := range item.Skipped {
+				if occ, ok := dates.Parse(todoOccurrence(item, day).Due); ok {
+					todo.ExDates = append(todo.ExDates, occ)
+				}
+			}
+		}
+		cal.Todos = append(cal.Todos, todo)
+	}
+
+	return ical.Marshal(cal, now)
+}
+
+// WriteICS exports data to path as an iCalendar file.
+func WriteICS(path string, data storage.SemesterData, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, ExportICS(data, now), 0o644)
+}
+
+// icalRRule renders a todo's rule for a calendar. A timed series needs its
+// UNTIL as a UTC time, which is taken as the end of that day.
+func icalRRule(rule models.Recurrence, allDay bool) string {
+	if rule.Until == "" || allDay {
+		return rule.String()
+	}
+	until, err := time.ParseInLocation(semesterDateLayout, rule.Until, time.Local)
+	if err != nil {
+		return rule.String()
+	}
+	end := until.AddDate(0, 0, 1).Add(-time.Second).UTC().Format("20060102T150405Z")
+	rule.Until = ""
+	return rule.String() + ";UNTIL=" + end
+}
+
+func icalPriority(level string) int {
+	switch strings.ToUpper(level) {
+	case "HIGH":
+		return ical.PriorityHigh
+	case "MED":
+		return ical.PriorityMedium
+	case "LOW":
+		return ical.PriorityLow
+	}
+	return ical.PriorityNone
+}
+
+// uidSet hands out UIDs from item IDs. Items without an ID (data that never
+// went through a store) get one derived from their content instead,
+// disambiguating items that share it (e.g. two exams with the same name) by
+// their order.
+type uidSet map[string]int
+
+func (u uidSet) next(id string, parts ...string) string {
+	if id != "" {
+		return id + "@seman"
+	}
+	key := strings.ToUpper(strings.Join(parts, "|"))
+	n := u[key]
+	u[key] = n + 1
+	if n > 0 {
+		key = fmt.Sprintf("%s|%d", key, n)
+	}
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:10]) + "@seman"
+}
+
+func retakeUID(examUID string, index int) string {
+	return strings.TrimSuffix(examUID, "@seman") + fmt.Sprintf("-r%d@seman", index+1)
+}
@@ -0,0 +1,357 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/ical"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// DefaultICSSubjectPattern finds subject codes such as CS101 or MATH2010B in
+// an event's categories or summary. When the pattern has a capture group the
+// first group is the code, otherwise the whole match is.
+const DefaultICSSubjectPattern = `\b([A-Z]{2,5}\d{2,4}[A-Z]?)\b`
+
+// ICSAction is what importing a calendar entry does.
+type ICSAction string
+
+const (
+	ICSNew     ICSAction = "NEW"
+	ICSUpdated ICSAction = "UPDATED"
+	ICSSkipped ICSAction = "SKIPPED"
+)
+
+// ICSEntry describes the outcome for one calendar entry.
+type ICSEntry struct {
+	Action  ICSAction `json:"action"`
+	Subject string    `json:"subject"`
+	Name    string    `json:"name"`
+	Date    string    `json:"date"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// ICSImport is a planned calendar import: the data after the import and what
+// happens to each entry of the calendar.
+type ICSImport struct {
+	Data    storage.SemesterData
+	Entries []ICSEntry
+}
+
+// Count returns the number of entries with the given action.
+func (p ICSImport) Count(action ICSAction) int {
+	n := 0
+	for _, e := range p.Entries {
+		if e.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary is a one-line count of new, updated and skipped entries.
+func (p ICSImport) Summary() string {
+	return fmt.Sprintf("%d new · %d updated · %d skipped", p.Count(ICSNew), p.Count(ICSUpdated), p.Count(ICSSkipped))
+}
+
+// CompileSubjectPattern compiles the subject regex, falling back to the
+// default for a blank pattern.
+func CompileSubjectPattern(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		pattern = DefaultICSSubjectPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("Subject regex is invalid: %v", err)
+	}
+	return re, nil
+}
+
+// ReadICS parses the iCalendar file at path.
+func ReadICS(path string) (ical.Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ical.Calendar{}, err
+	}
+	defer f.Close()
+	return ical.Parse(f)
+}
+
+// PlanICSImport maps the events of cal to exams of data. Each event's subject
+// code is taken from its categories or, failing that, its summary; unknown
+// subjects are created. An event that was imported before (same UID) updates
+// its exam instead of adding a second one, as does an event matching an exam
+// of the same name that was entered by hand. An event without a UID only
+// matches such an exam on the same day, since a course may hold several
+// exams of one name. Todos are not imported.
+func PlanICSImport(data storage.SemesterData, cal ical.Calendar, pattern string) (ICSImport, error) {
+	re, err := CompileSubjectPattern(pattern)
+	if err != nil {
+		return ICSImport{}, err
+	}
+	rules := normalizePriorityRules(data.PriorityRules)
+
+	out := data
+	out.Subjects = make([]models.SubjectItem, len(data.Subjects))
+	for i, s := range data.Subjects {
+		s.Exams = append([]models.ExamItem(nil), s.Exams...)
+		out.Subjects[i] = s
+	}
+
+	var entries []ICSEntry
+	seen := map[string]bool{}
+	for _, ev := range cal.Events {
+		entry := ICSEntry{Name: strings.TrimSpace(ev.Summary)}
+		if entry.Name == "" {
+			entry.Name = "(untitled)"
+		}
+		if ev.Start.IsZero() {
+			entries = append(entries, skipEntry(entry, "no start date"))
+			continue
+		}
+		entry.Date = dates.StoreTime(ev.Start)
+		if ev.AllDay {
+			entry.Date = dates.StoreDay(ev.Start)
+		}
+		code, ok := matchSubjectCode(re, ev)
+		if !ok {
+			entries = append(entries, skipEntry(entry, "no subject code"))
+			continue
+		}
+		entry.Subject = code
+		entry.Name = examNameFromSummary(ev.Summary, code)
+		if ev.UID != "" {
+			if seen[ev.UID] {
+				entries = append(entries, skipEntry(entry, "duplicate in file"))
+				continue
+			}
+			seen[ev.UID] = true
+		}
+
+		si, ei := findImportedExam(out.Subjects, ev.UID)
+		if si < 0 {
+			si = findSubjectIndex(out.Subjects, code)
+			if si >= 0 {
+				day := ""
+				if ev.UID == "" {
+					day = entry.Date
+				}
+				ei = findManualExam(out.Subjects[si].Exams, entry.Name, day)
+			}
+		}
+		if si >= 0 && ei >= 0 {
+			exam := &out.Subjects[si].Exams[ei]
+			entry.Subject = out.Subjects[si].Code
+			if exam.Name == entry.Name && exam.Date == entry.Date && exam.SourceUID == ev.UID {
+				entries = append(entries, skipEntry(entry, "already up to date"))
+				continue
+			}
+			exam.Name = entry.Name
+			exam.Date = entry.Date
+			exam.SourceUID = ev.UID
+			entry.Action = ICSUpdated
+			entries = append(entries, entry)
+			continue
+		}
+
+		if si < 0 {
+			out.Subjects = append(out.Subjects, models.SubjectItem{ID: models.NewID(), Code: code, Name: code})
+			si = len(out.Subjects) - 1
+			entry.Reason = "new subject"
+		}
+		priority, _ := parsePriorityInput("", rules, true)
+		if p := priorityFromICal(ev.Priority); p != "" {
+			priority = p
+		}
+		out.Subjects[si].Exams = append(out.Subjects[si].Exams, models.ExamItem{
+			ID:        models.NewID(),
+			Name:      entry.Name,
+			Date:      entry.Date,
+			Priority:  priority,
+			SourceUID: ev.UID,
+		})
+		entry.Action = ICSNew
+		entries = append(entries, entry)
+	}
+	for _, td := range cal.Todos {
+		entries = append(entries, skipEntry(ICSEntry{Name: td.Summary}, "task, not an event"))
+	}
+
+	return ICSImport{Data: out, Entries: entries}, nil
+}
+
+func skipEntry(e ICSEntry, reason string) ICSEntry {
+	e.Action = ICSSkipped
+	e.Reason = reason
+	return e
+}
+
+// matchSubjectCode looks for a subject code in the categories first, as
+// those are the most reliable, then in the summary.
+func matchSubjectCode(re *regexp.Regexp, ev ical.Event) (string, bool) {
+	for _, text := range append(append([]string(nil), ev.Categories...), ev.Summary) {
+		m := re.FindStringSubmatch(text)
+		if m == nil {
+			continue
+		}
+		code := m[0]
+		if len(m) > 1 && m[1] != "" {
+			code = m[1]
+		}
+		if code = strings.ToUpper(strings.TrimSpace(code)); code != "" {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+var emptyBrackets = regexp.MustCompile(`\(\s*\)|\[\s*\]`)
+
+// examNameFromSummary removes the subject code and the separators around it
+// from an event summary, e.g. "CS101 - Final Exam" becomes "Final Exam".
+func examNameFromSummary(summary, code string) string {
+	codeRe := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(code))
+	name := codeRe.ReplaceAllString(summary, "")
+	name = emptyBrackets.ReplaceAllString(name, "")
+	name = strings.Trim(name, " \t-–—:|,/")
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return strings.TrimSpace(summary)
+	}
+	return name
+}
+
+// priorityFromICal maps iCalendar's 1–9 scale onto HIGH/MED/LOW; 0 (undefined)
+// maps to nothing.
+func priorityFromICal(p int) string {
+	switch {
+	case p >= 1 && p <= 4:
+		return "HIGH"
+	case p == 5:
+		return "MED"
+	case p >= 6 && p <= 9:
+		return "LOW"
+	}
+	return ""
+}
+
+func findImportedExam(subjects []models.SubjectItem, uid string) (int, int) {
+	if uid == "" {
+		return -1, -1
+	}
+	for si, s := range subjects {
+		for ei, exam := range s.Exams {
+			if exam.SourceUID == uid {
+				return si, ei
+			}
+		}
+	}
+	return -1, -1
+}
+
+// findManualExam finds an exam of the given name that did not come from a
+// calendar import. Unless day is empty, the exam must also fall on the day
+// of that stored date.
+func findManualExam(exams []models.ExamItem, name, day string) int {
+	want, hasDay := dates.Parse(day)
+	for i, exam := range exams {
+		if exam.SourceUID != "" || !strings.EqualFold(exam.Name, name) {
+			continue
+		}
+		if day == "" {
+			return i
+		}
+		if date, ok := dates.Parse(exam.Date); ok && hasDay && dayOf(date.Local()).Equal(dayOf(want.Local())) {
+			return i
+		}
+	}
+	return -1
+}
+
+// previewICSImport reads the calendar at path and opens the review modal.
+func (m *Model) previewICSImport(path, pattern string) error {
+	path = expandPath(path)
+	cal, err := ReadICS(path)
+	if err != nil {
+		return fmt.Errorf("Cannot read %s: %v", filepath.Base(path), err)
+	}
+	plan, err := PlanICSImport(m.exportData(), cal, pattern)
+	if err != nil {
+		return err
+	}
+
+	m.closeModal()
+	m.icsImport = plan
+	m.icsNewPattern = strings.TrimSpace(pattern)
+	m.importSource = path
+	m.icsCursor = 0
+	m.modal = modalICSPreview
+	m.modalTitle = "Import Calendar"
+	m.modalHint = "↑↓ scroll · Enter import · Esc cancel"
+	return nil
+}
+
+func (m Model) updateICSPreviewModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "n", "N":
+		m.closeModal()
+	case "j", "down":
+		if m.icsCursor < len(m.icsImport.Entries)-1 {
+			m.icsCursor++
+		}
+	case "k", "up":
+		if m.icsCursor > 0 {
+			m.icsCursor--
+		}
+	case "enter", "y", "Y":
+		m.applyICSImport()
+		m.closeModal()
+	}
+	return m, nil
+}
+
+// applyICSImport adopts the planned data and remembers the subject regex for
+// the next import.
+func (m *Model) applyICSImport() {
+	plan := m.icsImport
+	if plan.Count(ICSNew)+plan.Count(ICSUpdated) == 0 {
+		m.notice = "Nothing to import from " + filepath.Base(m.importSource)
+		return
+	}
+	m.changeLabel = "Imported exams from " + filepath.Base(m.importSource)
+	m.subjects = plan.Data.Subjects
+	if m.icsNewPattern == DefaultICSSubjectPattern {
+		m.icsNewPattern = ""
+	}
+	m.icsPattern = m.icsNewPattern
+	m.clampCursors()
+	m.sortExamsByPriority()
+	m.refreshAllFilters()
+	if !m.persist() {
+		return
+	}
+	m.notice = fmt.Sprintf("Imported %s: %s  %s", filepath.Base(m.importSource), plan.Summary(), m.actionHint("undo", "Undo"))
+}
+
+func (m Model) icsEntryLabels() []string {
+	labels := make([]string, len(m.icsImport.Entries))
+	for i, e := range m.icsImport.Entries {
+		label := fmt.Sprintf("%-8s %-9s %s", e.Action, e.Subject, e.Name)
+		if e.Date != "" {
+			label += " · " + m.dateFormat.ShowStored(e.Date)
+		}
+		if e.Reason != "" {
+			label += " (" + e.Reason + ")"
+		}
+		labels[i] = label
+	}
+	return labels
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/romanguyen/seman/internal/ical"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// TestPlanICSImportMatchesManualExams checks which exams entered by hand an
+// event updates: any of its name when the event has a UID, only one on the
+// same day when it has none.
+func TestPlanICSImportMatchesManualExams(t *testing.T) {
+	withFixedZone(t)
+	data := storage.SemesterData{
+		SchemaVersion: storage.SchemaVersion,
+		Subjects: []models.SubjectItem{{ID: "s1", Code: "DB101", Name: "Databases", Exams: []models.ExamItem{
+			{ID: "e1", Name: "Midterm", Date: "2026-10-15T09:00:00+01:00"},
+		}}},
+	}
+	tests := []struct {
+		name   string
+		event  ical.Event
+		action ICSAction
+		exams  []string // dates of the subject's exams after the import
+	}{
+		{
+			name:   "without UID on another day",
+			event:  ical.Event{Summary: "DB101 Midterm", Start: clock(t, "2026-12-03 09:00")},
+			action: ICSNew,
+			exams:  []string{"2026-10-15T09:00:00+01:00", "2026-12-03T09:00:00+01:00"},
+		},
+		{
+			name:   "without UID on the same day",
+			event:  ical.Event{Summary: "DB101 Midterm", Start: clock(t, "2026-10-15 13:00")},
+			action: ICSUpdated,
+			exams:  []string{"2026-10-15T13:00:00+01:00"},
+		},
+		{
+			name:   "without UID at the same time, renamed",
+			event:  ical.Event{Summary: "DB101: midterm", Start: clock(t, "2026-10-15 09:00")},
+			action: ICSUpdated,
+			exams:  []string{"2026-10-15T09:00:00+01:00"},
+		},
+		{
+			name:   "with UID on another day",
+			event:  ical.Event{UID: "mid@uni", Summary: "DB101 Midterm", Start: clock(t, "2026-12-03 09:00")},
+			action: ICSUpdated,
+			exams:  []string{"2026-12-03T09:00:00+01:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := PlanICSImport(data, ical.Calendar{Events: []ical.Event{tt.event}}, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(plan.Entries) != 1 || plan.Entries[0].Action != tt.action {
+				t.Fatalf("entries = %+v, want one %s", plan.Entries, tt.action)
+			}
+			var got []string
+			for _, exam := range plan.Data.Subjects[0].Exams {
+				got = append(got, exam.Date)
+			}
+			if len(got) != len(tt.exams) {
+				t.Fatalf("exam dates = %v, want %v", got, tt.exams)
+			}
+			for i := range got {
+				if got[i] != tt.exams[i] {
+					t.Errorf("exam dates = %v, want %v", got, tt.exams)
+				}
+			}
+			if data.Subjects[0].Exams[0].Date != "2026-10-15T09:00:00+01:00" {
+				t.Error("planning the import changed the data passed in")
+			}
+		})
+	}
+}
+
+// TestPlanICSImportAgain checks that importing a file without UIDs twice does
+// not duplicate its exams.
+func TestPlanICSImportAgain(t *testing.T) {
+	withFixedZone(t)
+	cal := ical.Calendar{Events: []ical.Event{
+		{Summary: "DB101 Quiz", Start: clock(t, "2026-10-20 10:00")},
+		{Summary: "DB101 Quiz", Start: clock(t, "2026-11-17 10:00")},
+	}}
+	first, err := PlanICSImport(storage.SemesterData{SchemaVersion: storage.SchemaVersion}, cal, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := first.Count(ICSNew); n != 2 {
+		t.Fatalf("first import: %s, want 2 new", first.Summary())
+	}
+	second, err := PlanICSImport(first.Data, cal, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Count(ICSNew)+second.Count(ICSUpdated) != 0 {
+		t.Errorf("second import: %s, want nothing new or updated", second.Summary())
+	}
+	if exams := second.Data.Subjects[0].Exams; len(exams) != 2 {
+		t.Errorf("exams = %+v, want 2", exams)
+	}
+}
@@ -0,0 +1,133 @@
+package app
+
+import "github.com/romanguyen/seman/internal/models"
+
+// The find*ByID helpers return -1 when no item has the ID, including for an
+// empty ID.
+
+func findSubjectByID(subjects []models.SubjectItem, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, s := range subjects {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// findExamByID returns the subject and exam index of the exam with the ID.
+func findExamByID(subjects []models.SubjectItem, id string) (int, int) {
+	if id == "" {
+		return -1, -1
+	}
+	for si, s := range subjects {
+		for ei, exam := range s.Exams {
+			if exam.ID == id {
+				return si, ei
+			}
+		}
+	}
+	return -1, -1
+}
+
+// findSessionByID returns the subject and session index of the class with
+// the ID.
+func findSessionByID(subjects []models.SubjectItem, id string) (int, int) {
+	if id == "" {
+		return -1, -1
+	}
+	for si, s := range subjects {
+		for ci, session := range s.Sessions {
+			if session.ID == id {
+				return si, ci
+			}
+		}
+	}
+	return -1, -1
+}
+
+func findExamIndex(exams []models.ExamItem, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, e := range exams {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findSessionIndex(sessions []models.ClassSession, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, s := range sessions {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findProjectByID(projects []models.ProjectItem, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, p := range projects {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findSubtaskIndex(subtasks []models.Subtask, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, t := range subtasks {
+		if t.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findResourceIndex(resources []models.Resource, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, r := range resources {
+		if r.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findMilestoneIndex(milestones []models.Milestone, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, ms := range milestones {
+		if ms.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func findTodoByID(items []models.ChecklistItem, id string) int {
+	if id == "" {
+		return -1
+	}
+	for i, item := range items {
+		if item.ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,486 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/config"
+)
+
+// Keymap rebinds actions: the keys of each action ID, by scope. The scope
+// is "global" or the name of a tab; a tab's bindings win over the global
+// ones, which apply to the actions of that ID on every tab.
+type Keymap map[string]map[string][]string
+
+const globalScope = "global"
+
+// tabScopes names the tabs in keys.toml.
+var tabScopes = map[int]string{
+	tabDashboard: "dashboard",
+	tabSubjects:  "subjects",
+	tabExams:     "exams",
+	tabTodos:     "todos",
+	tabProjects:  "projects",
+	tabGrades:    "grades",
+	tabTimetable: "timetable",
+	tabCalendar:  "calendar",
+	tabSettings:  "settings",
+	tabLofi:      "lofi",
+}
+
+// keymapPresets are the keymaps keys.toml can start from with preset = "...".
+var keymapPresets = map[string]Keymap{
+	"default": {},
+	"vim": {
+		globalScope: {
+			"previous-week": {"left", "h"},
+			"next-week":     {"right", "l"},
+			"undo":          {"ctrl+z", "u"},
+			"redo":          {"ctrl+y", "ctrl+r"},
+			"page-down":     {"pgdown", "ctrl+d"},
+			"page-up":       {"pgup", "ctrl+u"},
+		},
+		"calendar": {
+			"previous-month": {"[", "pgup", "ctrl+u"},
+			"next-month":     {"]", "pgdown", "ctrl+d"},
+		},
+	},
+	"emacs": {
+		globalScope: {
+			"command-palette": {"alt+x", ":"},
+			"search":          {"/", "ctrl+s"},
+			"undo":            {"ctrl+z", "ctrl+_"},
+			"redo":            {"ctrl+y", "alt+_"},
+			"down":            {"j", "down", "ctrl+n"},
+			"up":              {"k", "up", "ctrl+p"},
+			"scroll-down":     {"j", "down", "ctrl+n"},
+			"scroll-up":       {"k", "up", "ctrl+p"},
+			"previous-week":   {"left", "ctrl+b"},
+			"next-week":       {"right", "ctrl+f"},
+			"page-down":       {"pgdown", "ctrl+v"},
+			"page-up":         {"pgup", "alt+v"},
+		},
+		"calendar": {
+			"previous-day":   {"left", "h", "ctrl+b"},
+			"next-day":       {"right", "l", "ctrl+f"},
+			"previous-month": {"[", "pgup", "alt+v"},
+			"next-month":     {"]", "pgdown", "ctrl+v"},
+		},
+	},
+}
+
+// KeymapPath is where the keymap is read from: keys.toml in the
+// configuration directory.
+func KeymapPath() (string, error) {
+	return config.Path("keys.toml")
+}
+
+// LoadKeymap reads the keymap file at path. Without one the default keys
+// apply.
+func LoadKeymap(path string) (Keymap, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Keymap{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeymap(string(data))
+}
+
+// ParseKeymap reads a keys.toml: an optional preset, then tables of action
+// IDs and their keys, e.g.
+//
+//	preset = "vim"
+//
+//	[global]
+//	search = ["/", "ctrl+f"]
+//
+//	[todos]
+//	toggle = "space"
+//
+// Unknown scopes, actions and keys are errors, and so are two actions that
+// the same key would run.
+func ParseKeymap(data string) (Keymap, error) {
+	doc, err := config.ParseTOML(data)
+	if err != nil {
+		return nil, err
+	}
+	preset := "default"
+	for key, value := range doc[""] {
+		name, ok := value.(string)
+		if key != "preset" || !ok {
+			return nil, fmt.Errorf("unknown setting %s (bindings go under [global] or a tab)", key)
+		}
+		preset = strings.ToLower(strings.TrimSpace(name))
+	}
+	base, ok := keymapPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q (want default, vim or emacs)", preset)
+	}
+	keymap := Keymap{}
+	for scope, ids := range base {
+		keymap[scope] = map[string][]string{}
+		for id, keys := range ids {
+			keymap[scope][id] = keys
+		}
+	}
+
+	actions := defaultActions()
+	for scope, table := range doc {
+		if scope == "" {
+			continue
+		}
+		tab, isTab := scopeTab(scope)
+		if !isTab && scope != globalScope {
+			return nil, fmt.Errorf("unknown scope [%s] (want global or a tab: %s)", scope, strings.Join(scopeNames(), ", "))
+		}
+		for id := range table {
+			raw, _, err := table.Strings(id)
+			if err != nil {
+				return nil, fmt.Errorf("[%s] %v", scope, err)
+			}
+			if !hasAction(actions, id, tab, isTab) {
+				return nil, fmt.Errorf("[%s] unknown action %s", scope, id)
+			}
+			keys := make([]string, 0, len(raw))
+			for _, r := range raw {
+				key, err := normalizeKey(r)
+				if err != nil {
+					return nil, fmt.Errorf("[%s] %s: %v", scope, id, err)
+				}
+				keys = append(keys, key)
+			}
+			if keymap[scope] == nil {
+				keymap[scope] = map[string][]string{}
+			}
+			keymap[scope][id] = keys
+		}
+	}
+	if err := keymap.conflicts(actions); err != nil {
+		return nil, err
+	}
+	return keymap, nil
+}
+
+func scopeTab(scope string) (int, bool) {
+	for tab, name := range tabScopes {
+		if name == scope {
+			return tab, true
+		}
+	}
+	return 0, false
+}
+
+func scopeNames() []string {
+	names := make([]string, 0, len(tabScopes))
+	for _, name := range tabScopes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// hasAction reports whether an action has the ID, on tab if onTab.
+func hasAction(actions []action, id string, tab int, onTab bool) bool {
+	for _, a := range actions {
+		if a.id == id && (!onTab || a.onTab(tab)) {
+			return true
+		}
+	}
+	return false
+}
+
+// namedKeys are the keys besides single characters that can be bound, with
+// their spellings in keys.toml.
+var namedKeys = map[string]string{
+	"space": " ", "enter": "enter", "return": "enter", "esc": "esc", "escape": "esc",
+	"tab": "tab", "backspace": "backspace", "delete": "delete", "insert": "insert",
+	"home": "home", "end": "end", "pgup": "pgup", "pageup": "pgup", "pgdown": "pgdown",
+	"pagedown": "pgdown", "up": "up", "down": "down", "left": "left", "right": "right",
+}
+
+// normalizeKey turns a key as written in keys.toml ("Ctrl+Z", "space", "K")
+// into how the terminal reports it. A lowercase letter also matches with
+// shift; an uppercase one only with shift.
+func normalizeKey(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if len([]rune(raw)) == 1 {
+		if raw[0] >= '0' && raw[0] <= '9' {
+			return "", fmt.Errorf("%q switches tabs and cannot be bound", raw)
+		}
+		return raw, nil
+	}
+	parts := strings.Split(raw, "+")
+	key := parts[len(parts)-1]
+	mods := parts[:len(parts)-1]
+	for i, mod := range mods {
+		mods[i] = strings.ToLower(mod)
+		if mods[i] != "ctrl" && mods[i] != "alt" && mods[i] != "shift" {
+			return "", fmt.Errorf("unknown key %q (modifiers are ctrl, alt and shift)", raw)
+		}
+	}
+	switch lower := strings.ToLower(key); {
+	case namedKeys[lower] != "":
+		key = namedKeys[lower]
+	case isFunctionKey(lower):
+		key = lower
+	case len([]rune(key)) != 1:
+		return "", fmt.Errorf("unknown key %q", raw)
+	case len(mods) == 1 && mods[0] == "shift":
+		// The terminal reports shift+a as A.
+		return strings.ToUpper(key), nil
+	case len(mods) > 0 && mods[0] == "ctrl":
+		key = lower
+	}
+	if key == " " && len(mods) > 0 {
+		return "", fmt.Errorf("unknown key %q", raw)
+	}
+	return strings.Join(append(mods, key), "+"), nil
+}
+
+// isFunctionKey reports whether key is one of f1 to f20.
+func isFunctionKey(key string) bool {
+	n, err := strconv.Atoi(strings.TrimPrefix(key, "f"))
+	return strings.HasPrefix(key, "f") && err == nil && n >= 1 && n <= 20
+}
+
+// keysFor returns the keys that run a on tab.
+func (k Keymap) keysFor(a action, tab int) []string {
+	if keys, ok := k[tabScopes[tab]][a.id]; ok {
+		return keys
+	}
+	if keys, ok := k[globalScope][a.id]; ok {
+		return keys
+	}
+	return a.keys
+}
+
+// conflicts reports keys that would run two actions on the same tab. A
+// tab's own action shadowing a global one is not a conflict, and neither
+// are actions that each apply only in some state (like Enter opening the
+// plan or toggling its items).
+func (k Keymap) conflicts(actions []action) error {
+	tabs := make([]int, 0, len(tabScopes))
+	for tab := range tabScopes {
+		tabs = append(tabs, tab)
+	}
+	sort.Ints(tabs)
+	var errs []error
+	for _, tab := range tabs {
+		for _, global := range []bool{false, true} {
+			bound := map[string]int{}
+			for i, a := range actions {
+				if !a.onTab(tab) || (a.tabs == nil) != global {
+					continue
+				}
+				for _, key := range k.keysFor(a, tab) {
+					for _, variant := range keyVariants(key) {
+						j, ok := bound[variant]
+						if !ok {
+							bound[variant] = i
+							continue
+						}
+						if j == i || actions[j].when != nil && a.when != nil {
+							continue
+						}
+						scope := tabScopes[tab]
+						if global {
+							scope = globalScope
+						}
+						errs = append(errs, fmt.Errorf("[%s] %s runs both %s and %s", scope, keyLabel(variant), actions[j].id, a.id))
+					}
+				}
+			}
+		}
+	}
+	return errors.Join(dedupeErrors(errs)...)
+}
+
+// keyVariants lists the keys a binding matches: a lowercase letter also
+// with shift.
+func keyVariants(key string) []string {
+	if len(key) == 1 && key >= "a" && key <= "z" {
+		return []string{key, strings.ToUpper(key)}
+	}
+	return []string{key}
+}
+
+// dedupeErrors drops repeated messages: a global conflict shows on every
+// tab.
+func dedupeErrors(errs []error) []error {
+	seen := map[string]bool{}
+	var out []error
+	for _, err := range errs {
+		if !seen[err.Error()] {
+			seen[err.Error()] = true
+			out = append(out, err)
+		}
+	}
+	return out
+}
+
+// WithKeymap rebinds the actions as keymap says.
+func (m Model) WithKeymap(keymap Keymap) Model {
+	m.keymap = keymap
+	return m
+}
+
+// KeyBinding is an action and the keys that run it in one scope, as
+// "seman keys" lists them.
+type KeyBinding struct {
+	Scope  string   `json:"scope"`
+	Action string   `json:"action"`
+	Name   string   `json:"name"`
+	Keys   []string `json:"keys"`
+}
+
+// KeyBindings lists every action with the keys keymap gives it, spelled as
+// in keys.toml: the global ones first, then each tab's.
+func KeyBindings(keymap Keymap) []KeyBinding {
+	actions := defaultActions()
+	var bindings []KeyBinding
+	add := func(scope string, a action, tab int) {
+		keys := make([]string, 0, len(a.keys))
+		for _, key := range keymap.keysFor(a, tab) {
+			if key == " " {
+				key = "space"
+			}
+			keys = append(keys, key)
+		}
+		bindings = append(bindings, KeyBinding{Scope: scope, Action: a.id, Name: a.name, Keys: keys})
+	}
+	for _, a := range actions {
+		if a.tabs == nil {
+			add(globalScope, a, -1)
+		}
+	}
+	for _, scope := range scopeNames() {
+		tab, _ := scopeTab(scope)
+		for _, a := range actions {
+			if a.tabs != nil && a.onTab(tab) {
+				add(scope, a, tab)
+			}
+		}
+	}
+	return bindings
+}
+
+// findAction returns the action with the given ID on tab: the tab's own,
+// or else a global one.
+func (m Model) findAction(id string, tab int) (action, bool) {
+	var global *action
+	for i := range m.actions {
+		a := &m.actions[i]
+		if a.id != id || !a.onTab(tab) {
+			continue
+		}
+		if a.tabs != nil {
+			return *a, true
+		}
+		if global == nil {
+			global = a
+		}
+	}
+	if global == nil {
+		return action{}, false
+	}
+	return *global, true
+}
+
+// reachableKeys returns the keys that run a on tab: those of a global
+// action that the tab's own actions take are left out.
+func (m Model) reachableKeys(a action, tab int) []string {
+	keys := m.keymap.keysFor(a, tab)
+	if a.tabs != nil {
+		return keys
+	}
+	var reachable []string
+	for _, key := range keys {
+		taken := false
+		for _, other := range m.actions {
+			if other.tabs != nil && other.onTab(tab) && boundTo(m.keymap.keysFor(other, tab), key) {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			reachable = append(reachable, key)
+		}
+	}
+	return reachable
+}
+
+// keyHint is an entry of the footer: the first keys of the actions, then
+// the label.
+type keyHint struct {
+	ids   []string
+	label string
+}
+
+func hint(id, label string) keyHint {
+	return keyHint{ids: []string{id}, label: label}
+}
+
+// footerHints are the actions each tab's footer names.
+var footerHints = map[int][]keyHint{
+	tabDashboard: {hint("add-subject", "Subject"), hint("add-exam", "Exam"), hint("add-project", "Project"), hint("filter", "Filter"), hint("clear-filter", "Clear"), hint("global-view", "Global"), hint("this-week", "Today"), hint("semesters", "Semesters"), hint("quit", "Quit")},
+	tabSubjects:  {hint("add-subject", "Add subject"), hint("edit-notes", "Notes"), hint("add-resource", "Resource"), hint("switch-focus", "Resources"), hint("open-resource", "Open"), hint("edit", "Edit"), hint("delete", "Delete"), hint("quit", "Quit")},
+	tabExams:     {hint("add-exam", "Add exam"), hint("edit", "Edit"), hint("delete", "Delete"), hint("filter", "Filter"), hint("clear-filter", "Clear"), hint("global-view", "Global"), hint("this-week", "Today"), hint("quit", "Quit")},
+	tabTodos:     {hint("add-todo", "Add"), hint("edit", "Edit"), hint("delete", "Delete"), hint("toggle", "Toggle"), hint("filter", "Filter"), hint("clear-filter", "Clear"), hint("global-view", "Global"), hint("this-week", "Today"), hint("quit", "Quit")},
+	tabProjects:  {hint("add-project", "Add project"), hint("add-subtask", "Subtask"), hint("add-milestone", "Milestone"), hint("switch-focus", "Plan"), hint("toggle", "Toggle"), hint("timeline", "Timeline"), hint("edit", "Edit"), hint("delete", "Delete"), hint("filter", "Filter"), hint("clear-filter", "Clear"), hint("quit", "Quit")},
+	tabSettings:  {hint("theme", "Theme"), hint("date-format", "Dates"), hint("confirm-delete", "Confirm"), hint("week-span", "Week span"), hint("priority-rules", "Rules"), hint("lofi", "Lofi"), hint("lofi-url", "Lofi URL"), hint("export", "Export"), hint("import", "Import"), hint("backup", "Backup"), hint("restore", "Restore"), hint("semesters", "Semesters"), hint("quit", "Quit")},
+	tabGrades:    {hint("record-result", "Result"), hint("edit", "Edit exam"), hint("grading-scale", "Scale"), hint("quit", "Quit")},
+	tabTimetable: {hint("add-class", "Add class"), hint("edit", "Edit"), hint("delete", "Delete"), {ids: []string{"previous-week", "next-week"}, label: "Week"}, hint("this-week", "Today"), hint("quit", "Quit")},
+	tabCalendar:  {{ids: []string{"previous-day", "next-day", "up", "down"}, label: "Day"}, {ids: []string{"previous-month", "next-month"}, label: "Month"}, hint("open-agenda", "Agenda"), hint("add-exam", "Exam"), hint("add-project", "Project"), hint("add-todo", "Todo"), hint("edit", "Edit"), hint("filter", "Filter"), hint("today", "Today"), hint("quit", "Quit")},
+	tabLofi:      {hint("play", "Play"), hint("pause", "Pause"), hint("next", "Next"), hint("previous", "Prev"), hint("stop", "Stop"), hint("quit", "Quit")},
+}
+
+// footerHint renders the footer of the active tab from the keys its
+// actions are bound to; actions without keys are left out.
+func (m Model) footerHint() string {
+	var parts []string
+	for _, h := range footerHints[m.activeTab] {
+		if text := m.keyHint(h); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// keyHint renders h as "[K] Label", or "" when none of its actions has a
+// key. The keys of several actions are run together when they are arrows,
+// as in "[←→] Week".
+func (m Model) keyHint(h keyHint) string {
+	var labels []string
+	arrows := true
+	for _, id := range h.ids {
+		a, ok := m.findAction(id, m.activeTab)
+		if !ok {
+			continue
+		}
+		if keys := m.reachableKeys(a, m.activeTab); len(keys) > 0 {
+			label := keyLabel(keys[0])
+			arrows = arrows && strings.Contains("←→↑↓", label)
+			labels = append(labels, label)
+		}
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+	sep := " "
+	if arrows {
+		sep = ""
+	}
+	return "[" + strings.Join(labels, sep) + "] " + h.label
+}
+
+// actionHint is keyHint for a single action, for notices like
+// "[M] Semesters".
+func (m Model) actionHint(id, label string) string {
+	return m.keyHint(hint(id, label))
+}
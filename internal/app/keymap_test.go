@@ -0,0 +1,23 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeymapDottedKeys(t *testing.T) {
+	tables, err := ParseKeymap("[todos]\ntoggle = \"x\"\n")
+	if err != nil {
+		t.Fatalf("ParseKeymap: %v", err)
+	}
+	dotted, err := ParseKeymap("todos.toggle = \"x\"\n")
+	if err != nil {
+		t.Fatalf("ParseKeymap: %v", err)
+	}
+	if !reflect.DeepEqual(tables, dotted) {
+		t.Error("a dotted key binds differently from a table")
+	}
+	if got := dotted["todos"]["toggle"]; !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("todos toggle = %v, want [x]", got)
+	}
+}
@@ -2,12 +2,14 @@ package app
 
 import (
 	"fmt"
-	"strconv"
+	"path/filepath"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/dates"
 	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
 	"github.com/romanguyen/seman/internal/style"
 )
 
@@ -19,7 +21,6 @@ const (
 	modalAddExam
 	modalAddProject
 	modalAddTodo
-	modalBulkAddTodo
 	modalEditSubject
 	modalEditExam
 	modalEditProject
@@ -28,6 +29,30 @@ const (
 	modalFilterExam
 	modalSubjectFilter
 	modalConfirm
+	modalExportData
+	modalImportData
+	modalImportPreview
+	modalRestoreBackup
+	modalPriorityRules
+	modalICSPreview
+	modalSemesters
+	modalNewSemester
+	modalEditSemester
+	modalCarryOver
+	modalExamResult
+	modalAddSession
+	modalEditSession
+	modalTodoScope
+	modalAddSubtask
+	modalEditSubtask
+	modalAddMilestone
+	modalEditMilestone
+	modalSearch
+	modalPalette
+	modalHistory
+	modalAddResource
+	modalEditResource
+	modalEditPlanList
 )
 
 type confirmKind int
@@ -37,14 +62,18 @@ const (
 	confirmDeleteExam
 	confirmDeleteProject
 	confirmDeleteTodo
+	confirmDeleteSession
+	confirmDeleteSubtask
+	confirmDeleteMilestone
+	confirmDeleteResource
 	confirmClearAll
 )
 
+// confirmAction names the item to delete by ID, so it still hits the right
+// item if the lists were re-sorted while the confirmation was open.
 type confirmAction struct {
-	kind       confirmKind
-	subjectIdx int
-	examIdx    int
-	projectIdx int
+	kind confirmKind
+	id   string
 }
 
 type formField struct {
@@ -53,6 +82,43 @@ type formField struct {
 	required bool
 }
 
+// Date fields by label: the forms show what these read as under the field.
+// Day fields drop any time of day; list fields take several dates.
+var (
+	dateFields     = map[string]bool{"Date": true, "Deadline": true, "Due": true}
+	dayFields      = map[string]bool{"From": true, "Until": true, "Start": true, "End": true}
+	dateListFields = map[string]bool{"Retakes": true}
+)
+
+// datePreview renders how the value of a date field reads, e.g. "Fri Jan 16,
+// 2026 10:00". ok is false when some of it is not a date; an empty preview
+// means there is nothing to show.
+func (m Model) datePreview(field formField) (preview string, ok bool) {
+	value := strings.TrimSpace(field.input.Value())
+	label := field.label
+	if value == "" || !dateFields[label] && !dayFields[label] && !dateListFields[label] {
+		return "", true
+	}
+	raws := []string{value}
+	if dateListFields[label] {
+		raws = splitDateList(value)
+	}
+	dc := m.dateContext()
+	shown := make([]string, 0, len(raws))
+	for _, raw := range raws {
+		t, ok := parseDateInput(raw, dc)
+		if !ok {
+			return fmt.Sprintf("%q is not a date", raw), false
+		}
+		if dayFields[label] {
+			t = dayOf(t)
+			raw = dates.StoreDay(t)
+		}
+		shown = append(shown, formatDatePreview(raw, t))
+	}
+	return strings.Join(shown, "; "), true
+}
+
 // isSubjectField reports whether the currently focused form field is a Subject field.
 func (m *Model) isSubjectField() bool {
 	if m.formFocus < 0 || m.formFocus >= len(m.formFields) {
@@ -108,6 +174,66 @@ func (m Model) updateModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 	}
+	if m.modal == modalSearch {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSearchModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalPalette {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updatePaletteModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalImportPreview {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateImportPreviewModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalRestoreBackup {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateBackupPickerModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalICSPreview {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateICSPreviewModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalSemesters {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateSemestersModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalHistory {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateHistoryModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalEditPlanList {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updatePlanListModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalCarryOver {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateCarryOverModal(key)
+		}
+		return m, nil
+	}
+	if m.modal == modalTodoScope {
+		if key, ok := msg.(tea.KeyMsg); ok {
+			return m.updateTodoScopeModal(key)
+		}
+		return m, nil
+	}
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		key := msg.String()
@@ -117,20 +243,12 @@ func (m Model) updateModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		case "enter":
 			if m.modal == modalConfirm {
-				m.pushUndo()
 				m.applyConfirmAction()
 				m.closeModal()
 				return m, nil
 			}
 			if m.formFocus == len(m.formFields)-1 {
-				m.pushUndo()
-				if err := m.submitForm(); err != nil {
-					m.undoStack = m.undoStack[:len(m.undoStack)-1]
-					m.modalError = err.Error()
-					return m, nil
-				}
-				cmd := m.consumeLofiReload()
-				m.closeModal()
+				cmd := m.submitFormModal()
 				return m, cmd
 			}
 			m.setFormFocus(m.formFocus + 1)
@@ -178,6 +296,10 @@ func (m Model) updateModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.refreshDropdown()
 				return m, nil
 			}
+		case "ctrl+o":
+			if m.modal != modalConfirm && len(m.formFields) > 0 {
+				return m, m.launchEditor(formDocument(m.modalTitle, m.formFields))
+			}
 		case "y", "Y":
 			if m.modal == modalConfirm {
 				m.applyConfirmAction()
@@ -199,11 +321,32 @@ func (m Model) updateModal(msg tea.Msg) (tea.Model, tea.Cmd) {
 	field := m.formFields[m.formFocus]
 	var cmd tea.Cmd
 	field.input, cmd = field.input.Update(msg)
+	if field.input.Value() != m.formFields[m.formFocus].input.Value() {
+		// The form changed since a file failed to parse; Ctrl+O writes it anew.
+		m.editorText = ""
+	}
 	m.formFields[m.formFocus] = field
 	m.refreshDropdown()
 	return m, cmd
 }
 
+// submitFormModal submits the open form and closes it, unless that failed,
+// which the form shows, or opened a follow-up modal (e.g. the import
+// preview).
+func (m *Model) submitFormModal() tea.Cmd {
+	kind := m.modal
+	if err := m.submitForm(); err != nil {
+		m.modalError = err.Error()
+		return nil
+	}
+	if m.modal != kind {
+		return nil
+	}
+	cmd := m.consumeLofiReload()
+	m.closeModal()
+	return cmd
+}
+
 func (m *Model) closeModal() {
 	m.modal = modalNone
 	m.formFields = nil
@@ -211,14 +354,40 @@ func (m *Model) closeModal() {
 	m.modalTitle = ""
 	m.modalHint = ""
 	m.modalError = ""
-	m.editSubjectIdx = -1
-	m.editExamIdx = -1
-	m.editProjectIdx = -1
-	m.editTodoIdx = -1
+	m.editSubjectID = ""
+	m.editExamID = ""
+	m.editProjectID = ""
+	m.editTodoID = ""
+	m.editTodoDate = ""
+	m.editTodoFuture = false
+	m.scopeDelete = false
+	m.scopeCursor = 0
+	m.editSessionID = ""
+	m.editResourceID = ""
 	m.dropdownMatches = nil
 	m.dropdownCursor = -1
 	m.filterModalActive = nil
 	m.filterModalCursor = 0
+	m.importData = storage.SemesterData{}
+	m.importSource = ""
+	m.backups = nil
+	m.backupCursor = 0
+	m.icsImport = ICSImport{}
+	m.icsNewPattern = ""
+	m.icsCursor = 0
+	m.semesters = nil
+	m.semesterCursor = 0
+	m.editSemesterID = ""
+	m.newSemester = storage.Semester{}
+	m.carryTodos = nil
+	m.searchResults = nil
+	m.searchCursor = 0
+	m.paletteItems = nil
+	m.paletteCursor = 0
+	m.historyCursor = 0
+	m.editorText = ""
+	m.editorOriginal = ""
+	m.editorStandalone = false
 }
 
 func (m Model) updateSubjectFilterModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -276,7 +445,9 @@ func (m *Model) openAddSubject() {
 	fields := []formField{
 		newFormField("Code", inputWidth, true),
 		newFormField("Name", inputWidth, true),
+		newFormField("Credits", inputWidth, false),
 	}
+	fields[2].input.Placeholder = "ECTS or credit hours"
 	m.openFormModal(modalAddSubject, "Add Subject", fields)
 }
 
@@ -292,13 +463,18 @@ func (m *Model) openAddExamWithFilter() {
 		newFormField("Date", inputWidth, true),
 		newFormField("Retakes", inputWidth, false),
 		newFormField("Priority", inputWidth, false),
+		newFormField("Weight", inputWidth, false),
 	}
-	if m.activeTab == tabExams && len(m.subjectFilters) == 1 {
+	if (m.activeTab == tabExams || m.activeTab == tabCalendar) && len(m.subjectFilters) == 1 {
 		fields[0].input.SetValue(m.subjectFilters[0])
 	}
-	fields[2].input.Placeholder = "Jan 2, 2006 @ 15:04"
+	fields[2].input.Placeholder = "Jan 2, 2006 @ 15:04 or fri 10:00"
 	fields[3].input.Placeholder = "Jan 5, 2006, Jan 8, 2006"
-	fields[4].input.Placeholder = "HIGH / MED / LOW"
+	fields[4].input.Placeholder = "HIGH / MED / LOW / AUTO"
+	if m.priorityRules.AutoDefault {
+		fields[4].input.Placeholder = "AUTO (or HIGH / MED / LOW)"
+	}
+	fields[5].input.Placeholder = "% of the subject grade (blank: equal share)"
 	m.openFormModal(modalAddExam, "Add Exam", fields)
 }
 
@@ -315,49 +491,52 @@ func (m *Model) openSubjectFilter() {
 	m.formFields = nil
 }
 
-func (m *Model) openAddProject() {
+func (m *Model) projectFields() []formField {
 	inputWidth := m.modalInputWidth()
 	fields := []formField{
 		newFormField("Name", inputWidth, true),
 		newFormField("Subject", inputWidth, true),
+		newFormField("Start", inputWidth, false),
 		newFormField("Deadline", inputWidth, true),
 		newFormField("Status", inputWidth, false),
 	}
-	m.openFormModal(modalAddProject, "Add Project", fields)
+	fields[2].input.Placeholder = "first day of work (optional)"
+	fields[4].input.Placeholder = "NOT STARTED / IN PROGRESS / DONE / AUTO"
+	return fields
 }
 
-func (m *Model) openAddTodo() {
-	inputWidth := m.modalInputWidth()
-	fields := []formField{
-		newFormField("Task", inputWidth, true),
-		newFormField("Subject", inputWidth, false),
-	}
-	m.openFormModal(modalAddTodo, "Add Todo", fields)
+func (m *Model) openAddProject() {
+	m.openFormModal(modalAddProject, "Add Project", m.projectFields())
 }
 
-func (m *Model) openBulkAddTodo() {
-	w := m.modalInputWidth()
-	fields := []formField{
-		newFormField("Task Name", w, true),
-		newFormField("Subject", w, false),
-		newFormField("Weeks", w, true),
-		newFormField("Start #", w, false),
-	}
-	fields[2].input.Placeholder = "e.g. 12"
-	fields[3].input.Placeholder = "1"
-	m.openFormModal(modalBulkAddTodo, "Bulk Add Todos", fields)
+func (m *Model) openAddTodo() {
+	m.openFormModal(modalAddTodo, "Add Todo", m.todoFields(true))
 }
 
 func (m *Model) openEditCurrent() {
 	switch m.activeTab {
 	case tabSubjects:
-		m.openEditSubject()
+		if m.subjectFocus {
+			m.openEditResource()
+		} else {
+			m.openEditSubject()
+		}
 	case tabExams:
 		m.openEditExam()
 	case tabTodos:
 		m.openEditTodo()
 	case tabProjects:
-		m.openEditProject()
+		if m.projectFocus {
+			m.openEditPlanItem()
+		} else {
+			m.openEditProject()
+		}
+	case tabGrades:
+		if rows := m.gradeRows(); m.gradeCursor >= 0 && m.gradeCursor < len(rows) {
+			m.openEditExamItem(rows[m.gradeCursor])
+		}
+	case tabTimetable:
+		m.openEditSession()
 	}
 }
 
@@ -370,10 +549,15 @@ func (m *Model) openEditSubject() {
 	fields := []formField{
 		newFormField("Code", inputWidth, true),
 		newFormField("Name", inputWidth, true),
+		newFormField("Credits", inputWidth, false),
 	}
 	fields[0].input.SetValue(subj.Code)
 	fields[1].input.SetValue(subj.Name)
-	m.editSubjectIdx = m.selectedSubj
+	if subj.Credits > 0 {
+		fields[2].input.SetValue(FormatNumber(subj.Credits))
+	}
+	fields[2].input.Placeholder = "ECTS or credit hours"
+	m.editSubjectID = subj.ID
 	m.openFormModal(modalEditSubject, "Edit Subject", fields)
 }
 
@@ -381,7 +565,10 @@ func (m *Model) openEditExam() {
 	if m.examCursor < 0 || m.examCursor >= len(m.flatExams) {
 		return
 	}
-	flat := m.flatExams[m.examCursor]
+	m.openEditExamItem(m.flatExams[m.examCursor])
+}
+
+func (m *Model) openEditExamItem(flat models.FlatExam) {
 	exam := flat.Exam
 	inputWidth := m.modalInputWidth()
 	fields := []formField{
@@ -389,13 +576,18 @@ func (m *Model) openEditExam() {
 		newFormField("Date", inputWidth, true),
 		newFormField("Retakes", inputWidth, false),
 		newFormField("Priority", inputWidth, false),
+		newFormField("Weight", inputWidth, false),
 	}
 	fields[0].input.SetValue(exam.Name)
-	fields[1].input.SetValue(exam.Date)
-	fields[2].input.SetValue(strings.Join(exam.Retakes, ", "))
+	fields[1].input.SetValue(m.dateFormat.ShowStored(exam.Date))
+	fields[2].input.SetValue(m.dateFormat.ShowList(exam.Retakes))
 	fields[3].input.SetValue(exam.Priority)
-	m.editSubjectIdx = flat.SubjectIdx
-	m.editExamIdx = flat.ExamIdx
+	fields[3].input.Placeholder = "HIGH / MED / LOW / AUTO"
+	if exam.Weight > 0 {
+		fields[4].input.SetValue(FormatNumber(exam.Weight))
+	}
+	fields[4].input.Placeholder = "% of the subject grade (blank: equal share)"
+	m.editExamID = exam.ID
 	m.openFormModal(modalEditExam, "Edit Exam ("+flat.SubjectCode+")", fields)
 }
 
@@ -404,18 +596,16 @@ func (m *Model) openEditProject() {
 		return
 	}
 	project := m.projects[m.projectCursor]
-	inputWidth := m.modalInputWidth()
-	fields := []formField{
-		newFormField("Name", inputWidth, true),
-		newFormField("Subject", inputWidth, true),
-		newFormField("Deadline", inputWidth, true),
-		newFormField("Status", inputWidth, false),
-	}
+	fields := m.projectFields()
 	fields[0].input.SetValue(project.Name)
 	fields[1].input.SetValue(project.Subject)
-	fields[2].input.SetValue(project.Due)
-	fields[3].input.SetValue(project.Status)
-	m.editProjectIdx = m.projectCursor
+	fields[2].input.SetValue(m.dateFormat.ShowStored(project.Start))
+	fields[3].input.SetValue(m.dateFormat.ShowStored(project.Due))
+	fields[4].input.SetValue(project.Status)
+	if project.AutoStatus {
+		fields[4].input.SetValue(models.StatusAuto)
+	}
+	m.editProjectID = project.ID
 	m.openFormModal(modalEditProject, "Edit Project", fields)
 }
 
@@ -424,14 +614,16 @@ func (m *Model) openEditTodo() {
 		return
 	}
 	item := m.checklistItems[m.checklistCursor]
-	inputWidth := m.modalInputWidth()
-	fields := []formField{
-		newFormField("Task", inputWidth, true),
-		newFormField("Subject", inputWidth, false),
+	if m.checklistDate != "" {
+		m.openTodoScope(item, m.checklistDate, false)
+		return
 	}
+	fields := m.todoFields(true)
 	fields[0].input.SetValue(item.Text)
 	fields[1].input.SetValue(item.Subject)
-	m.editTodoIdx = m.checklistCursor
+	fields[2].input.SetValue(m.dateFormat.ShowStored(item.Due))
+	fields[3].input.SetValue(describeRepeat(item, ""))
+	m.editTodoID = item.ID
 	m.openFormModal(modalEditTodo, "Edit Todo", fields)
 }
 
@@ -445,7 +637,7 @@ func (m *Model) openEditLofiURL() {
 }
 
 func (m *Model) openFormModal(kind modalKind, title string, fields []formField) {
-	t := style.ThemeOf(m.themeName)
+	t := m.theme()
 	for i := range fields {
 		fields[i].input.TextStyle = t.InputText
 		fields[i].input.PlaceholderStyle = t.InputHint
@@ -462,9 +654,9 @@ func (m *Model) openFormModal(kind modalKind, title string, fields []formField)
 		}
 	}
 	if hasSubjectField {
-		m.modalHint = "Tab/↑↓ select subject · Enter to advance · Esc to cancel"
+		m.modalHint = "Tab/↑↓ subject · Enter next · Ctrl+O editor · Esc cancel"
 	} else {
-		m.modalHint = "Tab to switch · Enter to save · Esc to cancel"
+		m.modalHint = "Tab switch · Enter save · Ctrl+O editor · Esc cancel"
 	}
 	m.modalError = ""
 	m.dropdownCursor = 0
@@ -491,205 +683,136 @@ func newFormField(label string, width int, required bool) formField {
 func (m *Model) submitForm() error {
 	switch m.modal {
 	case modalAddSubject:
-		code := strings.TrimSpace(m.formFields[0].input.Value())
-		name := strings.TrimSpace(m.formFields[1].input.Value())
-		if code == "" || name == "" {
-			return fmt.Errorf("Code and Name are required.")
+		subject, err := BuildSubject(m.formFields[0].input.Value(), m.formFields[1].input.Value(), m.formFields[2].input.Value())
+		if err != nil {
+			return err
 		}
-		m.subjects = append(m.subjects, models.SubjectItem{Code: code, Name: name})
+		m.subjects = append(m.subjects, subject)
 		m.selectedSubj = len(m.subjects) - 1
 		m.persist()
 	case modalAddExam:
-		subjectCode := strings.TrimSpace(m.formFields[0].input.Value())
-		examName := strings.TrimSpace(m.formFields[1].input.Value())
-		date := strings.TrimSpace(m.formFields[2].input.Value())
-		retakesRaw := strings.TrimSpace(m.formFields[3].input.Value())
-		priority := strings.TrimSpace(m.formFields[4].input.Value())
-		if subjectCode == "" || examName == "" || date == "" {
-			return fmt.Errorf("Subject, Exam Name, and Date are required.")
-		}
-		idx := findSubjectIndex(m.subjects, subjectCode)
-		if idx < 0 {
-			return fmt.Errorf("Subject code not found.")
-		}
-		retakes := splitCSV(retakesRaw)
-		m.subjects[idx].Exams = append(m.subjects[idx].Exams, models.ExamItem{
-			Name:     examName,
-			Date:     date,
-			Retakes:  retakes,
-			Priority: strings.ToUpper(priority),
-		})
-		newExamIdx := len(m.subjects[idx].Exams) - 1
+		idx, exam, err := BuildExam(m.subjects, m.priorityRules, m.dateContext(),
+			m.formFields[0].input.Value(),
+			m.formFields[1].input.Value(),
+			m.formFields[2].input.Value(),
+			m.formFields[3].input.Value(),
+			m.formFields[4].input.Value(),
+			m.formFields[5].input.Value(),
+		)
+		if err != nil {
+			return err
+		}
+		m.subjects[idx].Exams = append(m.subjects[idx].Exams, exam)
 		m.refreshFlatExams()
-		for i, flat := range m.flatExams {
-			if flat.SubjectIdx == idx && flat.ExamIdx == newExamIdx {
-				m.examCursor = i
-				break
-			}
-		}
+		m.selectFlatExam(exam.ID)
 		m.persist()
 	case modalAddProject:
-		name := strings.TrimSpace(m.formFields[0].input.Value())
-		subject := strings.TrimSpace(m.formFields[1].input.Value())
-		deadline := strings.TrimSpace(m.formFields[2].input.Value())
-		status := strings.TrimSpace(m.formFields[3].input.Value())
-		if name == "" || subject == "" || deadline == "" {
-			return fmt.Errorf("Name, Subject, and Deadline are required.")
-		}
-		if status == "" {
-			status = "NOT STARTED"
-		}
-		m.projects = append(m.projects, models.ProjectItem{
-			Name:    name,
-			Subject: subject,
-			Due:     deadline,
-			Status:  strings.ToUpper(status),
-		})
+		project, err := BuildProject(m.dateContext(),
+			m.formFields[0].input.Value(),
+			m.formFields[1].input.Value(),
+			m.formFields[2].input.Value(),
+			m.formFields[3].input.Value(),
+			m.formFields[4].input.Value(),
+		)
+		if err != nil {
+			return err
+		}
+		m.projects = append(m.projects, project)
 		m.projectCursor = len(m.projects) - 1
 		m.sortProjectsByStatus()
 		m.refreshProjectFilter()
 		m.persist()
 	case modalAddTodo:
-		task := strings.TrimSpace(m.formFields[0].input.Value())
-		subject := strings.TrimSpace(m.formFields[1].input.Value())
-		if task == "" {
-			return fmt.Errorf("Task is required.")
-		}
-		if subject != "" && findSubjectIndex(m.subjects, subject) < 0 {
-			return fmt.Errorf("Subject not found.")
-		}
-		subjectCode := strings.ToUpper(subject)
-		m.checklistItems = append(m.checklistItems, models.ChecklistItem{
-			Text:    task,
-			Done:    false,
-			Due:     m.weekStart.Format("2006-01-02"),
-			Subject: subjectCode,
-		})
-		m.checklistCursor = len(m.checklistItems) - 1
-		m.sortChecklistByDone()
-		m.persist()
-		m.refreshChecklistView()
-	case modalBulkAddTodo:
-		baseName := strings.TrimSpace(m.formFields[0].input.Value())
-		subject := strings.TrimSpace(m.formFields[1].input.Value())
-		weeksStr := strings.TrimSpace(m.formFields[2].input.Value())
-		startStr := strings.TrimSpace(m.formFields[3].input.Value())
-		if baseName == "" {
-			return fmt.Errorf("Task Name is required.")
-		}
-		weeks, err := strconv.Atoi(weeksStr)
-		if err != nil || weeks < 1 {
-			return fmt.Errorf("Weeks must be a positive number.")
-		}
-		if weeks > 52 {
-			return fmt.Errorf("Weeks must be 52 or fewer.")
-		}
-		startNum := 1
-		if startStr != "" {
-			n, err := strconv.Atoi(startStr)
-			if err != nil || n < 1 {
-				return fmt.Errorf("Start # must be a positive number.")
-			}
-			startNum = n
-		}
-		if subject != "" && findSubjectIndex(m.subjects, subject) < 0 {
-			return fmt.Errorf("Subject not found.")
-		}
-		subjectCode := strings.ToUpper(subject)
-		for i := 0; i < weeks; i++ {
-			due := m.weekStart.AddDate(0, 0, i*7)
-			m.checklistItems = append(m.checklistItems, models.ChecklistItem{
-				Text:    fmt.Sprintf("%s - Week %d", baseName, startNum+i),
-				Done:    false,
-				Due:     due.Format("2006-01-02"),
-				Subject: subjectCode,
-			})
+		item, err := BuildTodo(m.subjects, m.dateContext(), m.formFields[0].input.Value(), m.formFields[1].input.Value(), m.formFields[2].input.Value(), m.formFields[3].input.Value())
+		if err != nil {
+			return err
 		}
+		m.checklistItems = append(m.checklistItems, item)
 		m.checklistCursor = len(m.checklistItems) - 1
+		m.checklistDate = todoFirstDay(item)
 		m.sortChecklistByDone()
 		m.persist()
 		m.refreshChecklistView()
 	case modalEditSubject:
-		if m.editSubjectIdx < 0 || m.editSubjectIdx >= len(m.subjects) {
+		si := findSubjectByID(m.subjects, m.editSubjectID)
+		if si < 0 {
 			return nil
 		}
-		code := strings.TrimSpace(m.formFields[0].input.Value())
-		name := strings.TrimSpace(m.formFields[1].input.Value())
-		if code == "" || name == "" {
-			return fmt.Errorf("Code and Name are required.")
+		subject, err := BuildSubject(m.formFields[0].input.Value(), m.formFields[1].input.Value(), m.formFields[2].input.Value())
+		if err != nil {
+			return err
 		}
-		m.subjects[m.editSubjectIdx].Code = code
-		m.subjects[m.editSubjectIdx].Name = name
+		m.subjects[si].Code = subject.Code
+		m.subjects[si].Name = subject.Name
+		m.subjects[si].Credits = subject.Credits
 		m.persist()
 	case modalEditExam:
-		if m.editSubjectIdx < 0 || m.editSubjectIdx >= len(m.subjects) {
+		si, ei := findExamByID(m.subjects, m.editExamID)
+		if si < 0 {
 			return nil
 		}
-		exams := m.subjects[m.editSubjectIdx].Exams
-		if m.editExamIdx < 0 || m.editExamIdx >= len(exams) {
-			return nil
-		}
-		examName := strings.TrimSpace(m.formFields[0].input.Value())
-		date := strings.TrimSpace(m.formFields[1].input.Value())
-		retakesRaw := strings.TrimSpace(m.formFields[2].input.Value())
-		priority := strings.TrimSpace(m.formFields[3].input.Value())
-		if examName == "" || date == "" {
-			return fmt.Errorf("Exam Name and Date are required.")
-		}
-		exams[m.editExamIdx].Name = examName
-		exams[m.editExamIdx].Date = date
-		exams[m.editExamIdx].Retakes = splitCSV(retakesRaw)
-		exams[m.editExamIdx].Priority = strings.ToUpper(priority)
-		m.subjects[m.editSubjectIdx].Exams = exams
-		editedSI := m.editSubjectIdx
-		editedEI := m.editExamIdx
+		exams := m.subjects[si].Exams
+		exam, err := UpdateExam(exams[ei], m.priorityRules, m.dateContext(),
+			m.formFields[0].input.Value(),
+			m.formFields[1].input.Value(),
+			m.formFields[2].input.Value(),
+			m.formFields[3].input.Value(),
+			m.formFields[4].input.Value(),
+		)
+		if err != nil {
+			return err
+		}
+		exams[ei] = exam
 		m.refreshFlatExams()
-		for i, flat := range m.flatExams {
-			if flat.SubjectIdx == editedSI && flat.ExamIdx == editedEI {
-				m.examCursor = i
-				break
-			}
-		}
+		m.selectFlatExam(exam.ID)
 		m.persist()
 	case modalEditProject:
-		if m.editProjectIdx < 0 || m.editProjectIdx >= len(m.projects) {
+		pi := findProjectByID(m.projects, m.editProjectID)
+		if pi < 0 {
 			return nil
 		}
-		name := strings.TrimSpace(m.formFields[0].input.Value())
-		subject := strings.TrimSpace(m.formFields[1].input.Value())
-		deadline := strings.TrimSpace(m.formFields[2].input.Value())
-		status := strings.TrimSpace(m.formFields[3].input.Value())
-		if name == "" || subject == "" || deadline == "" {
-			return fmt.Errorf("Name, Subject, and Deadline are required.")
-		}
-		if status == "" {
-			status = "NOT STARTED"
-		}
-		m.projects[m.editProjectIdx].Name = name
-		m.projects[m.editProjectIdx].Subject = subject
-		m.projects[m.editProjectIdx].Due = deadline
-		m.projects[m.editProjectIdx].Status = strings.ToUpper(status)
-		m.projectCursor = m.editProjectIdx
+		project, err := UpdateProject(m.projects[pi], m.dateContext(),
+			m.formFields[0].input.Value(),
+			m.formFields[1].input.Value(),
+			m.formFields[2].input.Value(),
+			m.formFields[3].input.Value(),
+			m.formFields[4].input.Value(),
+		)
+		if err != nil {
+			return err
+		}
+		m.projects[pi] = project
+		m.projectCursor = pi
 		m.sortProjectsByStatus()
 		m.refreshProjectFilter()
 		m.persist()
 	case modalEditTodo:
-		if m.editTodoIdx < 0 || m.editTodoIdx >= len(m.checklistItems) {
-			return nil
-		}
-		task := strings.TrimSpace(m.formFields[0].input.Value())
-		subject := strings.TrimSpace(m.formFields[1].input.Value())
-		if task == "" {
-			return fmt.Errorf("Task is required.")
-		}
-		if subject != "" && findSubjectIndex(m.subjects, subject) < 0 {
-			return fmt.Errorf("Subject not found.")
-		}
-		m.checklistItems[m.editTodoIdx].Text = task
-		m.checklistItems[m.editTodoIdx].Subject = strings.ToUpper(subject)
-		m.sortChecklistByDone()
-		m.persist()
-		m.refreshChecklistView()
+		return m.submitEditTodo()
+	case modalExportData:
+		return m.submitExport(m.formFields[0].input.Value(), m.formFields[1].input.Value())
+	case modalImportData:
+		path := strings.TrimSpace(m.formFields[0].input.Value())
+		if path == "" {
+			return fmt.Errorf("Path is required.")
+		}
+		if strings.EqualFold(filepath.Ext(path), ".ics") {
+			return m.previewICSImport(path, m.formFields[1].input.Value())
+		}
+		return m.previewImport(path)
+	case modalPriorityRules:
+		return m.submitPriorityRules()
+	case modalNewSemester, modalEditSemester:
+		return m.submitSemester()
+	case modalExamResult:
+		return m.submitExamResult()
+	case modalAddSession, modalEditSession:
+		return m.submitSession()
+	case modalAddSubtask, modalEditSubtask:
+		return m.submitSubtask()
+	case modalAddMilestone, modalEditMilestone:
+		return m.submitMilestone()
+	case modalAddResource, modalEditResource:
+		return m.submitResource()
 	case modalFilterExam:
 		// legacy: handled by modalSubjectFilter now; kept for safety
 	case modalEditLofiURL:
@@ -736,10 +859,14 @@ func splitCSV(raw string) []string {
 func (m *Model) queueDelete() {
 	switch m.activeTab {
 	case tabSubjects:
+		if m.subjectFocus {
+			m.queueDeleteResource()
+			return
+		}
 		if len(m.subjects) == 0 {
 			return
 		}
-		action := confirmAction{kind: confirmDeleteSubject, subjectIdx: m.selectedSubj}
+		action := confirmAction{kind: confirmDeleteSubject, id: m.subjects[m.selectedSubj].ID}
 		message := fmt.Sprintf("Delete subject %s and its exams?", m.subjects[m.selectedSubj].Code)
 		m.confirmOrApply(action, message)
 	case tabExams:
@@ -747,22 +874,39 @@ func (m *Model) queueDelete() {
 			return
 		}
 		flat := m.flatExams[m.examCursor]
-		action := confirmAction{kind: confirmDeleteExam, subjectIdx: flat.SubjectIdx, examIdx: flat.ExamIdx}
+		action := confirmAction{kind: confirmDeleteExam, id: flat.Exam.ID}
 		message := fmt.Sprintf("Delete exam \"%s\" (%s)?", flat.Exam.Name, flat.SubjectCode)
 		m.confirmOrApply(action, message)
 	case tabProjects:
+		if m.projectFocus {
+			m.queueDeletePlanItem()
+			return
+		}
 		if len(m.projects) == 0 {
 			return
 		}
-		action := confirmAction{kind: confirmDeleteProject, projectIdx: m.projectCursor}
+		action := confirmAction{kind: confirmDeleteProject, id: m.projects[m.projectCursor].ID}
 		message := fmt.Sprintf("Delete project %s?", m.projects[m.projectCursor].Name)
 		m.confirmOrApply(action, message)
 	case tabTodos:
-		if len(m.checklistItems) == 0 || m.checklistCursor < 0 || m.checklistCursor >= len(m.checklistItems) {
+		item, day, ok := m.selectedTodo()
+		if !ok {
 			return
 		}
-		action := confirmAction{kind: confirmDeleteTodo, projectIdx: m.checklistCursor}
-		message := fmt.Sprintf("Delete task \"%s\"?", m.checklistItems[m.checklistCursor].Text)
+		if day != "" {
+			m.openTodoScope(item, day, true)
+			return
+		}
+		action := confirmAction{kind: confirmDeleteTodo, id: item.ID}
+		message := fmt.Sprintf("Delete task \"%s\"?", item.Text)
+		m.confirmOrApply(action, message)
+	case tabTimetable:
+		entry, ok := m.selectedSession()
+		if !ok {
+			return
+		}
+		action := confirmAction{kind: confirmDeleteSession, id: entry.Session.ID}
+		message := fmt.Sprintf("Delete %s %s on %s %s?", entry.SubjectCode, entry.Session.Type, entry.Session.Day, entry.Session.TimeRange())
 		m.confirmOrApply(action, message)
 	}
 }
@@ -789,18 +933,14 @@ func (m *Model) confirmOrApply(action confirmAction, message string) {
 func (m *Model) applyConfirmAction() {
 	switch m.confirmAction.kind {
 	case confirmDeleteExam:
-		si := m.confirmAction.subjectIdx
-		ei := m.confirmAction.examIdx
-		if si >= 0 && si < len(m.subjects) {
+		if si, ei := findExamByID(m.subjects, m.confirmAction.id); si >= 0 {
 			exams := m.subjects[si].Exams
-			if ei >= 0 && ei < len(exams) {
-				m.subjects[si].Exams = append(exams[:ei], exams[ei+1:]...)
-			}
+			m.subjects[si].Exams = append(exams[:ei], exams[ei+1:]...)
 		}
 		m.refreshFlatExams()
 	case confirmDeleteSubject:
-		if m.confirmAction.subjectIdx >= 0 && m.confirmAction.subjectIdx < len(m.subjects) {
-			m.subjects = append(m.subjects[:m.confirmAction.subjectIdx], m.subjects[m.confirmAction.subjectIdx+1:]...)
+		if si := findSubjectByID(m.subjects, m.confirmAction.id); si >= 0 {
+			m.subjects = append(m.subjects[:si], m.subjects[si+1:]...)
 			if m.selectedSubj >= len(m.subjects) {
 				m.selectedSubj = len(m.subjects) - 1
 			}
@@ -809,8 +949,8 @@ func (m *Model) applyConfirmAction() {
 			}
 		}
 	case confirmDeleteProject:
-		if m.confirmAction.projectIdx >= 0 && m.confirmAction.projectIdx < len(m.projects) {
-			m.projects = append(m.projects[:m.confirmAction.projectIdx], m.projects[m.confirmAction.projectIdx+1:]...)
+		if pi := findProjectByID(m.projects, m.confirmAction.id); pi >= 0 {
+			m.projects = append(m.projects[:pi], m.projects[pi+1:]...)
 			if m.projectCursor >= len(m.projects) {
 				m.projectCursor = len(m.projects) - 1
 			}
@@ -820,8 +960,7 @@ func (m *Model) applyConfirmAction() {
 			m.refreshProjectFilter()
 		}
 	case confirmDeleteTodo:
-		if m.confirmAction.projectIdx >= 0 && m.confirmAction.projectIdx < len(m.checklistItems) {
-			idx := m.confirmAction.projectIdx
+		if idx := findTodoByID(m.checklistItems, m.confirmAction.id); idx >= 0 {
 			m.checklistItems = append(m.checklistItems[:idx], m.checklistItems[idx+1:]...)
 			if m.checklistCursor >= len(m.checklistItems) {
 				m.checklistCursor = len(m.checklistItems) - 1
@@ -831,7 +970,18 @@ func (m *Model) applyConfirmAction() {
 			}
 			m.refreshChecklistView()
 		}
+	case confirmDeleteSession:
+		if si, ci := findSessionByID(m.subjects, m.confirmAction.id); si >= 0 {
+			sessions := m.subjects[si].Sessions
+			m.subjects[si].Sessions = append(sessions[:ci], sessions[ci+1:]...)
+			m.moveSessionCursor(0)
+		}
+	case confirmDeleteSubtask, confirmDeleteMilestone:
+		m.deletePlanItem(m.confirmAction.id)
+	case confirmDeleteResource:
+		m.deleteResource(m.confirmAction.id)
 	case confirmClearAll:
+		m.changeLabel = "Cleared all data"
 		m.subjects = nil
 		m.projects = nil
 		m.checklistItems = nil
@@ -7,6 +7,7 @@ import (
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/dates"
 	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/storage"
 	"github.com/romanguyen/seman/internal/style"
@@ -21,11 +22,14 @@ type Model struct {
 	checklist       viewport.Model
 	checklistItems  []models.ChecklistItem
 	checklistCursor int
-	todoVisible     []int
+	checklistDate   string
+	todoVisible     []todoEntry
 	projects        []models.ProjectItem
 	projectVisible  []int
 	subjects        []models.SubjectItem
 	selectedSubj         int
+	subjectFocus         bool
+	resourceCursor       int
 	examCursor           int
 	subjectFilters       []string
 	filterModalActive    []bool
@@ -35,6 +39,9 @@ type Model struct {
 	weekStart       time.Time
 	weeklyExams     []string
 	projectCursor   int
+	projectFocus    bool
+	planCursor      int
+	projectTimeline bool
 	confirmOn        bool
 	weekSpan         int
 	previousWeekSpan int
@@ -55,12 +62,61 @@ type Model struct {
 	dropdownMatches  []string
 	dropdownCursor   int
 	confirmAction   confirmAction
-	editSubjectIdx  int
-	editExamIdx     int
-	editProjectIdx  int
-	editTodoIdx     int
+	editSubjectID   string
+	editExamID      string
+	editProjectID   string
+	editPlanID      string
+	editResourceID  string
+	editTodoID      string
+	editTodoDate    string
+	editTodoFuture  bool
+	scopeDelete     bool
+	scopeCursor     int
 	store           storage.Store
-	undoStack       []undoSnapshot
+	history         []storage.HistoryEntry
+	historyAt       int
+	historyCursor   int
+	savedItems      historyItems
+	changeLabel     string
+	editorText       string
+	editorOriginal   string
+	editorStandalone bool
+	notice          string
+	importData      storage.SemesterData
+	importSource    string
+	backups         []storage.Backup
+	backupCursor    int
+	priorityRules   models.PriorityRules
+	priorityDay     time.Time
+	icsImport       ICSImport
+	icsNewPattern   string
+	icsCursor       int
+	icsPattern      string
+	library         *storage.Library
+	semester        storage.Semester
+	archived        bool
+	semesters       []storage.Semester
+	semesterCursor  int
+	editSemesterID  string
+	newSemester     storage.Semester
+	carryTodos      []int
+	gradingScale    string
+	dateFormat      dates.Format
+	gradeCursor     int
+	sessionCursor   int
+	editSessionID   string
+	calendarDay     time.Time
+	agendaOpen      bool
+	agendaCursor    int
+	searchResults   []searchResult
+	searchCursor    int
+	actions         []action
+	keymap          Keymap
+	themes          style.Themes
+	themesDir       string
+	themesStamp     string
+	paletteItems    []action
+	paletteCursor   int
 }
 
 const (
@@ -71,6 +127,9 @@ const (
 	tabSettings
 	tabLofi
 	tabSubjects
+	tabGrades
+	tabTimetable
+	tabCalendar
 )
 
 func NewModel(store storage.Store, data storage.SemesterData, hasData bool) Model {
@@ -84,23 +143,23 @@ func NewModel(store storage.Store, data storage.SemesterData, hasData bool) Mode
 		selectedSubj:   0,
 		examCursor:     0,
 		projectCursor:  0,
-		editSubjectIdx: -1,
-		editExamIdx:    -1,
-		editProjectIdx: -1,
-		editTodoIdx:    -1,
 		lofiNow:        -1,
 		store:          store,
+		actions:        defaultActions(),
 	}
 	m.lofiPlaylist = defaultLofiPlaylist()
+	m.calendarDay = dayOf(time.Now())
 	m.applyData(data)
+	m.loadHistory()
 	return m
 }
 
 func (m Model) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.watchThemes(), watchDay()}
 	if m.lofi.enabled && strings.TrimSpace(m.lofi.url) != "" {
-		return loadLofiPlaylist(m.lofi.url)
+		cmds = append(cmds, loadLofiPlaylist(m.lofi.url))
 	}
-	return nil
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -120,217 +179,42 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case lofiPlaybackMsg:
 		cmd := m.applyLofiPlayback(msg)
 		return m, cmd
+	case themesMsg:
+		cmd := m.handleThemes(msg)
+		return m, cmd
+	case dayMsg:
+		m.handleDay(msg)
+		return m, watchDay()
+	case notesEditedMsg:
+		m.applyNotes(msg)
+		return m, nil
+	case resourceOpenedMsg:
+		m.handleResourceOpened(msg)
+		return m, nil
+	case editorMsg:
+		cmd := m.handleEditor(msg)
+		return m, cmd
 	case tea.KeyMsg:
 		key := msg.String()
+		m.notice = ""
 		if m.modal != modalNone {
 			return m.updateModal(msg)
 		}
-		if len(key) == 1 && key[0] >= '1' && key[0] <= '9' {
-			if m.switchToTab(int(key[0] - '1')) {
-				return m, nil
-			}
-		}
-		switch key {
-		case "ctrl+c", "q":
-			m.shutdownLofi()
-			return m, tea.Quit
-		case "ctrl+z":
-			m.undo()
-			return m, nil
-		case "g", "G":
-			m.toggleGlobalView()
-			return m, nil
-		case "left":
-			m.shiftWeek(-1)
-			return m, nil
-		case "right":
-			m.shiftWeek(1)
-			return m, nil
-		case "a", "A":
-			m.openAddExamWithFilter()
-			return m, nil
-		case "s", "S":
-			m.openAddSubject()
-			return m, nil
-		case "p", "P":
-			m.openAddProject()
-			return m, nil
-		case "e", "E":
-			m.openEditCurrent()
-			return m, nil
-		case "f", "F":
-			switch m.activeTab {
-			case tabDashboard, tabExams, tabTodos, tabProjects:
-				m.openSubjectFilter()
-				return m, nil
-			}
-		case "r", "R":
-			switch m.activeTab {
-			case tabDashboard, tabExams, tabTodos, tabProjects:
-				m.subjectFilters = nil
-				m.refreshAllFilters()
-				return m, nil
-			}
-		case "n", "N":
-			if m.activeTab == tabTodos {
-				m.openAddTodo()
-				return m, nil
-			}
-		case "d", "D":
-			m.queueDelete()
-			return m, nil
-		case "c", "C":
-			if m.activeTab == tabSettings {
-				m.queueClearAll()
-				return m, nil
-			}
-		case "o", "O":
-			if m.activeTab == tabSettings {
-				m.confirmOn = !m.confirmOn
-				m.persist()
-				return m, nil
-			}
-		case "t", "T":
-			if m.activeTab == tabSettings {
-				m.cycleTheme()
-				return m, nil
-			}
-			m.jumpToCurrentWeek()
-			return m, nil
-		case "w", "W":
-			if m.activeTab == tabSettings {
-				m.cycleWeekSpan()
-				return m, nil
-			}
-		case "l", "L":
-			if m.activeTab == tabSettings {
-				cmd := m.toggleLofiEnabled()
-				return m, cmd
-			}
-		case "u", "U":
-			if m.activeTab == tabSettings || m.activeTab == tabLofi {
-				m.openEditLofiURL()
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+			// 0 is the tenth tab.
+			if m.switchToTab((int(key[0]-'0') + 9) % 10) {
 				return m, nil
 			}
 		}
-
-		if m.activeTab == tabTodos {
-			switch key {
-			case "j", "down":
-				m.moveChecklistCursor(1)
-				return m, nil
-			case "k", "up":
-				m.moveChecklistCursor(-1)
-				return m, nil
-			case "pgdown":
-				m.moveChecklistCursor(m.checklist.Height)
-				return m, nil
-			case "pgup":
-				m.moveChecklistCursor(-m.checklist.Height)
-				return m, nil
-			case " ", "enter", "x", "X":
-				m.toggleChecklistItem()
-				return m, nil
-			case "b", "B":
-				m.openBulkAddTodo()
-				return m, nil
-			}
-
-			var cmd tea.Cmd
-			m.checklist, cmd = m.checklist.Update(msg)
+		if a, ok := m.actionFor(key); ok {
+			cmd := m.runAction(a)
 			return m, cmd
 		}
-
-		if m.activeTab == tabDashboard {
-			switch key {
-			case "j", "down":
-				m.checklist.LineDown(1)
-				return m, nil
-			case "k", "up":
-				m.checklist.LineUp(1)
-				return m, nil
-			case "pgdown":
-				m.checklist.ViewDown()
-				return m, nil
-			case "pgup":
-				m.checklist.ViewUp()
-				return m, nil
-			}
-
+		if m.activeTab == tabTodos {
 			var cmd tea.Cmd
 			m.checklist, cmd = m.checklist.Update(msg)
 			return m, cmd
 		}
-
-		if m.activeTab == tabSubjects {
-			switch key {
-			case "j", "down":
-				if m.selectedSubj < len(m.subjects)-1 {
-					m.selectedSubj++
-				}
-				return m, nil
-			case "k", "up":
-				if m.selectedSubj > 0 {
-					m.selectedSubj--
-				}
-				return m, nil
-			case "enter":
-				if len(m.subjects) == 0 {
-					m.openAddSubject()
-				} else {
-					m.openEditSubject()
-				}
-				return m, nil
-			}
-		}
-
-		if m.activeTab == tabExams {
-			switch key {
-			case "j", "down":
-				m.moveExamCursor(1)
-				return m, nil
-			case "k", "up":
-				m.moveExamCursor(-1)
-				return m, nil
-			}
-		}
-
-		if m.activeTab == tabProjects {
-			switch key {
-			case "j", "down":
-				m.moveProjectCursor(1)
-				return m, nil
-			case "k", "up":
-				m.moveProjectCursor(-1)
-				return m, nil
-			}
-		}
-
-		if m.activeTab == tabLofi {
-			switch key {
-			case "j", "down":
-				m.moveLofiCursor(1)
-				return m, nil
-			case "k", "up":
-				m.moveLofiCursor(-1)
-				return m, nil
-			case "enter":
-				cmd := m.playLofiAt(m.lofiCursor)
-				return m, cmd
-			case " ":
-				cmd := m.toggleLofiPlayPause()
-				return m, cmd
-			case "n", "N":
-				cmd := m.lofiNext()
-				return m, cmd
-			case "b", "B":
-				cmd := m.lofiPrev()
-				return m, cmd
-			case "x", "X":
-				m.lofiStop()
-				return m, nil
-			}
-		}
 	}
 
 	return m, nil
@@ -341,8 +225,8 @@ func (m Model) View() string {
 		return "Loading..."
 	}
 
-	t := style.ThemeOf(m.themeName)
-	header := components.RenderHeader(m.width, t)
+	t := m.theme()
+	header := components.RenderHeader(m.width, m.semesterLabel(), t)
 	tabs := components.RenderTabs(m.activeTab, m.width, m.weekLabel, m.tabItems(), t)
 	divider := components.RenderDivider(m.width, t)
 	mainHeight := components.MainAreaHeight(m.height)
@@ -352,7 +236,7 @@ func (m Model) View() string {
 		modal := components.RenderModalContent(state.Modal, m.width, t)
 		main = components.PlaceOverlay(main, modal)
 	}
-	footer := components.RenderFooter(m.width, len(m.tabItems()), m.activeTab, t)
+	footer := components.RenderFooter(m.width, len(m.tabItems()), m.footerHint(), m.notice, t)
 
 	return strings.Join([]string{header, tabs, divider, main, divider, footer}, "\n")
 }
@@ -382,6 +266,7 @@ func (m *Model) resize(width, height int) {
 }
 
 func (m *Model) applyData(data storage.SemesterData) {
+	storage.EnsureIDs(&data)
 	m.subjects = data.Subjects
 	m.projects = data.Projects
 	m.checklistItems = data.Checklist
@@ -391,13 +276,16 @@ func (m *Model) applyData(data storage.SemesterData) {
 	if m.themeName == "" {
 		m.themeName = "green"
 	}
+	m.priorityRules = normalizePriorityRules(data.PriorityRules)
+	m.icsPattern = data.ICSSubjectPattern
+	m.gradingScale = models.GradingScaleOf(data.GradingScale).Name
+	m.dateFormat = dates.FormatOf(data.DateFormat)
 	m.setWeekSpanFromData(data.WeekSpan)
 	m.setWeekStartFromData(data.WeekStart)
 	m.lofi.enabled = data.LofiEnabled
 	m.lofi.url = strings.TrimSpace(data.LofiURL)
 	m.lofi.status = lofiStatusStopped
 	m.lofi.err = ""
-	m.ensureTodoDueDates()
 	m.sortExamsByPriority()
 	m.sortProjectsByStatus()
 	m.sortChecklistByDone()
@@ -433,21 +321,25 @@ func (m *Model) applyData(data storage.SemesterData) {
 
 func (m Model) exportData() storage.SemesterData {
 	return storage.SemesterData{
-		Subjects:    m.subjects,
-		Projects:    m.projects,
-		Checklist:   m.checklistItems,
-		WeeklyExams: m.weeklyExams,
-		ConfirmOn:   m.confirmOn,
-		WeekStart:   m.weekStart.Format("2006-01-02"),
-		WeekSpan:    m.weekSpan,
-		LofiEnabled: m.lofi.enabled,
-		LofiURL:     m.lofi.url,
-		Theme:       m.themeName,
+		Subjects:          m.subjects,
+		Projects:          m.projects,
+		Checklist:         m.checklistItems,
+		WeeklyExams:       m.weeklyExams,
+		ConfirmOn:         m.confirmOn,
+		WeekStart:         m.weekStart.Format("2006-01-02"),
+		WeekSpan:          m.weekSpan,
+		LofiEnabled:       m.lofi.enabled,
+		LofiURL:           m.lofi.url,
+		Theme:             m.themeName,
+		PriorityRules:     m.priorityRules,
+		ICSSubjectPattern: m.icsPattern,
+		GradingScale:      m.gradingScale,
+		DateFormat:        m.dateFormat.Name,
 	}
 }
 
 func (m *Model) cycleTheme() {
-	names := style.ThemeNames
+	names := m.themes.Names()
 	cur := 0
 	for i, n := range names {
 		if n == m.themeName {
@@ -459,25 +351,57 @@ func (m *Model) cycleTheme() {
 	m.persist()
 }
 
-func (m *Model) persist() {
-	if m.store == nil {
-		return
+// cycleDateFormat switches to the next way of showing dates; stored dates
+// are not touched.
+func (m *Model) cycleDateFormat() {
+	cur := 0
+	for i, f := range dates.Formats {
+		if f.Name == m.dateFormat.Name {
+			cur = i
+			break
+		}
 	}
-	_ = m.store.Save(m.exportData())
+	m.dateFormat = dates.Formats[(cur+1)%len(dates.Formats)]
+	m.persist()
+	m.refreshChecklistView()
 }
 
+// persist saves the data and adds the change to the undo history. It
+// reports whether the save succeeded; when it did not the notice says why,
+// and a change to an archived semester is rolled back.
+func (m *Model) persist() bool {
+	if m.store != nil {
+		if err := m.store.Save(m.exportData()); err != nil {
+			return m.saveFailed(err)
+		}
+	}
+	m.recordChange()
+	return true
+}
+
+// refreshChecklistView renders the visible todos into the checklist viewport:
+// grouped by day on the Todos tab, as a flat list on the Dashboard.
 func (m *Model) refreshChecklistView() {
 	m.refreshTodoFilter()
-	t := style.ThemeOf(m.themeName)
-	filtered := make([]models.ChecklistItem, 0, len(m.todoVisible))
-	for _, idx := range m.todoVisible {
-		if idx >= 0 && idx < len(m.checklistItems) {
-			filtered = append(filtered, m.checklistItems[idx])
+	t := m.theme()
+	now := time.Now()
+	rows := make([]components.TodoRow, 0, len(m.todoVisible))
+	for _, e := range m.todoVisible {
+		if e.idx >= 0 && e.idx < len(m.checklistItems) {
+			item := m.todoAt(e)
+			due, hasDue := dates.Parse(item.Due)
+			rows = append(rows, components.TodoRow{
+				Item:    item,
+				Group:   todoGroup(item, now),
+				Due:     due,
+				HasDue:  hasDue,
+				Overdue: todoOverdue(item, now),
+			})
 		}
 	}
-	visibleCursor := m.visibleIndex(m.todoVisible, m.checklistCursor)
-	m.checklist.SetContent(components.RenderChecklist(filtered, visibleCursor, m.activeTab == tabTodos, t))
-	m.ensureChecklistVisible(visibleCursor, len(filtered))
+	content, cursorLine := components.RenderChecklist(rows, m.todoPos(), m.activeTab == tabTodos, t)
+	m.checklist.SetContent(content)
+	m.ensureChecklistVisible(cursorLine, strings.Count(content, "\n")+1)
 }
 
 func (m *Model) sortChecklistByDone() {
@@ -531,7 +455,7 @@ func (m *Model) moveChecklistCursor(delta int) {
 	if len(m.todoVisible) == 0 {
 		return
 	}
-	pos := m.visibleIndex(m.todoVisible, m.checklistCursor)
+	pos := m.todoPos()
 	if pos < 0 {
 		pos = 0
 	}
@@ -542,7 +466,8 @@ func (m *Model) moveChecklistCursor(delta int) {
 	if pos >= len(m.todoVisible) {
 		pos = len(m.todoVisible) - 1
 	}
-	m.checklistCursor = m.todoVisible[pos]
+	m.checklistCursor = m.todoVisible[pos].idx
+	m.checklistDate = m.todoVisible[pos].date
 	m.refreshChecklistView()
 }
 
@@ -554,8 +479,10 @@ func (m *Model) toggleChecklistItem() {
 	if idx < 0 || idx >= len(m.checklistItems) {
 		return
 	}
-	m.pushUndo()
-	m.checklistItems[idx].Done = !m.checklistItems[idx].Done
+	if _, err := ToggleTodo(&m.checklistItems[idx], m.checklistDate, time.Now()); err != nil {
+		m.notice = err.Error()
+		return
+	}
 	m.sortChecklistByDone()
 	m.persist()
 	m.refreshChecklistView()
@@ -573,8 +500,15 @@ func (m *Model) setWeekStartFromData(value string) {
 	m.updateWeekLabel()
 }
 
+// dateContext resolves relative dates typed into forms against the week on
+// screen.
+func (m Model) dateContext() DateContext {
+	return DateContext{Now: time.Now(), Week: m.weekStart}
+}
+
 func (m *Model) shiftWeek(delta int) {
 	m.weekStart = m.weekStart.AddDate(0, 0, delta*7)
+	m.sessionCursor = 0
 	m.updateWeekLabel()
 	m.refreshFlatExams()
 	m.refreshChecklistView()
@@ -583,6 +517,7 @@ func (m *Model) shiftWeek(delta int) {
 
 func (m *Model) jumpToCurrentWeek() {
 	m.weekStart = weekStartOf(time.Now())
+	m.sessionCursor = 0
 	m.updateWeekLabel()
 	m.refreshFlatExams()
 	m.refreshChecklistView()
@@ -648,31 +583,54 @@ func (m *Model) weekRange() (time.Time, time.Time, bool) {
 	return start, end, false
 }
 
+// refreshTodoFilter picks the todos of the shown weeks: those due in them,
+// those without a due date and, while the weeks include today, the overdue
+// ones. Recurring todos contribute their occurrences in that range (in the
+// global view, up to four weeks ahead). The rows are listed in the order of
+// the Todos tab's day groups.
 func (m *Model) refreshTodoFilter() {
 	start, end, all := m.weekRange()
-	visible := make([]int, 0, len(m.checklistItems))
+	now := time.Now()
+	current := !now.Before(start) && now.Before(end)
+	if all {
+		end = now.Add(recurringHorizon)
+	}
+	visible := make([]todoEntry, 0, len(m.checklistItems))
 	for i, item := range m.checklistItems {
 		if len(m.subjectFilters) > 0 && !m.isFiltered(item.Subject) {
 			continue
 		}
-		if !all {
-			due, ok := parseTodoDate(item.Due)
-			if !ok {
-				continue
+		if _, _, ok := todoRecurrence(item); ok {
+			for _, day := range todoOccurrences(item, end) {
+				occ := todoOccurrence(item, day)
+				due, _ := dates.Parse(occ.Due)
+				if all || !due.Before(start) || (current && todoOverdue(occ, now)) {
+					visible = append(visible, todoEntry{idx: i, date: day})
+				}
 			}
-			if due.Before(start) || !due.Before(end) {
+			continue
+		}
+		if !all {
+			due, ok := dates.Parse(item.Due)
+			inRange := !due.Before(start) && due.Before(end)
+			if ok && !inRange && !(current && todoOverdue(item, now)) {
 				continue
 			}
 		}
-		visible = append(visible, i)
+		visible = append(visible, todoEntry{idx: i})
 	}
+	sort.SliceStable(visible, func(a, b int) bool {
+		return todoLess(m.todoAt(visible[a]), m.todoAt(visible[b]), now)
+	})
 	m.todoVisible = visible
 	if len(m.todoVisible) == 0 {
 		m.checklistCursor = -1
+		m.checklistDate = ""
 		return
 	}
-	if m.visibleIndex(m.todoVisible, m.checklistCursor) == -1 {
-		m.checklistCursor = m.todoVisible[0]
+	if m.todoPos() == -1 {
+		m.checklistCursor = m.todoVisible[0].idx
+		m.checklistDate = m.todoVisible[0].date
 	}
 }
 
@@ -725,7 +683,7 @@ func (m *Model) refreshFlatExams() {
 		}
 		for ei, exam := range subject.Exams {
 			if !all {
-				date, ok := parseExamDate(exam.Date)
+				date, ok := dates.Parse(exam.Date)
 				if !ok {
 					continue
 				}
@@ -742,8 +700,8 @@ func (m *Model) refreshFlatExams() {
 		}
 	}
 	sort.Slice(list, func(i, j int) bool {
-		di, oki := parseExamDate(list[i].Exam.Date)
-		dj, okj := parseExamDate(list[j].Exam.Date)
+		di, oki := dates.Parse(list[i].Exam.Date)
+		dj, okj := dates.Parse(list[j].Exam.Date)
 		if !oki {
 			return false
 		}
@@ -798,24 +756,10 @@ func (m *Model) moveProjectCursor(delta int) {
 	if pos >= len(m.projectVisible) {
 		pos = len(m.projectVisible) - 1
 	}
-	m.projectCursor = m.projectVisible[pos]
-}
-
-func (m *Model) ensureTodoDueDates() {
-	if len(m.checklistItems) == 0 {
-		return
-	}
-	changed := false
-	defaultDue := m.weekStart.Format("2006-01-02")
-	for i := range m.checklistItems {
-		if strings.TrimSpace(m.checklistItems[i].Due) == "" {
-			m.checklistItems[i].Due = defaultDue
-			changed = true
-		}
-	}
-	if changed {
-		m.persist()
+	if m.projectCursor != m.projectVisible[pos] {
+		m.planCursor = 0
 	}
+	m.projectCursor = m.projectVisible[pos]
 }
 
 func (m Model) viewState() screens.State {
@@ -833,11 +777,14 @@ func (m Model) viewState() screens.State {
 		ActiveTab:     m.activeTab,
 		ConfirmOn:     m.confirmOn,
 		ThemeName:     m.themeName,
+		PriorityRules: m.priorityRules,
 		SubjectFilter: m.subjectFilterLabel(),
 		ChecklistView: m.checklist.View(),
 		Projects:      visibleProjects,
 		Subjects:      m.subjects,
 		SelectedSubj:  m.selectedSubj,
+		SubjectFocus:   m.subjectFocus,
+		ResourceCursor: m.resourceCursor,
 		ExamCursor:        m.examCursor,
 		FlatExams:         m.flatExams,
 		WeekLabel:     m.weekLabel,
@@ -847,6 +794,9 @@ func (m Model) viewState() screens.State {
 		WeekSpan:      m.weekSpan,
 		WeeklyExams:   m.weeklyExams,
 		ProjectCursor: visibleProjectCursor,
+		ProjectFocus:  m.projectFocus,
+		PlanCursor:    m.planCursor,
+		Timeline:      m.projectTimeline,
 		LofiEnabled:   m.lofi.enabled,
 		LofiURL:       m.lofi.url,
 		LofiStatus:    m.lofi.status,
@@ -855,6 +805,24 @@ func (m Model) viewState() screens.State {
 		LofiCursor:    m.lofiCursor,
 		LofiOffset:    m.lofiOffset,
 		LofiNow:       m.lofiNow,
+		GradingScale:  m.gradingScale,
+		GradeCursor:   m.gradeCursor,
+		DateFormat:    m.dateFormat,
+		WeekStart:     m.weekStart,
+		WeekSessions:  m.weekSessions(),
+		SessionCursor: m.sessionCursor,
+		TodaySessions: m.todaySessions(),
+		CalendarDay:   m.calendarDay,
+		AgendaOpen:    m.agendaOpen,
+		AgendaCursor:  m.agendaCursor,
+	}
+	if m.activeTab == tabProjects && m.projectTimeline {
+		state.TimelineExams = m.timelineExams()
+	}
+	if m.activeTab == tabCalendar {
+		from, to := m.calendarRange()
+		state.CalendarEntries = m.calendarEntries(from, to)
+		state.AgendaEntries = m.agendaEntries()
 	}
 
 	if m.modal == modalNone {
@@ -863,9 +831,12 @@ func (m Model) viewState() screens.State {
 
 	fields := make([]components.ModalField, 0, len(m.formFields))
 	for _, field := range m.formFields {
+		preview, ok := m.datePreview(field)
 		fields = append(fields, components.ModalField{
-			Label: field.label,
-			Value: field.input.View(),
+			Label:      field.label,
+			Value:      field.input.View(),
+			Preview:    preview,
+			PreviewBad: !ok,
 		})
 	}
 
@@ -882,9 +853,66 @@ func (m Model) viewState() screens.State {
 		DropdownFieldIdx: dropdownFieldIdx,
 	}
 	switch m.modal {
-	case modalConfirm:
+	case modalConfirm, modalImportPreview, modalEditPlanList:
 		modalState.Mode = components.ModalConfirm
 		modalState.Message = m.modalError
+	case modalTodoScope:
+		modalState.Mode = components.ModalPicker
+		modalState.SelectItems = m.todoScopeLabels()
+		modalState.SelectCursor = m.scopeCursor
+	case modalICSPreview:
+		modalState.Mode = components.ModalPicker
+		modalState.Title = "Import Calendar — " + m.icsImport.Summary()
+		modalState.SelectItems = m.icsEntryLabels()
+		modalState.SelectCursor = m.icsCursor
+		if len(m.icsImport.Entries) == 0 {
+			modalState.Message = "The calendar has no events."
+		}
+	case modalRestoreBackup:
+		modalState.Mode = components.ModalPicker
+		modalState.SelectItems = m.backupLabels()
+		modalState.SelectCursor = m.backupCursor
+		if len(m.backups) == 0 {
+			modalState.Message = "No backups yet — press [B] on Settings to create one."
+		}
+		if m.modalError != "" {
+			modalState.Message = m.modalError
+		}
+	case modalSemesters:
+		modalState.Mode = components.ModalPicker
+		modalState.SelectItems = m.semesterLabels()
+		modalState.SelectCursor = m.semesterCursor
+		modalState.Message = m.modalError
+	case modalHistory:
+		modalState.Mode = components.ModalPicker
+		modalState.SelectItems, modalState.SelectDimmed = m.historyLabels()
+		modalState.SelectCursor = m.historyCursor
+		if len(m.history) == 0 {
+			modalState.Message = "No changes yet."
+		}
+	case modalCarryOver:
+		modalState.Mode = components.ModalSubjectSelect
+		modalState.SelectItems = m.carryOverLabels()
+		modalState.SelectActive = m.filterModalActive
+		modalState.SelectCursor = m.filterModalCursor
+		modalState.Message = "Subjects keep their notes and resources; exams and classes stay behind."
+	case modalSearch:
+		modalState.Mode = components.ModalSearch
+		modalState.SelectItems, modalState.SelectGroups = m.searchLabels()
+		modalState.SelectCursor = m.searchCursor
+		if len(m.searchResults) == 0 {
+			modalState.Message = "Type to search subjects, exams, projects and todos."
+			if strings.TrimSpace(m.formFields[0].input.Value()) != "" {
+				modalState.Message = "No matches."
+			}
+		}
+	case modalPalette:
+		modalState.Mode = components.ModalSearch
+		modalState.SelectItems, modalState.SelectGroups = m.paletteLabels()
+		modalState.SelectCursor = m.paletteCursor
+		if len(m.paletteItems) == 0 {
+			modalState.Message = "No matching commands."
+		}
 	case modalSubjectFilter:
 		modalState.Mode = components.ModalSubjectSelect
 		items := make([]string, len(m.subjects))
@@ -913,25 +941,26 @@ func (m *Model) sortProjectsByStatus() {
 	if len(m.projects) < 2 {
 		return
 	}
-	selectedKey := projectKey(m.projects, m.projectCursor)
+	var selectedID string
+	if m.projectCursor >= 0 && m.projectCursor < len(m.projects) {
+		selectedID = m.projects[m.projectCursor].ID
+	}
 	sort.SliceStable(m.projects, func(i, j int) bool {
 		return statusRank(m.projects[i].Status) < statusRank(m.projects[j].Status)
 	})
-	if selectedKey != "" {
-		if idx := findProjectIndex(m.projects, selectedKey); idx >= 0 {
-			m.projectCursor = idx
-		}
+	if idx := findProjectByID(m.projects, selectedID); idx >= 0 {
+		m.projectCursor = idx
 	}
 }
 
 func (m *Model) sortExamsByPriority() {
+	m.priorityDay = dayOf(time.Now())
 	if len(m.subjects) == 0 {
 		return
 	}
-	var selectedKey string
-	selectedSubject := m.selectedSubj
-	if selectedSubject >= 0 && selectedSubject < len(m.subjects) {
-		selectedKey = examKey(m.subjects[selectedSubject].Exams, m.examCursor)
+	var selectedID string
+	if m.examCursor >= 0 && m.examCursor < len(m.flatExams) {
+		selectedID = m.flatExams[m.examCursor].Exam.ID
 	}
 	for i := range m.subjects {
 		exams := m.subjects[i].Exams
@@ -939,50 +968,26 @@ func (m *Model) sortExamsByPriority() {
 			continue
 		}
 		sort.SliceStable(exams, func(a, b int) bool {
-			return priorityRank(exams[a].Priority) < priorityRank(exams[b].Priority)
+			return priorityRank(m.effectivePriority(exams[a])) < priorityRank(m.effectivePriority(exams[b]))
 		})
 		m.subjects[i].Exams = exams
 	}
-	if selectedKey != "" && selectedSubject >= 0 && selectedSubject < len(m.subjects) {
-		if idx := findExamIndex(m.subjects[selectedSubject].Exams, selectedKey); idx >= 0 {
-			m.examCursor = idx
-		}
-	}
 	m.refreshFlatExams()
+	m.selectFlatExam(selectedID)
 }
 
-func projectKey(items []models.ProjectItem, idx int) string {
-	if idx < 0 || idx >= len(items) {
-		return ""
-	}
-	item := items[idx]
-	return strings.ToUpper(item.Name) + "|" + strings.ToUpper(item.Subject) + "|" + item.Due
-}
-
-func examKey(items []models.ExamItem, idx int) string {
-	if idx < 0 || idx >= len(items) {
-		return ""
-	}
-	item := items[idx]
-	return strings.ToUpper(item.Name) + "|" + item.Date + "|" + strings.ToUpper(item.Priority)
-}
-
-func findProjectIndex(items []models.ProjectItem, key string) int {
-	for i := range items {
-		if projectKey(items, i) == key {
-			return i
-		}
+// selectFlatExam moves the exam cursor to the exam with the given ID, if it
+// is in the current list.
+func (m *Model) selectFlatExam(id string) {
+	if id == "" {
+		return
 	}
-	return -1
-}
-
-func findExamIndex(items []models.ExamItem, key string) int {
-	for i := range items {
-		if examKey(items, i) == key {
-			return i
+	for i, flat := range m.flatExams {
+		if flat.Exam.ID == id {
+			m.examCursor = i
+			return
 		}
 	}
-	return -1
 }
 
 func statusRank(status string) int {
@@ -1018,6 +1023,9 @@ func (m Model) tabItems() []components.TabItem {
 		{ID: tabExams, Label: "Exams"},
 		{ID: tabTodos, Label: "Todos"},
 		{ID: tabProjects, Label: "Projects"},
+		{ID: tabGrades, Label: "Grades"},
+		{ID: tabTimetable, Label: "Timetable"},
+		{ID: tabCalendar, Label: "Calendar"},
 		{ID: tabSettings, Label: "Settings"},
 	}
 	if m.lofi.enabled {
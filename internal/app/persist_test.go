@@ -0,0 +1,128 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// failingStore refuses every save with err.
+type failingStore struct {
+	err   error
+	saves int
+}
+
+func (s *failingStore) Load() (storage.SemesterData, bool, error) {
+	return storage.SemesterData{}, false, nil
+}
+
+func (s *failingStore) Save(storage.SemesterData) error {
+	s.saves++
+	return s.err
+}
+
+func persistTestData() storage.SemesterData {
+	return storage.SemesterData{
+		SchemaVersion: storage.SchemaVersion,
+		Subjects:      []models.SubjectItem{{ID: "s1", Code: "DB", Name: "Databases", Notes: "old"}},
+		Checklist:     []models.ChecklistItem{{ID: "t1", Text: "Read", Due: "2026-10-18"}},
+	}
+}
+
+func TestArchivedChangesAreRolledBack(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(m *Model)
+		check  func(t *testing.T, m *Model)
+	}{
+		{
+			name: "form submit",
+			change: func(m *Model) {
+				m.openAddSubject()
+				m.formFields[0].input.SetValue("OS")
+				m.formFields[1].input.SetValue("Operating Systems")
+				m.submitFormModal()
+			},
+			check: func(t *testing.T, m *Model) {
+				if len(m.subjects) != 1 {
+					t.Errorf("subjects = %d, want the new one dropped", len(m.subjects))
+				}
+			},
+		},
+		{
+			name: "todo toggle",
+			change: func(m *Model) {
+				m.checklistItems[0].Done = true
+				m.persist()
+			},
+			check: func(t *testing.T, m *Model) {
+				if m.checklistItems[0].Done {
+					t.Error("the todo stayed done")
+				}
+			},
+		},
+		{
+			name: "editor callback",
+			change: func(m *Model) {
+				path, err := writeTempFile("seman-test-*.md", "new notes\n")
+				if err != nil {
+					t.Fatal(err)
+				}
+				m.applyNotes(notesEditedMsg{subjectID: "s1", path: path})
+			},
+			check: func(t *testing.T, m *Model) {
+				if m.subjects[0].Notes != "old" {
+					t.Errorf("notes = %q, want the old ones", m.subjects[0].Notes)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &failingStore{err: storage.ErrArchived}
+			m := NewModel(store, persistTestData(), true)
+			tt.change(&m)
+			tt.check(t, &m)
+			if store.saves == 0 {
+				t.Error("nothing was saved")
+			}
+			if !strings.Contains(m.notice, "archived") {
+				t.Errorf("notice = %q, want it to say the semester is archived", m.notice)
+			}
+			if m.historyAt != 0 {
+				t.Errorf("historyAt = %d, want the change left out of the history", m.historyAt)
+			}
+		})
+	}
+}
+
+func TestSaveErrorIsReported(t *testing.T) {
+	store := &failingStore{err: errors.New("disk full")}
+	m := NewModel(store, persistTestData(), true)
+	m.checklistItems[0].Done = true
+	if m.persist() {
+		t.Fatal("persist reported success")
+	}
+	if !strings.Contains(m.notice, "disk full") {
+		t.Errorf("notice = %q, want the error", m.notice)
+	}
+	// The change is kept, to be saved with the next one.
+	if !m.checklistItems[0].Done || m.historyAt != 1 {
+		t.Errorf("done = %v, historyAt = %d; want the change kept", m.checklistItems[0].Done, m.historyAt)
+	}
+}
+
+func TestArchivedWeekMovesAreNotChanges(t *testing.T) {
+	store := &failingStore{err: storage.ErrArchived}
+	m := NewModel(store, persistTestData(), true)
+	m.notice = ""
+	if !m.persist() {
+		t.Error("persist failed without a change")
+	}
+	if m.notice != "" {
+		t.Errorf("notice = %q, want none", m.notice)
+	}
+}
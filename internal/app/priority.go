@@ -0,0 +1,126 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// normalizePriorityRules fills in defaults for data saved before the rules
+// existed and keeps the MED threshold at or above the HIGH one.
+func normalizePriorityRules(r models.PriorityRules) models.PriorityRules {
+	if r.HighDays <= 0 && r.MedDays <= 0 {
+		def := models.DefaultPriorityRules()
+		r.HighDays = def.HighDays
+		r.MedDays = def.MedDays
+	}
+	if r.MedDays < r.HighDays {
+		r.MedDays = r.HighDays
+	}
+	return r
+}
+
+// effectivePriority resolves AUTO priorities against the configured rules.
+func (m *Model) effectivePriority(exam models.ExamItem) string {
+	date, ok := dates.Parse(exam.Date)
+	return m.priorityRules.Resolve(exam.Priority, date, ok, time.Now())
+}
+
+// dayPollInterval is how often the date is checked for a new day, which
+// can change AUTO priorities. A timer set to midnight could fire late after
+// the computer sleeps.
+const dayPollInterval = time.Minute
+
+// dayMsg reports the date at a check.
+type dayMsg struct {
+	day time.Time
+}
+
+// watchDay checks the date after a while.
+func watchDay() tea.Cmd {
+	return tea.Tick(dayPollInterval, func(now time.Time) tea.Msg {
+		return dayMsg{day: dayOf(now)}
+	})
+}
+
+// handleDay sorts the exams again once a new day has begun since they were
+// last sorted, as AUTO priorities count the days to each exam, and refreshes
+// every list that depends on the date. The selected exam stays selected.
+func (m *Model) handleDay(msg dayMsg) {
+	if msg.day.Equal(m.priorityDay) {
+		return
+	}
+	var selectedID string
+	if m.examCursor >= 0 && m.examCursor < len(m.flatExams) {
+		selectedID = m.flatExams[m.examCursor].Exam.ID
+	}
+	m.sortExamsByPriority()
+	m.refreshAllFilters()
+	m.selectFlatExam(selectedID)
+}
+
+// parsePriorityInput validates a priority typed into a form. An empty value
+// becomes AUTO when the rules make that the default for new exams.
+func parsePriorityInput(raw string, rules models.PriorityRules, isNew bool) (string, error) {
+	priority := strings.ToUpper(strings.TrimSpace(raw))
+	switch priority {
+	case "":
+		if isNew && rules.AutoDefault {
+			return models.PriorityAuto, nil
+		}
+		return "", nil
+	case "HIGH", "MED", "LOW", models.PriorityAuto:
+		return priority, nil
+	case "MEDIUM":
+		return "MED", nil
+	}
+	return "", fmt.Errorf("Priority must be HIGH, MED, LOW or AUTO.")
+}
+
+func (m *Model) openPriorityRules() {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("High within", inputWidth, true),
+		newFormField("Med within", inputWidth, true),
+		newFormField("New exams", inputWidth, false),
+	}
+	fields[0].input.SetValue(strconv.Itoa(m.priorityRules.HighDays))
+	fields[1].input.SetValue(strconv.Itoa(m.priorityRules.MedDays))
+	if m.priorityRules.AutoDefault {
+		fields[2].input.SetValue("AUTO")
+	} else {
+		fields[2].input.SetValue("MANUAL")
+	}
+	fields[0].input.Placeholder = "days"
+	fields[1].input.Placeholder = "days"
+	fields[2].input.Placeholder = "AUTO / MANUAL"
+	m.openFormModal(modalPriorityRules, "Priority Rules", fields)
+}
+
+func (m *Model) submitPriorityRules() error {
+	high, err := strconv.Atoi(strings.TrimSpace(m.formFields[0].input.Value()))
+	if err != nil || high < 1 {
+		return fmt.Errorf("High within must be at least 1 day.")
+	}
+	med, err := strconv.Atoi(strings.TrimSpace(m.formFields[1].input.Value()))
+	if err != nil || med < high {
+		return fmt.Errorf("Med within must be a number of days no smaller than High.")
+	}
+	autoDefault := false
+	switch strings.ToUpper(strings.TrimSpace(m.formFields[2].input.Value())) {
+	case "AUTO", "YES", "Y":
+		autoDefault = true
+	case "", "MANUAL", "NO", "N":
+	default:
+		return fmt.Errorf("New exams must be AUTO or MANUAL.")
+	}
+	m.priorityRules = models.PriorityRules{HighDays: high, MedDays: med, AutoDefault: autoDefault}
+	m.sortExamsByPriority()
+	m.persist()
+	return nil
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+func examIDs(subject models.SubjectItem) []string {
+	var ids []string
+	for _, exam := range subject.Exams {
+		ids = append(ids, exam.ID)
+	}
+	return ids
+}
+
+// TestNewDayResortsAutoPriorities checks that an AUTO exam moves up once a
+// new day brings it within the HIGH rule, without any edit.
+func TestNewDayResortsAutoPriorities(t *testing.T) {
+	today := dayOf(time.Now())
+	m := newTestModel(t, storage.SemesterData{
+		SchemaVersion: storage.SchemaVersion,
+		Subjects: []models.SubjectItem{{ID: "s1", Code: "DB", Exams: []models.ExamItem{
+			{ID: "med", Name: "Quiz", Date: dates.StoreDay(today.AddDate(0, 0, 10)), Priority: "MED"},
+			{ID: "auto", Name: "Final", Date: dates.StoreDay(today.AddDate(0, 0, 7)), Priority: models.PriorityAuto},
+		}}},
+		PriorityRules: models.DefaultPriorityRules(),
+		WeekSpan:      -1,
+	})
+	if got := examIDs(m.subjects[0]); got[0] != "auto" {
+		t.Fatalf("exams = %v, want the AUTO exam, due within a week, first", got)
+	}
+
+	// As sorted yesterday, when the AUTO exam was eight days away.
+	m.subjects[0].Exams[0], m.subjects[0].Exams[1] = m.subjects[0].Exams[1], m.subjects[0].Exams[0]
+	m.priorityDay = today.AddDate(0, 0, -1)
+	m.refreshAllFilters()
+	m.selectFlatExam("med")
+
+	m.handleDay(dayMsg{day: m.priorityDay})
+	if got := examIDs(m.subjects[0]); got[0] != "med" {
+		t.Errorf("exams = %v, re-sorted on the same day", got)
+	}
+	m.handleDay(dayMsg{day: today})
+	if got := examIDs(m.subjects[0]); got[0] != "auto" {
+		t.Errorf("exams = %v, want the AUTO exam first on the new day", got)
+	}
+	if !m.priorityDay.Equal(today) {
+		t.Errorf("priorityDay = %v, want %v", m.priorityDay, today)
+	}
+	if m.examCursor < 0 || m.flatExams[m.examCursor].Exam.ID != "med" {
+		t.Errorf("exam cursor = %d, want the selected exam to stay selected", m.examCursor)
+	}
+	for _, flat := range m.flatExams {
+		if got := m.subjects[flat.SubjectIdx].Exams[flat.ExamIdx].ID; got != flat.Exam.ID {
+			t.Errorf("the exam list points %s at %s", flat.Exam.ID, got)
+		}
+	}
+}
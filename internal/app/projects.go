@@ -0,0 +1,262 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// The Projects tab lists the projects beside the plan of the selected one:
+// its subtasks, then its milestones. Tab moves the focus between the two;
+// planCursor indexes the plan in that order.
+
+// selectedProject returns the index of the project under the cursor.
+func (m Model) selectedProject() (int, bool) {
+	if m.visibleIndex(m.projectVisible, m.projectCursor) == -1 {
+		return -1, false
+	}
+	return m.projectCursor, true
+}
+
+// planLen is the number of subtasks and milestones of the selected project.
+func (m Model) planLen() int {
+	pi, ok := m.selectedProject()
+	if !ok {
+		return 0
+	}
+	return len(m.projects[pi].Subtasks) + len(m.projects[pi].Milestones)
+}
+
+func (m *Model) movePlanCursor(delta int) {
+	n := m.planLen()
+	m.planCursor += delta
+	if m.planCursor >= n {
+		m.planCursor = n - 1
+	}
+	if m.planCursor < 0 {
+		m.planCursor = 0
+	}
+}
+
+func (m *Model) toggleProjectFocus() {
+	if _, ok := m.selectedProject(); !ok {
+		m.projectFocus = false
+		return
+	}
+	m.projectFocus = !m.projectFocus
+	m.movePlanCursor(0)
+}
+
+// selectedPlanItem returns the subtask or milestone under the plan cursor;
+// exactly one of the indexes is not -1 when ok.
+func (m Model) selectedPlanItem() (pi, ti, mi int, ok bool) {
+	pi, ok = m.selectedProject()
+	if !ok || m.planCursor < 0 {
+		return -1, -1, -1, false
+	}
+	project := m.projects[pi]
+	switch {
+	case m.planCursor < len(project.Subtasks):
+		return pi, m.planCursor, -1, true
+	case m.planCursor < len(project.Subtasks)+len(project.Milestones):
+		return pi, -1, m.planCursor - len(project.Subtasks), true
+	}
+	return -1, -1, -1, false
+}
+
+// timelineExams lists the exams and retakes the Projects timeline can show:
+// those of the half year from the shown week.
+func (m Model) timelineExams() []models.CalendarEntry {
+	var exams []models.CalendarEntry
+	for _, e := range m.calendarEntries(m.weekStart, m.weekStart.AddDate(0, 6, 0)) {
+		if e.Kind == models.CalendarExam || e.Kind == models.CalendarRetake {
+			exams = append(exams, e)
+		}
+	}
+	return exams
+}
+
+// togglePlanItem ticks the selected subtask or milestone off, or back on.
+func (m *Model) togglePlanItem() {
+	pi, ti, mi, ok := m.selectedPlanItem()
+	if !ok {
+		return
+	}
+	project := &m.projects[pi]
+	if ti >= 0 {
+		project.Subtasks[ti].Done = !project.Subtasks[ti].Done
+	} else {
+		project.Milestones[mi].Done = !project.Milestones[mi].Done
+	}
+	m.projectChanged(pi)
+	m.persist()
+}
+
+// projectChanged re-derives the status of project pi after its subtasks
+// changed and re-sorts the list around it.
+func (m *Model) projectChanged(pi int) {
+	project := &m.projects[pi]
+	if project.SyncStatus() && project.Status == models.StatusDone {
+		m.notice = fmt.Sprintf("All subtasks done: %s is DONE", project.Name)
+	}
+	m.projectCursor = pi
+	m.sortProjectsByStatus()
+	m.refreshProjectFilter()
+	m.movePlanCursor(0)
+}
+
+func (m *Model) openAddSubtask() {
+	pi, ok := m.selectedProject()
+	if !ok {
+		m.notice = strings.TrimSpace("Add a project first  " + m.actionHint("add-project", "Add project"))
+		return
+	}
+	fields := []formField{newFormField("Task", m.modalInputWidth(), true)}
+	m.editProjectID = m.projects[pi].ID
+	m.openFormModal(modalAddSubtask, "Add Subtask ("+m.projects[pi].Name+")", fields)
+}
+
+func (m *Model) milestoneFields() []formField {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Name", inputWidth, true),
+		newFormField("Date", inputWidth, true),
+	}
+	fields[1].input.Placeholder = "May 6, 2026 or next fri"
+	return fields
+}
+
+func (m *Model) openAddMilestone() {
+	pi, ok := m.selectedProject()
+	if !ok {
+		m.notice = strings.TrimSpace("Add a project first  " + m.actionHint("add-project", "Add project"))
+		return
+	}
+	m.editProjectID = m.projects[pi].ID
+	m.openFormModal(modalAddMilestone, "Add Milestone ("+m.projects[pi].Name+")", m.milestoneFields())
+}
+
+// openEditPlanItem opens the form of the selected subtask or milestone.
+func (m *Model) openEditPlanItem() {
+	pi, ti, mi, ok := m.selectedPlanItem()
+	if !ok {
+		return
+	}
+	project := m.projects[pi]
+	m.editProjectID = project.ID
+	if ti >= 0 {
+		fields := []formField{newFormField("Task", m.modalInputWidth(), true)}
+		fields[0].input.SetValue(project.Subtasks[ti].Text)
+		m.editPlanID = project.Subtasks[ti].ID
+		m.openFormModal(modalEditSubtask, "Edit Subtask ("+project.Name+")", fields)
+		return
+	}
+	milestone := project.Milestones[mi]
+	fields := m.milestoneFields()
+	fields[0].input.SetValue(milestone.Name)
+	fields[1].input.SetValue(m.dateFormat.ShowStored(milestone.Date))
+	m.editPlanID = milestone.ID
+	m.openFormModal(modalEditMilestone, "Edit Milestone ("+project.Name+")", fields)
+}
+
+// submitSubtask handles the add and edit subtask forms.
+func (m *Model) submitSubtask() error {
+	pi := findProjectByID(m.projects, m.editProjectID)
+	if pi < 0 {
+		return nil
+	}
+	subtask, err := BuildSubtask(m.formFields[0].input.Value())
+	if err != nil {
+		return err
+	}
+	project := &m.projects[pi]
+	if m.modal == modalEditSubtask {
+		ti := findSubtaskIndex(project.Subtasks, m.editPlanID)
+		if ti < 0 {
+			return nil
+		}
+		project.Subtasks[ti].Text = subtask.Text
+	} else {
+		project.Subtasks = append(project.Subtasks, subtask)
+		m.planCursor = len(project.Subtasks) - 1
+	}
+	m.projectChanged(pi)
+	m.persist()
+	return nil
+}
+
+// submitMilestone handles the add and edit milestone forms. Milestones are
+// kept in date order.
+func (m *Model) submitMilestone() error {
+	pi := findProjectByID(m.projects, m.editProjectID)
+	if pi < 0 {
+		return nil
+	}
+	milestone, err := BuildMilestone(m.dateContext(), m.formFields[0].input.Value(), m.formFields[1].input.Value())
+	if err != nil {
+		return err
+	}
+	project := &m.projects[pi]
+	if m.modal == modalEditMilestone {
+		mi := findMilestoneIndex(project.Milestones, m.editPlanID)
+		if mi < 0 {
+			return nil
+		}
+		project.Milestones[mi].Name = milestone.Name
+		project.Milestones[mi].Date = keepStored(project.Milestones[mi].Date, milestone.Date)
+		milestone.ID = m.editPlanID
+	} else {
+		project.Milestones = append(project.Milestones, milestone)
+	}
+	sortMilestones(project.Milestones)
+	m.planCursor = len(project.Subtasks) + findMilestoneIndex(project.Milestones, milestone.ID)
+	m.projectChanged(pi)
+	m.persist()
+	return nil
+}
+
+func sortMilestones(milestones []models.Milestone) {
+	sort.SliceStable(milestones, func(i, j int) bool {
+		a, okA := dates.Parse(milestones[i].Date)
+		b, okB := dates.Parse(milestones[j].Date)
+		if okA != okB {
+			return okA
+		}
+		return a.Before(b)
+	})
+}
+
+// queueDeletePlanItem asks to delete the selected subtask or milestone.
+func (m *Model) queueDeletePlanItem() {
+	pi, ti, mi, ok := m.selectedPlanItem()
+	if !ok {
+		return
+	}
+	project := m.projects[pi]
+	if ti >= 0 {
+		action := confirmAction{kind: confirmDeleteSubtask, id: project.Subtasks[ti].ID}
+		m.confirmOrApply(action, fmt.Sprintf("Delete subtask \"%s\"?", project.Subtasks[ti].Text))
+		return
+	}
+	action := confirmAction{kind: confirmDeleteMilestone, id: project.Milestones[mi].ID}
+	m.confirmOrApply(action, fmt.Sprintf("Delete milestone \"%s\"?", project.Milestones[mi].Name))
+}
+
+// deletePlanItem removes the subtask or milestone with the given ID.
+func (m *Model) deletePlanItem(id string) {
+	for pi := range m.projects {
+		project := &m.projects[pi]
+		if ti := findSubtaskIndex(project.Subtasks, id); ti >= 0 {
+			project.Subtasks = append(project.Subtasks[:ti], project.Subtasks[ti+1:]...)
+		} else if mi := findMilestoneIndex(project.Milestones, id); mi >= 0 {
+			project.Milestones = append(project.Milestones[:mi], project.Milestones[mi+1:]...)
+		} else {
+			continue
+		}
+		m.projectChanged(pi)
+		return
+	}
+}
@@ -0,0 +1,312 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/dates"
+)
+
+// searchKind is what a search result points at; results are grouped by it,
+// in this order.
+type searchKind int
+
+const (
+	searchSubject searchKind = iota
+	searchExam
+	searchProject
+	searchTodo
+)
+
+var searchGroups = []string{"Subjects", "Exams", "Projects", "Todos"}
+
+// searchResult is a match of the search overlay. id is that of the subject,
+// exam, project or todo; planID that of the matched subtask or milestone of
+// a project, or resource of a subject.
+type searchResult struct {
+	kind   searchKind
+	label  string
+	score  int
+	id     string
+	planID string
+}
+
+func (m *Model) openSearch() {
+	fields := []formField{newFormField("Search", m.modalInputWidth(), false)}
+	fields[0].input.Placeholder = "subject, notes, exam, project or todo"
+	m.openFormModal(modalSearch, "Search", fields)
+	m.modalHint = "Type to search · ↑↓ select · Enter jump · Esc close"
+	m.searchResults = nil
+	m.searchCursor = 0
+}
+
+func (m Model) updateSearchModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.closeModal()
+		return m, nil
+	case "enter":
+		if m.searchCursor >= 0 && m.searchCursor < len(m.searchResults) {
+			result := m.searchResults[m.searchCursor]
+			m.closeModal()
+			m.jumpToResult(result)
+			return m, nil
+		}
+		return m, nil
+	case "up", "ctrl+p", "shift+tab":
+		if m.searchCursor > 0 {
+			m.searchCursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "tab":
+		if m.searchCursor < len(m.searchResults)-1 {
+			m.searchCursor++
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.formFields[0].input, cmd = m.formFields[0].input.Update(key)
+	m.searchResults = m.search(m.formFields[0].input.Value())
+	m.searchCursor = 0
+	return m, cmd
+}
+
+// search matches query against subject codes and names with their
+// resources and notes, exams, projects with their subtasks and milestones,
+// and todos, best matches first within each group. Of the notes, only the
+// best matching line of each subject is listed.
+func (m Model) search(query string) []searchResult {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+	var results []searchResult
+	add := func(kind searchKind, text, label, id, planID string) {
+		if score, ok := fuzzyScore(words, text); ok {
+			results = append(results, searchResult{kind: kind, label: label, score: score, id: id, planID: planID})
+		}
+	}
+	when := func(date string) string {
+		if date == "" {
+			return ""
+		}
+		return " · " + m.dateFormat.ShowStored(date)
+	}
+
+	for _, subject := range m.subjects {
+		add(searchSubject, subject.Code+" "+subject.Name, fmt.Sprintf("%-8s %s", subject.Code, subject.Name), subject.ID, "")
+		for _, r := range subject.Resources {
+			add(searchSubject, subject.Code+" "+r.Title+" "+r.Target, fmt.Sprintf("%-8s ↗ %s", subject.Code, r.Title), subject.ID, r.ID)
+		}
+		best := searchResult{kind: searchSubject, id: subject.ID}
+		for _, line := range strings.Split(subject.Notes, "\n") {
+			line = strings.TrimSpace(line)
+			if score, ok := fuzzyScore(words, subject.Code+" "+line); ok && line != "" && score > best.score {
+				best.score = score
+				best.label = fmt.Sprintf("%-8s ✎ %s", subject.Code, line)
+			}
+		}
+		if best.label != "" {
+			results = append(results, best)
+		}
+	}
+	for _, subject := range m.subjects {
+		for _, exam := range subject.Exams {
+			add(searchExam, subject.Code+" "+exam.Name, fmt.Sprintf("%-8s %s%s", subject.Code, exam.Name, when(exam.Date)), exam.ID, "")
+		}
+	}
+	for _, project := range m.projects {
+		add(searchProject, project.Subject+" "+project.Name, fmt.Sprintf("%-8s %s%s", project.Subject, project.Name, when(project.Due)), project.ID, "")
+		for _, subtask := range project.Subtasks {
+			add(searchProject, project.Subject+" "+subtask.Text, fmt.Sprintf("%-8s %s › %s", project.Subject, project.Name, subtask.Text), project.ID, subtask.ID)
+		}
+		for _, ms := range project.Milestones {
+			add(searchProject, project.Subject+" "+ms.Name, fmt.Sprintf("%-8s %s ◇ %s%s", project.Subject, project.Name, ms.Name, when(ms.Date)), project.ID, ms.ID)
+		}
+	}
+	for _, item := range m.checklistItems {
+		add(searchTodo, item.Subject+" "+item.Text, fmt.Sprintf("%-8s %s%s", item.Subject, item.Text, when(item.Due)), item.ID, "")
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].kind != results[j].kind {
+			return results[i].kind < results[j].kind
+		}
+		return results[i].score > results[j].score
+	})
+	return results
+}
+
+// fuzzyScore reports whether every word occurs in text as a subsequence of
+// its letters, ignoring case, and how well: a word found whole scores
+// highest, then letters that follow one another or start a word.
+func fuzzyScore(words []string, text string) (int, bool) {
+	text = strings.ToLower(text)
+	runes := []rune(text)
+	total := 0
+	for _, word := range words {
+		if i := strings.Index(text, word); i >= 0 {
+			score := 100 + 10*len([]rune(word))
+			if before, _ := utf8.DecodeLastRuneInString(text[:i]); i == 0 || !isWordRune(before) {
+				score += 50
+			}
+			total += score
+			continue
+		}
+		score, last, pos := 0, -2, 0
+		for _, r := range word {
+			for pos < len(runes) && runes[pos] != r {
+				pos++
+			}
+			if pos == len(runes) {
+				return 0, false
+			}
+			score++
+			if pos == last+1 {
+				score += 5
+			}
+			if pos == 0 || !isWordRune(runes[pos-1]) {
+				score += 3
+			}
+			last = pos
+			pos++
+		}
+		total += score
+	}
+	return total, true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// searchLabels returns the labels of the results and the group of each.
+func (m Model) searchLabels() ([]string, []string) {
+	labels := make([]string, len(m.searchResults))
+	groups := make([]string, len(m.searchResults))
+	for i, r := range m.searchResults {
+		labels[i] = r.label
+		groups[i] = searchGroups[r.kind]
+	}
+	return labels, groups
+}
+
+// jumpToResult switches to the tab of the result and puts the cursor on it.
+// A subject filter that hides it is cleared, and the weeks shown move to
+// the week of a dated exam or todo that lies outside them.
+func (m *Model) jumpToResult(r searchResult) {
+	switch r.kind {
+	case searchSubject:
+		si := findSubjectByID(m.subjects, r.id)
+		if si < 0 {
+			return
+		}
+		m.selectedSubj = si
+		m.subjectFocus = false
+		m.resourceCursor = 0
+		if ri := findResourceIndex(m.subjects[si].Resources, r.planID); ri >= 0 {
+			m.subjectFocus = true
+			m.resourceCursor = ri
+		}
+		m.showTab(tabSubjects)
+	case searchExam:
+		si, ei := findExamByID(m.subjects, r.id)
+		if si < 0 {
+			return
+		}
+		m.revealSubject(m.subjects[si].Code)
+		if date, ok := dates.Parse(m.subjects[si].Exams[ei].Date); ok {
+			m.revealWeek(date)
+		} else if m.weekSpan >= 0 {
+			m.toggleGlobalView()
+		}
+		m.selectFlatExam(r.id)
+		m.showTab(tabExams)
+	case searchProject:
+		pi := findProjectByID(m.projects, r.id)
+		if pi < 0 {
+			return
+		}
+		m.revealSubject(m.projects[pi].Subject)
+		m.projectCursor = pi
+		m.projectFocus = false
+		m.planCursor = 0
+		project := m.projects[pi]
+		if ti := findSubtaskIndex(project.Subtasks, r.planID); ti >= 0 {
+			m.projectFocus = true
+			m.planCursor = ti
+		} else if mi := findMilestoneIndex(project.Milestones, r.planID); mi >= 0 {
+			m.projectFocus = true
+			m.planCursor = len(project.Subtasks) + mi
+		}
+		m.showTab(tabProjects)
+	case searchTodo:
+		ti := findTodoByID(m.checklistItems, r.id)
+		if ti < 0 {
+			return
+		}
+		item := m.checklistItems[ti]
+		m.revealSubject(item.Subject)
+		day := ""
+		if _, _, ok := todoRecurrence(item); ok {
+			day = todoNextDay(item, time.Now())
+			if date, ok := dates.Parse(day); ok {
+				m.revealWeek(date)
+			}
+		} else if due, ok := dates.Parse(item.Due); ok {
+			m.revealWeek(due)
+		}
+		m.showTab(tabTodos)
+		m.selectTodo(ti, day)
+	}
+}
+
+// revealSubject clears the subject filter if it hides subject.
+func (m *Model) revealSubject(subject string) {
+	if len(m.subjectFilters) > 0 && !m.isFiltered(subject) {
+		m.subjectFilters = nil
+		m.refreshAllFilters()
+	}
+}
+
+// revealWeek moves the weeks shown to the one of date, unless they already
+// include it.
+func (m *Model) revealWeek(date time.Time) {
+	start, end, all := m.weekRange()
+	if all || !date.Before(start) && date.Before(end) {
+		return
+	}
+	m.weekStart = weekStartOf(date)
+	m.shiftWeek(0)
+}
+
+// showTab makes the tab with the given ID active.
+func (m *Model) showTab(id int) {
+	m.activeTab = id
+	m.resize(m.width, m.height)
+}
+
+// selectTodo puts the todos cursor on todo ti, on its occurrence day when it
+// recurs and that one is listed.
+func (m *Model) selectTodo(ti int, day string) {
+	pos := -1
+	for i, e := range m.todoVisible {
+		if e.idx != ti {
+			continue
+		}
+		if pos < 0 || e.date == day {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		return
+	}
+	m.checklistCursor = m.todoVisible[pos].idx
+	m.checklistDate = m.todoVisible[pos].date
+	m.refreshChecklistView()
+}
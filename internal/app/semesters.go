@@ -0,0 +1,354 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+const semesterDateLayout = "2006-01-02"
+
+// WithLibrary lets the model switch between the semesters of lib. The store
+// the model was created with must be lib's open semester.
+func (m Model) WithLibrary(lib *storage.Library) Model {
+	m.library = lib
+	m.semester = lib.Opened()
+	m.archived = m.semester.Archived
+	return m
+}
+
+func (m *Model) openSemesters() {
+	if m.library == nil {
+		m.notice = "Semesters are not available with this storage."
+		return
+	}
+	m.closeModal()
+	m.semesters = m.library.Semesters()
+	m.semesterCursor = 0
+	for i, s := range m.semesters {
+		if s.ID == m.semester.ID {
+			m.semesterCursor = i
+		}
+	}
+	m.modal = modalSemesters
+	m.modalTitle = "Semesters"
+	m.modalHint = "↑↓ select · Enter open · [N] New · [E] Edit · [A] Archive · Esc close"
+}
+
+func (m Model) updateSemestersModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "q":
+		m.closeModal()
+	case "j", "down":
+		if m.semesterCursor < len(m.semesters)-1 {
+			m.semesterCursor++
+		}
+	case "k", "up":
+		if m.semesterCursor > 0 {
+			m.semesterCursor--
+		}
+	case "enter":
+		if m.semesterCursor >= len(m.semesters) {
+			return m, nil
+		}
+		id := m.semesters[m.semesterCursor].ID
+		m.closeModal()
+		cmd := m.switchSemester(id)
+		return m, cmd
+	case "n", "N":
+		m.openNewSemester()
+	case "e", "E":
+		if m.semesterCursor < len(m.semesters) {
+			m.openEditSemester(m.semesters[m.semesterCursor])
+		}
+	case "a", "A":
+		if m.semesterCursor >= len(m.semesters) {
+			return m, nil
+		}
+		s := m.semesters[m.semesterCursor]
+		s.Archived = !s.Archived
+		if err := m.library.Update(s); err != nil {
+			m.modalError = err.Error()
+			return m, nil
+		}
+		m.semesters = m.library.Semesters()
+		if s.ID == m.semester.ID {
+			// Reopen so the store matches the new state.
+			m.closeModal()
+			cmd := m.switchSemester(s.ID)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// switchSemester opens the semester with the given ID in place of the
+// current one and makes it the default for the next start.
+func (m *Model) switchSemester(id string) tea.Cmd {
+	// If the new semester cannot be read, the open one stays open.
+	store, data, found, err := m.library.Load(id)
+	if err != nil {
+		m.notice = "Cannot load semester: " + err.Error()
+		return nil
+	}
+	if !found {
+		data = DefaultData()
+	}
+	if err := m.library.SetCurrent(id); err != nil {
+		m.notice = "Cannot remember the semester: " + err.Error()
+	}
+
+	m.shutdownLofi()
+	m.store = store
+	m.semester = m.library.Opened()
+	m.archived = m.semester.Archived
+	m.subjectFilters = nil
+	m.selectedSubj = 0
+	m.examCursor = 0
+	m.projectCursor = 0
+	m.checklistCursor = 0
+	m.checklistDate = ""
+	m.gradeCursor = 0
+	m.sessionCursor = 0
+	m.applyData(data)
+	m.loadHistory()
+	m.refreshChecklistView()
+	if m.notice == "" {
+		m.notice = "Opened " + m.semesterLabel()
+	}
+	if m.lofi.enabled && m.lofi.url != "" {
+		return loadLofiPlaylist(m.lofi.url)
+	}
+	return nil
+}
+
+// semesterLabel is the open semester's name as shown in the header.
+func (m Model) semesterLabel() string {
+	if m.library == nil {
+		return ""
+	}
+	if m.archived {
+		return m.semester.Name + " (archived)"
+	}
+	return m.semester.Name
+}
+
+func (m *Model) openNewSemester() {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Name", inputWidth, true),
+		newFormField("Start", inputWidth, true),
+		newFormField("End", inputWidth, true),
+	}
+	fields[0].input.Placeholder = "e.g. Summer 2026"
+	fields[1].input.Placeholder = "YYYY-MM-DD"
+	fields[2].input.Placeholder = "YYYY-MM-DD"
+	m.openFormModal(modalNewSemester, "New Semester", fields)
+}
+
+func (m *Model) openEditSemester(s storage.Semester) {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Name", inputWidth, true),
+		newFormField("Start", inputWidth, true),
+		newFormField("End", inputWidth, true),
+	}
+	fields[0].input.SetValue(s.Name)
+	fields[1].input.SetValue(s.Start)
+	fields[2].input.SetValue(s.End)
+	m.openFormModal(modalEditSemester, "Edit Semester", fields)
+	m.editSemesterID = s.ID
+}
+
+// buildSemester validates the semester form. Dates may be given in any
+// layout the exam form accepts, or relative to dc, and are stored as
+// YYYY-MM-DD.
+func buildSemester(dc DateContext, name, start, end string) (storage.Semester, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return storage.Semester{}, fmt.Errorf("Name is required.")
+	}
+	from, ok := parseDateInput(start, dc)
+	if !ok {
+		return storage.Semester{}, fmt.Errorf("Start must be a date like 2026-02-16.")
+	}
+	to, ok := parseDateInput(end, dc)
+	if !ok {
+		return storage.Semester{}, fmt.Errorf("End must be a date like 2026-06-30.")
+	}
+	if to.Before(from) {
+		return storage.Semester{}, fmt.Errorf("End must not be before Start.")
+	}
+	return storage.Semester{
+		Name:  name,
+		Start: from.Format(semesterDateLayout),
+		End:   to.Format(semesterDateLayout),
+	}, nil
+}
+
+// submitSemester handles the new and edit semester forms. A new semester
+// continues with the carry-over picker.
+func (m *Model) submitSemester() error {
+	s, err := buildSemester(m.dateContext(), m.formFields[0].input.Value(), m.formFields[1].input.Value(), m.formFields[2].input.Value())
+	if err != nil {
+		return err
+	}
+	for _, other := range m.library.Semesters() {
+		if strings.EqualFold(other.Name, s.Name) && other.ID != m.editSemesterID {
+			return fmt.Errorf("A semester named %s already exists.", other.Name)
+		}
+	}
+
+	if m.modal == modalEditSemester {
+		for _, other := range m.library.Semesters() {
+			if other.ID == m.editSemesterID {
+				s.ID, s.Archived = other.ID, other.Archived
+			}
+		}
+		if err := m.library.Update(s); err != nil {
+			return err
+		}
+		if s.ID == m.semester.ID {
+			m.semester = m.library.Opened()
+		}
+		m.notice = "Saved semester " + s.Name
+		return nil
+	}
+
+	m.closeModal()
+	m.newSemester = s
+	m.openCarryOver()
+	return nil
+}
+
+// openCarryOver lists the subjects and open todos that can be taken into the
+// new semester, all selected. Recurring todos stay with their semester.
+func (m *Model) openCarryOver() {
+	m.carryTodos = nil
+	for i, item := range m.checklistItems {
+		if !item.Done && item.Repeat == "" {
+			m.carryTodos = append(m.carryTodos, i)
+		}
+	}
+	m.filterModalActive = make([]bool, len(m.subjects)+len(m.carryTodos))
+	for i := range m.filterModalActive {
+		m.filterModalActive[i] = true
+	}
+	m.filterModalCursor = 0
+	m.modal = modalCarryOver
+	m.modalTitle = "Carry over to " + m.newSemester.Name
+	m.modalHint = "Space toggle · [C] Clear · Enter create · Esc cancel"
+}
+
+func (m Model) carryOverLabels() []string {
+	labels := make([]string, 0, len(m.subjects)+len(m.carryTodos))
+	for _, s := range m.subjects {
+		labels = append(labels, "Subject  "+s.Code+" "+s.Name)
+	}
+	for _, i := range m.carryTodos {
+		item := m.checklistItems[i]
+		label := "Todo     " + item.Text
+		if item.Subject != "" {
+			label += " (" + item.Subject + ")"
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
+func (m Model) updateCarryOverModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc":
+		m.closeModal()
+	case "j", "down":
+		if m.filterModalCursor < len(m.filterModalActive)-1 {
+			m.filterModalCursor++
+		}
+	case "k", "up":
+		if m.filterModalCursor > 0 {
+			m.filterModalCursor--
+		}
+	case " ":
+		if m.filterModalCursor >= 0 && m.filterModalCursor < len(m.filterModalActive) {
+			m.filterModalActive[m.filterModalCursor] = !m.filterModalActive[m.filterModalCursor]
+		}
+	case "c", "C":
+		m.filterModalActive = make([]bool, len(m.filterModalActive))
+	case "enter":
+		data := m.carryOverData()
+		s, err := m.library.Create(m.newSemester.Name, m.newSemester.Start, m.newSemester.End, data)
+		m.closeModal()
+		if err != nil {
+			m.notice = "Cannot create semester: " + err.Error()
+			return m, nil
+		}
+		cmd := m.switchSemester(s.ID)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// carryOverData is the starting data of the new semester: the current
+// settings plus copies of the selected subjects and todos. Subjects keep
+// their credits, notes and resources but not their exams or classes, which
+// belong to the old semester's dates; todos lose their due dates.
+func (m Model) carryOverData() storage.SemesterData {
+	cur := m.exportData()
+	data := storage.SemesterData{
+		WeeklyExams:       []string{},
+		ConfirmOn:         cur.ConfirmOn,
+		WeekSpan:          cur.WeekSpan,
+		LofiEnabled:       cur.LofiEnabled,
+		LofiURL:           cur.LofiURL,
+		Theme:             cur.Theme,
+		DateFormat:        cur.DateFormat,
+		PriorityRules:     cur.PriorityRules,
+		ICSSubjectPattern: cur.ICSSubjectPattern,
+		GradingScale:      cur.GradingScale,
+		Subjects:          []models.SubjectItem{},
+		Projects:          []models.ProjectItem{},
+		Checklist:         []models.ChecklistItem{},
+	}
+	if start, err := time.ParseInLocation(semesterDateLayout, m.newSemester.Start, time.Local); err == nil {
+		data.WeekStart = weekStartOf(start).Format(semesterDateLayout)
+	}
+	for i, s := range m.subjects {
+		if m.filterModalActive[i] {
+			subject := models.SubjectItem{ID: models.NewID(), Code: s.Code, Name: s.Name, Credits: s.Credits, Notes: s.Notes}
+			for _, r := range s.Resources {
+				r.ID = models.NewID()
+				subject.Resources = append(subject.Resources, r)
+			}
+			data.Subjects = append(data.Subjects, subject)
+		}
+	}
+	for j, i := range m.carryTodos {
+		if m.filterModalActive[len(m.subjects)+j] {
+			item := m.checklistItems[i]
+			data.Checklist = append(data.Checklist, models.ChecklistItem{ID: models.NewID(), Text: item.Text, Subject: item.Subject})
+		}
+	}
+	return data
+}
+
+func (m Model) semesterLabels() []string {
+	labels := make([]string, len(m.semesters))
+	for i, s := range m.semesters {
+		label := s.Name
+		if s.Start != "" || s.End != "" {
+			label += "  " + s.Start + " – " + s.End
+		}
+		if s.Archived {
+			label += "  (archived)"
+		}
+		if s.ID == m.semester.ID {
+			label += "  ●"
+		}
+		labels[i] = label
+	}
+	return labels
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+func TestCarryOverKeepsSettings(t *testing.T) {
+	m := newTestModel(t, storage.SemesterData{
+		SchemaVersion: storage.SchemaVersion,
+		DateFormat:    "dotted",
+		Theme:         "light",
+		WeekSpan:      2,
+		Subjects: []models.SubjectItem{{
+			ID: "s1", Code: "DB", Name: "Databases", Credits: 6, Notes: "Room 2.14",
+			Exams:     []models.ExamItem{{ID: "e1", Name: "Final", Date: "2026-01-13"}},
+			Sessions:  []models.ClassSession{{ID: "c1", Day: "Mon", Start: "09:00", End: "10:30"}},
+			Resources: []models.Resource{{ID: "r1", Title: "Slides", Target: "https://example.com"}},
+		}},
+		Checklist: []models.ChecklistItem{
+			{ID: "t1", Text: "Open", Subject: "DB"},
+			{ID: "t2", Text: "Done", Done: true},
+		},
+	})
+	m.newSemester = storage.Semester{Name: "Spring", Start: "2027-02-15"}
+	m.openCarryOver()
+	data := m.carryOverData()
+
+	if data.DateFormat != "dotted" {
+		t.Errorf("DateFormat = %q, want dotted", data.DateFormat)
+	}
+	if data.Theme != "light" || data.WeekSpan != 2 {
+		t.Errorf("Theme, WeekSpan = %q, %d; want light, 2", data.Theme, data.WeekSpan)
+	}
+	if data.WeekStart != "2027-02-15" {
+		t.Errorf("WeekStart = %q, want the Monday of the start", data.WeekStart)
+	}
+	if len(data.Subjects) != 1 || data.Subjects[0].Code != "DB" || data.Subjects[0].ID == "s1" {
+		t.Fatalf("Subjects = %+v, want a copy of DB with a new ID", data.Subjects)
+	}
+	subject := data.Subjects[0]
+	if subject.Credits != 6 || subject.Notes != "Room 2.14" {
+		t.Errorf("Credits, Notes = %v, %q; want them kept", subject.Credits, subject.Notes)
+	}
+	if len(subject.Resources) != 1 || subject.Resources[0].Title != "Slides" || subject.Resources[0].ID == "r1" {
+		t.Errorf("Resources = %+v, want a copy with a new ID", subject.Resources)
+	}
+	if len(subject.Exams) != 0 || len(subject.Sessions) != 0 {
+		t.Errorf("Exams, Sessions = %+v, %+v; want them left behind", subject.Exams, subject.Sessions)
+	}
+	if len(data.Checklist) != 1 || data.Checklist[0].Text != "Open" {
+		t.Errorf("Checklist = %+v, want the open todo only", data.Checklist)
+	}
+}
+
+func TestSwitchToBrokenSemesterKeepsStore(t *testing.T) {
+	dir := t.TempDir()
+	lib, err := storage.OpenLibrary(dir, func(base string) (storage.Store, func() error, error) {
+		return storage.NewJSONStore(base + ".json"), func() error { return nil }, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := lib.Open(lib.Current().ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewModel(store, persistTestData(), true).WithLibrary(lib)
+	broken, err := lib.Create("Broken", "", "", storage.SemesterData{SchemaVersion: storage.SchemaVersion})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, filepath.FromSlash(broken.File))+".json", []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m.switchSemester(broken.ID)
+	if !strings.HasPrefix(m.notice, "Cannot load semester") {
+		t.Errorf("notice = %q", m.notice)
+	}
+	if m.store != store || m.semester.ID != lib.Current().ID {
+		t.Error("the model left the open semester")
+	}
+	m.checklistItems[0].Text = "Still saved"
+	if !m.persist() {
+		t.Fatalf("persist after the failed switch: %s", m.notice)
+	}
+	if data, _, err := store.Load(); err != nil || data.Checklist[0].Text != "Still saved" {
+		t.Errorf("Load = %+v, %v", data.Checklist, err)
+	}
+}
@@ -0,0 +1,306 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// The Subjects tab lists the subjects beside the details of the selected
+// one: its resources, then its notes. Tab moves the focus to the resources;
+// resourceCursor indexes them. Notes are markdown, edited in $EDITOR while
+// the program is suspended.
+
+// notesEditedMsg reports that the editor started by editNotes exited.
+type notesEditedMsg struct {
+	subjectID string
+	path      string
+	err       error
+}
+
+// resourceOpenedMsg reports how opening a resource went.
+type resourceOpenedMsg struct {
+	title string
+	err   error
+}
+
+// selectedSubject returns the index of the subject under the cursor.
+func (m Model) selectedSubject() (int, bool) {
+	if m.selectedSubj < 0 || m.selectedSubj >= len(m.subjects) {
+		return -1, false
+	}
+	return m.selectedSubj, true
+}
+
+func (m *Model) moveSubjectCursor(delta int) {
+	next := m.selectedSubj + delta
+	if next < 0 || next >= len(m.subjects) {
+		return
+	}
+	m.selectedSubj = next
+	m.resourceCursor = 0
+}
+
+func (m *Model) moveResourceCursor(delta int) {
+	n := 0
+	if si, ok := m.selectedSubject(); ok {
+		n = len(m.subjects[si].Resources)
+	}
+	m.resourceCursor += delta
+	if m.resourceCursor >= n {
+		m.resourceCursor = n - 1
+	}
+	if m.resourceCursor < 0 {
+		m.resourceCursor = 0
+	}
+}
+
+func (m *Model) toggleSubjectFocus() {
+	if _, ok := m.selectedSubject(); !ok {
+		m.subjectFocus = false
+		return
+	}
+	m.subjectFocus = !m.subjectFocus
+	m.moveResourceCursor(0)
+}
+
+// selectedResource returns the subject and resource under the cursor.
+func (m Model) selectedResource() (si, ri int, ok bool) {
+	si, ok = m.selectedSubject()
+	if !ok || m.resourceCursor < 0 || m.resourceCursor >= len(m.subjects[si].Resources) {
+		return -1, -1, false
+	}
+	return si, m.resourceCursor, true
+}
+
+func (m *Model) resourceFields() []formField {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Title", inputWidth, false),
+		newFormField("Link", inputWidth, true),
+	}
+	fields[0].input.Placeholder = "Syllabus (blank: the link)"
+	fields[1].input.Placeholder = "https://… or ~/notes/lecture1.pdf"
+	return fields
+}
+
+func (m *Model) openAddResource() {
+	si, ok := m.selectedSubject()
+	if !ok {
+		m.notice = strings.TrimSpace("Add a subject first  " + m.actionHint("add-subject", "Add subject"))
+		return
+	}
+	m.editSubjectID = m.subjects[si].ID
+	m.openFormModal(modalAddResource, "Add Resource ("+m.subjects[si].Code+")", m.resourceFields())
+}
+
+func (m *Model) openEditResource() {
+	si, ri, ok := m.selectedResource()
+	if !ok {
+		return
+	}
+	subject := m.subjects[si]
+	fields := m.resourceFields()
+	fields[0].input.SetValue(subject.Resources[ri].Title)
+	fields[1].input.SetValue(subject.Resources[ri].Target)
+	m.editSubjectID = subject.ID
+	m.editResourceID = subject.Resources[ri].ID
+	m.openFormModal(modalEditResource, "Edit Resource ("+subject.Code+")", fields)
+}
+
+// submitResource handles the add and edit resource forms.
+func (m *Model) submitResource() error {
+	si := findSubjectByID(m.subjects, m.editSubjectID)
+	if si < 0 {
+		return nil
+	}
+	resource, err := BuildResource(m.formFields[0].input.Value(), m.formFields[1].input.Value())
+	if err != nil {
+		return err
+	}
+	subject := &m.subjects[si]
+	if m.modal == modalEditResource {
+		ri := findResourceIndex(subject.Resources, m.editResourceID)
+		if ri < 0 {
+			return nil
+		}
+		subject.Resources[ri].Title = resource.Title
+		subject.Resources[ri].Target = resource.Target
+		m.resourceCursor = ri
+	} else {
+		subject.Resources = append(subject.Resources, resource)
+		m.resourceCursor = len(subject.Resources) - 1
+	}
+	m.persist()
+	return nil
+}
+
+// queueDeleteResource asks to delete the selected resource.
+func (m *Model) queueDeleteResource() {
+	si, ri, ok := m.selectedResource()
+	if !ok {
+		return
+	}
+	resource := m.subjects[si].Resources[ri]
+	action := confirmAction{kind: confirmDeleteResource, id: resource.ID}
+	m.confirmOrApply(action, fmt.Sprintf("Delete resource \"%s\"?", resource.Title))
+}
+
+// deleteResource removes the resource with the given ID.
+func (m *Model) deleteResource(id string) {
+	for si := range m.subjects {
+		subject := &m.subjects[si]
+		if ri := findResourceIndex(subject.Resources, id); ri >= 0 {
+			subject.Resources = append(subject.Resources[:ri], subject.Resources[ri+1:]...)
+			m.moveResourceCursor(0)
+			return
+		}
+	}
+}
+
+// editNotes writes the notes of the selected subject to a temporary file
+// and suspends the program while $EDITOR edits it.
+func (m *Model) editNotes() tea.Cmd {
+	si, ok := m.selectedSubject()
+	if !ok {
+		m.notice = strings.TrimSpace("Add a subject first  " + m.actionHint("add-subject", "Add subject"))
+		return nil
+	}
+	subject := m.subjects[si]
+	path, err := writeTempFile("seman-"+fileSafe(subject.Code)+"-*.md", notesFile(subject.Notes))
+	if err != nil {
+		m.notice = "Cannot edit the notes: " + err.Error()
+		return nil
+	}
+	id := subject.ID
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return notesEditedMsg{subjectID: id, path: path, err: err}
+	})
+}
+
+// applyNotes saves the notes the editor left in the temporary file.
+func (m *Model) applyNotes(msg notesEditedMsg) {
+	defer os.Remove(msg.path)
+	if msg.err != nil {
+		m.notice = "The editor failed: " + msg.err.Error()
+		return
+	}
+	raw, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.notice = "Cannot read the notes: " + err.Error()
+		return
+	}
+	si := findSubjectByID(m.subjects, msg.subjectID)
+	if si < 0 {
+		return
+	}
+	notes := cleanNotes(string(raw))
+	if notes == m.subjects[si].Notes {
+		m.notice = "Notes unchanged."
+		return
+	}
+	m.subjects[si].Notes = notes
+	if m.persist() {
+		m.notice = "Saved the notes of " + m.subjects[si].Code + "."
+	}
+}
+
+// notesFile is the text an editor opens for notes: they end in a newline,
+// as editors expect.
+func notesFile(notes string) string {
+	if notes == "" {
+		return ""
+	}
+	return notes + "\n"
+}
+
+// cleanNotes drops carriage returns and the blank lines and spaces at the
+// end, which editors add and the detail pane would show.
+func cleanNotes(raw string) string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	return strings.TrimRight(raw, " \t\n")
+}
+
+// editorCommand runs $VISUAL, or else $EDITOR, or else vi on path. The
+// variables may carry arguments, e.g. "code --wait".
+func editorCommand(path string) *exec.Cmd {
+	editor := strings.Fields(os.Getenv("VISUAL"))
+	if len(editor) == 0 {
+		editor = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	return exec.Command(editor[0], append(editor[1:], path)...)
+}
+
+// writeTempFile writes content to a new temporary file named after pattern
+// (see os.CreateTemp) and returns its path.
+func writeTempFile(pattern, content string) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// fileSafe keeps the letters, digits and dashes of name, for a file name.
+func fileSafe(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// openResource opens the selected resource with the system's opener.
+func (m *Model) openResource() tea.Cmd {
+	si, ri, ok := m.selectedResource()
+	if !ok {
+		m.notice = strings.TrimSpace("No resources yet  " + m.actionHint("add-resource", "Add resource"))
+		return nil
+	}
+	resource := m.subjects[si].Resources[ri]
+	target := expandPath(resource.Target)
+	return func() tea.Msg {
+		// The opener runs detached from the terminal, so that what it
+		// prints does not garble the screen.
+		err := openerCommand(target).Run()
+		return resourceOpenedMsg{title: resource.Title, err: err}
+	}
+}
+
+func (m *Model) handleResourceOpened(msg resourceOpenedMsg) {
+	if msg.err != nil {
+		m.notice = fmt.Sprintf("Cannot open %s: %s", msg.title, msg.err)
+		return
+	}
+	m.notice = "Opened " + msg.title + "."
+}
+
+// openerCommand opens target, a URL or path, in the application the system
+// associates with it.
+func openerCommand(target string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	}
+	return exec.Command("xdg-open", target)
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/config"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// themesPollInterval is how often the theme files are checked for changes.
+const themesPollInterval = 2 * time.Second
+
+// themesMsg reports the theme files after a check: themes and errs are set
+// only when the files changed since the last one.
+type themesMsg struct {
+	stamp   string
+	changed bool
+	themes  style.Themes
+	errs    []error
+}
+
+// ThemesDir is where theme files are read from: themes/ in the configuration
+// directory.
+func ThemesDir() (string, error) {
+	return config.Path("themes")
+}
+
+// WithThemes adds the themes of the files in dir to the built-in ones and
+// reloads them whenever the files change.
+func (m Model) WithThemes(dir string) Model {
+	m.themesDir = dir
+	m.themesStamp = themesStamp(dir)
+	themes, errs := style.LoadThemes(dir)
+	m.applyThemes(themes, errs)
+	return m
+}
+
+// theme returns the active theme.
+func (m Model) theme() style.Theme {
+	return m.themes.Of(m.themeName)
+}
+
+func (m *Model) applyThemes(themes style.Themes, errs []error) {
+	m.themes = themes
+	if len(errs) > 0 {
+		m.notice = "Theme " + errs[0].Error()
+		if len(errs) > 1 {
+			m.notice += fmt.Sprintf(" (and %d more)", len(errs)-1)
+		}
+	}
+	if m.modal != modalNone {
+		t := m.theme()
+		for i := range m.formFields {
+			m.formFields[i].input.TextStyle = t.InputText
+			m.formFields[i].input.PlaceholderStyle = t.InputHint
+			m.formFields[i].input.CursorStyle = t.InputCursor
+		}
+	}
+	m.refreshChecklistView()
+}
+
+// watchThemes checks the theme files for changes after a while.
+func (m Model) watchThemes() tea.Cmd {
+	if m.themesDir == "" {
+		return nil
+	}
+	dir, last := m.themesDir, m.themesStamp
+	return tea.Tick(themesPollInterval, func(time.Time) tea.Msg {
+		stamp := themesStamp(dir)
+		if stamp == last {
+			return themesMsg{stamp: stamp}
+		}
+		themes, errs := style.LoadThemes(dir)
+		return themesMsg{stamp: stamp, changed: true, themes: themes, errs: errs}
+	})
+}
+
+func (m *Model) handleThemes(msg themesMsg) tea.Cmd {
+	m.themesStamp = msg.stamp
+	if msg.changed {
+		m.applyThemes(msg.themes, msg.errs)
+		switch {
+		case len(msg.errs) > 0:
+		case !m.themes.Has(m.themeName):
+			m.notice = fmt.Sprintf("Theme %s no longer exists; showing green.", m.themeName)
+		default:
+			m.notice = "Themes reloaded."
+		}
+	}
+	return m.watchThemes()
+}
+
+// themesStamp sums up the names, sizes and modification times of the theme
+// files in dir, so that any change to them changes it.
+func themesStamp(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	var parts []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || ext != ".toml" && ext != ".json" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// parseWeekday reads a day name such as "Mon" or "monday"; any unambiguous
+// prefix of two letters or more will do.
+func parseWeekday(raw string) (string, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	names := []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+	if len(raw) >= 2 {
+		for i, name := range names {
+			if strings.HasPrefix(name, raw) {
+				return models.Weekdays[i], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("Day must be a weekday like Mon or Thursday.")
+}
+
+// parseClockTime reads 9, 9:30, 09:30 or 9.30 as "15:04".
+func parseClockTime(raw string) (string, bool) {
+	raw = strings.ReplaceAll(strings.TrimSpace(raw), ".", ":")
+	hour, minute, hasMinute := strings.Cut(raw, ":")
+	h, err := strconv.Atoi(hour)
+	if err != nil || h < 0 || h > 23 {
+		return "", false
+	}
+	min := 0
+	if hasMinute {
+		if len(minute) != 2 {
+			return "", false
+		}
+		if min, err = strconv.Atoi(minute); err != nil || min < 0 || min > 59 {
+			return "", false
+		}
+	}
+	return fmt.Sprintf("%02d:%02d", h, min), true
+}
+
+// parseTimeRange reads "9:00-10:30" (or with an en dash) into start and end.
+func parseTimeRange(raw string) (string, string, error) {
+	raw = strings.NewReplacer("–", "-", "—", "-").Replace(raw)
+	from, to, ok := strings.Cut(raw, "-")
+	if ok {
+		start, okStart := parseClockTime(from)
+		end, okEnd := parseClockTime(to)
+		if okStart && okEnd {
+			if end <= start {
+				return "", "", fmt.Errorf("Time must end after it starts.")
+			}
+			return start, end, nil
+		}
+	}
+	return "", "", fmt.Errorf("Time must look like 9:00-10:30.")
+}
+
+// parseSessionType matches the known session types case-insensitively and
+// keeps anything else as entered; blank means a lecture.
+func parseSessionType(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return models.SessionTypes[0]
+	}
+	for _, kind := range models.SessionTypes {
+		if strings.EqualFold(kind, raw) {
+			return kind
+		}
+	}
+	return raw
+}
+
+func parseWeeks(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "all", "every", "weekly":
+		return models.WeeksAll, nil
+	case "odd":
+		return models.WeeksOdd, nil
+	case "even":
+		return models.WeeksEven, nil
+	}
+	return "", fmt.Errorf("Weeks must be all, odd or even.")
+}
+
+// parseSessionBound reads an optional From or Until date as YYYY-MM-DD.
+func parseSessionBound(label, raw string, dc DateContext) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "", nil
+	}
+	date, ok := parseDateInput(raw, dc)
+	if !ok {
+		return "", fmt.Errorf("%s must be a date like 2026-02-16.", label)
+	}
+	return date.Format(semesterDateLayout), nil
+}
+
+// weekSessions lists the classes of the week shown on the Timetable tab.
+func (m Model) weekSessions() []models.TimetableEntry {
+	return models.Timetable(m.subjects, m.weekStart, 7)
+}
+
+// todaySessions lists today's classes for the Dashboard, honouring the
+// subject filter.
+func (m Model) todaySessions() []models.TimetableEntry {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var entries []models.TimetableEntry
+	for _, e := range models.Timetable(m.subjects, today, 1) {
+		if len(m.subjectFilters) == 0 || m.isFiltered(e.SubjectCode) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+func (m *Model) moveSessionCursor(delta int) {
+	entries := m.weekSessions()
+	m.sessionCursor += delta
+	if m.sessionCursor >= len(entries) {
+		m.sessionCursor = len(entries) - 1
+	}
+	if m.sessionCursor < 0 {
+		m.sessionCursor = 0
+	}
+}
+
+// selectedSession returns the class under the Timetable tab cursor.
+func (m Model) selectedSession() (models.TimetableEntry, bool) {
+	entries := m.weekSessions()
+	if m.sessionCursor < 0 || m.sessionCursor >= len(entries) {
+		return models.TimetableEntry{}, false
+	}
+	return entries[m.sessionCursor], true
+}
+
+func (m *Model) sessionFields() []formField {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Subject", inputWidth, true),
+		newFormField("Type", inputWidth, false),
+		newFormField("Day", inputWidth, true),
+		newFormField("Time", inputWidth, true),
+		newFormField("Room", inputWidth, false),
+		newFormField("Weeks", inputWidth, false),
+		newFormField("From", inputWidth, false),
+		newFormField("Until", inputWidth, false),
+	}
+	fields[1].input.Placeholder = strings.Join(models.SessionTypes, " / ")
+	fields[2].input.Placeholder = "Mon … Sun"
+	fields[3].input.Placeholder = "9:00-10:30"
+	fields[5].input.Placeholder = "all / odd / even"
+	fields[6].input.Placeholder = "YYYY-MM-DD (blank: no limit)"
+	fields[7].input.Placeholder = "YYYY-MM-DD (blank: no limit)"
+	return fields
+}
+
+// openAddSession opens the class form for the selected day of the shown
+// week, limited to the open semester's dates when it has them.
+func (m *Model) openAddSession() {
+	fields := m.sessionFields()
+	if entry, ok := m.selectedSession(); ok {
+		fields[2].input.SetValue(entry.Session.Day)
+	}
+	fields[6].input.SetValue(m.semester.Start)
+	fields[7].input.SetValue(m.semester.End)
+	m.openFormModal(modalAddSession, "Add Class", fields)
+}
+
+func (m *Model) openEditSession() {
+	entry, ok := m.selectedSession()
+	if !ok {
+		return
+	}
+	c := entry.Session
+	fields := m.sessionFields()
+	fields[0].input.SetValue(entry.SubjectCode)
+	fields[1].input.SetValue(c.Type)
+	fields[2].input.SetValue(c.Day)
+	fields[3].input.SetValue(c.Start + "-" + c.End)
+	fields[4].input.SetValue(c.Room)
+	fields[5].input.SetValue(c.Weeks)
+	fields[6].input.SetValue(c.From)
+	fields[7].input.SetValue(c.Until)
+	m.editSessionID = c.ID
+	m.openFormModal(modalEditSession, "Edit Class", fields)
+}
+
+// submitSession handles the add and edit class forms. Editing a class can
+// move it to another subject.
+func (m *Model) submitSession() error {
+	values := make([]string, len(m.formFields))
+	for i, f := range m.formFields {
+		values[i] = f.input.Value()
+	}
+	si, session, err := BuildSession(m.subjects, m.dateContext(), values[0], values[1], values[2], values[3], values[4], values[5], values[6], values[7])
+	if err != nil {
+		return err
+	}
+	if m.modal == modalEditSession {
+		oldSi, oldCi := findSessionByID(m.subjects, m.editSessionID)
+		if oldSi < 0 {
+			return nil
+		}
+		session.ID = m.editSessionID
+		if oldSi == si {
+			m.subjects[si].Sessions[oldCi] = session
+			m.persist()
+			return nil
+		}
+		old := m.subjects[oldSi].Sessions
+		m.subjects[oldSi].Sessions = append(old[:oldCi], old[oldCi+1:]...)
+	}
+	m.subjects[si].Sessions = append(m.subjects[si].Sessions, session)
+	m.persist()
+	return nil
+}
@@ -0,0 +1,549 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+const (
+	todoDuePlaceholder    = "tomorrow 10:00, +3d … (optional)"
+	todoRepeatPlaceholder = "weekly on Mon, Thu / every 2 weeks until 2026-06-30"
+)
+
+// todoEntry is a row of the todo list: a todo, or one occurrence of a
+// recurring todo.
+type todoEntry struct {
+	idx  int    // into checklistItems
+	date string // the occurrence's day (2006-01-02); "" for one-off todos
+}
+
+// recurringHorizon is how far ahead the global view lists the occurrences
+// of recurring todos that have no end.
+const recurringHorizon = 4 * 7 * 24 * time.Hour
+
+// todoOverdue reports whether an open todo is past due: date-only todos
+// from the day after, timed ones from the minute after.
+func todoOverdue(item models.ChecklistItem, now time.Time) bool {
+	if item.Done {
+		return false
+	}
+	due, ok := dates.Parse(item.Due)
+	if !ok {
+		return false
+	}
+	if dates.IsDay(item.Due) {
+		due = due.AddDate(0, 0, 1)
+	}
+	return due.Before(now)
+}
+
+// todoRank orders the day groups: overdue first, then by day, then the
+// todos without a due date.
+func todoRank(item models.ChecklistItem, now time.Time) int {
+	if todoOverdue(item, now) {
+		return 0
+	}
+	if _, ok := dates.Parse(item.Due); ok {
+		return 1
+	}
+	return 2
+}
+
+// todoLess sorts todos into their day groups; within a day open todos come
+// first, each by time.
+func todoLess(a, b models.ChecklistItem, now time.Time) bool {
+	ra, rb := todoRank(a, now), todoRank(b, now)
+	if ra != rb {
+		return ra < rb
+	}
+	da, _ := dates.Parse(a.Due)
+	db, _ := dates.Parse(b.Due)
+	if ra == 1 {
+		if dayA, dayB := da.Format("2006-01-02"), db.Format("2006-01-02"); dayA != dayB {
+			return dayA < dayB
+		}
+	}
+	if a.Done != b.Done {
+		return !a.Done
+	}
+	return da.Before(db)
+}
+
+// todoGroup names the day group a todo is listed under on the Todos tab.
+func todoGroup(item models.ChecklistItem, now time.Time) string {
+	if todoOverdue(item, now) {
+		return "Overdue"
+	}
+	due, ok := dates.Parse(item.Due)
+	if !ok {
+		return "No due date"
+	}
+	label := due.Format("Mon Jan 2")
+	switch due.Format("2006-01-02") {
+	case now.Format("2006-01-02"):
+		return "Today · " + label
+	case now.AddDate(0, 0, 1).Format("2006-01-02"):
+		return "Tomorrow · " + label
+	}
+	return label
+}
+
+// parseRepeat reads the Repeat field of the todo forms: "weekly",
+// "biweekly", "every 3 weeks", weekday names, "weekdays", and then either
+// "until DATE" or "N times", in any order but with the date last. A raw
+// RRULE is accepted too. It reports false for a blank field.
+func parseRepeat(raw string, dc DateContext) (models.Recurrence, bool, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return models.Recurrence{}, false, nil
+	}
+	if upper := strings.ToUpper(raw); strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=") {
+		rule, err := models.ParseRecurrence(raw)
+		if err != nil {
+			return models.Recurrence{}, false, fmt.Errorf("Repeat rule not understood: %v.", err)
+		}
+		return rule, true, nil
+	}
+	errRepeat := fmt.Errorf("Repeat must look like \"weekly on Mon, Thu\", \"every 2 weeks until 2026-06-30\" or \"biweekly, 6 times\".")
+	rule := models.Recurrence{Interval: 1}
+	words := strings.Fields(raw)
+	for i := 0; i < len(words); i++ {
+		word := strings.ToLower(strings.Trim(words[i], ","))
+		next := ""
+		if i+1 < len(words) {
+			next = strings.ToLower(strings.Trim(words[i+1], ","))
+		}
+		switch word {
+		case "", "on", "and", "week", "weeks":
+		case "weekly":
+			rule.Interval = 1
+		case "biweekly", "fortnightly":
+			rule.Interval = 2
+		case "every":
+			if next == "other" {
+				rule.Interval = 2
+				i++
+			} else if n, err := strconv.Atoi(next); err == nil && n > 0 {
+				rule.Interval = n
+				i++
+			}
+		case "weekdays":
+			rule.Days = append(rule.Days, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case "once":
+			rule.Count = 1
+		case "until":
+			date, ok := parseDateInput(strings.Join(words[i+1:], " "), dc)
+			if !ok {
+				return models.Recurrence{}, false, fmt.Errorf("Repeat must end on a date like 2026-06-30.")
+			}
+			rule.Until = date.Format(semesterDateLayout)
+			i = len(words)
+		default:
+			if n, err := strconv.Atoi(word); err == nil && n > 0 && (next == "times" || next == "x") {
+				rule.Count = n
+				i++
+				continue
+			}
+			day, err := parseWeekday(word)
+			if err != nil {
+				return models.Recurrence{}, false, errRepeat
+			}
+			rule.Days = append(rule.Days, time.Weekday((indexOfString(models.Weekdays, day)+1)%7))
+		}
+	}
+	if rule.Until != "" && rule.Count > 0 {
+		return models.Recurrence{}, false, fmt.Errorf("Repeat can end on a date or after a number of times, not both.")
+	}
+	return rule, true, nil
+}
+
+// todoRecurrence returns the rule and first occurrence of a recurring todo.
+// Todos without a rule, or whose rule or due date cannot be read, are
+// treated as one-off todos.
+func todoRecurrence(item models.ChecklistItem) (models.Recurrence, time.Time, bool) {
+	if item.Repeat == "" {
+		return models.Recurrence{}, time.Time{}, false
+	}
+	first, ok := dates.Parse(item.Due)
+	if !ok {
+		return models.Recurrence{}, time.Time{}, false
+	}
+	rule, err := models.ParseRecurrence(item.Repeat)
+	if err != nil {
+		return models.Recurrence{}, time.Time{}, false
+	}
+	return rule, first, true
+}
+
+// todoOccurrences lists the days of a recurring todo's occurrences before
+// to, leaving out skipped ones.
+func todoOccurrences(item models.ChecklistItem, to time.Time) []string {
+	rule, first, ok := todoRecurrence(item)
+	if !ok {
+		return nil
+	}
+	var days []string
+	for _, date := range rule.Dates(first, time.Time{}, to) {
+		if day := date.Format(semesterDateLayout); !containsString(item.Skipped, day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// todoFirstDay is the day of a recurring todo's first occurrence, or "".
+func todoFirstDay(item models.ChecklistItem) string {
+	rule, first, ok := todoRecurrence(item)
+	if !ok {
+		return ""
+	}
+	interval := rule.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	if dates := rule.Dates(first, time.Time{}, first.AddDate(0, 0, 7*interval+7)); len(dates) > 0 {
+		return dates[0].Format(semesterDateLayout)
+	}
+	return ""
+}
+
+// todoNextDay returns the first occurrence of a recurring todo on or after
+// the day of now, or its last one if the series has ended.
+func todoNextDay(item models.ChecklistItem, now time.Time) string {
+	days := todoOccurrences(item, now.AddDate(1, 0, 0))
+	today := dayOf(now).Format(semesterDateLayout)
+	for _, day := range days {
+		if day >= today {
+			return day
+		}
+	}
+	if len(days) > 0 {
+		return days[len(days)-1]
+	}
+	return ""
+}
+
+// todoOccurrence is the occurrence of a recurring todo on day, as a todo of
+// its own: due that day at the series' time, done if ticked off.
+func todoOccurrence(item models.ChecklistItem, day string) models.ChecklistItem {
+	occ := item
+	if date, err := time.ParseInLocation(semesterDateLayout, day, time.Local); err == nil {
+		occ.Due = dates.StoreDay(date)
+		if first, ok := dates.Parse(item.Due); ok && !dates.IsDay(item.Due) {
+			date = time.Date(date.Year(), date.Month(), date.Day(), first.Hour(), first.Minute(), 0, 0, time.Local)
+			occ.Due = dates.StoreTime(date)
+		}
+	}
+	occ.Done = containsString(item.DoneDates, day)
+	return occ
+}
+
+// describeRepeat renders a todo's rule for the Repeat field. With from set,
+// a count covers only the occurrences from that day on, so that the text
+// fits a series starting there.
+func describeRepeat(item models.ChecklistItem, from string) string {
+	rule, first, ok := todoRecurrence(item)
+	if !ok {
+		return item.Repeat
+	}
+	if rule.Count > 0 && from != "" {
+		if day, err := time.ParseInLocation(semesterDateLayout, from, time.Local); err == nil {
+			rule.Count -= len(rule.Dates(first, time.Time{}, day))
+		}
+	}
+	return rule.Describe()
+}
+
+// ToggleTodo ticks a todo off or reopens it. For a recurring todo it
+// toggles the occurrence on the given date or, without one, ticks off the
+// earliest open occurrence. It returns that occurrence's day, or "" for a
+// one-off todo.
+func ToggleTodo(item *models.ChecklistItem, on string, now time.Time) (string, error) {
+	if _, _, ok := todoRecurrence(*item); !ok {
+		if strings.TrimSpace(on) != "" {
+			return "", fmt.Errorf("Only recurring todos take a date.")
+		}
+		item.Done = !item.Done
+		return "", nil
+	}
+	if strings.TrimSpace(on) != "" {
+		date, ok := parseDateInput(on, DateContext{Now: now})
+		if !ok {
+			return "", fmt.Errorf("Date must be a date like Jan 2, 2006, 2006-01-02 or yesterday.")
+		}
+		day := date.Format(semesterDateLayout)
+		next := time.Date(date.Year(), date.Month(), date.Day()+1, 0, 0, 0, 0, time.Local)
+		if !containsString(todoOccurrences(*item, next), day) {
+			return "", fmt.Errorf("The todo does not occur on %s.", day)
+		}
+		item.DoneDates = toggleDate(item.DoneDates, day)
+		return day, nil
+	}
+	for _, day := range todoOccurrences(*item, now.AddDate(1, 0, 0)) {
+		if !containsString(item.DoneDates, day) {
+			item.DoneDates = toggleDate(item.DoneDates, day)
+			return day, nil
+		}
+	}
+	return "", fmt.Errorf("The todo has no open occurrences left.")
+}
+
+// toggleDate adds day to dates or removes it, returning a new sorted slice.
+func toggleDate(dates []string, day string) []string {
+	out := make([]string, 0, len(dates)+1)
+	for _, d := range dates {
+		if d != day {
+			out = append(out, d)
+		}
+	}
+	if len(out) == len(dates) {
+		out = append(out, day)
+		sort.Strings(out)
+	}
+	return out
+}
+
+// splitDates splits sorted dates into those before day and the rest.
+func splitDates(dates []string, day string) ([]string, []string) {
+	var before, after []string
+	for _, d := range dates {
+		if d < day {
+			before = append(before, d)
+		} else {
+			after = append(after, d)
+		}
+	}
+	return before, after
+}
+
+// endSeriesBefore makes a recurring todo stop before day, dropping what it
+// recorded about occurrences from then on.
+func endSeriesBefore(item *models.ChecklistItem, day string) {
+	rule, _, ok := todoRecurrence(*item)
+	if !ok {
+		return
+	}
+	if date, err := time.ParseInLocation(semesterDateLayout, day, time.Local); err == nil {
+		rule.Until = date.AddDate(0, 0, -1).Format(semesterDateLayout)
+		rule.Count = 0
+	}
+	item.Repeat = rule.String()
+	item.DoneDates, _ = splitDates(item.DoneDates, day)
+	item.Skipped, _ = splitDates(item.Skipped, day)
+}
+
+func indexOfString(items []string, value string) int {
+	for i, item := range items {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Model) todoFields(withRepeat bool) []formField {
+	inputWidth := m.modalInputWidth()
+	fields := []formField{
+		newFormField("Task", inputWidth, true),
+		newFormField("Subject", inputWidth, false),
+		newFormField("Due", inputWidth, false),
+	}
+	fields[2].input.Placeholder = todoDuePlaceholder
+	if withRepeat {
+		fields = append(fields, newFormField("Repeat", inputWidth, false))
+		fields[3].input.Placeholder = todoRepeatPlaceholder
+	}
+	return fields
+}
+
+// selectedTodo returns the todo under the cursor and, for a recurring one,
+// the day of the selected occurrence.
+func (m Model) selectedTodo() (models.ChecklistItem, string, bool) {
+	if m.checklistCursor < 0 || m.checklistCursor >= len(m.checklistItems) {
+		return models.ChecklistItem{}, "", false
+	}
+	return m.checklistItems[m.checklistCursor], m.checklistDate, true
+}
+
+// todoAt returns the todo shown by a row of the list.
+func (m Model) todoAt(e todoEntry) models.ChecklistItem {
+	item := m.checklistItems[e.idx]
+	if e.date != "" {
+		item = todoOccurrence(item, e.date)
+	}
+	return item
+}
+
+// todoPos is the cursor's row in the visible todos, or -1.
+func (m Model) todoPos() int {
+	for i, e := range m.todoVisible {
+		if e.idx == m.checklistCursor && e.date == m.checklistDate {
+			return i
+		}
+	}
+	return -1
+}
+
+// openTodoScope asks whether editing or deleting the selected occurrence
+// of a recurring todo applies to it alone or to the rest of the series.
+func (m *Model) openTodoScope(item models.ChecklistItem, day string, delete bool) {
+	m.closeModal()
+	m.editTodoID = item.ID
+	m.editTodoDate = day
+	m.scopeDelete = delete
+	m.scopeCursor = 0
+	m.modal = modalTodoScope
+	m.modalTitle = "Edit recurring todo"
+	if delete {
+		m.modalTitle = "Delete recurring todo"
+	}
+	m.modalHint = "↑↓ select · Enter choose · Esc cancel"
+}
+
+func (m Model) todoScopeLabels() []string {
+	label := m.editTodoDate
+	if date, err := time.ParseInLocation(semesterDateLayout, m.editTodoDate, time.Local); err == nil {
+		label = date.Format("Mon Jan 2")
+	}
+	return []string{
+		"Only this occurrence (" + label + ")",
+		"This and all following occurrences",
+	}
+}
+
+func (m Model) updateTodoScopeModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "q":
+		m.closeModal()
+	case "j", "down":
+		m.scopeCursor = 1
+	case "k", "up":
+		m.scopeCursor = 0
+	case "enter":
+		future := m.scopeCursor == 1
+		if m.scopeDelete {
+			m.deleteOccurrences(future)
+			m.closeModal()
+			return m, nil
+		}
+		m.openEditOccurrence(future)
+	}
+	return m, nil
+}
+
+// openEditOccurrence opens the todo form for the occurrence chosen in the
+// scope picker. Only an edit of the following occurrences can change the
+// repeat rule.
+func (m *Model) openEditOccurrence(future bool) {
+	ti := findTodoByID(m.checklistItems, m.editTodoID)
+	if ti < 0 {
+		m.closeModal()
+		return
+	}
+	item := m.checklistItems[ti]
+	occ := todoOccurrence(item, m.editTodoDate)
+	fields := m.todoFields(future)
+	fields[0].input.SetValue(occ.Text)
+	fields[1].input.SetValue(occ.Subject)
+	fields[2].input.SetValue(m.dateFormat.ShowStored(occ.Due))
+	title := "Edit Occurrence"
+	if future {
+		fields[3].input.SetValue(describeRepeat(item, m.editTodoDate))
+		title = "Edit Following Occurrences"
+	}
+	m.editTodoFuture = future
+	m.openFormModal(modalEditTodo, title, fields)
+}
+
+// submitEditTodo saves the edit todo form. A one-off todo is changed in
+// place (and becomes recurring when given a rule). A single occurrence is
+// split off the series as a todo of its own; an edit of the following
+// occurrences ends the series before the occurrence and starts a new one
+// with it, unless it is the first occurrence, which changes the series.
+func (m *Model) submitEditTodo() error {
+	ti := findTodoByID(m.checklistItems, m.editTodoID)
+	if ti < 0 {
+		return nil
+	}
+	repeat := ""
+	if len(m.formFields) > 3 {
+		repeat = m.formFields[3].input.Value()
+	}
+	item, err := BuildTodo(m.subjects, m.dateContext(), m.formFields[0].input.Value(), m.formFields[1].input.Value(), m.formFields[2].input.Value(), repeat)
+	if err != nil {
+		return err
+	}
+	day := m.editTodoDate
+	series := m.checklistItems[ti]
+	switch {
+	case day == "":
+		item.ID = series.ID
+		item.Due = keepStored(series.Due, item.Due)
+		item.Done = series.Done && item.Repeat == ""
+		m.checklistItems[ti] = item
+		m.checklistCursor = ti
+	case !m.editTodoFuture:
+		item.Done = containsString(series.DoneDates, day)
+		series.Skipped = toggleDate(series.Skipped, day)
+		if item.Done {
+			series.DoneDates = toggleDate(series.DoneDates, day)
+		}
+		m.checklistItems[ti] = series
+		m.checklistItems = append(m.checklistItems, item)
+		m.checklistCursor = len(m.checklistItems) - 1
+	default:
+		_, done := splitDates(series.DoneDates, day)
+		_, skipped := splitDates(series.Skipped, day)
+		if item.Repeat != "" {
+			item.DoneDates, item.Skipped = done, skipped
+		} else {
+			item.Done = containsString(done, day)
+		}
+		if day <= todoFirstDay(series) {
+			item.ID = series.ID
+			m.checklistItems[ti] = item
+			m.checklistCursor = ti
+		} else {
+			endSeriesBefore(&series, day)
+			m.checklistItems[ti] = series
+			m.checklistItems = append(m.checklistItems, item)
+			m.checklistCursor = len(m.checklistItems) - 1
+		}
+	}
+	m.checklistDate = todoFirstDay(item)
+	m.sortChecklistByDone()
+	m.persist()
+	m.refreshChecklistView()
+	return nil
+}
+
+// deleteOccurrences removes the occurrence chosen in the scope picker, or it
+// and all that follow; from the first occurrence that is the whole series.
+func (m *Model) deleteOccurrences(future bool) {
+	ti := findTodoByID(m.checklistItems, m.editTodoID)
+	if ti < 0 {
+		return
+	}
+	day := m.editTodoDate
+	series := &m.checklistItems[ti]
+	switch {
+	case !future:
+		series.Skipped = toggleDate(series.Skipped, day)
+		if containsString(series.DoneDates, day) {
+			series.DoneDates = toggleDate(series.DoneDates, day)
+		}
+	case day <= todoFirstDay(*series):
+		m.checklistItems = append(m.checklistItems[:ti], m.checklistItems[ti+1:]...)
+	default:
+		endSeriesBefore(series, day)
+	}
+	m.persist()
+	m.refreshChecklistView()
+}
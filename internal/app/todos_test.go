@@ -0,0 +1,194 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// seriesTestData has a weekly todo of five occurrences from Monday
+// 2026-10-05: the first and third are done and the fourth is skipped.
+func seriesTestData() storage.SemesterData {
+	return storage.SemesterData{
+		SchemaVersion: storage.SchemaVersion,
+		Checklist: []models.ChecklistItem{{
+			ID:        "t1",
+			Text:      "Gym",
+			Due:       "2026-10-05",
+			Repeat:    "FREQ=WEEKLY;COUNT=5",
+			DoneDates: []string{"2026-10-05", "2026-10-19"},
+			Skipped:   []string{"2026-10-26"},
+		}},
+	}
+}
+
+// splitTodos returns the series t1, if it is left, and the todos split off
+// it.
+func splitTodos(m *Model) (*models.ChecklistItem, []models.ChecklistItem) {
+	var series *models.ChecklistItem
+	var others []models.ChecklistItem
+	for i, item := range m.checklistItems {
+		if item.ID == "t1" {
+			series = &m.checklistItems[i]
+		} else {
+			others = append(others, item)
+		}
+	}
+	return series, others
+}
+
+func TestSubmitEditTodoScopes(t *testing.T) {
+	tests := []struct {
+		name   string
+		day    string
+		future bool
+		due    string // typed into the Due field; empty keeps it
+		want   models.ChecklistItem
+		split  []models.ChecklistItem // without IDs
+	}{
+		{
+			name: "only this",
+			day:  "2026-10-12",
+			due:  "2026-10-13",
+			want: models.ChecklistItem{
+				ID: "t1", Text: "Gym", Due: "2026-10-05", Repeat: "FREQ=WEEKLY;COUNT=5",
+				DoneDates: []string{"2026-10-05", "2026-10-19"},
+				Skipped:   []string{"2026-10-12", "2026-10-26"},
+			},
+			split: []models.ChecklistItem{{Text: "Swim", Due: "2026-10-13"}},
+		},
+		{
+			name: "only this, done",
+			day:  "2026-10-19",
+			want: models.ChecklistItem{
+				ID: "t1", Text: "Gym", Due: "2026-10-05", Repeat: "FREQ=WEEKLY;COUNT=5",
+				DoneDates: []string{"2026-10-05"},
+				Skipped:   []string{"2026-10-19", "2026-10-26"},
+			},
+			split: []models.ChecklistItem{{Text: "Swim", Due: "2026-10-19", Done: true}},
+		},
+		{
+			name:   "this and following",
+			day:    "2026-10-19",
+			future: true,
+			want: models.ChecklistItem{
+				ID: "t1", Text: "Gym", Due: "2026-10-05", Repeat: "FREQ=WEEKLY;UNTIL=20261018",
+				DoneDates: []string{"2026-10-05"},
+			},
+			split: []models.ChecklistItem{{
+				Text: "Swim", Due: "2026-10-19", Repeat: "FREQ=WEEKLY;COUNT=3",
+				DoneDates: []string{"2026-10-19"},
+				Skipped:   []string{"2026-10-26"},
+			}},
+		},
+		{
+			name:   "this and following from the first occurrence",
+			day:    "2026-10-05",
+			future: true,
+			want: models.ChecklistItem{
+				ID: "t1", Text: "Swim", Due: "2026-10-05", Repeat: "FREQ=WEEKLY;COUNT=5",
+				DoneDates: []string{"2026-10-05", "2026-10-19"},
+				Skipped:   []string{"2026-10-26"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, seriesTestData())
+			m.editTodoID = "t1"
+			m.editTodoDate = tt.day
+			m.openEditOccurrence(tt.future)
+			if got, want := len(m.formFields) == 4, tt.future; got != want {
+				t.Fatalf("Repeat field shown = %v, want %v", got, want)
+			}
+			m.formFields[0].input.SetValue("Swim")
+			if tt.due != "" {
+				m.formFields[2].input.SetValue(tt.due)
+			}
+			if err := m.submitEditTodo(); err != nil {
+				t.Fatalf("submitEditTodo: %v", err)
+			}
+			series, others := splitTodos(&m)
+			if series == nil {
+				t.Fatal("the series is gone")
+			}
+			if !reflect.DeepEqual(*series, tt.want) {
+				t.Errorf("series =\n%+v\nwant\n%+v", *series, tt.want)
+			}
+			for i := range others {
+				if others[i].ID == "" {
+					t.Errorf("todo %q has no ID", others[i].Text)
+				}
+				others[i].ID = ""
+			}
+			if !reflect.DeepEqual(others, tt.split) {
+				t.Errorf("split off =\n%+v\nwant\n%+v", others, tt.split)
+			}
+		})
+	}
+}
+
+func TestDeleteOccurrences(t *testing.T) {
+	tests := []struct {
+		name   string
+		day    string
+		future bool
+		want   *models.ChecklistItem // nil: the series is deleted
+		days   []string              // occurrences left
+	}{
+		{
+			name: "only this",
+			day:  "2026-10-19",
+			want: &models.ChecklistItem{
+				ID: "t1", Text: "Gym", Due: "2026-10-05", Repeat: "FREQ=WEEKLY;COUNT=5",
+				DoneDates: []string{"2026-10-05"},
+				Skipped:   []string{"2026-10-19", "2026-10-26"},
+			},
+			days: []string{"2026-10-05", "2026-10-12", "2026-11-02"},
+		},
+		{
+			name:   "this and following",
+			day:    "2026-10-19",
+			future: true,
+			want: &models.ChecklistItem{
+				ID: "t1", Text: "Gym", Due: "2026-10-05", Repeat: "FREQ=WEEKLY;UNTIL=20261018",
+				DoneDates: []string{"2026-10-05"},
+			},
+			days: []string{"2026-10-05", "2026-10-12"},
+		},
+		{
+			name:   "this and following from the first occurrence",
+			day:    "2026-10-05",
+			future: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, seriesTestData())
+			m.editTodoID = "t1"
+			m.editTodoDate = tt.day
+			m.deleteOccurrences(tt.future)
+			series, others := splitTodos(&m)
+			if len(others) > 0 {
+				t.Errorf("todos added: %+v", others)
+			}
+			if tt.want == nil {
+				if series != nil {
+					t.Errorf("series = %+v, want it deleted", *series)
+				}
+				return
+			}
+			if series == nil {
+				t.Fatal("the series is gone")
+			}
+			if !reflect.DeepEqual(*series, *tt.want) {
+				t.Errorf("series =\n%+v\nwant\n%+v", *series, *tt.want)
+			}
+			if got := todoOccurrences(*series, mustDay(t, "2027-01-01")); !reflect.DeepEqual(got, tt.days) {
+				t.Errorf("occurrences = %v, want %v", got, tt.days)
+			}
+		})
+	}
+}
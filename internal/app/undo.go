@@ -1,63 +1,158 @@
 package app
 
-import "github.com/romanguyen/seman/internal/models"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
-const maxUndoStack = 50
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/romanguyen/seman/internal/storage"
+)
 
-type undoSnapshot struct {
-	subjects       []models.SubjectItem
-	projects       []models.ProjectItem
-	checklistItems []models.ChecklistItem
-	weeklyExams    []string
+// The undo history is a list of changes, of which the first historyAt are
+// applied. Every save that changes the data adds one, labelled by
+// changeLabel or else after what changed (see describeChanges); undo and
+// redo move historyAt back and forth. Stores that implement
+// storage.Historian keep the history across sessions.
+
+// loadHistory starts the history over from the store's log, for data just
+// loaded.
+func (m *Model) loadHistory() {
+	m.history, m.historyAt = nil, 0
+	m.savedItems = historyItemsOf(m.exportData())
+	h, ok := m.store.(storage.Historian)
+	if !ok {
+		return
+	}
+	entries, at, err := h.History()
+	if err != nil {
+		m.notice = "Cannot read the undo history: " + err.Error()
+		return
+	}
+	m.history, m.historyAt = entries, at
 }
 
-func (m *Model) pushUndo() {
-	snap := undoSnapshot{
-		checklistItems: make([]models.ChecklistItem, len(m.checklistItems)),
-		projects:       make([]models.ProjectItem, len(m.projects)),
-		weeklyExams:    make([]string, len(m.weeklyExams)),
-		subjects:       make([]models.SubjectItem, len(m.subjects)),
-	}
-	copy(snap.checklistItems, m.checklistItems)
-	copy(snap.projects, m.projects)
-	copy(snap.weeklyExams, m.weeklyExams)
-	for i, s := range m.subjects {
-		sc := s
-		sc.Exams = make([]models.ExamItem, len(s.Exams))
-		for j, e := range s.Exams {
-			ec := e
-			if len(e.Retakes) > 0 {
-				ec.Retakes = make([]string, len(e.Retakes))
-				copy(ec.Retakes, e.Retakes)
-			}
-			sc.Exams[j] = ec
-		}
-		snap.subjects[i] = sc
+// recordChange adds what changed since the last save to the history.
+func (m *Model) recordChange() {
+	items := historyItemsOf(m.exportData())
+	changes := diffHistoryItems(m.savedItems, items)
+	m.savedItems = items
+	label := m.changeLabel
+	m.changeLabel = ""
+	if len(changes) == 0 {
+		return
+	}
+	if label == "" {
+		label = describeChanges(changes)
+	}
+	entry := storage.HistoryEntry{Label: label, Time: time.Now(), Changes: changes}
+	m.history = append(m.history[:m.historyAt], entry)
+	m.historyAt = len(m.history)
+	m.logHistory(storage.HistoryRecord{Entry: &entry})
+}
+
+// saveFailed handles a save the store refused. A change to an archived
+// semester is rolled back to the data last saved, wherever it was made;
+// after other errors the change is kept, to be saved with the next one.
+func (m *Model) saveFailed(err error) bool {
+	if !errors.Is(err, storage.ErrArchived) {
+		m.recordChange()
+		m.notice = "Cannot save: " + err.Error()
+		return false
+	}
+	current := m.exportData()
+	m.changeLabel = ""
+	if len(diffHistoryItems(m.savedItems, historyItemsOf(current))) == 0 {
+		// Nothing the history tracks changed, e.g. the weeks shown.
+		return true
 	}
-	if len(m.undoStack) >= maxUndoStack {
-		m.undoStack = m.undoStack[1:]
+	if data, err := m.savedItems.data(current); err == nil {
+		m.restoreData(data)
+	}
+	m.notice = strings.TrimSpace("This semester is archived (read-only); the change was undone  " + m.actionHint("semesters", "Semesters"))
+	return false
+}
+
+func (m *Model) logHistory(r storage.HistoryRecord) {
+	if h, ok := m.store.(storage.Historian); ok {
+		if err := h.AppendHistory(r); err != nil {
+			m.notice = "Cannot save the undo history: " + err.Error()
+		}
 	}
-	m.undoStack = append(m.undoStack, snap)
 }
 
 func (m *Model) undo() {
-	if len(m.undoStack) == 0 {
+	if m.historyAt == 0 {
+		m.notice = "Nothing to undo."
+		return
+	}
+	label := m.history[m.historyAt-1].Label
+	if m.moveHistory(m.historyAt - 1) {
+		m.notice = fmt.Sprintf("Undid: %s  %s", label, m.actionHint("redo", "Redo"))
+	}
+}
+
+func (m *Model) redo() {
+	if m.historyAt == len(m.history) {
+		m.notice = "Nothing to redo."
 		return
 	}
-	snap := m.undoStack[len(m.undoStack)-1]
-	m.undoStack = m.undoStack[:len(m.undoStack)-1]
+	label := m.history[m.historyAt].Label
+	if m.moveHistory(m.historyAt + 1) {
+		m.notice = fmt.Sprintf("Redid: %s  %s", label, m.actionHint("undo", "Undo"))
+	}
+}
+
+// moveHistory undoes or redoes changes until at of them are applied. When
+// the result cannot be saved the data stays as it was.
+func (m *Model) moveHistory(at int) bool {
+	before := m.exportData()
+	items := historyItemsOf(before)
+	for p := m.historyAt; p > at; p-- {
+		items.apply(m.history[p-1].Changes, true)
+	}
+	for p := m.historyAt; p < at; p++ {
+		items.apply(m.history[p].Changes, false)
+	}
+	data, err := items.data(before)
+	if err != nil {
+		m.notice = "Cannot undo: " + err.Error()
+		return false
+	}
+	m.restoreData(data)
+	if m.store != nil {
+		if err := m.store.Save(m.exportData()); err != nil {
+			m.restoreData(before)
+			m.notice = "Cannot save: " + err.Error()
+			return false
+		}
+	}
+	m.historyAt = at
+	m.savedItems = historyItemsOf(m.exportData())
+	m.logHistory(storage.HistoryRecord{At: &at})
+	return true
+}
 
-	m.subjects = snap.subjects
-	m.projects = snap.projects
-	m.checklistItems = snap.checklistItems
-	m.weeklyExams = snap.weeklyExams
+// restoreData swaps in data from the history, keeping the state of the
+// lofi player.
+func (m *Model) restoreData(data storage.SemesterData) {
+	status, lofiErr := m.lofi.status, m.lofi.err
+	m.applyData(data)
+	m.lofi.status, m.lofi.err = status, lofiErr
+	m.clampCursors()
+}
 
+// clampCursors keeps the selection cursors inside the current slices after
+// they were swapped out wholesale (undo, import).
+func (m *Model) clampCursors() {
 	if m.selectedSubj >= len(m.subjects) {
 		m.selectedSubj = len(m.subjects) - 1
 	}
 	if m.selectedSubj < 0 {
 		m.selectedSubj = 0
 	}
+	m.moveResourceCursor(0)
 	if m.projectCursor >= len(m.projects) {
 		m.projectCursor = len(m.projects) - 1
 	}
@@ -70,10 +165,79 @@ func (m *Model) undo() {
 	if m.checklistCursor < 0 {
 		m.checklistCursor = -1
 	}
+}
+
+// openHistory lists the changes, newest first, to go back or forward to.
+func (m *Model) openHistory() {
+	m.closeModal()
+	m.modal = modalHistory
+	m.modalTitle = "History"
+	m.modalHint = "↑↓ select · Enter go back (or forward) to there · Esc close"
+	// The cursor starts on the change the data is at.
+	m.historyCursor = len(m.history) - m.historyAt
+}
+
+func (m Model) updateHistoryModal(key tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key.String() {
+	case "esc", "q":
+		m.closeModal()
+	case "j", "down":
+		if m.historyCursor < len(m.history) {
+			m.historyCursor++
+		}
+	case "k", "up":
+		if m.historyCursor > 0 {
+			m.historyCursor--
+		}
+	case "enter":
+		at := len(m.history) - m.historyCursor
+		m.closeModal()
+		if at == m.historyAt {
+			return m, nil
+		}
+		from := m.historyAt
+		if !m.moveHistory(at) {
+			return m, nil
+		}
+		switch {
+		case at == 0:
+			m.notice = fmt.Sprintf("Undid %s: back to the start of the history", changesCount(from))
+		case at < from:
+			m.notice = fmt.Sprintf("Undid %s: back to %s", changesCount(from-at), m.history[at-1].Label)
+		default:
+			m.notice = fmt.Sprintf("Redid %s: up to %s", changesCount(at-from), m.history[at-1].Label)
+		}
+	}
+	return m, nil
+}
+
+func changesCount(n int) string {
+	if n == 1 {
+		return "1 change"
+	}
+	return fmt.Sprintf("%d changes", n)
+}
 
-	m.sortChecklistByDone()
-	m.sortProjectsByStatus()
-	m.refreshFlatExams()
-	m.refreshAllFilters()
-	m.persist()
+// historyLabels lists the changes newest first, then the start of the
+// history; the changes undone are reported as dimmed.
+func (m Model) historyLabels() ([]string, []bool) {
+	labels := make([]string, 0, len(m.history)+1)
+	dimmed := make([]bool, 0, len(m.history)+1)
+	for i := len(m.history) - 1; i >= 0; i-- {
+		entry := m.history[i]
+		mark := " "
+		if i == m.historyAt-1 {
+			mark = "•"
+		}
+		when := m.dateFormat.Show(entry.Time.Local().Truncate(time.Minute))
+		labels = append(labels, fmt.Sprintf("%s %-20s %s", mark, when, entry.Label))
+		dimmed = append(dimmed, i >= m.historyAt)
+	}
+	mark := " "
+	if m.historyAt == 0 {
+		mark = "•"
+	}
+	labels = append(labels, mark+" Start of history")
+	dimmed = append(dimmed, false)
+	return labels, dimmed
 }
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// historyStore keeps the history log in memory and fails saves while err is
+// set.
+type historyStore struct {
+	failingStore
+	records []storage.HistoryRecord
+}
+
+func (s *historyStore) History() ([]storage.HistoryEntry, int, error) {
+	return nil, 0, nil
+}
+
+func (s *historyStore) AppendHistory(r storage.HistoryRecord) error {
+	s.records = append(s.records, r)
+	return nil
+}
+
+func TestUndoKeepsStateWhenSaveFails(t *testing.T) {
+	store := &historyStore{}
+	m := NewModel(store, persistTestData(), true)
+	m.checklistItems[0].Done = true
+	if !m.persist() {
+		t.Fatal("persist failed")
+	}
+	if m.historyAt != 1 || len(store.records) != 1 {
+		t.Fatalf("historyAt = %d, records = %d; want 1, 1", m.historyAt, len(store.records))
+	}
+
+	store.err = errors.New("disk full")
+	m.undo()
+	if !m.checklistItems[0].Done {
+		t.Error("the todo was undone though the save failed")
+	}
+	if m.historyAt != 1 {
+		t.Errorf("historyAt = %d, want 1", m.historyAt)
+	}
+	if len(store.records) != 1 {
+		t.Errorf("logged %d records, want no move logged", len(store.records))
+	}
+	if m.notice != "Cannot save: disk full" {
+		t.Errorf("notice = %q", m.notice)
+	}
+
+	store.err = nil
+	m.undo()
+	if m.checklistItems[0].Done || m.historyAt != 0 {
+		t.Errorf("done = %v, historyAt = %d; want the change undone", m.checklistItems[0].Done, m.historyAt)
+	}
+	if n := len(store.records); n != 2 || store.records[1].At == nil || *store.records[1].At != 0 {
+		t.Errorf("records = %+v, want a move to 0 logged", store.records)
+	}
+}
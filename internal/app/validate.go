@@ -0,0 +1,309 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// The Build* helpers hold the validation shared by the TUI forms and the
+// command line, so both reject the same input with the same messages. The
+// items they return carry a fresh ID; callers editing an existing item copy
+// over only the fields they change.
+
+// BuildSubject validates a subject's code, name and optional credits.
+func BuildSubject(code, name, credits string) (models.SubjectItem, error) {
+	code = strings.TrimSpace(code)
+	name = strings.TrimSpace(name)
+	if code == "" || name == "" {
+		return models.SubjectItem{}, fmt.Errorf("Code and Name are required.")
+	}
+	value, err := parseCredits(credits)
+	if err != nil {
+		return models.SubjectItem{}, err
+	}
+	return models.SubjectItem{ID: models.NewID(), Code: code, Name: name, Credits: value}, nil
+}
+
+// BuildExam validates a new exam for the subject with the given code and
+// returns the index of that subject alongside the exam. Here and in the other
+// Build helpers, dates may be relative ("fri 10:00") and are resolved
+// against dc before they are stored.
+func BuildExam(subjects []models.SubjectItem, rules models.PriorityRules, dc DateContext, subjectCode, name, date, retakes, priority, weight string) (int, models.ExamItem, error) {
+	subjectCode = strings.TrimSpace(subjectCode)
+	name = strings.TrimSpace(name)
+	date = strings.TrimSpace(date)
+	if subjectCode == "" || name == "" || date == "" {
+		return -1, models.ExamItem{}, fmt.Errorf("Subject, Exam Name, and Date are required.")
+	}
+	idx := findSubjectIndex(subjects, subjectCode)
+	if idx < 0 {
+		return -1, models.ExamItem{}, fmt.Errorf("Subject code not found.")
+	}
+	exam, err := buildExamFields(rules, dc, name, date, retakes, priority, weight, true)
+	if err != nil {
+		return -1, models.ExamItem{}, err
+	}
+	exam.ID = models.NewID()
+	return idx, exam, nil
+}
+
+// UpdateExam validates edited exam fields and applies them to exam.
+func UpdateExam(exam models.ExamItem, rules models.PriorityRules, dc DateContext, name, date, retakes, priority, weight string) (models.ExamItem, error) {
+	if strings.TrimSpace(name) == "" || strings.TrimSpace(date) == "" {
+		return exam, fmt.Errorf("Exam Name and Date are required.")
+	}
+	fields, err := buildExamFields(rules, dc, name, date, retakes, priority, weight, false)
+	if err != nil {
+		return exam, err
+	}
+	exam.Name = fields.Name
+	exam.Date = keepStored(exam.Date, fields.Date)
+	for i := range fields.Retakes {
+		if i < len(exam.Retakes) {
+			fields.Retakes[i] = keepStored(exam.Retakes[i], fields.Retakes[i])
+		}
+	}
+	exam.Retakes = fields.Retakes
+	exam.Priority = fields.Priority
+	exam.Weight = fields.Weight
+	return exam, nil
+}
+
+func buildExamFields(rules models.PriorityRules, dc DateContext, name, date, retakes, priority, weight string, isNew bool) (models.ExamItem, error) {
+	when, ok := parseDateInput(date, dc)
+	if !ok {
+		return models.ExamItem{}, fmt.Errorf("Date must be a date like Jan 13, 2026 @ 13:00 or fri 10:00.")
+	}
+	var retakeDates []string
+	for _, raw := range splitDateList(retakes) {
+		retake, ok := parseDateInput(raw, dc)
+		if !ok {
+			return models.ExamItem{}, fmt.Errorf("Retake %q is not a date.", raw)
+		}
+		retakeDates = append(retakeDates, storeInput(raw, retake))
+	}
+	level, err := parsePriorityInput(priority, rules, isNew)
+	if err != nil {
+		return models.ExamItem{}, err
+	}
+	share, err := parseWeight(weight)
+	if err != nil {
+		return models.ExamItem{}, err
+	}
+	return models.ExamItem{
+		Name:     strings.TrimSpace(name),
+		Date:     storeInput(date, when),
+		Retakes:  retakeDates,
+		Priority: level,
+		Weight:   share,
+	}, nil
+}
+
+// BuildSession validates a weekly class of the subject with the given code
+// and returns the index of that subject alongside the class. Type, room,
+// weeks and the From/Until dates are optional.
+func BuildSession(subjects []models.SubjectItem, dc DateContext, subjectCode, kind, day, times, room, weeks, from, until string) (int, models.ClassSession, error) {
+	subjectCode = strings.TrimSpace(subjectCode)
+	if subjectCode == "" || strings.TrimSpace(day) == "" || strings.TrimSpace(times) == "" {
+		return -1, models.ClassSession{}, fmt.Errorf("Subject, Day, and Time are required.")
+	}
+	idx := findSubjectIndex(subjects, subjectCode)
+	if idx < 0 {
+		return -1, models.ClassSession{}, fmt.Errorf("Subject code not found.")
+	}
+	session := models.ClassSession{ID: models.NewID(), Type: parseSessionType(kind), Room: strings.TrimSpace(room)}
+	var err error
+	if session.Day, err = parseWeekday(day); err != nil {
+		return -1, models.ClassSession{}, err
+	}
+	if session.Start, session.End, err = parseTimeRange(times); err != nil {
+		return -1, models.ClassSession{}, err
+	}
+	if session.Weeks, err = parseWeeks(weeks); err != nil {
+		return -1, models.ClassSession{}, err
+	}
+	if session.From, err = parseSessionBound("From", from, dc); err != nil {
+		return -1, models.ClassSession{}, err
+	}
+	if session.Until, err = parseSessionBound("Until", until, dc); err != nil {
+		return -1, models.ClassSession{}, err
+	}
+	if session.From != "" && session.Until != "" && session.Until < session.From {
+		return -1, models.ClassSession{}, fmt.Errorf("Until must not be before From.")
+	}
+	return idx, session, nil
+}
+
+// BuildProject validates a project. The start date is optional and must not
+// fall after the deadline. A blank status means NOT STARTED; AUTO makes the
+// status follow the subtasks; any other status is refused.
+func BuildProject(dc DateContext, name, subject, start, deadline, status string) (models.ProjectItem, error) {
+	name = strings.TrimSpace(name)
+	subject = strings.TrimSpace(subject)
+	start = strings.TrimSpace(start)
+	deadline = strings.TrimSpace(deadline)
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if name == "" || subject == "" || deadline == "" {
+		return models.ProjectItem{}, fmt.Errorf("Name, Subject, and Deadline are required.")
+	}
+	due, ok := parseDateInput(deadline, dc)
+	if !ok {
+		return models.ProjectItem{}, fmt.Errorf("Deadline must be a date like May 20, 2026 or next fri.")
+	}
+	var first string
+	if start != "" {
+		day, ok := parseDateInput(start, dc)
+		if !ok {
+			return models.ProjectItem{}, fmt.Errorf("Start must be a date like May 4, 2026 or mon.")
+		}
+		if day = dayOf(day); day.After(due) {
+			return models.ProjectItem{}, fmt.Errorf("Start must not be after the Deadline.")
+		}
+		first = dates.StoreDay(day)
+	}
+	project := models.ProjectItem{
+		ID:      models.NewID(),
+		Name:    name,
+		Subject: subject,
+		Due:     storeInput(deadline, due),
+		Start:   first,
+		Status:  status,
+	}
+	switch status {
+	case "":
+		project.Status = models.StatusNotStarted
+	case models.StatusNotStarted, models.StatusInProgress, models.StatusDone:
+	case models.StatusAuto:
+		project.AutoStatus = true
+		project.Status = project.SubtaskStatus()
+	default:
+		return models.ProjectItem{}, fmt.Errorf("Status must be NOT STARTED, IN PROGRESS, DONE or AUTO.")
+	}
+	return project, nil
+}
+
+// UpdateProject applies the project form to an existing project, keeping its
+// ID, subtasks and milestones. A status the project already has is kept even
+// if BuildProject would refuse it, so files from older versions stay editable.
+func UpdateProject(project models.ProjectItem, dc DateContext, name, subject, start, deadline, status string) (models.ProjectItem, error) {
+	kept := !project.AutoStatus && project.Status != "" && strings.EqualFold(strings.TrimSpace(status), project.Status)
+	if kept {
+		status = ""
+	}
+	updated, err := BuildProject(dc, name, subject, start, deadline, status)
+	if err != nil {
+		return models.ProjectItem{}, err
+	}
+	if kept {
+		updated.Status = project.Status
+	}
+	project.Name = updated.Name
+	project.Subject = updated.Subject
+	project.Due = keepStored(project.Due, updated.Due)
+	project.Start = keepStored(project.Start, updated.Start)
+	project.Status = updated.Status
+	project.AutoStatus = updated.AutoStatus
+	project.SyncStatus()
+	return project, nil
+}
+
+// BuildSubtask validates a project subtask.
+func BuildSubtask(text string) (models.Subtask, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return models.Subtask{}, fmt.Errorf("Task is required.")
+	}
+	return models.Subtask{ID: models.NewID(), Text: text}, nil
+}
+
+// BuildResource validates a subject resource: a URL or file path, titled
+// after itself unless a title is given.
+func BuildResource(title, target string) (models.Resource, error) {
+	title = strings.TrimSpace(title)
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return models.Resource{}, fmt.Errorf("Link is required.")
+	}
+	if title == "" {
+		title = target
+	}
+	return models.Resource{ID: models.NewID(), Title: title, Target: target}, nil
+}
+
+// BuildMilestone validates a project milestone; its date may carry a time.
+func BuildMilestone(dc DateContext, name, date string) (models.Milestone, error) {
+	name = strings.TrimSpace(name)
+	date = strings.TrimSpace(date)
+	if name == "" || date == "" {
+		return models.Milestone{}, fmt.Errorf("Name and Date are required.")
+	}
+	when, ok := parseDateInput(date, dc)
+	if !ok {
+		return models.Milestone{}, fmt.Errorf("Date must be a date like May 6, 2026 or next fri.")
+	}
+	return models.Milestone{ID: models.NewID(), Name: name, Date: storeInput(date, when)}, nil
+}
+
+// BuildTodo validates a todo. The subject is optional but must exist when
+// given; it is stored upper-cased. The due date is optional too and may
+// carry a time. A repeat rule (see parseRepeat) makes the todo recurring
+// from its due date and is stored as an RRULE.
+func BuildTodo(subjects []models.SubjectItem, dc DateContext, task, subject, due, repeat string) (models.ChecklistItem, error) {
+	task = strings.TrimSpace(task)
+	subject = strings.TrimSpace(subject)
+	if task == "" {
+		return models.ChecklistItem{}, fmt.Errorf("Task is required.")
+	}
+	if subject != "" && findSubjectIndex(subjects, subject) < 0 {
+		return models.ChecklistItem{}, fmt.Errorf("Subject not found.")
+	}
+	due = strings.TrimSpace(due)
+	if due != "" {
+		date, ok := parseDateInput(due, dc)
+		if !ok {
+			return models.ChecklistItem{}, fmt.Errorf("Due must be a date like Jan 2, 2006, 2006-01-02 15:04 or tomorrow 10:00.")
+		}
+		due = storeInput(due, date)
+	}
+	rule, recurring, err := parseRepeat(repeat, dc)
+	if err != nil {
+		return models.ChecklistItem{}, err
+	}
+	if recurring && due == "" {
+		return models.ChecklistItem{}, fmt.Errorf("A repeating todo needs a due date for its first occurrence.")
+	}
+	item := models.ChecklistItem{
+		ID:      models.NewID(),
+		Text:    task,
+		Due:     due,
+		Subject: strings.ToUpper(subject),
+	}
+	if recurring {
+		item.Repeat = rule.String()
+	}
+	return item, nil
+}
+
+// FindSubject returns the index of the subject with the given code
+// (case-insensitive), or -1.
+func FindSubject(subjects []models.SubjectItem, code string) int {
+	return findSubjectIndex(subjects, code)
+}
+
+// NewData returns an empty semester with the default settings, for callers
+// that create data without going through the TUI's sample data.
+func NewData() storage.SemesterData {
+	return storage.SemesterData{
+		WeeklyExams:   []string{},
+		ConfirmOn:     true,
+		WeekStart:     weekStartOf(time.Now()).Format("2006-01-02"),
+		WeekSpan:      1,
+		PriorityRules: models.DefaultPriorityRules(),
+		GradingScale:  models.DefaultGradingScale,
+	}
+}
@@ -0,0 +1,204 @@
+// Package cli implements seman's headless subcommands, which read and write
+// the same store as the TUI so scripts, editor integrations and cron jobs can
+// manage data without opening the interface.
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/app"
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+const usage = `Usage:
+  seman                                   open the interactive planner
+  seman list <subjects|exams|projects|todos> [--subject CODE] [--json]
+  seman add subject --code CODE --name NAME [--credits N]
+  seman add exam --subject CODE --name NAME --date DATE [--retakes LIST] [--priority P]
+                 [--weight PERCENT]
+  seman add project --name NAME --subject CODE --due DATE [--start DATE] [--status STATUS]
+  seman add todo TEXT [--subject CODE] [--due DATE] [--repeat RULE]
+  seman edit <kind> <ref> [flags of the matching add command]
+  seman delete <kind> <ref>
+  seman done <ref> [--on DATE]            toggle a todo between done and open; for
+                                          a recurring one the occurrence on DATE
+                                          (default: the earliest open one)
+  seman export <json|ics> [--output FILE] write everything as JSON or an iCalendar file
+  seman import ics FILE [--pattern REGEX] [--dry-run]
+                                          add or update exams from a calendar file
+  seman result <exam-ref> [--attempt N] [--grade G] [--points GOT/MAX] [--passed yes|no]
+                                          record an exam result (attempt 1 is the
+                                          original date, 2 the first retake)
+  seman grades [--scale A-F|1-5|percent]  subject grades and the semester GPA
+  seman keys                              the action IDs and their keys, as
+                                          keys.toml sets them
+
+<ref> is the number shown by "seman list" for that kind, or the item's ID
+(shown with --json).
+Every command accepts --json for machine-readable output.
+
+Global options (before the command):
+  --store json|sqlite                     storage backend (default: $SEMAN_STORE,
+                                          or sqlite once semester.db exists)
+  --semester NAME|ID                      semester to use (default: the one
+                                          opened last)
+`
+
+// errUsage marks errors caused by malformed command lines.
+var errUsage = errors.New("usage")
+
+// Exit codes returned by Run.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+type env struct {
+	store  storage.Store
+	stdout io.Writer
+	json   bool
+	// dateFormat is how text output shows dates; set by load. JSON output
+	// keeps them as stored.
+	dateFormat dates.Format
+}
+
+// IsCommand reports whether args (without the program name) ask for a
+// subcommand rather than the interactive UI.
+func IsCommand(args []string) bool {
+	return len(args) > 0
+}
+
+// Run executes the subcommand in args against store and returns the process
+// exit code. Errors are written to stderr.
+func Run(args []string, store storage.Store, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	e := &env{store: store, stdout: stdout}
+
+	var err error
+	switch args[0] {
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	case "list", "ls":
+		err = e.list(args[1:])
+	case "add":
+		err = e.add(args[1:])
+	case "edit":
+		err = e.edit(args[1:])
+	case "delete", "rm":
+		err = e.delete(args[1:])
+	case "done":
+		err = e.done(args[1:])
+	case "export":
+		err = e.export(args[1:])
+	case "import":
+		err = e.importCalendar(args[1:])
+	case "result":
+		err = e.result(args[1:])
+	case "grades":
+		err = e.grades(args[1:])
+	case "keys":
+		err = e.keys(args[1:])
+	default:
+		err = fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(stderr, "seman: %s\n\n%s", strings.TrimPrefix(err.Error(), "usage: "), usage)
+		return exitUsage
+	}
+	fmt.Fprintf(stderr, "seman: %v\n", err)
+	return exitError
+}
+
+func (e *env) load() (storage.SemesterData, error) {
+	data, found, err := e.store.Load()
+	if err != nil {
+		return storage.SemesterData{}, fmt.Errorf("loading data: %w", err)
+	}
+	if !found {
+		data = app.NewData()
+	}
+	e.dateFormat = dates.FormatOf(data.DateFormat)
+	return data, nil
+}
+
+func (e *env) save(data storage.SemesterData) error {
+	if err := e.store.Save(data); err != nil {
+		return fmt.Errorf("saving data: %w", err)
+	}
+	return nil
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting and
+// registers the shared --json flag.
+func (e *env) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&e.json, "json", false, "print machine-readable JSON")
+	return fs
+}
+
+// parseArgs parses flags that may appear before, between or after
+// positional arguments and returns the positional ones.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// setFlags returns the names of the flags given explicitly on the command line.
+func setFlags(fs *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	return set
+}
+
+// parseRef converts a reference into an index into the items with the given
+// IDs. A reference is either the 1-based number from "seman list" or an
+// item's ID; IDs stay valid as items are added, removed and re-sorted.
+func parseRef(ref string, ids []string, kind string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	for i, id := range ids {
+		if id != "" && id == ref {
+			return i, nil
+		}
+	}
+	n, err := strconv.Atoi(ref)
+	if err != nil {
+		return -1, fmt.Errorf("%w: %q is neither a %s number nor an ID", errUsage, ref, kind)
+	}
+	if n < 1 || n > len(ids) {
+		return -1, fmt.Errorf("no %s #%d (there are %d)", kind, n, len(ids))
+	}
+	return n - 1, nil
+}
+
+func (e *env) printJSON(v any) error {
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// testData has one of every kind of item, each with a known ID.
+func testData() storage.SemesterData {
+	return storage.SemesterData{
+		SchemaVersion: storage.SchemaVersion,
+		WeekSpan:      1,
+		PriorityRules: models.DefaultPriorityRules(),
+		GradingScale:  models.DefaultGradingScale,
+		Subjects: []models.SubjectItem{{
+			ID: "s1", Code: "DB", Name: "Databases", Credits: 6,
+			Exams: []models.ExamItem{{
+				ID: "e1", Name: "Final", Date: "2026-12-10", Retakes: []string{"2027-01-14"}, Priority: "HIGH",
+			}},
+		}},
+		Projects: []models.ProjectItem{{
+			ID: "p1", Name: "Schema design", Subject: "DB", Due: "2026-11-20", Status: models.StatusInProgress,
+		}},
+		Checklist: []models.ChecklistItem{{ID: "t1", Text: "Read chapter 3", Subject: "DB", Due: "2026-10-20"}},
+	}
+}
+
+// newTestStore returns a JSON store in a temporary directory holding data.
+// The configuration directory points there too, so keys.toml is not read
+// from the home directory.
+func newTestStore(t *testing.T, data storage.SemesterData) *storage.JSONStore {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	store := storage.NewJSONStore(filepath.Join(dir, "semester.json"))
+	if err := store.Save(data); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func run(store storage.Store, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(args, store, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func loadData(t *testing.T, store storage.Store) storage.SemesterData {
+	t.Helper()
+	data, _, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseRef(t *testing.T) {
+	ids := []string{"a1", "", "7"}
+	tests := []struct {
+		ref   string
+		want  int
+		usage bool   // the error is a usage error
+		err   string // part of the error; empty: no error
+	}{
+		{ref: "1", want: 0},
+		{ref: " 2 ", want: 1},
+		{ref: "a1", want: 0},
+		{ref: "7", want: 2}, // an ID wins over a number
+		{ref: "3", want: 2},
+		{ref: "0", err: "no item #0 (there are 3)"},
+		{ref: "4", err: "no item #4 (there are 3)"},
+		{ref: "b2", usage: true, err: `"b2" is neither a item number nor an ID`},
+		{ref: "", usage: true, err: "neither"},
+	}
+	for _, tt := range tests {
+		got, err := parseRef(tt.ref, ids, "item")
+		if tt.err == "" {
+			if err != nil || got != tt.want {
+				t.Errorf("parseRef(%q) = %d, %v; want %d", tt.ref, got, err, tt.want)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("parseRef(%q) = %d, %v; want an error with %q", tt.ref, got, err, tt.err)
+		} else if errors.Is(err, errUsage) != tt.usage {
+			t.Errorf("parseRef(%q): usage error = %v, want %v", tt.ref, !tt.usage, tt.usage)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string // part of the output
+		stderr string // part of the error output
+	}{
+		{name: "no command", args: []string{}, code: exitUsage, stderr: "Usage:"},
+		{name: "help", args: []string{"help"}, code: exitOK, stdout: "Usage:"},
+		{name: "unknown command", args: []string{"frobnicate"}, code: exitUsage, stderr: `unknown command "frobnicate"`},
+		{name: "unknown flag", args: []string{"list", "exams", "--colour"}, code: exitUsage, stderr: "flag provided but not defined"},
+
+		{name: "list subjects", args: []string{"list", "subjects"}, code: exitOK, stdout: "DB         Databases (1 exams, 6 credits)"},
+		{name: "list exams", args: []string{"ls", "exams"}, code: exitOK, stdout: "Final"},
+		{name: "list by subject", args: []string{"list", "todos", "--subject", "db"}, code: exitOK, stdout: "Read chapter 3"},
+		{name: "list without kind", args: []string{"list"}, code: exitUsage, stderr: "list needs exactly one kind"},
+		{name: "list unknown kind", args: []string{"list", "grades"}, code: exitUsage, stderr: `unknown kind "grades"`},
+
+		{name: "add subject", args: []string{"add", "subject", "--code", "OS", "--name", "Operating systems"}, code: exitOK, stdout: "Added subject #2: OS (Operating systems)"},
+		{name: "add subject without name", args: []string{"add", "subject", "--code", "OS"}, code: exitError, stderr: "Code and Name are required."},
+		{name: "add exam", args: []string{"add", "exam", "--subject", "DB", "--name", "Midterm", "--date", "2026-11-05"}, code: exitOK, stdout: "Added exam #2: Midterm (DB)"},
+		{name: "add exam to unknown subject", args: []string{"add", "exam", "--subject", "XX", "--name", "Midterm", "--date", "2026-11-05"}, code: exitError, stderr: "Subject code not found."},
+		{name: "add project", args: []string{"add", "project", "--name", "Report", "--subject", "DB", "--due", "2026-12-01", "--status", "done"}, code: exitOK, stdout: "Added project #2: Report (DB)"},
+		{name: "add project with bad status", args: []string{"add", "project", "--name", "Report", "--subject", "DB", "--due", "2026-12-01", "--status", "started"}, code: exitError, stderr: "Status must be NOT STARTED, IN PROGRESS, DONE or AUTO."},
+		{name: "add todo", args: []string{"add", "todo", "Buy", "pens"}, code: exitOK, stdout: "Added todo #2: Buy pens"},
+		{name: "add todo with bad date", args: []string{"add", "todo", "Buy pens", "--due", "someday"}, code: exitError, stderr: "Due"},
+		{name: "add without kind", args: []string{"add"}, code: exitUsage, stderr: "add needs a kind"},
+		{name: "add with stray argument", args: []string{"add", "subject", "OS"}, code: exitUsage, stderr: `unexpected argument "OS"`},
+
+		{name: "edit subject", args: []string{"edit", "subject", "1", "--name", "Database systems"}, code: exitOK, stdout: "Updated subject #1: DB (Database systems)"},
+		{name: "edit exam by ID", args: []string{"edit", "exam", "e1", "--name", "Final exam"}, code: exitOK, stdout: "Updated exam #1: Final exam (DB)"},
+		{name: "edit project status", args: []string{"edit", "project", "1", "--status", "AUTO"}, code: exitOK, stdout: "Updated project #1: Schema design"},
+		{name: "edit project with bad status", args: []string{"edit", "project", "1", "--status", "half done"}, code: exitError, stderr: "Status must be"},
+		{name: "edit todo", args: []string{"edit", "todo", "t1", "--text", "Read chapter 4"}, code: exitOK, stdout: "Updated todo #1: Read chapter 4"},
+		{name: "edit missing item", args: []string{"edit", "todo", "9", "--text", "x"}, code: exitError, stderr: "no todo #9 (there are 1)"},
+		{name: "edit without ref", args: []string{"edit", "todo", "--text", "x"}, code: exitUsage, stderr: "edit needs exactly one <ref>"},
+
+		{name: "delete", args: []string{"rm", "project", "p1"}, code: exitOK, stdout: "Deleted project #1: Schema design"},
+		{name: "delete bad ref", args: []string{"delete", "exam", "first"}, code: exitUsage, stderr: `"first" is neither a exam number nor an ID`},
+		{name: "delete without ref", args: []string{"delete", "exam"}, code: exitUsage, stderr: "delete needs a kind and a <ref>"},
+
+		{name: "done", args: []string{"done", "1"}, code: exitOK, stdout: "Completed todo #1: Read chapter 3"},
+		{name: "done without ref", args: []string{"done"}, code: exitUsage, stderr: "done needs exactly one todo <ref>"},
+
+		{name: "export json", args: []string{"export", "json"}, code: exitOK, stdout: `"code": "DB"`},
+		{name: "export ics", args: []string{"export", "ics"}, code: exitOK, stdout: "BEGIN:VCALENDAR"},
+		{name: "export unknown format", args: []string{"export", "csv"}, code: exitUsage, stderr: `unknown export format "csv"`},
+		{name: "import missing file", args: []string{"import", "ics", "missing.ics"}, code: exitError, stderr: "reading missing.ics"},
+		{name: "import without file", args: []string{"import", "ics"}, code: exitUsage, stderr: "import needs a format and a file"},
+
+		{name: "result", args: []string{"result", "1", "--grade", "B"}, code: exitOK, stdout: "Recorded result for exam #1: Final (DB)"},
+		{name: "result with bad attempt", args: []string{"result", "1", "--attempt", "5", "--grade", "B"}, code: exitError, stderr: "Attempt must be"},
+		{name: "result with bad grade", args: []string{"result", "1", "--grade", "Q"}, code: exitError, stderr: "Grade must be one of"},
+
+		{name: "grades", args: []string{"grades"}, code: exitOK, stdout: "GPA - (A-F), 0 of 6 credits earned"},
+		{name: "grades on a scale", args: []string{"grades", "--scale", "percent"}, code: exitOK, stdout: "(percent)"},
+		{name: "grades on an unknown scale", args: []string{"grades", "--scale", "1-10"}, code: exitUsage, stderr: `unknown scale "1-10"`},
+
+		{name: "keys", args: []string{"keys"}, code: exitOK, stdout: "quit"},
+		{name: "keys with argument", args: []string{"keys", "all"}, code: exitUsage, stderr: "keys takes no arguments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestStore(t, testData())
+			code, stdout, stderr := run(store, tt.args...)
+			if code != tt.code {
+				t.Errorf("exit code = %d, want %d (stderr: %q)", code, tt.code, stderr)
+			}
+			if !strings.Contains(stdout, tt.stdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout, tt.stdout)
+			}
+			if !strings.Contains(stderr, tt.stderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr, tt.stderr)
+			}
+			if code == exitOK && stderr != "" {
+				t.Errorf("stderr = %q on success", stderr)
+			}
+		})
+	}
+}
+
+// TestRunSaves checks that commands change the stored data, and that failed
+// ones leave it alone.
+func TestRunSaves(t *testing.T) {
+	store := newTestStore(t, testData())
+	before := loadData(t, store)
+	if code, _, _ := run(store, "add", "project", "--name", "Report", "--subject", "DB", "--due", "2026-12-01", "--status", "started"); code != exitError {
+		t.Fatalf("exit code = %d, want %d", code, exitError)
+	}
+	if after := loadData(t, store); !reflect.DeepEqual(before, after) {
+		t.Error("a failed add changed the data")
+	}
+
+	if code, _, stderr := run(store, "edit", "project", "p1", "--status", "auto"); code != exitOK {
+		t.Fatalf("edit: %s", stderr)
+	}
+	if code, _, stderr := run(store, "done", "t1"); code != exitOK {
+		t.Fatalf("done: %s", stderr)
+	}
+	data := loadData(t, store)
+	if p := data.Projects[0]; !p.AutoStatus || p.Status != models.StatusNotStarted {
+		t.Errorf("project = %+v, want AUTO and NOT STARTED", p)
+	}
+	if !data.Checklist[0].Done {
+		t.Error("the todo is not done")
+	}
+
+	// A status older versions accepted survives edits of other fields.
+	data.Projects[0].Status, data.Projects[0].AutoStatus = "BLOCKED", false
+	if err := store.Save(data); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := run(store, "edit", "project", "p1", "--name", "Schema"); code != exitOK {
+		t.Fatalf("edit: %s", stderr)
+	}
+	if p := loadData(t, store).Projects[0]; p.Name != "Schema" || p.Status != "BLOCKED" {
+		t.Errorf("project = %+v, want the BLOCKED status kept", p)
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	store := newTestStore(t, testData())
+
+	code, stdout, _ := run(store, "list", "exams", "--json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d", code)
+	}
+	var exams []examView
+	if err := json.Unmarshal([]byte(stdout), &exams); err != nil {
+		t.Fatalf("list exams --json: %v\n%s", err, stdout)
+	}
+	if len(exams) != 1 || exams[0].ID != "e1" || exams[0].Ref != 1 || exams[0].Subject != "DB" || exams[0].Date != "2026-12-10" {
+		t.Errorf("exams = %+v", exams)
+	}
+
+	// An empty list is [], not null.
+	code, stdout, _ = run(store, "list", "projects", "--subject", "OS", "--json")
+	if code != exitOK || strings.TrimSpace(stdout) != "[]" {
+		t.Errorf("empty list = %d, %q; want []", code, stdout)
+	}
+
+	code, stdout, _ = run(store, "add", "todo", "--json", "--due", "2026-10-25", "Buy pens")
+	if code != exitOK {
+		t.Fatalf("exit code = %d", code)
+	}
+	var todo todoView
+	if err := json.Unmarshal([]byte(stdout), &todo); err != nil {
+		t.Fatalf("add todo --json: %v\n%s", err, stdout)
+	}
+	if todo.Ref != 2 || todo.ID == "" || todo.Text != "Buy pens" || todo.Due != "2026-10-25" {
+		t.Errorf("todo = %+v", todo)
+	}
+
+	code, stdout, _ = run(store, "grades", "--json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d", code)
+	}
+	var grades gradesView
+	if err := json.Unmarshal([]byte(stdout), &grades); err != nil {
+		t.Fatalf("grades --json: %v\n%s", err, stdout)
+	}
+	if grades.Scale != models.DefaultGradingScale || grades.GPA != nil || grades.CreditsTotal != 6 || len(grades.Subjects) != 1 {
+		t.Errorf("grades = %+v", grades)
+	}
+
+	// Errors are not JSON: they go to stderr and stdout stays empty.
+	code, stdout, stderr := run(store, "delete", "todo", "9", "--json")
+	if code != exitError || stdout != "" || !strings.Contains(stderr, "no todo #9") {
+		t.Errorf("delete --json = %d, %q, %q", code, stdout, stderr)
+	}
+}
+
+// TestResultAttempts checks that --attempt, the text output and the JSON
+// output all number attempts from 1.
+func TestResultAttempts(t *testing.T) {
+	store := newTestStore(t, testData())
+	if code, _, stderr := run(store, "result", "e1", "--attempt", "1", "--grade", "F"); code != exitOK {
+		t.Fatalf("result: %s", stderr)
+	}
+	// Without --attempt the result goes to the next attempt, the retake.
+	if code, _, stderr := run(store, "result", "e1", "--grade", "C"); code != exitOK {
+		t.Fatalf("result: %s", stderr)
+	}
+	results := loadData(t, store).Subjects[0].Exams[0].Results
+	if len(results) != 2 || results[0].Attempt != 0 || results[1].Attempt != 1 {
+		t.Fatalf("stored results = %+v, want attempts 0 and 1", results)
+	}
+
+	_, stdout, _ := run(store, "list", "exams")
+	if !strings.Contains(stdout, "results: 1: F failed, 2: C passed") {
+		t.Errorf("list exams = %q", stdout)
+	}
+	_, stdout, _ = run(store, "list", "exams", "--json")
+	var exams []examView
+	if err := json.Unmarshal([]byte(stdout), &exams); err != nil {
+		t.Fatal(err)
+	}
+	want := []resultView{{Attempt: 1, Grade: "F", Scale: "A-F"}, {Attempt: 2, Grade: "C", Scale: "A-F", Passed: true}}
+	if len(exams) != 1 || !reflect.DeepEqual(exams[0].Results, want) {
+		t.Errorf("results = %+v, want %+v", exams, want)
+	}
+}
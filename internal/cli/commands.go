@@ -0,0 +1,516 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/app"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+const (
+	kindSubject = "subject"
+	kindExam    = "exam"
+	kindProject = "project"
+	kindTodo    = "todo"
+)
+
+// parseKind accepts singular and plural item kinds.
+func parseKind(arg string) (string, error) {
+	switch strings.ToLower(arg) {
+	case "subject", "subjects":
+		return kindSubject, nil
+	case "exam", "exams":
+		return kindExam, nil
+	case "project", "projects":
+		return kindProject, nil
+	case "todo", "todos", "task", "tasks":
+		return kindTodo, nil
+	}
+	return "", fmt.Errorf("%w: unknown kind %q (want subject, exam, project or todo)", errUsage, arg)
+}
+
+// itemFlags holds every field flag; each command registers the ones that
+// apply to its kind.
+type itemFlags struct {
+	code     string
+	name     string
+	subject  string
+	date     string
+	retakes  string
+	priority string
+	due      string
+	start    string
+	status   string
+	text     string
+	credits  string
+	weight   string
+	repeat   string
+}
+
+func (f *itemFlags) register(fs *flag.FlagSet, kind string) {
+	switch kind {
+	case kindSubject:
+		fs.StringVar(&f.code, "code", "", "subject code")
+		fs.StringVar(&f.name, "name", "", "subject name")
+		fs.StringVar(&f.credits, "credits", "", "ECTS or credit hours")
+	case kindExam:
+		fs.StringVar(&f.subject, "subject", "", "subject code")
+		fs.StringVar(&f.name, "name", "", "exam name")
+		fs.StringVar(&f.date, "date", "", "exam date")
+		fs.StringVar(&f.retakes, "retakes", "", "comma-separated retake dates")
+		fs.StringVar(&f.priority, "priority", "", "HIGH, MED, LOW or AUTO")
+		fs.StringVar(&f.weight, "weight", "", "percent of the subject grade")
+	case kindProject:
+		fs.StringVar(&f.name, "name", "", "project name")
+		fs.StringVar(&f.subject, "subject", "", "subject code")
+		fs.StringVar(&f.start, "start", "", "first day of work")
+		fs.StringVar(&f.due, "due", "", "deadline")
+		fs.StringVar(&f.status, "status", "", "NOT STARTED, IN PROGRESS, DONE or AUTO (follow the subtasks)")
+	case kindTodo:
+		fs.StringVar(&f.text, "text", "", "task text")
+		fs.StringVar(&f.subject, "subject", "", "subject code")
+		fs.StringVar(&f.due, "due", "", "due date (YYYY-MM-DD)")
+		fs.StringVar(&f.repeat, "repeat", "", "repeat rule, e.g. \"weekly on Mon, Thu\"")
+	}
+}
+
+func (e *env) list(args []string) error {
+	fs := e.newFlagSet("list")
+	var subject string
+	fs.StringVar(&subject, "subject", "", "only items of this subject")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: list needs exactly one kind", errUsage)
+	}
+	kind, err := parseKind(pos[0])
+	if err != nil {
+		return err
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+	match := func(code string) bool {
+		return subject == "" || strings.EqualFold(code, subject)
+	}
+
+	switch kind {
+	case kindSubject:
+		views := []subjectView{}
+		for i, s := range data.Subjects {
+			if match(s.Code) {
+				views = append(views, newSubjectView(i+1, s))
+			}
+		}
+		if e.json {
+			return e.printJSON(views)
+		}
+		for _, v := range views {
+			writeSubject(e.stdout, v)
+		}
+	case kindExam:
+		views := []examView{}
+		for i, flat := range flattenExams(data) {
+			if match(flat.subject) {
+				views = append(views, newExamView(i+1, flat.subject, flat.exam))
+			}
+		}
+		if e.json {
+			return e.printJSON(views)
+		}
+		for _, v := range views {
+			writeExam(e.stdout, v, e.dateFormat)
+		}
+	case kindProject:
+		views := []projectView{}
+		for i, p := range data.Projects {
+			if match(p.Subject) {
+				views = append(views, newProjectView(i+1, p))
+			}
+		}
+		if e.json {
+			return e.printJSON(views)
+		}
+		for _, v := range views {
+			writeProject(e.stdout, v, e.dateFormat)
+		}
+	case kindTodo:
+		views := []todoView{}
+		for i, c := range data.Checklist {
+			if match(c.Subject) {
+				views = append(views, newTodoView(i+1, c))
+			}
+		}
+		if e.json {
+			return e.printJSON(views)
+		}
+		for _, v := range views {
+			writeTodo(e.stdout, v, e.dateFormat)
+		}
+	}
+	return nil
+}
+
+func (e *env) add(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: add needs a kind", errUsage)
+	}
+	kind, err := parseKind(args[0])
+	if err != nil {
+		return err
+	}
+	fs := e.newFlagSet("add " + kind)
+	var f itemFlags
+	f.register(fs, kind)
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if kind == kindTodo && f.text == "" {
+		f.text = strings.Join(pos, " ")
+	} else if len(pos) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, pos[0])
+	}
+
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case kindSubject:
+		subject, err := app.BuildSubject(f.code, f.name, f.credits)
+		if err != nil {
+			return err
+		}
+		data.Subjects = append(data.Subjects, subject)
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportSubject("Added", len(data.Subjects), subject)
+	case kindExam:
+		idx, exam, err := app.BuildExam(data.Subjects, data.PriorityRules, app.Today(), f.subject, f.name, f.date, f.retakes, f.priority, f.weight)
+		if err != nil {
+			return err
+		}
+		data.Subjects[idx].Exams = append(data.Subjects[idx].Exams, exam)
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportExam("Added", data, idx, len(data.Subjects[idx].Exams)-1)
+	case kindProject:
+		project, err := app.BuildProject(app.Today(), f.name, f.subject, f.start, f.due, f.status)
+		if err != nil {
+			return err
+		}
+		data.Projects = append(data.Projects, project)
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportProject("Added", len(data.Projects), project)
+	default:
+		item, err := app.BuildTodo(data.Subjects, app.Today(), f.text, f.subject, f.due, f.repeat)
+		if err != nil {
+			return err
+		}
+		data.Checklist = append(data.Checklist, item)
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportTodo("Added", len(data.Checklist), item)
+	}
+}
+
+func (e *env) edit(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: edit needs a kind", errUsage)
+	}
+	kind, err := parseKind(args[0])
+	if err != nil {
+		return err
+	}
+	fs := e.newFlagSet("edit " + kind)
+	var f itemFlags
+	f.register(fs, kind)
+	pos, err := parseArgs(fs, args[1:])
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: edit needs exactly one <ref>", errUsage)
+	}
+	set := setFlags(fs)
+	pick := func(name, flagValue, current string) string {
+		if set[name] {
+			return flagValue
+		}
+		return current
+	}
+
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	switch kind {
+	case kindSubject:
+		idx, err := parseRef(pos[0], subjectIDs(data.Subjects), kind)
+		if err != nil {
+			return err
+		}
+		cur := data.Subjects[idx]
+		subject, err := app.BuildSubject(pick("code", f.code, cur.Code), pick("name", f.name, cur.Name),
+			pick("credits", f.credits, app.FormatNumber(cur.Credits)))
+		if err != nil {
+			return err
+		}
+		data.Subjects[idx].Code = subject.Code
+		data.Subjects[idx].Name = subject.Name
+		data.Subjects[idx].Credits = subject.Credits
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportSubject("Updated", idx+1, data.Subjects[idx])
+	case kindExam:
+		flat := flattenExams(data)
+		idx, err := parseRef(pos[0], examIDs(flat), kind)
+		if err != nil {
+			return err
+		}
+		cur := flat[idx]
+		exam, err := app.UpdateExam(cur.exam, data.PriorityRules, app.Today(),
+			pick("name", f.name, cur.exam.Name),
+			pick("date", f.date, cur.exam.Date),
+			pick("retakes", f.retakes, strings.Join(cur.exam.Retakes, ", ")),
+			pick("priority", f.priority, cur.exam.Priority),
+			pick("weight", f.weight, app.FormatNumber(cur.exam.Weight)),
+		)
+		if err != nil {
+			return err
+		}
+		si, ei := cur.subjectIdx, cur.examIdx
+		data.Subjects[si].Exams[ei] = exam
+		if set["subject"] && !strings.EqualFold(f.subject, cur.subject) {
+			target := app.FindSubject(data.Subjects, f.subject)
+			if target < 0 {
+				return fmt.Errorf("Subject code not found.")
+			}
+			exams := data.Subjects[si].Exams
+			data.Subjects[si].Exams = append(exams[:ei:ei], exams[ei+1:]...)
+			data.Subjects[target].Exams = append(data.Subjects[target].Exams, exam)
+			si, ei = target, len(data.Subjects[target].Exams)-1
+		}
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportExam("Updated", data, si, ei)
+	case kindProject:
+		idx, err := parseRef(pos[0], projectIDs(data.Projects), kind)
+		if err != nil {
+			return err
+		}
+		cur := data.Projects[idx]
+		status := cur.Status
+		if cur.AutoStatus {
+			status = models.StatusAuto
+		}
+		cur, err = app.UpdateProject(cur, app.Today(),
+			pick("name", f.name, cur.Name),
+			pick("subject", f.subject, cur.Subject),
+			pick("start", f.start, cur.Start),
+			pick("due", f.due, cur.Due),
+			pick("status", f.status, status),
+		)
+		if err != nil {
+			return err
+		}
+		data.Projects[idx] = cur
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportProject("Updated", idx+1, cur)
+	default:
+		idx, err := parseRef(pos[0], todoIDs(data.Checklist), kind)
+		if err != nil {
+			return err
+		}
+		cur := data.Checklist[idx]
+		item, err := app.BuildTodo(data.Subjects, app.Today(),
+			pick("text", f.text, cur.Text),
+			pick("subject", f.subject, cur.Subject),
+			pick("due", f.due, cur.Due),
+			pick("repeat", f.repeat, cur.Repeat),
+		)
+		if err != nil {
+			return err
+		}
+		cur.Text, cur.Subject, cur.Due = item.Text, item.Subject, item.Due
+		if item.Repeat == "" {
+			cur.DoneDates, cur.Skipped = nil, nil
+		} else if cur.Repeat == "" {
+			cur.Done = false
+		}
+		cur.Repeat = item.Repeat
+		data.Checklist[idx] = cur
+		if err := e.save(data); err != nil {
+			return err
+		}
+		return e.reportTodo("Updated", idx+1, cur)
+	}
+}
+
+func (e *env) delete(args []string) error {
+	fs := e.newFlagSet("delete")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		return fmt.Errorf("%w: delete needs a kind and a <ref>", errUsage)
+	}
+	kind, err := parseKind(pos[0])
+	if err != nil {
+		return err
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	var report func() error
+	switch kind {
+	case kindSubject:
+		idx, err := parseRef(pos[1], subjectIDs(data.Subjects), kind)
+		if err != nil {
+			return err
+		}
+		removed := data.Subjects[idx]
+		data.Subjects = append(data.Subjects[:idx], data.Subjects[idx+1:]...)
+		report = func() error { return e.reportSubject("Deleted", idx+1, removed) }
+	case kindExam:
+		flat := flattenExams(data)
+		idx, err := parseRef(pos[1], examIDs(flat), kind)
+		if err != nil {
+			return err
+		}
+		removed := flat[idx]
+		exams := data.Subjects[removed.subjectIdx].Exams
+		data.Subjects[removed.subjectIdx].Exams = append(exams[:removed.examIdx], exams[removed.examIdx+1:]...)
+		report = func() error {
+			return e.reportExamView("Deleted", newExamView(idx+1, removed.subject, removed.exam))
+		}
+	case kindProject:
+		idx, err := parseRef(pos[1], projectIDs(data.Projects), kind)
+		if err != nil {
+			return err
+		}
+		removed := data.Projects[idx]
+		data.Projects = append(data.Projects[:idx], data.Projects[idx+1:]...)
+		report = func() error { return e.reportProject("Deleted", idx+1, removed) }
+	default:
+		idx, err := parseRef(pos[1], todoIDs(data.Checklist), kind)
+		if err != nil {
+			return err
+		}
+		removed := data.Checklist[idx]
+		data.Checklist = append(data.Checklist[:idx], data.Checklist[idx+1:]...)
+		report = func() error { return e.reportTodo("Deleted", idx+1, removed) }
+	}
+	if err := e.save(data); err != nil {
+		return err
+	}
+	return report()
+}
+
+func (e *env) done(args []string) error {
+	fs := e.newFlagSet("done")
+	var on string
+	fs.StringVar(&on, "on", "", "occurrence of a recurring todo")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: done needs exactly one todo <ref>", errUsage)
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+	idx, err := parseRef(pos[0], todoIDs(data.Checklist), kindTodo)
+	if err != nil {
+		return err
+	}
+	item := &data.Checklist[idx]
+	day, err := app.ToggleTodo(item, on, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := e.save(data); err != nil {
+		return err
+	}
+	done := item.Done
+	if day != "" {
+		done = containsDay(item.DoneDates, day)
+	}
+	verb := "Reopened"
+	if done {
+		verb = "Completed"
+	}
+	if day != "" && !e.json {
+		fmt.Fprintf(e.stdout, "%s todo #%d: %s on %s\n", verb, idx+1, item.Text, day)
+		return nil
+	}
+	return e.reportTodo(verb, idx+1, *item)
+}
+
+func (e *env) reportSubject(verb string, ref int, s models.SubjectItem) error {
+	v := newSubjectView(ref, s)
+	if e.json {
+		return e.printJSON(v)
+	}
+	fmt.Fprintf(e.stdout, "%s subject #%d: %s (%s)\n", verb, v.Ref, v.Code, v.Name)
+	return nil
+}
+
+func (e *env) reportExam(verb string, data storage.SemesterData, subjectIdx, examIdx int) error {
+	for i, flat := range flattenExams(data) {
+		if flat.subjectIdx == subjectIdx && flat.examIdx == examIdx {
+			return e.reportExamView(verb, newExamView(i+1, flat.subject, flat.exam))
+		}
+	}
+	return nil
+}
+
+func (e *env) reportExamView(verb string, v examView) error {
+	if e.json {
+		return e.printJSON(v)
+	}
+	fmt.Fprintf(e.stdout, "%s exam #%d: %s (%s) on %s\n", verb, v.Ref, v.Name, v.Subject, e.dateFormat.ShowStored(v.Date))
+	return nil
+}
+
+func (e *env) reportProject(verb string, ref int, p models.ProjectItem) error {
+	v := newProjectView(ref, p)
+	if e.json {
+		return e.printJSON(v)
+	}
+	fmt.Fprintf(e.stdout, "%s project #%d: %s (%s) due %s\n", verb, v.Ref, v.Name, v.Subject, e.dateFormat.ShowStored(v.Due))
+	return nil
+}
+
+func (e *env) reportTodo(verb string, ref int, c models.ChecklistItem) error {
+	v := newTodoView(ref, c)
+	if e.json {
+		return e.printJSON(v)
+	}
+	fmt.Fprintf(e.stdout, "%s todo #%d: %s\n", verb, v.Ref, v.Text)
+	return nil
+}
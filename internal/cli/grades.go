@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/app"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+type subjectGradeView struct {
+	Code     string   `json:"code"`
+	Name     string   `json:"name"`
+	Credits  float64  `json:"credits"`
+	Percent  *float64 `json:"percent"`
+	Grade    string   `json:"grade"`
+	Complete bool     `json:"complete"`
+	Passed   bool     `json:"passed"`
+}
+
+type gradesView struct {
+	Scale         string             `json:"scale"`
+	GPA           *float64           `json:"gpa"`
+	CreditsEarned float64            `json:"credits_earned"`
+	CreditsTotal  float64            `json:"credits_total"`
+	Subjects      []subjectGradeView `json:"subjects"`
+}
+
+// result records the outcome of one attempt at an exam.
+func (e *env) result(args []string) error {
+	fs := e.newFlagSet("result")
+	var attempt, grade, points, passed string
+	fs.StringVar(&attempt, "attempt", "", "1 for the original date, 2 for the first retake, ...")
+	fs.StringVar(&grade, "grade", "", "grade on the configured scale")
+	fs.StringVar(&points, "points", "", "points as GOT/MAX, e.g. 45/50")
+	fs.StringVar(&passed, "passed", "", "yes or no (default: from the grade or points)")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: result needs exactly one exam <ref>", errUsage)
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+	flat := flattenExams(data)
+	idx, err := parseRef(pos[0], examIDs(flat), kindExam)
+	if err != nil {
+		return err
+	}
+	cur := flat[idx]
+	if attempt == "" {
+		attempt = strconv.Itoa(app.NextAttempt(cur.exam) + 1)
+	}
+	exam, err := app.SetExamResult(cur.exam, models.GradingScaleOf(data.GradingScale), attempt, grade, points, passed)
+	if err != nil {
+		return err
+	}
+	data.Subjects[cur.subjectIdx].Exams[cur.examIdx] = exam
+	if err := e.save(data); err != nil {
+		return err
+	}
+	return e.reportExam("Recorded result for", data, cur.subjectIdx, cur.examIdx)
+}
+
+// grades prints every subject's grade and the semester GPA.
+func (e *env) grades(args []string) error {
+	fs := e.newFlagSet("grades")
+	var scaleName string
+	fs.StringVar(&scaleName, "scale", "", "show grades on this scale instead of the configured one")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return fmt.Errorf("%w: unexpected argument %q", errUsage, pos[0])
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+	if scaleName == "" {
+		scaleName = data.GradingScale
+	} else if models.GradingScaleOf(scaleName).Name != scaleName {
+		return fmt.Errorf("%w: unknown scale %q (want %s)", errUsage, scaleName, strings.Join(models.GradingScaleNames, ", "))
+	}
+
+	v := newGradesView(data, models.GradingScaleOf(scaleName))
+	if e.json {
+		return e.printJSON(v)
+	}
+	for _, s := range v.Subjects {
+		line := fmt.Sprintf("%-10s %s", s.Code, s.Name)
+		if s.Credits > 0 {
+			line += fmt.Sprintf(" (%s cr)", app.FormatNumber(s.Credits))
+		}
+		if s.Percent != nil {
+			line += fmt.Sprintf("  %s %.1f%%", s.Grade, *s.Percent)
+		}
+		switch {
+		case !s.Passed:
+			line += "  failed"
+		case s.Complete:
+			line += "  passed"
+		default:
+			line += "  in progress"
+		}
+		fmt.Fprintln(e.stdout, line)
+	}
+	gpa := "-"
+	if v.GPA != nil {
+		gpa = models.GradingScaleOf(v.Scale).FormatValue(*v.GPA)
+	}
+	summary := fmt.Sprintf("GPA %s (%s)", gpa, v.Scale)
+	if v.CreditsTotal > 0 {
+		summary += fmt.Sprintf(", %s of %s credits earned", app.FormatNumber(v.CreditsEarned), app.FormatNumber(v.CreditsTotal))
+	}
+	fmt.Fprintln(e.stdout, summary)
+	return nil
+}
+
+func newGradesView(data storage.SemesterData, scale models.GradingScale) gradesView {
+	v := gradesView{Scale: scale.Name, Subjects: []subjectGradeView{}}
+	if gpa, ok := models.GPA(data.Subjects, scale); ok {
+		v.GPA = &gpa
+	}
+	for _, s := range data.Subjects {
+		g := models.GradeSubject(s, scale)
+		sv := subjectGradeView{Code: s.Code, Name: s.Name, Credits: s.Credits, Complete: g.Complete, Passed: g.Passed}
+		if g.Graded {
+			percent := g.Percent
+			sv.Percent = &percent
+			sv.Grade = scale.Grade(g.Percent)
+		}
+		v.CreditsTotal += s.Credits
+		if g.Complete && g.Passed {
+			v.CreditsEarned += s.Credits
+		}
+		v.Subjects = append(v.Subjects, sv)
+	}
+	return v
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/app"
+)
+
+// keys prints the key bindings in effect: the defaults, as keys.toml
+// changes them.
+func (e *env) keys(args []string) error {
+	fs := e.newFlagSet("keys")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return fmt.Errorf("%w: keys takes no arguments", errUsage)
+	}
+	path, err := app.KeymapPath()
+	if err != nil {
+		return err
+	}
+	keymap, err := app.LoadKeymap(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	bindings := app.KeyBindings(keymap)
+	if e.json {
+		return e.printJSON(bindings)
+	}
+	scope := ""
+	for _, b := range bindings {
+		if b.Scope != scope {
+			if scope != "" {
+				fmt.Fprintln(e.stdout)
+			}
+			scope = b.Scope
+			fmt.Fprintf(e.stdout, "[%s]\n", scope)
+		}
+		keys := strings.Join(b.Keys, ", ")
+		if keys == "" {
+			keys = "(unbound)"
+		}
+		fmt.Fprintf(e.stdout, "  %-16s %-18s %s\n", b.Action, keys, b.Name)
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/app"
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// The JSON views are the stable, snake_case representation printed by --json.
+// Ref is the number to pass to edit, delete and done; ID works there too and,
+// unlike Ref, does not change when other items are added or removed.
+
+type subjectView struct {
+	Ref       int               `json:"ref"`
+	ID        string            `json:"id"`
+	Code      string            `json:"code"`
+	Name      string            `json:"name"`
+	Exams     int               `json:"exams"`
+	Credits   float64           `json:"credits"`
+	Notes     string            `json:"notes"`
+	Resources []models.Resource `json:"resources"`
+}
+
+type examView struct {
+	Ref      int          `json:"ref"`
+	ID       string       `json:"id"`
+	Subject  string       `json:"subject"`
+	Name     string       `json:"name"`
+	Date     string       `json:"date"`
+	Retakes  []string     `json:"retakes"`
+	Priority string       `json:"priority"`
+	Weight   float64      `json:"weight"`
+	Results  []resultView `json:"results"`
+}
+
+// resultView is an exam result with its attempt numbered from 1, as
+// "seman result --attempt" takes it.
+type resultView struct {
+	Attempt   int     `json:"attempt"`
+	Grade     string  `json:"grade,omitempty"`
+	Scale     string  `json:"scale,omitempty"`
+	Points    float64 `json:"points,omitempty"`
+	MaxPoints float64 `json:"max_points,omitempty"`
+	Passed    bool    `json:"passed"`
+}
+
+type projectView struct {
+	Ref        int                `json:"ref"`
+	ID         string             `json:"id"`
+	Name       string             `json:"name"`
+	Subject    string             `json:"subject"`
+	Due        string             `json:"due"`
+	Start      string             `json:"start"`
+	Status     string             `json:"status"`
+	AutoStatus bool               `json:"auto_status"`
+	Subtasks   []models.Subtask   `json:"subtasks"`
+	Milestones []models.Milestone `json:"milestones"`
+}
+
+type todoView struct {
+	Ref       int      `json:"ref"`
+	ID        string   `json:"id"`
+	Text      string   `json:"text"`
+	Done      bool     `json:"done"`
+	Due       string   `json:"due"`
+	Subject   string   `json:"subject"`
+	Repeat    string   `json:"repeat,omitempty"`
+	DoneDates []string `json:"done_dates,omitempty"`
+	Skipped   []string `json:"skipped,omitempty"`
+}
+
+// flatExam is an exam with its position in the data, in list order.
+type flatExam struct {
+	subjectIdx int
+	examIdx    int
+	subject    string
+	exam       models.ExamItem
+}
+
+func flattenExams(data storage.SemesterData) []flatExam {
+	var list []flatExam
+	for si, s := range data.Subjects {
+		for ei, exam := range s.Exams {
+			list = append(list, flatExam{subjectIdx: si, examIdx: ei, subject: s.Code, exam: exam})
+		}
+	}
+	return list
+}
+
+func subjectIDs(items []models.SubjectItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func examIDs(items []flatExam) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.exam.ID
+	}
+	return ids
+}
+
+func projectIDs(items []models.ProjectItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func todoIDs(items []models.ChecklistItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func newSubjectView(ref int, s models.SubjectItem) subjectView {
+	resources := s.Resources
+	if resources == nil {
+		resources = []models.Resource{}
+	}
+	return subjectView{Ref: ref, ID: s.ID, Code: s.Code, Name: s.Name, Exams: len(s.Exams), Credits: s.Credits,
+		Notes: s.Notes, Resources: resources}
+}
+
+func newExamView(ref int, subject string, e models.ExamItem) examView {
+	retakes := e.Retakes
+	if retakes == nil {
+		retakes = []string{}
+	}
+	results := make([]resultView, len(e.Results))
+	for i, r := range e.Results {
+		results[i] = resultView{Attempt: r.Number(), Grade: r.Grade, Scale: r.Scale, Points: r.Points,
+			MaxPoints: r.MaxPoints, Passed: r.Passed}
+	}
+	return examView{Ref: ref, ID: e.ID, Subject: subject, Name: e.Name, Date: e.Date, Retakes: retakes, Priority: e.Priority,
+		Weight: e.Weight, Results: results}
+}
+
+func newProjectView(ref int, p models.ProjectItem) projectView {
+	subtasks, milestones := p.Subtasks, p.Milestones
+	if subtasks == nil {
+		subtasks = []models.Subtask{}
+	}
+	if milestones == nil {
+		milestones = []models.Milestone{}
+	}
+	return projectView{Ref: ref, ID: p.ID, Name: p.Name, Subject: p.Subject, Due: p.Due, Start: p.Start, Status: p.Status,
+		AutoStatus: p.AutoStatus, Subtasks: subtasks, Milestones: milestones}
+}
+
+func newTodoView(ref int, c models.ChecklistItem) todoView {
+	return todoView{Ref: ref, ID: c.ID, Text: c.Text, Done: c.Done, Due: c.Due, Subject: c.Subject,
+		Repeat: c.Repeat, DoneDates: c.DoneDates, Skipped: c.Skipped}
+}
+
+func containsDay(days []string, day string) bool {
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func writeSubject(w io.Writer, v subjectView) {
+	credits := ""
+	if v.Credits > 0 {
+		credits = ", " + app.FormatNumber(v.Credits) + " credits"
+	}
+	fmt.Fprintf(w, "%3d  %-10s %s (%d exams%s)\n", v.Ref, v.Code, v.Name, v.Exams, credits)
+}
+
+func writeExam(w io.Writer, v examView, df dates.Format) {
+	line := fmt.Sprintf("%3d  %-10s %s  %s", v.Ref, v.Subject, v.Name, df.ShowStored(v.Date))
+	if v.Priority != "" {
+		line += "  " + v.Priority
+	}
+	if len(v.Retakes) > 0 {
+		line += "  (retakes: " + df.ShowList(v.Retakes) + ")"
+	}
+	if v.Weight > 0 {
+		line += "  weight " + app.FormatNumber(v.Weight) + "%"
+	}
+	if len(v.Results) > 0 {
+		results := make([]string, len(v.Results))
+		for i, r := range v.Results {
+			results[i] = formatResult(r)
+		}
+		line += "  results: " + strings.Join(results, ", ")
+	}
+	fmt.Fprintln(w, line)
+}
+
+// formatResult renders a result as e.g. "2: C 72/100 passed".
+func formatResult(r resultView) string {
+	text := fmt.Sprintf("%d:", r.Attempt)
+	if r.Grade != "" {
+		text += " " + r.Grade
+	}
+	if r.MaxPoints > 0 {
+		text += " " + app.FormatNumber(r.Points) + "/" + app.FormatNumber(r.MaxPoints)
+	}
+	if r.Passed {
+		return text + " passed"
+	}
+	return text + " failed"
+}
+
+func writeProject(w io.Writer, v projectView, df dates.Format) {
+	progress := ""
+	if len(v.Subtasks) > 0 {
+		done := 0
+		for _, t := range v.Subtasks {
+			if t.Done {
+				done++
+			}
+		}
+		progress = fmt.Sprintf("  %d/%d done", done, len(v.Subtasks))
+	}
+	fmt.Fprintf(w, "%3d  %-10s %s  due %s  [%s]%s\n", v.Ref, v.Subject, v.Name, df.ShowStored(v.Due), v.Status, progress)
+}
+
+func writeTodo(w io.Writer, v todoView, df dates.Format) {
+	box := "[ ]"
+	if v.Done {
+		box = "[x]"
+	}
+	subject := v.Subject
+	if subject == "" {
+		subject = "-"
+	}
+	if v.Due == "" {
+		fmt.Fprintf(w, "%3d  %s %-10s %s\n", v.Ref, box, subject, v.Text)
+		return
+	}
+	if rule, err := models.ParseRecurrence(v.Repeat); v.Repeat != "" && err == nil {
+		box = "[↻]"
+		fmt.Fprintf(w, "%3d  %s %-10s %s  (from %s, %s; %d done)\n", v.Ref, box, subject, v.Text, df.ShowStored(v.Due), rule.Describe(), len(v.DoneDates))
+		return
+	}
+	fmt.Fprintf(w, "%3d  %s %-10s %s  (due %s)\n", v.Ref, box, subject, v.Text, df.ShowStored(v.Due))
+}
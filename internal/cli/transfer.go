@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/app"
+	"github.com/romanguyen/seman/internal/storage"
+)
+
+// export writes the data as JSON or iCalendar to --output, or to stdout.
+func (e *env) export(args []string) error {
+	fs := e.newFlagSet("export")
+	var output string
+	fs.StringVar(&output, "output", "", "write to FILE instead of stdout")
+	fs.StringVar(&output, "o", "", "shorthand for --output")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return fmt.Errorf("%w: export needs a format (json or ics)", errUsage)
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(pos[0]) {
+	case "json":
+		if output == "" {
+			payload, err := storage.Encode(data)
+			if err != nil {
+				return err
+			}
+			_, err = e.stdout.Write(payload)
+			return err
+		}
+		if err := storage.WriteFile(output, data); err != nil {
+			return fmt.Errorf("writing %s: %w", output, err)
+		}
+	case "ics", "ical":
+		if output == "" {
+			_, err := e.stdout.Write(app.ExportICS(data, time.Now()))
+			return err
+		}
+		if err := app.WriteICS(output, data, time.Now()); err != nil {
+			return fmt.Errorf("writing %s: %w", output, err)
+		}
+	default:
+		return fmt.Errorf("%w: unknown export format %q (want json or ics)", errUsage, pos[0])
+	}
+
+	if abs, err := filepath.Abs(output); err == nil {
+		output = abs
+	}
+	fmt.Fprintf(e.stdout, "Exported to %s\n", output)
+	return nil
+}
+
+// importCalendar maps the events of an iCalendar file to exams. With
+// --dry-run it only reports what would change.
+func (e *env) importCalendar(args []string) error {
+	fs := e.newFlagSet("import")
+	var pattern string
+	var dryRun bool
+	fs.StringVar(&pattern, "pattern", "", "regex that finds subject codes")
+	fs.BoolVar(&dryRun, "dry-run", false, "report changes without saving")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 || !strings.EqualFold(pos[0], "ics") {
+		return fmt.Errorf("%w: import needs a format and a file (import ics FILE)", errUsage)
+	}
+	data, err := e.load()
+	if err != nil {
+		return err
+	}
+	patternSet := setFlags(fs)["pattern"]
+	if !patternSet {
+		pattern = data.ICSSubjectPattern
+	}
+
+	cal, err := app.ReadICS(pos[1])
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", pos[1], err)
+	}
+	plan, err := app.PlanICSImport(data, cal, pattern)
+	if err != nil {
+		return err
+	}
+	changed := plan.Count(app.ICSNew)+plan.Count(app.ICSUpdated) > 0
+	if !dryRun && (changed || patternSet) {
+		if patternSet {
+			plan.Data.ICSSubjectPattern = strings.TrimSpace(pattern)
+		}
+		if err := e.save(plan.Data); err != nil {
+			return err
+		}
+	}
+
+	if e.json {
+		entries := plan.Entries
+		if entries == nil {
+			entries = []app.ICSEntry{}
+		}
+		return e.printJSON(entries)
+	}
+	for _, entry := range plan.Entries {
+		line := fmt.Sprintf("%-8s %-10s %s", entry.Action, entry.Subject, entry.Name)
+		if entry.Date != "" {
+			line += "  " + e.dateFormat.ShowStored(entry.Date)
+		}
+		if entry.Reason != "" {
+			line += "  (" + entry.Reason + ")"
+		}
+		fmt.Fprintln(e.stdout, line)
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Fprintf(e.stdout, "%s %s: %s\n", verb, filepath.Base(pos[1]), plan.Summary())
+	return nil
+}
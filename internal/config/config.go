@@ -0,0 +1,30 @@
+// Package config locates seman's configuration directory and reads its
+// configuration files, written in a subset of TOML or in JSON.
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns the directory of seman's configuration files:
+// $XDG_CONFIG_HOME/seman, or ~/.config/seman.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "seman"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "seman"), nil
+}
+
+// Path returns the path of the configuration file name.
+func Path(name string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
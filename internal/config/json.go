@@ -0,0 +1,56 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ParseJSON reads a JSON object into a Document like the one ParseTOML
+// returns: its objects become tables and its other members go in the table
+// "". Values are limited as in the TOML files: strings, arrays of strings,
+// booleans and numbers.
+func ParseJSON(data []byte) (Document, error) {
+	var root map[string]any
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	doc := Document{"": Table{}}
+	for key, raw := range root {
+		if obj, ok := raw.(map[string]any); ok {
+			table := Table{}
+			for k, v := range obj {
+				value, err := jsonValue(v)
+				if err != nil {
+					return nil, fmt.Errorf("%s.%s: %v", key, k, err)
+				}
+				table[k] = value
+			}
+			doc[key] = table
+			continue
+		}
+		value, err := jsonValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		doc[""][key] = value
+	}
+	return doc, nil
+}
+
+func jsonValue(v any) (any, error) {
+	switch v := v.(type) {
+	case string, bool, float64:
+		return v, nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("arrays may only hold strings")
+			}
+			items = append(items, s)
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("unsupported value %v", v)
+}
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Table holds the values of a TOML table by key. A value is a string, a
+// []string, a bool or a float64.
+type Table map[string]any
+
+// Document is a parsed TOML file: its tables by name, with the values
+// before the first table header in the table "".
+type Document map[string]Table
+
+// ParseTOML reads the subset of TOML the configuration files use: comments,
+// [table] headers, and key = value lines whose value is a basic or literal
+// string, an array of strings, a boolean or a number. Arrays may span lines.
+// Keys may be dotted: a.b = 1 sets b in the table a, nested in the current
+// one; the names of nested tables join their keys with dots.
+func ParseTOML(data string) (Document, error) {
+	doc := Document{"": Table{}}
+	// headers are the tables given a [header]; dotted keys create the others.
+	headers := map[string]bool{}
+	table := ""
+	lines := strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n")
+	for n := 0; n < len(lines); n++ {
+		line := strings.TrimSpace(stripComment(lines[n]))
+		if line == "" {
+			continue
+		}
+		lineNo := n + 1
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: malformed table header", lineNo)
+			}
+			path, err := parseKey(strings.TrimSpace(line[1 : len(line)-1]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			name := strings.Join(path, ".")
+			if _, ok := doc[name]; ok {
+				return nil, fmt.Errorf("line %d: table [%s] defined twice", lineNo, name)
+			}
+			if err := doc.checkPath(path); err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNo, err)
+			}
+			doc[name] = Table{}
+			headers[name] = true
+			table = name
+			continue
+		}
+		eq := scan(line, func(c byte) bool { return c == '=' })
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		path, err := parseKey(strings.TrimSpace(line[:eq]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		raw := strings.TrimSpace(line[eq+1:])
+		// An array continues until its brackets balance.
+		for strings.HasPrefix(raw, "[") && !arrayClosed(raw) && n+1 < len(lines) {
+			n++
+			raw += " " + strings.TrimSpace(stripComment(lines[n]))
+		}
+		key := strings.Join(path, ".")
+		value, err := parseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", lineNo, key, err)
+		}
+		if table != "" {
+			path = append(strings.Split(table, "."), path...)
+		}
+		if err := doc.checkPath(path); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNo, err)
+		}
+		target := strings.Join(path[:len(path)-1], ".")
+		name := path[len(path)-1]
+		if target != table && headers[target] {
+			return nil, fmt.Errorf("line %d: %s: table [%s] is defined by its header", lineNo, key, target)
+		}
+		if _, ok := doc[strings.Join(path, ".")]; ok {
+			return nil, fmt.Errorf("line %d: %s is a table", lineNo, key)
+		}
+		if doc[target] == nil {
+			doc[target] = Table{}
+		}
+		if _, ok := doc[target][name]; ok {
+			return nil, fmt.Errorf("line %d: %s is set twice", lineNo, key)
+		}
+		doc[target][name] = value
+	}
+	return doc, nil
+}
+
+// checkPath reports an error if a table on path, other than the last key,
+// is already a value.
+func (doc Document) checkPath(path []string) error {
+	for i := 0; i < len(path)-1; i++ {
+		parent := strings.Join(path[:i], ".")
+		if _, ok := doc[parent][path[i]]; ok {
+			return fmt.Errorf("%s is a value, not a table", strings.Join(path[:i+1], "."))
+		}
+	}
+	return nil
+}
+
+// scan returns the index of the first byte of line outside strings for which
+// stop is true, or -1. In a basic string a backslash escapes the byte after
+// it, so "\\" ends where it seems to; literal strings have no escapes.
+func scan(line string, stop func(c byte) bool) int {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case stop(c):
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment drops a # comment that is not inside a string.
+func stripComment(line string) string {
+	if i := scan(line, func(c byte) bool { return c == '#' }); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+func arrayClosed(raw string) bool {
+	depth := 0
+	scan(raw, func(c byte) bool {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		return false
+	})
+	return depth == 0
+}
+
+// parseKey reads a key: bare (letters, digits, - and _) or quoted parts
+// joined by dots. It returns the parts.
+func parseKey(raw string) ([]string, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("missing key")
+	}
+	var path []string
+	rest := raw
+	for {
+		dot := scan(rest, func(c byte) bool { return c == '.' })
+		part := rest
+		if dot >= 0 {
+			part = rest[:dot]
+		}
+		key, err := parseKeyPart(strings.TrimSpace(part), raw)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, key)
+		if dot < 0 {
+			return path, nil
+		}
+		rest = rest[dot+1:]
+	}
+}
+
+// parseKeyPart reads one part of the dotted key raw.
+func parseKeyPart(part, raw string) (string, error) {
+	if part == "" {
+		return "", fmt.Errorf("malformed key %s (empty part)", raw)
+	}
+	if strings.HasPrefix(part, `"`) || strings.HasPrefix(part, "'") {
+		key, rest, err := parseString(part)
+		if err != nil || strings.TrimSpace(rest) != "" {
+			return "", fmt.Errorf("malformed key %s", raw)
+		}
+		return key, nil
+	}
+	for _, r := range part {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", fmt.Errorf("malformed key %s (quote keys with %q in them)", raw, r)
+		}
+	}
+	return part, nil
+}
+
+func parseValue(raw string) (any, error) {
+	switch {
+	case raw == "":
+		return nil, fmt.Errorf("missing value")
+	case raw == "true":
+		return true, nil
+	case raw == "false":
+		return false, nil
+	case strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"):
+		s, rest, err := parseString(raw)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("unexpected %q after the string", strings.TrimSpace(rest))
+		}
+		return s, nil
+	case strings.HasPrefix(raw, "["):
+		return parseArray(raw)
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(raw, "_", ""), 64)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %s (quote strings)", raw)
+	}
+	return f, nil
+}
+
+// parseArray reads an array of strings; a trailing comma is allowed.
+func parseArray(raw string) ([]string, error) {
+	rest := strings.TrimSpace(raw[1:])
+	items := []string{}
+	for {
+		if rest == "" {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		if strings.HasPrefix(rest, "]") {
+			if strings.TrimSpace(rest[1:]) != "" {
+				return nil, fmt.Errorf("unexpected %q after the array", strings.TrimSpace(rest[1:]))
+			}
+			return items, nil
+		}
+		if !strings.HasPrefix(rest, `"`) && !strings.HasPrefix(rest, "'") {
+			return nil, fmt.Errorf("arrays may only hold strings")
+		}
+		s, after, err := parseString(rest)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, s)
+		rest = strings.TrimSpace(after)
+		switch {
+		case rest == "":
+			return nil, fmt.Errorf("unterminated array")
+		case strings.HasPrefix(rest, ","):
+			rest = strings.TrimSpace(rest[1:])
+		case strings.HasPrefix(rest, "]"):
+		default:
+			return nil, fmt.Errorf("expected , or ] in the array")
+		}
+	}
+}
+
+// parseString reads the string raw starts with and returns what follows it.
+// Basic strings ("...") take the usual escapes; literal ones ('...') none.
+func parseString(raw string) (string, string, error) {
+	if raw[0] == '\'' {
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated string")
+		}
+		return raw[1 : end+1], raw[end+2:], nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			return b.String(), raw[i+1:], nil
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(raw[i])
+			default:
+				return "", "", fmt.Errorf("unsupported escape \\%c", raw[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated string")
+}
+
+// Strings returns the value of key as a list: a string is a list of one.
+func (t Table) Strings(key string) ([]string, bool, error) {
+	switch v := t[key].(type) {
+	case nil:
+		return nil, false, nil
+	case string:
+		return []string{v}, true, nil
+	case []string:
+		return v, true, nil
+	}
+	return nil, true, fmt.Errorf("%s must be a string or a list of strings", key)
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Document
+	}{
+		{
+			name: "tables and values",
+			data: "preset = \"vim\"\n\n[global]\nsearch = [\"/\", \"ctrl+f\"]\nconfirm = true\nwidth = 1_000\nratio = -0.5\n",
+			want: Document{
+				"":       {"preset": "vim"},
+				"global": {"search": []string{"/", "ctrl+f"}, "confirm": true, "width": 1000.0, "ratio": -0.5},
+			},
+		},
+		{
+			name: "comments",
+			data: "# heading\nkey = \"a # b\" # trailing\nother = 'c # d'#x\n[t] # table\n",
+			want: Document{"": {"key": "a # b", "other": "c # d"}, "t": {}},
+		},
+		{
+			name: "quoted keys",
+			data: "\"a=b\" = \"1\"\n'c = d' = \"2\"\n\"x.y\" = \"3\"\n[\"my table\"]\n\"#\" = \"4\"\n",
+			want: Document{"": {"a=b": "1", "c = d": "2", "x.y": "3"}, "my table": {"#": "4"}},
+		},
+		{
+			name: "escapes",
+			data: `tab = "a\tb"` + "\n" + `quote = "say \"hi\""` + "\n" + `slash = "C:\\" # a comment` + "\n" + `literal = 'C:\n'` + "\n" + `line = "1\n2"` + "\n",
+			want: Document{"": {"tab": "a\tb", "quote": `say "hi"`, "slash": `C:\`, "literal": `C:\n`, "line": "1\n2"}},
+		},
+		{
+			name: "escaped backslash before a bracket",
+			data: "keys = [\"\\\\\", \"]\"] # ]\nnext = \"x\"\n",
+			want: Document{"": {"keys": []string{`\`, "]"}, "next": "x"}},
+		},
+		{
+			name: "multi-line arrays",
+			data: "keys = [\n  \"a\", # first\n  'b]',\n  \"c\\\\\",\n]\nafter = \"z\"\n",
+			want: Document{"": {"keys": []string{"a", "b]", `c\`}, "after": "z"}},
+		},
+		{
+			name: "empty array",
+			data: "keys = [ ]\n",
+			want: Document{"": {"keys": []string{}}},
+		},
+		{
+			name: "dotted keys",
+			data: "global.search = \"/\"\n\"to dos\".toggle = 'space'\n[todos]\nsub.key = \"x\"\n[a.\"b.c\"]\nd = \"e\"\n",
+			want: Document{
+				"":          {},
+				"global":    {"search": "/"},
+				"to dos":    {"toggle": "space"},
+				"todos":     {},
+				"todos.sub": {"key": "x"},
+				"a.b.c":     {"d": "e"},
+			},
+		},
+		{
+			name: "crlf",
+			data: "a = \"1\"\r\n[t]\r\nb = \"2\"\r\n",
+			want: Document{"": {"a": "1"}, "t": {"b": "2"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTOML(tt.data)
+			if err != nil {
+				t.Fatalf("ParseTOML: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseTOML =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"[table", "line 1: malformed table header"},
+		{"[[array]]", "line 1: malformed table header"},
+		{"[]", "line 1: missing key"},
+		{"[a]\n[a]", "line 2: table [a] defined twice"},
+		{"a.b = \"1\"\n[a]", "line 2: table [a] defined twice"},
+		{"just words", "line 1: expected key = value"},
+		{"= \"1\"", "line 1: missing key"},
+		{"a b = \"1\"", "line 1: malformed key a b"},
+		{"a..b = \"1\"", "line 1: malformed key a..b (empty part)"},
+		{"\"a\"b = \"1\"", "line 1: malformed key \"a\"b"},
+		{"a = ", "line 1: a: missing value"},
+		{"a = \"1\"\na = \"2\"", "line 2: a is set twice"},
+		{"a = \"1\"\na.b = \"2\"", "line 2: a is a value, not a table"},
+		{"a.b = \"1\"\na = \"2\"", "line 2: a is a table"},
+		{"[a.b]\nx = \"1\"\n[a]\nb.y = \"2\"", "line 4: b.y: table [a.b] is defined by its header"},
+		{"a = \"1\"\n[a.b]", "line 2: a is a value, not a table"},
+		{"a = soon", "line 1: a: unsupported value soon (quote strings)"},
+		{"a = \"open", "line 1: a: unterminated string"},
+		{"a = 'open", "line 1: a: unterminated string"},
+		{"a = \"1\" \"2\"", "line 1: a: unexpected \"\\\"2\\\"\" after the string"},
+		{`a = "\q"`, `line 1: a: unsupported escape \q`},
+		{"a = [1]", "line 1: a: arrays may only hold strings"},
+		{"a = [\"1\" \"2\"]", "line 1: a: expected , or ] in the array"},
+		{"a = [\"1\"] x", "line 1: a: unexpected \"x\" after the array"},
+		{"a = [\"1\",\n\"2\"", "line 1: a: unterminated array"},
+	}
+	for _, tt := range tests {
+		_, err := ParseTOML(tt.data)
+		if err == nil {
+			t.Errorf("ParseTOML(%q) succeeded, want %q", tt.data, tt.want)
+			continue
+		}
+		if !strings.HasPrefix(err.Error(), tt.want) {
+			t.Errorf("ParseTOML(%q) = %q, want %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestTableStrings(t *testing.T) {
+	table := Table{"one": "a", "many": []string{"a", "b"}, "flag": true}
+	if got, ok, err := table.Strings("one"); !ok || err != nil || !reflect.DeepEqual(got, []string{"a"}) {
+		t.Errorf("Strings(one) = %v, %v, %v", got, ok, err)
+	}
+	if got, ok, err := table.Strings("many"); !ok || err != nil || !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Strings(many) = %v, %v, %v", got, ok, err)
+	}
+	if _, ok, err := table.Strings("missing"); ok || err != nil {
+		t.Errorf("Strings(missing) = %v, %v", ok, err)
+	}
+	if _, _, err := table.Strings("flag"); err == nil {
+		t.Error("Strings(flag) succeeded")
+	}
+}
@@ -0,0 +1,119 @@
+// Package dates reads, stores and shows the dates of exams, projects and
+// todos.
+//
+// Dates are stored as RFC 3339: a full date ("2026-01-14") when no time was
+// given, otherwise a date-time with its UTC offset
+// ("2026-01-14T10:00:00+01:00"), so a file opened in another time zone still
+// means the same moment. How dates are shown is chosen separately, see
+// Format.
+package dates
+
+import (
+	"strings"
+	"time"
+)
+
+// DayLayout is the stored layout of a date without a time.
+const DayLayout = "2006-01-02"
+
+// legacyLayouts are the layouts dates were typed in, and stored as typed,
+// before they were normalized. They are read in local time. The layouts
+// without a clock are dates without a time.
+var legacyLayouts = []struct {
+	layout string
+	day    bool
+}{
+	{"Jan 2, 2006 @ 15:04", false},
+	{"Jan 2, 2006", true},
+	{"2006-01-02 15:04", false},
+	{"2.1.2006 15:04", false},
+	{"2.1.2006", true},
+	{"02.01.2006 15:04", false},
+	{"02.01.2006", true},
+	{"2/1/2006 15:04", false},
+	{"2/1/2006", true},
+	{"02/01/2006 15:04", false},
+	{"02/01/2006", true},
+}
+
+// Parse reads a stored date: RFC 3339, or one of the layouts older files and
+// the forms use. Date-times are returned in local time, dates at local
+// midnight.
+func Parse(value string) (time.Time, bool) {
+	t, _, ok := parse(value)
+	return t, ok
+}
+
+// IsDay reports whether value is a date without a time. This is decided by
+// the layout it is stored in, not by the clock: a deadline at midnight in
+// one time zone is still a deadline elsewhere.
+func IsDay(value string) bool {
+	_, day, ok := parse(value)
+	return ok && day
+}
+
+// parse reads value and tells whether it was stored without a time.
+func parse(value string) (t time.Time, day, ok bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false, false
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Local(), false, true
+	}
+	if t, err := time.ParseInLocation(DayLayout, value, time.Local); err == nil {
+		return t, true, true
+	}
+	for _, legacy := range legacyLayouts {
+		if t, err := time.ParseInLocation(legacy.layout, value, time.Local); err == nil {
+			return t, legacy.day, true
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// AllDay treats midnight as "no time given", for times just typed into a
+// form, where "Jan 5" and "Jan 5 00:00" read the same. Stored dates say
+// whether they have a time, see IsDay.
+func AllDay(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0
+}
+
+// StoreDay renders the day of t as a date without a time.
+func StoreDay(t time.Time) string {
+	return t.Format(DayLayout)
+}
+
+// StoreTime renders t as a date-time, keeping its UTC offset.
+func StoreTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// Normalize rewrites a date stored in a legacy layout in the current one.
+// RFC 3339 values and values that are not dates are kept as they are, so
+// normalizing never moves a date to the offset of whoever opened the file.
+func Normalize(value string) string {
+	if _, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+		return value
+	}
+	t, day, ok := parse(value)
+	if !ok {
+		return value
+	}
+	if day {
+		return StoreDay(t)
+	}
+	return StoreTime(t)
+}
+
+// NormalizeList normalizes every date in values.
+func NormalizeList(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = Normalize(value)
+	}
+	return out
+}
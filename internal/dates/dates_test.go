@@ -0,0 +1,160 @@
+package dates
+
+import (
+	"testing"
+	"time"
+)
+
+// zones are the local time zones the tests run in: east and west of UTC,
+// and one with a half-hour offset.
+var zones = []*time.Location{
+	time.UTC,
+	time.FixedZone("CET", 1*60*60),
+	time.FixedZone("EST", -5*60*60),
+	time.FixedZone("IST", 5*60*60+30*60),
+	time.FixedZone("NZDT", 13*60*60),
+}
+
+// inZone runs f with time.Local set to loc.
+func inZone(t *testing.T, loc *time.Location, f func(t *testing.T)) {
+	t.Run(loc.String(), func(t *testing.T) {
+		saved := time.Local
+		time.Local = loc
+		defer func() { time.Local = saved }()
+		f(t)
+	})
+}
+
+func TestIsDay(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"2026-01-14", true},
+		{" 2026-01-14 ", true},
+		{"Jan 14, 2026", true},
+		{"14.1.2026", true},
+		{"14/01/2026", true},
+		{"2026-01-14T00:00:00Z", false},
+		{"2026-01-14T00:00:00+09:00", false},
+		{"2026-01-14T10:00:00+01:00", false},
+		{"Jan 14, 2026 @ 00:00", false},
+		{"2026-01-14 00:00", false},
+		{"", false},
+		{"soon", false},
+	}
+	for _, loc := range zones {
+		inZone(t, loc, func(t *testing.T) {
+			for _, tt := range tests {
+				if got := IsDay(tt.value); got != tt.want {
+					t.Errorf("IsDay(%q) = %v, want %v", tt.value, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string // "%z" is replaced by the local offset
+	}{
+		// Stored values are kept, whatever the local zone.
+		{"2026-01-14", "2026-01-14"},
+		{"2026-01-14T00:00:00+09:00", "2026-01-14T00:00:00+09:00"},
+		{"2026-01-14T10:00:00-05:00", "2026-01-14T10:00:00-05:00"},
+		{"2026-01-14T23:30:00Z", "2026-01-14T23:30:00Z"},
+		// Legacy layouts are read in local time.
+		{"Jan 14, 2026", "2026-01-14"},
+		{"14.1.2026", "2026-01-14"},
+		{"Jan 14, 2026 @ 10:00", "2026-01-14T10:00:00%z"},
+		{"Jan 14, 2026 @ 00:00", "2026-01-14T00:00:00%z"},
+		{"14/01/2026 09:15", "2026-01-14T09:15:00%z"},
+		// Values that are not dates are kept.
+		{"", ""},
+		{"next week", "next week"},
+	}
+	for _, loc := range zones {
+		inZone(t, loc, func(t *testing.T) {
+			offset := time.Date(2026, 1, 14, 0, 0, 0, 0, loc).Format("Z07:00")
+			for _, tt := range tests {
+				want := tt.want
+				if n := len(want); n > 2 && want[n-2:] == "%z" {
+					want = want[:n-2] + offset
+				}
+				if got := Normalize(tt.value); got != want {
+					t.Errorf("Normalize(%q) = %q, want %q", tt.value, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseKeepsTheMoment(t *testing.T) {
+	const value = "2026-01-14T00:00:00+09:00"
+	moment := time.Date(2026, 1, 14, 0, 0, 0, 0, time.FixedZone("", 9*60*60))
+	for _, loc := range zones {
+		inZone(t, loc, func(t *testing.T) {
+			got, ok := Parse(value)
+			if !ok || !got.Equal(moment) {
+				t.Fatalf("Parse(%q) = %v, %v; want %v", value, got, ok, moment)
+			}
+			if got.Location() != time.Local {
+				t.Errorf("Parse(%q) is in %v, want local time", value, got.Location())
+			}
+			if stored := StoreTime(got); stored != got.Format(time.RFC3339) {
+				t.Errorf("StoreTime = %q, want the local offset", stored)
+			}
+		})
+	}
+}
+
+func TestStoreTimeKeepsOffset(t *testing.T) {
+	for _, loc := range zones {
+		inZone(t, loc, func(t *testing.T) {
+			when := time.Date(2026, 3, 1, 0, 0, 0, 0, time.FixedZone("", -3*60*60))
+			if got, want := StoreTime(when), "2026-03-01T00:00:00-03:00"; got != want {
+				t.Errorf("StoreTime = %q, want %q", got, want)
+			}
+			if got, want := StoreDay(when), "2026-03-01"; got != want {
+				t.Errorf("StoreDay = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestShowStored(t *testing.T) {
+	iso := FormatOf("iso")
+	for _, loc := range zones {
+		inZone(t, loc, func(t *testing.T) {
+			// A deadline at midnight somewhere keeps its time wherever it
+			// is shown.
+			when := time.Date(2026, 1, 14, 0, 0, 0, 0, time.UTC).In(loc)
+			tests := []struct {
+				value string
+				want  string
+			}{
+				{"2026-01-14", "2026-01-14"},
+				{"2026-01-14T00:00:00Z", when.Format("2006-01-02 15:04")},
+				{"Jan 14, 2026 @ 00:00", "2026-01-14 00:00"},
+				{"later", "later"},
+			}
+			for _, tt := range tests {
+				if got := iso.ShowStored(tt.value); got != tt.want {
+					t.Errorf("ShowStored(%q) = %q, want %q", tt.value, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeList(t *testing.T) {
+	if got := NormalizeList(nil); got != nil {
+		t.Errorf("NormalizeList(nil) = %v, want nil", got)
+	}
+	got := NormalizeList([]string{"Jan 2, 2026", "2026-01-03T08:00:00+02:00"})
+	want := []string{"2026-01-02", "2026-01-03T08:00:00+02:00"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("NormalizeList = %q, want %q", got, want)
+	}
+}
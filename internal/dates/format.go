@@ -0,0 +1,68 @@
+package dates
+
+import (
+	"strings"
+	"time"
+)
+
+// Format is a way of showing dates. Every layout can be typed back into a
+// form, so edit forms are filled in with it.
+type Format struct {
+	Name     string
+	Date     string // layout of a date without a time
+	DateTime string // layout of a date with a time
+}
+
+// Formats are the available display formats; the first is the default.
+var Formats = []Format{
+	{Name: "default", Date: "Jan 2, 2006", DateTime: "Jan 2, 2006 @ 15:04"},
+	{Name: "iso", Date: "2006-01-02", DateTime: "2006-01-02 15:04"},
+	{Name: "dotted", Date: "2.1.2006", DateTime: "2.1.2006 15:04"},
+}
+
+// FormatOf returns the named format, or the default for unknown names.
+func FormatOf(name string) Format {
+	for _, f := range Formats {
+		if f.Name == name {
+			return f
+		}
+	}
+	return Formats[0]
+}
+
+// Show renders t, leaving out the time when it is midnight (see AllDay);
+// stored dates are shown with ShowStored.
+func (f Format) Show(t time.Time) string {
+	if f.Date == "" {
+		f = Formats[0]
+	}
+	if AllDay(t) {
+		return t.Format(f.Date)
+	}
+	return t.Format(f.DateTime)
+}
+
+// ShowStored renders a stored date, with its time if it has one (see
+// IsDay); values that are not dates are shown as they are.
+func (f Format) ShowStored(value string) string {
+	t, day, ok := parse(value)
+	if !ok {
+		return value
+	}
+	if f.Date == "" {
+		f = Formats[0]
+	}
+	if day {
+		return t.Format(f.Date)
+	}
+	return t.Format(f.DateTime)
+}
+
+// ShowList renders stored dates separated by commas.
+func (f Format) ShowList(values []string) string {
+	shown := make([]string, len(values))
+	for i, value := range values {
+		shown[i] = f.ShowStored(value)
+	}
+	return strings.Join(shown, ", ")
+}
@@ -0,0 +1,252 @@
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// property is one content line: NAME;PARAM=VALUE;...:value
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse decodes the events and todos of an iCalendar document. Components
+// other than VEVENT and VTODO (time zones, alarms, journals) are ignored, as
+// are properties seman has no use for.
+func Parse(r io.Reader) (Calendar, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	var cal Calendar
+	var stack []string
+	var props []property
+	seenCalendar := false
+
+	for n, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		p, ok := parseProperty(line)
+		if !ok {
+			return Calendar{}, fmt.Errorf("line %d: malformed content line", n+1)
+		}
+		switch p.name {
+		case "BEGIN":
+			comp := strings.ToUpper(p.value)
+			if comp == "VCALENDAR" {
+				seenCalendar = true
+			}
+			stack = append(stack, comp)
+			if comp == "VEVENT" || comp == "VTODO" {
+				props = nil
+			}
+			continue
+		case "END":
+			comp := strings.ToUpper(p.value)
+			if len(stack) == 0 || stack[len(stack)-1] != comp {
+				return Calendar{}, fmt.Errorf("line %d: unexpected END:%s", n+1, p.value)
+			}
+			stack = stack[:len(stack)-1]
+			switch comp {
+			case "VEVENT":
+				cal.Events = append(cal.Events, buildEvent(props))
+			case "VTODO":
+				cal.Todos = append(cal.Todos, buildTodo(props))
+			}
+			continue
+		}
+
+		if len(stack) == 0 {
+			continue
+		}
+		switch stack[len(stack)-1] {
+		case "VEVENT", "VTODO":
+			props = append(props, p)
+		case "VCALENDAR":
+			if p.name == "X-WR-CALNAME" {
+				cal.Name = unescapeText(p.value)
+			}
+		}
+	}
+
+	if !seenCalendar {
+		return Calendar{}, fmt.Errorf("not an iCalendar file (no BEGIN:VCALENDAR)")
+	}
+	if len(stack) > 0 {
+		return Calendar{}, fmt.Errorf("unterminated %s", stack[len(stack)-1])
+	}
+	return cal, nil
+}
+
+// unfold joins continuation lines (those starting with a space or tab) to
+// the line before them.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+func parseProperty(line string) (property, bool) {
+	// The value starts at the first colon outside a quoted parameter value.
+	inQuote := false
+	split := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuote = !inQuote
+		} else if r == ':' && !inQuote {
+			split = i
+			break
+		}
+	}
+	if split <= 0 {
+		return property{}, false
+	}
+	head, value := line[:split], line[split+1:]
+	parts := strings.Split(head, ";")
+	p := property{name: strings.ToUpper(parts[0]), params: map[string]string{}, value: value}
+	for _, param := range parts[1:] {
+		key, val, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		p.params[strings.ToUpper(key)] = strings.Trim(val, `"`)
+	}
+	return p, true
+}
+
+func buildEvent(props []property) Event {
+	var ev Event
+	for _, p := range props {
+		switch p.name {
+		case "UID":
+			ev.UID = unescapeText(p.value)
+		case "SUMMARY":
+			ev.Summary = unescapeText(p.value)
+		case "DESCRIPTION":
+			ev.Description = unescapeText(p.value)
+		case "CATEGORIES":
+			ev.Categories = append(ev.Categories, splitList(p.value)...)
+		case "PRIORITY":
+			ev.Priority, _ = strconv.Atoi(strings.TrimSpace(p.value))
+		case "DTSTART":
+			if t, allDay, ok := parseDate(p); ok {
+				ev.Start, ev.AllDay = t, allDay
+			}
+		case "DTEND":
+			if t, _, ok := parseDate(p); ok {
+				ev.End = t
+			}
+		}
+	}
+	return ev
+}
+
+func buildTodo(props []property) Todo {
+	var td Todo
+	for _, p := range props {
+		switch p.name {
+		case "UID":
+			td.UID = unescapeText(p.value)
+		case "SUMMARY":
+			td.Summary = unescapeText(p.value)
+		case "DESCRIPTION":
+			td.Description = unescapeText(p.value)
+		case "CATEGORIES":
+			td.Categories = append(td.Categories, splitList(p.value)...)
+		case "PRIORITY":
+			td.Priority, _ = strconv.Atoi(strings.TrimSpace(p.value))
+		case "DUE":
+			if t, allDay, ok := parseDate(p); ok {
+				td.Due, td.DueAllDay = t, allDay
+			}
+		case "STATUS":
+			td.Done = strings.EqualFold(strings.TrimSpace(p.value), "COMPLETED")
+		case "RRULE":
+			td.RRule = strings.TrimSpace(p.value)
+		case "EXDATE":
+			for _, value := range strings.Split(p.value, ",") {
+				if t, _, ok := parseDate(property{name: p.name, params: p.params, value: value}); ok {
+					td.ExDates = append(td.ExDates, t)
+				}
+			}
+		}
+	}
+	return td
+}
+
+// parseDate reads a DATE or DATE-TIME value. UTC times end in Z, times with
+// a TZID are read in that zone when it is known, and floating times are
+// local. Results are returned in local time.
+func parseDate(p property) (time.Time, bool, bool) {
+	value := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.params["VALUE"], "DATE") || len(value) == len(dateLayout) {
+		t, err := time.ParseInLocation(dateLayout, value, time.Local)
+		return t, true, err == nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		return t.Local(), false, err == nil
+	}
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(dateTimeLayout, value, loc)
+	return t.Local(), false, err == nil
+}
+
+// splitList splits a comma-separated text list, honouring escaped commas.
+func splitList(value string) []string {
+	var out []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range value {
+		switch {
+		case escaped:
+			cur.WriteRune('\\')
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			if s := strings.TrimSpace(unescapeText(cur.String())); s != "" {
+				out = append(out, s)
+			}
+			cur.Reset()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if s := strings.TrimSpace(unescapeText(cur.String())); s != "" {
+		out = append(out, s)
+	}
+	return out
+}
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+
+func unescapeText(s string) string {
+	return textUnescaper.Replace(s)
+}
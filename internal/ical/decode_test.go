@@ -0,0 +1,146 @@
+package ical
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func parseFile(t *testing.T, name string) Calendar {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cal, err := Parse(f)
+	if err != nil {
+		t.Fatalf("Parse(%s): %v", name, err)
+	}
+	return cal
+}
+
+func TestParseFixture(t *testing.T) {
+	withZone(t)
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	cal := parseFile(t, "calendar.ics")
+	if cal.Name != "Winter term, 2026" {
+		t.Errorf("Name = %q", cal.Name)
+	}
+	if len(cal.Events) != 4 || len(cal.Todos) != 2 {
+		t.Fatalf("%d events and %d todos, want 4 and 2", len(cal.Events), len(cal.Todos))
+	}
+
+	utcEvent := cal.Events[0]
+	if utcEvent.UID != "utc@example.com" || utcEvent.Summary != "Databases: final exam" {
+		t.Errorf("UTC event = %+v", utcEvent)
+	}
+	if want := "Room 2.14; bring ID, pen\nand a calculator. Path C:\\exam"; utcEvent.Description != want {
+		t.Errorf("Description = %q, want %q (the alarm's must not replace it)", utcEvent.Description, want)
+	}
+	if want := []string{"DB", "Exam,written", "Finals"}; !reflect.DeepEqual(utcEvent.Categories, want) {
+		t.Errorf("Categories = %q, want %q", utcEvent.Categories, want)
+	}
+	if utcEvent.Priority != PriorityHigh || utcEvent.AllDay {
+		t.Errorf("Priority = %d, AllDay = %v", utcEvent.Priority, utcEvent.AllDay)
+	}
+	assertSameTime(t, "UTC start", utcEvent.Start, utc("20261020T080000Z"))
+	assertSameTime(t, "UTC end", utcEvent.End, utc("20261020T100000Z"))
+
+	// New York is on EST again by November 3.
+	tzEvent := cal.Events[1]
+	if want := "Operating systems midterm, folded over two lines"; tzEvent.Summary != want {
+		t.Errorf("folded Summary = %q, want %q", tzEvent.Summary, want)
+	}
+	assertSameTime(t, "TZID start", tzEvent.Start, utc("20261103T140000Z"))
+	assertSameTime(t, "TZID end", tzEvent.End, utc("20261103T153000Z"))
+
+	allDay := cal.Events[2]
+	if !allDay.AllDay {
+		t.Error("VALUE=DATE event is not all-day")
+	}
+	assertSameTime(t, "all-day start", allDay.Start, local("20261215"))
+	assertSameTime(t, "all-day end", allDay.End, local("20261217"))
+
+	floating := cal.Events[3]
+	if floating.Summary != "Office hours\n(floating time)" {
+		t.Errorf("Summary = %q", floating.Summary)
+	}
+	assertSameTime(t, "floating start", floating.Start, local("20261105T140000"))
+	if !floating.End.IsZero() {
+		t.Errorf("End = %v, want none", floating.End)
+	}
+
+	series := cal.Todos[0]
+	if series.RRule != "FREQ=WEEKLY;BYDAY=MO;COUNT=5" || !series.DueAllDay || series.Done || series.Priority != PriorityMedium {
+		t.Errorf("recurring todo = %+v", series)
+	}
+	assertSameTime(t, "todo due", series.Due, local("20261005"))
+	if len(series.ExDates) != 2 {
+		t.Fatalf("ExDates = %v, want 2", series.ExDates)
+	}
+	assertSameTime(t, "first EXDATE", series.ExDates[0], local("20261012"))
+	assertSameTime(t, "second EXDATE", series.ExDates[1], local("20261026"))
+
+	done := cal.Todos[1]
+	if !done.Done || done.DueAllDay || done.RRule != "" {
+		t.Errorf("done todo = %+v", done)
+	}
+	assertSameTime(t, "done due", done.Due, utc("20261018T220000Z"))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		data string
+		want string
+	}{
+		{"BEGIN:VEVENT\r\nEND:VEVENT\r\n", "not an iCalendar file"},
+		{"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nSUMMARY:x\r\n", "unterminated VEVENT"},
+		{"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VTODO\r\n", "line 3: unexpected END:VTODO"},
+		{"BEGIN:VCALENDAR\r\nno colon here\r\nEND:VCALENDAR\r\n", "line 2: malformed content line"},
+		{"BEGIN:VCALENDAR\r\n:value\r\nEND:VCALENDAR\r\n", "line 2: malformed content line"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) = %v, want an error with %q", tt.data, err, tt.want)
+		}
+	}
+}
+
+func TestParseLenientInput(t *testing.T) {
+	withZone(t)
+	// A byte order mark, bare newlines, lower-case names and a colon in a
+	// quoted parameter.
+	data := "\ufeffbegin:vcalendar\nbegin:vevent\nuid:a\ndtstart;x-note=\"at 9:00\":20261020T090000\nsummary:Lab\nend:vevent\nend:vcalendar\n"
+	cal, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cal.Events) != 1 || cal.Events[0].Summary != "Lab" {
+		t.Fatalf("Events = %+v", cal.Events)
+	}
+	assertSameTime(t, "start", cal.Events[0].Start, local("20261020T090000"))
+}
+
+func TestUnescapeText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`a\, b\; c`, "a, b; c"},
+		{`one\ntwo\Nthree`, "one\ntwo\nthree"},
+		{`C:\\new`, `C:\new`},
+		{`\\n`, `\n`},
+	}
+	for _, tt := range tests {
+		if got := unescapeText(tt.value); got != tt.want {
+			t.Errorf("unescapeText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
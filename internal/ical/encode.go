@@ -0,0 +1,137 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxLineOctets = 75
+
+// Marshal encodes cal as an iCalendar document. stamp is written as the
+// DTSTAMP of every component.
+func Marshal(cal Calendar, stamp time.Time) []byte {
+	w := &writer{}
+	w.line("BEGIN:VCALENDAR")
+	w.line("VERSION:2.0")
+	w.line("PRODID:-//seman//Student Manager//EN")
+	w.line("CALSCALE:GREGORIAN")
+	if cal.Name != "" {
+		w.line("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+	dtstamp := "DTSTAMP:" + stamp.UTC().Format(utcLayout)
+
+	for _, ev := range cal.Events {
+		w.line("BEGIN:VEVENT")
+		w.line("UID:" + escapeText(ev.UID))
+		w.line(dtstamp)
+		if ev.AllDay {
+			w.line("DTSTART;VALUE=DATE:" + ev.Start.Format(dateLayout))
+			end := ev.End
+			if end.IsZero() || !end.After(ev.Start) {
+				end = ev.Start.AddDate(0, 0, 1)
+			}
+			w.line("DTEND;VALUE=DATE:" + end.Format(dateLayout))
+		} else {
+			w.line("DTSTART:" + ev.Start.UTC().Format(utcLayout))
+			if !ev.End.IsZero() {
+				w.line("DTEND:" + ev.End.UTC().Format(utcLayout))
+			}
+		}
+		w.common(ev.Summary, ev.Description, ev.Categories, ev.Priority)
+		w.line("END:VEVENT")
+	}
+
+	for _, td := range cal.Todos {
+		w.line("BEGIN:VTODO")
+		w.line("UID:" + escapeText(td.UID))
+		w.line(dtstamp)
+		if !td.Due.IsZero() {
+			if td.RRule != "" {
+				// Recurrence is anchored on DTSTART, which a VTODO may omit.
+				w.date("DTSTART", td.Due, td.DueAllDay)
+			}
+			w.date("DUE", td.Due, td.DueAllDay)
+			if td.RRule != "" {
+				w.line("RRULE:" + td.RRule)
+				for _, ex := range td.ExDates {
+					w.date("EXDATE", ex, td.DueAllDay)
+				}
+			}
+		}
+		w.common(td.Summary, td.Description, td.Categories, td.Priority)
+		if td.Done {
+			w.line("STATUS:COMPLETED")
+		} else {
+			w.line("STATUS:NEEDS-ACTION")
+		}
+		w.line("END:VTODO")
+	}
+
+	w.line("END:VCALENDAR")
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf bytes.Buffer
+}
+
+// date writes a DATE property, or a DATE-TIME one in UTC.
+func (w *writer) date(name string, t time.Time, allDay bool) {
+	if allDay {
+		w.line(name + ";VALUE=DATE:" + t.Format(dateLayout))
+		return
+	}
+	w.line(name + ":" + t.UTC().Format(utcLayout))
+}
+
+func (w *writer) common(summary, description string, categories []string, priority int) {
+	w.line("SUMMARY:" + escapeText(summary))
+	if description != "" {
+		w.line("DESCRIPTION:" + escapeText(description))
+	}
+	if len(categories) > 0 {
+		escaped := make([]string, len(categories))
+		for i, c := range categories {
+			escaped[i] = escapeText(c)
+		}
+		w.line("CATEGORIES:" + strings.Join(escaped, ","))
+	}
+	if priority != PriorityNone {
+		w.line("PRIORITY:" + strconv.Itoa(priority))
+	}
+}
+
+// line writes a content line, folding it at 75 octets without splitting a
+// UTF-8 sequence.
+func (w *writer) line(s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.buf.WriteString(s[:cut])
+		w.buf.WriteString("\r\n ")
+		s = s[cut:]
+		// Continuation lines start with a space, which counts towards the limit.
+		limit = maxLineOctets - 1
+	}
+	w.buf.WriteString(s)
+	w.buf.WriteString("\r\n")
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+	"\r", `\n`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
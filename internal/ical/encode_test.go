@@ -0,0 +1,213 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var stamp = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+// exportCalendar has a component of every kind Marshal writes.
+func exportCalendar() Calendar {
+	return Calendar{
+		Name: "Winter term; seman",
+		Events: []Event{
+			{
+				UID:         "exam-1@seman",
+				Summary:     "Databases, final",
+				Description: "Room 2.14\nBring C:\\notes; no phones",
+				Categories:  []string{"DB", "Exam,written"},
+				Start:       utc("20261020T080000Z"),
+				End:         utc("20261020T100000Z"),
+				Priority:    PriorityHigh,
+			},
+			{
+				UID:     "week@seman",
+				Summary: "Project week",
+				Start:   local("20261215"),
+				End:     local("20261217"),
+				AllDay:  true,
+			},
+			{
+				UID:     "deadline@seman",
+				Summary: "Hand-in",
+				Start:   local("20261109"),
+				AllDay:  true,
+			},
+			{
+				UID:         "long@seman",
+				Summary:     "Übung: Verteilte Systeme – Präsentation der Gruppenergebnisse und Diskussion",
+				Description: strings.Repeat("žluťoučký kůň ", 8),
+				Start:       local("20261105T140000"),
+			},
+		},
+		Todos: []Todo{
+			{
+				UID:       "sheet@seman",
+				Summary:   "Weekly sheet",
+				Due:       local("20261005"),
+				DueAllDay: true,
+				Priority:  PriorityMedium,
+				RRule:     "FREQ=WEEKLY;BYDAY=MO;COUNT=5",
+				ExDates:   []time.Time{local("20261012"), local("20261026")},
+			},
+			{
+				UID:     "lab@seman",
+				Summary: "Lab report",
+				Due:     local("20261007T235900"),
+				RRule:   "FREQ=WEEKLY;INTERVAL=2;UNTIL=20261216",
+				ExDates: []time.Time{local("20261021T235900")},
+			},
+			{
+				UID:     "register@seman",
+				Summary: "Register for exams",
+				Due:     utc("20261018T220000Z"),
+				Done:    true,
+			},
+			{
+				UID:     "someday@seman",
+				Summary: "Read the syllabus",
+			},
+		},
+	}
+}
+
+func TestMarshalGolden(t *testing.T) {
+	withZone(t)
+	got := Marshal(exportCalendar(), stamp)
+	path := filepath.Join("testdata", "export.ics")
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to write it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("export.ics differs:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarshalFoldsLines(t *testing.T) {
+	withZone(t)
+	out := Marshal(exportCalendar(), stamp)
+	if !bytes.HasSuffix(out, []byte("\r\n")) {
+		t.Fatal("the document does not end with CRLF")
+	}
+	folded := 0
+	for n, line := range strings.Split(strings.TrimSuffix(string(out), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d has %d octets: %q", n+1, len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("line %d splits a UTF-8 sequence: %q", n+1, line)
+		}
+		if strings.ContainsAny(line, "\r\n") {
+			t.Errorf("line %d holds a line break: %q", n+1, line)
+		}
+		if strings.HasPrefix(line, " ") {
+			folded++
+		}
+	}
+	if folded == 0 {
+		t.Error("no line was folded")
+	}
+}
+
+func TestFoldUnfold(t *testing.T) {
+	for _, s := range []string{
+		"",
+		strings.Repeat("a", maxLineOctets),
+		strings.Repeat("a", maxLineOctets+1),
+		strings.Repeat("b", 3*maxLineOctets),
+		strings.Repeat("é", 100),
+		"x" + strings.Repeat("😀", 40),
+	} {
+		w := &writer{}
+		w.line(s)
+		lines, err := unfold(bytes.NewReader(w.buf.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(lines) != 1 || lines[0] != s {
+			t.Errorf("fold and unfold of %d octets = %q", len(s), lines)
+		}
+	}
+}
+
+// TestRoundTrip parses what Marshal writes back into the same calendar.
+func TestRoundTrip(t *testing.T) {
+	withZone(t)
+	want := exportCalendar()
+	got, err := Parse(bytes.NewReader(Marshal(want, stamp)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An all-day event without an end is written as lasting a day.
+	want.Events[2].End = local("20261110")
+
+	if got.Name != want.Name {
+		t.Errorf("Name = %q, want %q", got.Name, want.Name)
+	}
+	if len(got.Events) != len(want.Events) || len(got.Todos) != len(want.Todos) {
+		t.Fatalf("%d events and %d todos, want %d and %d", len(got.Events), len(got.Todos), len(want.Events), len(want.Todos))
+	}
+	for i, ev := range got.Events {
+		w := want.Events[i]
+		assertSameTime(t, w.UID+" start", ev.Start, w.Start)
+		assertSameTime(t, w.UID+" end", ev.End, w.End)
+		ev.Start, ev.End, w.Start, w.End = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+		if !reflect.DeepEqual(ev, w) {
+			t.Errorf("event %d =\n%+v\nwant\n%+v", i, ev, w)
+		}
+	}
+	for i, td := range got.Todos {
+		w := want.Todos[i]
+		assertSameTime(t, w.UID+" due", td.Due, w.Due)
+		if len(td.ExDates) != len(w.ExDates) {
+			t.Errorf("%s: ExDates = %v, want %v", w.UID, td.ExDates, w.ExDates)
+		} else {
+			for j := range td.ExDates {
+				assertSameTime(t, w.UID+" EXDATE", td.ExDates[j], w.ExDates[j])
+			}
+		}
+		td.Due, td.ExDates, w.Due, w.ExDates = time.Time{}, nil, time.Time{}, nil
+		if !reflect.DeepEqual(td, w) {
+			t.Errorf("todo %d =\n%+v\nwant\n%+v", i, td, w)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	newlines := strings.NewReplacer("\r\n", "\n", "\r", "\n")
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"a, b; c", `a\, b\; c`},
+		{"one\ntwo\r\nthree\rfour", `one\ntwo\nthree\nfour`},
+		{`C:\new`, `C:\\new`},
+		{"plain: text", "plain: text"},
+	}
+	for _, tt := range tests {
+		got := escapeText(tt.value)
+		if got != tt.want {
+			t.Errorf("escapeText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if back := unescapeText(got); back != newlines.Replace(tt.value) {
+			t.Errorf("unescapeText(%q) = %q, want %q", got, back, tt.value)
+		}
+	}
+}
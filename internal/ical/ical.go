@@ -0,0 +1,56 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) that seman
+// exchanges with calendar apps: events and todos with a summary, categories,
+// priority and either a timed or an all-day date.
+package ical
+
+import "time"
+
+// Event is a VEVENT. When AllDay is set only the date part of Start is used.
+// A zero End is omitted.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Priority    int
+}
+
+// Todo is a VTODO. When DueAllDay is set only the date part of Due is used.
+// A todo with an RRule (without the "RRULE:" prefix) repeats from Due,
+// except on ExDates.
+type Todo struct {
+	UID         string
+	Summary     string
+	Description string
+	Categories  []string
+	Due         time.Time
+	DueAllDay   bool
+	Priority    int
+	Done        bool
+	RRule       string
+	ExDates     []time.Time
+}
+
+// Calendar is a VCALENDAR holding events and todos.
+type Calendar struct {
+	Name   string
+	Events []Event
+	Todos  []Todo
+}
+
+// iCalendar PRIORITY values: 1 is highest, 9 lowest, 0 undefined.
+const (
+	PriorityNone   = 0
+	PriorityHigh   = 1
+	PriorityMedium = 5
+	PriorityLow    = 9
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+)
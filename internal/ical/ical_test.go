@@ -0,0 +1,44 @@
+package ical
+
+import (
+	"testing"
+	"time"
+)
+
+// withZone reads and writes floating and all-day dates in a fixed time zone.
+func withZone(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("CET", 60*60)
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func utc(value string) time.Time {
+	t, err := time.Parse(utcLayout, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func local(value string) time.Time {
+	layout := dateTimeLayout
+	if len(value) == len(dateLayout) {
+		layout = dateLayout
+	}
+	t, err := time.ParseInLocation(layout, value, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// assertSameTime compares instants and reports the field that differs.
+func assertSameTime(t *testing.T, field string, got, want time.Time) {
+	t.Helper()
+	if !got.Equal(want) {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+	if !got.IsZero() && got.Location() != time.Local {
+		t.Errorf("%s is in %v, want local time", field, got.Location())
+	}
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// CalendarKind tells what a CalendarEntry is.
+type CalendarKind int
+
+const (
+	CalendarExam CalendarKind = iota
+	CalendarRetake
+	CalendarProject
+	CalendarMilestone
+	CalendarTodo
+)
+
+// CalendarEntry is something on a day of the Calendar tab: an exam, a
+// retake, a project deadline or milestone, or a todo.
+type CalendarEntry struct {
+	Kind        CalendarKind
+	Date        time.Time
+	HasTime     bool
+	Title       string
+	SubjectCode string
+	Priority    string // resolved priority of exams and retakes
+	Done        bool   // finished todos, projects and milestones
+	ID          string // of the exam, project, milestone or todo
+	Day         string // occurrence of a recurring todo, 2006-01-02
+}
+
+// OnDay reports whether the entry falls on the given day.
+func (e CalendarEntry) OnDay(day time.Time) bool {
+	y1, m1, d1 := e.Date.Date()
+	y2, m2, d2 := day.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
@@ -0,0 +1,279 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ExamResult is the outcome of one attempt at an exam. Attempt is stored
+// from 0: attempt 0 is the original date and attempt n the n-th retake; the
+// interface and the command line number attempts from 1 (see Number). A
+// result may carry a grade, points, both or neither (a plain pass or fail).
+// Scale is the grading scale the grade was given on, so it keeps its meaning
+// when the scale changes.
+type ExamResult struct {
+	Attempt   int     `json:"attempt"`
+	Grade     string  `json:"grade,omitempty"`
+	Scale     string  `json:"scale,omitempty"`
+	Points    float64 `json:"points,omitempty"`
+	MaxPoints float64 `json:"max_points,omitempty"`
+	Passed    bool    `json:"passed"`
+}
+
+// Number is the attempt as users see and enter it: 1 for the original date,
+// 2 for the first retake and so on.
+func (r ExamResult) Number() int {
+	return r.Attempt + 1
+}
+
+// GradeBand is one grade of a scale: every percentage from Min up to the next
+// better band gets this grade.
+type GradeBand struct {
+	Grade string
+	Min   float64
+	Value float64 // counted towards the GPA
+	Pass  bool
+}
+
+// GradingScale turns percentages into grades and grade values. A scale
+// without bands (percent) uses the percentage itself as grade and value.
+type GradingScale struct {
+	Name        string
+	Bands       []GradeBand // best first
+	PassPercent float64     // only used without bands
+}
+
+// DefaultGradingScale is used when none is configured.
+const DefaultGradingScale = "A-F"
+
+// GradingScaleNames is the ordered list of available grading scales.
+var GradingScaleNames = []string{"A-F", "1-5", "percent"}
+
+var gradingScales = map[string]GradingScale{
+	"A-F": {
+		Name: "A-F",
+		Bands: []GradeBand{
+			{Grade: "A", Min: 90, Value: 4, Pass: true},
+			{Grade: "B", Min: 80, Value: 3, Pass: true},
+			{Grade: "C", Min: 70, Value: 2, Pass: true},
+			{Grade: "D", Min: 60, Value: 1, Pass: true},
+			{Grade: "F", Min: 0, Value: 0},
+		},
+	},
+	"1-5": {
+		Name: "1-5",
+		Bands: []GradeBand{
+			{Grade: "1", Min: 90, Value: 1, Pass: true},
+			{Grade: "2", Min: 80, Value: 2, Pass: true},
+			{Grade: "3", Min: 70, Value: 3, Pass: true},
+			{Grade: "4", Min: 60, Value: 4, Pass: true},
+			{Grade: "5", Min: 0, Value: 5},
+		},
+	},
+	"percent": {
+		Name:        "percent",
+		PassPercent: 50,
+	},
+}
+
+// GradingScaleOf returns the named scale, or the default for unknown names.
+func GradingScaleOf(name string) GradingScale {
+	if s, ok := gradingScales[name]; ok {
+		return s
+	}
+	return gradingScales[DefaultGradingScale]
+}
+
+// band returns the band a percentage falls into.
+func (s GradingScale) band(percent float64) GradeBand {
+	for _, b := range s.Bands {
+		if percent >= b.Min {
+			return b
+		}
+	}
+	return s.Bands[len(s.Bands)-1]
+}
+
+// Grade names the grade for a percentage.
+func (s GradingScale) Grade(percent float64) string {
+	if len(s.Bands) == 0 {
+		return strconv.FormatFloat(math.Round(percent*10)/10, 'f', -1, 64) + "%"
+	}
+	return s.band(percent).Grade
+}
+
+// Value is what a percentage counts towards the GPA.
+func (s GradingScale) Value(percent float64) float64 {
+	if len(s.Bands) == 0 {
+		return percent
+	}
+	return s.band(percent).Value
+}
+
+// Passes reports whether a percentage is a passing result.
+func (s GradingScale) Passes(percent float64) bool {
+	if len(s.Bands) == 0 {
+		return percent >= s.PassPercent
+	}
+	return s.band(percent).Pass
+}
+
+// Percent reads a grade of this scale as a percentage: the middle of its
+// band, so that the grade survives the round trip through Grade.
+func (s GradingScale) Percent(grade string) (float64, bool) {
+	grade = strings.TrimSpace(grade)
+	if len(s.Bands) == 0 {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(grade, "%"), 64)
+		if err != nil || v < 0 || v > 100 {
+			return 0, false
+		}
+		return v, true
+	}
+	upper := 100.0
+	for _, b := range s.Bands {
+		if strings.EqualFold(b.Grade, grade) {
+			return (b.Min + upper) / 2, true
+		}
+		upper = b.Min
+	}
+	return 0, false
+}
+
+// FormatValue renders a GPA on this scale.
+func (s GradingScale) FormatValue(v float64) string {
+	if len(s.Bands) == 0 {
+		return fmt.Sprintf("%.1f%%", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+// Percent returns the result as a percentage, from its points when it has
+// them and from its grade otherwise. Plain pass/fail results have none. scale
+// is only used for grades that do not record their own.
+func (r ExamResult) Percent(scale GradingScale) (float64, bool) {
+	if r.MaxPoints > 0 {
+		return r.Points / r.MaxPoints * 100, true
+	}
+	if r.Grade != "" {
+		if r.Scale != "" {
+			scale = GradingScaleOf(r.Scale)
+		}
+		return scale.Percent(r.Grade)
+	}
+	return 0, false
+}
+
+// Result returns the result recorded for the given attempt.
+func (e ExamItem) Result(attempt int) (ExamResult, bool) {
+	for _, r := range e.Results {
+		if r.Attempt == attempt {
+			return r, true
+		}
+	}
+	return ExamResult{}, false
+}
+
+// FinalResult is the result of the latest attempt that has one; it is what
+// counts towards the subject grade.
+func (e ExamItem) FinalResult() (ExamResult, bool) {
+	var final ExamResult
+	found := false
+	for _, r := range e.Results {
+		if !found || r.Attempt > final.Attempt {
+			final, found = r, true
+		}
+	}
+	return final, found
+}
+
+// SubjectGrade is a subject's grade computed from its exams.
+type SubjectGrade struct {
+	Percent  float64
+	Graded   bool // at least one exam has a graded result
+	Complete bool // every exam has a result
+	Passed   bool // every exam with a result was passed
+}
+
+// ExamWeights returns each exam's share of the subject grade in percent.
+// Exams without a weight split what the weighted ones leave of 100 equally.
+func ExamWeights(exams []ExamItem) []float64 {
+	weights := make([]float64, len(exams))
+	fixed, open := 0.0, 0
+	for _, e := range exams {
+		if e.Weight > 0 {
+			fixed += e.Weight
+		} else {
+			open++
+		}
+	}
+	share := 0.0
+	if open > 0 {
+		share = math.Max(0, 100-fixed) / float64(open)
+	}
+	for i, e := range exams {
+		weights[i] = e.Weight
+		if e.Weight <= 0 {
+			weights[i] = share
+		}
+	}
+	return weights
+}
+
+// GradeSubject combines the final results of a subject's exams, weighted by
+// ExamWeights. Until every exam is graded the percentage is the weighted mean
+// of the graded ones.
+func GradeSubject(s SubjectItem, scale GradingScale) SubjectGrade {
+	g := SubjectGrade{Complete: len(s.Exams) > 0, Passed: true}
+	weights := ExamWeights(s.Exams)
+	sum, total := 0.0, 0.0
+	for i, e := range s.Exams {
+		r, ok := e.FinalResult()
+		if !ok {
+			g.Complete = false
+			continue
+		}
+		if !r.Passed {
+			g.Passed = false
+		}
+		if p, ok := r.Percent(scale); ok && weights[i] > 0 {
+			sum += p * weights[i]
+			total += weights[i]
+		}
+	}
+	if total > 0 {
+		g.Percent = sum / total
+		g.Graded = true
+	}
+	return g
+}
+
+// GPA is the mean grade value of the graded subjects, weighted by credits.
+// When no subject has credits every subject counts once; otherwise subjects
+// without credits are left out.
+func GPA(subjects []SubjectItem, scale GradingScale) (float64, bool) {
+	anyCredits := false
+	for _, s := range subjects {
+		if s.Credits > 0 {
+			anyCredits = true
+		}
+	}
+	sum, total := 0.0, 0.0
+	for _, s := range subjects {
+		g := GradeSubject(s, scale)
+		if !g.Graded {
+			continue
+		}
+		weight := 1.0
+		if anyCredits {
+			weight = s.Credits
+		}
+		sum += scale.Value(g.Percent) * weight
+		total += weight
+	}
+	if total == 0 {
+		return 0, false
+	}
+	return sum / total, true
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGradingScaleBands(t *testing.T) {
+	tests := []struct {
+		scale   string
+		percent float64
+		grade   string
+		value   float64
+		pass    bool
+	}{
+		{"A-F", 100, "A", 4, true},
+		{"A-F", 90, "A", 4, true},
+		{"A-F", 89.99, "B", 3, true},
+		{"A-F", 80, "B", 3, true},
+		{"A-F", 70, "C", 2, true},
+		{"A-F", 60, "D", 1, true},
+		{"A-F", 59.9, "F", 0, false},
+		{"A-F", 0, "F", 0, false},
+		{"A-F", -5, "F", 0, false},
+		{"1-5", 95, "1", 1, true},
+		{"1-5", 79.5, "3", 3, true},
+		{"1-5", 60, "4", 4, true},
+		{"1-5", 12, "5", 5, false},
+		{"percent", 72.25, "72.3%", 72.25, true},
+		{"percent", 50, "50%", 50, true},
+		{"percent", 49.9, "49.9%", 49.9, false},
+		{"unknown", 85, "B", 3, true}, // the default scale
+	}
+	for _, tt := range tests {
+		s := GradingScaleOf(tt.scale)
+		if got := s.Grade(tt.percent); got != tt.grade {
+			t.Errorf("%s: Grade(%v) = %q, want %q", tt.scale, tt.percent, got, tt.grade)
+		}
+		if got := s.Value(tt.percent); got != tt.value {
+			t.Errorf("%s: Value(%v) = %v, want %v", tt.scale, tt.percent, got, tt.value)
+		}
+		if got := s.Passes(tt.percent); got != tt.pass {
+			t.Errorf("%s: Passes(%v) = %v, want %v", tt.scale, tt.percent, got, tt.pass)
+		}
+	}
+}
+
+func TestGradingScalePercent(t *testing.T) {
+	tests := []struct {
+		scale string
+		grade string
+		want  float64
+		ok    bool
+	}{
+		{"A-F", "A", 95, true},
+		{"A-F", " b ", 85, true},
+		{"A-F", "F", 30, true},
+		{"A-F", "E", 0, false},
+		{"1-5", "2", 85, true},
+		{"1-5", "6", 0, false},
+		{"percent", "72.5%", 72.5, true},
+		{"percent", "72.5", 72.5, true},
+		{"percent", "101", 0, false},
+		{"percent", "-1", 0, false},
+		{"percent", "A", 0, false},
+	}
+	for _, tt := range tests {
+		s := GradingScaleOf(tt.scale)
+		got, ok := s.Percent(tt.grade)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: Percent(%q) = %v, %v; want %v, %v", tt.scale, tt.grade, got, ok, tt.want, tt.ok)
+		}
+		// A grade read as a percentage must come back as the same grade.
+		if ok && len(s.Bands) > 0 {
+			if back := s.Grade(got); !strings.EqualFold(back, strings.TrimSpace(tt.grade)) {
+				t.Errorf("%s: Grade(Percent(%q)) = %q", tt.scale, tt.grade, back)
+			}
+		}
+	}
+}
+
+func TestExamResultPercent(t *testing.T) {
+	af := GradingScaleOf("A-F")
+	tests := []struct {
+		name   string
+		result ExamResult
+		want   float64
+		ok     bool
+	}{
+		{"points win over the grade", ExamResult{Grade: "A", Points: 36, MaxPoints: 48}, 75, true},
+		{"grade on the given scale", ExamResult{Grade: "C"}, 75, true},
+		{"grade keeps its own scale", ExamResult{Grade: "2", Scale: "1-5"}, 85, true},
+		{"pass without grade", ExamResult{Passed: true}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.result.Percent(af)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: Percent = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExamAttempts(t *testing.T) {
+	exam := ExamItem{Results: []ExamResult{
+		{Attempt: 1, Grade: "C", Passed: true},
+		{Attempt: 0, Grade: "F"},
+	}}
+	if r, ok := exam.Result(0); !ok || r.Grade != "F" || r.Number() != 1 {
+		t.Errorf("Result(0) = %+v, %v; want the F of attempt number 1", r, ok)
+	}
+	if _, ok := exam.Result(2); ok {
+		t.Error("Result(2) found a result")
+	}
+	if r, ok := exam.FinalResult(); !ok || r.Grade != "C" || r.Number() != 2 {
+		t.Errorf("FinalResult = %+v, %v; want the C of attempt number 2", r, ok)
+	}
+	if _, ok := (ExamItem{}).FinalResult(); ok {
+		t.Error("FinalResult of an exam without results found one")
+	}
+}
+
+func TestExamWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		weights []float64
+		want    []float64
+	}{
+		{"none", nil, []float64{}},
+		{"equal shares", []float64{0, 0, 0, 0}, []float64{25, 25, 25, 25}},
+		{"the rest is shared", []float64{40, 0, 0}, []float64{40, 30, 30}},
+		{"all weighted", []float64{70, 30}, []float64{70, 30}},
+		{"weights over 100 leave nothing", []float64{80, 40, 0}, []float64{80, 40, 0}},
+	}
+	for _, tt := range tests {
+		exams := make([]ExamItem, len(tt.weights))
+		for i, w := range tt.weights {
+			exams[i].Weight = w
+		}
+		if got := ExamWeights(exams); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ExamWeights = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// graded returns an exam whose final result is the given percentage.
+func graded(weight, percent float64, passed bool) ExamItem {
+	return ExamItem{Weight: weight, Results: []ExamResult{{Points: percent, MaxPoints: 100, Passed: passed}}}
+}
+
+func TestGradeSubject(t *testing.T) {
+	tests := []struct {
+		name  string
+		exams []ExamItem
+		want  SubjectGrade
+	}{
+		{"no exams", nil, SubjectGrade{Passed: true}},
+		{"no results", []ExamItem{{}}, SubjectGrade{Passed: true}},
+		{
+			"equal weights",
+			[]ExamItem{graded(0, 90, true), graded(0, 70, true)},
+			SubjectGrade{Percent: 80, Graded: true, Complete: true, Passed: true},
+		},
+		{
+			"weighted",
+			[]ExamItem{graded(75, 80, true), graded(25, 40, true)},
+			SubjectGrade{Percent: 70, Graded: true, Complete: true, Passed: true},
+		},
+		{
+			"a share of the rest",
+			[]ExamItem{graded(60, 100, true), graded(0, 50, true), graded(0, 50, true)},
+			SubjectGrade{Percent: 80, Graded: true, Complete: true, Passed: true},
+		},
+		{
+			"ungraded exams do not count until graded",
+			[]ExamItem{graded(30, 60, true), {Weight: 70}},
+			SubjectGrade{Percent: 60, Graded: true, Passed: true},
+		},
+		{
+			"a failed exam fails the subject",
+			[]ExamItem{graded(0, 95, true), graded(0, 45, false)},
+			SubjectGrade{Percent: 70, Graded: true, Complete: true},
+		},
+		{
+			"the retake counts",
+			[]ExamItem{{Results: []ExamResult{{Attempt: 0, Grade: "F"}, {Attempt: 1, Grade: "B", Passed: true}}}},
+			SubjectGrade{Percent: 85, Graded: true, Complete: true, Passed: true},
+		},
+		{
+			"plain passes complete without a grade",
+			[]ExamItem{{Results: []ExamResult{{Passed: true}}}},
+			SubjectGrade{Complete: true, Passed: true},
+		},
+	}
+	for _, tt := range tests {
+		got := GradeSubject(SubjectItem{Exams: tt.exams}, GradingScaleOf("A-F"))
+		got.Percent = math.Round(got.Percent*1e9) / 1e9
+		if got != tt.want {
+			t.Errorf("%s: GradeSubject = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGPA(t *testing.T) {
+	subject := func(credits, percent float64) SubjectItem {
+		return SubjectItem{Credits: credits, Exams: []ExamItem{graded(0, percent, true)}}
+	}
+	tests := []struct {
+		name     string
+		scale    string
+		subjects []SubjectItem
+		want     float64
+		ok       bool
+	}{
+		{"nothing graded", "A-F", []SubjectItem{{Credits: 6, Exams: []ExamItem{{}}}}, 0, false},
+		{"no subjects", "A-F", nil, 0, false},
+		{"without credits every subject counts once", "A-F", []SubjectItem{subject(0, 95), subject(0, 75)}, 3, true},
+		{"weighted by credits", "A-F", []SubjectItem{subject(6, 95), subject(2, 65)}, 3.25, true},
+		{"subjects without credits are left out", "A-F", []SubjectItem{subject(4, 85), subject(0, 10)}, 3, true},
+		{"ungraded subjects are left out", "A-F", []SubjectItem{subject(4, 85), {Credits: 8, Exams: []ExamItem{{}}}}, 3, true},
+		{"1-5", "1-5", []SubjectItem{subject(5, 92), subject(5, 71)}, 2, true},
+		{"percent", "percent", []SubjectItem{subject(3, 90), subject(1, 50)}, 80, true},
+	}
+	for _, tt := range tests {
+		got, ok := GPA(tt.subjects, GradingScaleOf(tt.scale))
+		if math.Abs(got-tt.want) > 1e-9 || ok != tt.ok {
+			t.Errorf("%s: GPA = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
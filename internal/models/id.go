@@ -0,0 +1,21 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// NewID returns a random identifier for a subject, exam, project or todo.
+// IDs are 16 hex characters, unique for all practical purposes and stable
+// for the lifetime of the item, so they can be referenced from outside.
+func NewID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to
+		// the clock rather than returning a duplicate-prone empty ID.
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
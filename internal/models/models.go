@@ -1,24 +1,53 @@
 package models
 
+import (
+	"math"
+	"strings"
+	"time"
+)
+
 type ChecklistItem struct {
-	Text    string
-	Done    bool
-	Due     string
-	Subject string
+	ID      string `json:"id"`
+	Text    string `json:"text"`
+	Done    bool   `json:"done"`
+	Due     string `json:"due"`
+	Subject string `json:"subject"`
+	// Repeat is the RRULE of a recurring todo (see Recurrence), which
+	// repeats from Due. Its occurrences are not stored; DoneDates and
+	// Skipped list those ticked off or removed, by date (2006-01-02).
+	Repeat    string   `json:"repeat,omitempty"`
+	DoneDates []string `json:"done_dates,omitempty"`
+	Skipped   []string `json:"skipped,omitempty"`
 }
 
 type ProjectItem struct {
-	Name    string
-	Subject string
-	Due     string
-	Status  string
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Due     string `json:"due"`
+	Status  string `json:"status"`
+	// Start is the optional first day of work, 2006-01-02; the timeline
+	// draws the project from it to Due.
+	Start string `json:"start,omitempty"`
+	// AutoStatus makes Status follow the subtasks (see SyncStatus).
+	AutoStatus bool        `json:"auto_status,omitempty"`
+	Subtasks   []Subtask   `json:"subtasks,omitempty"`
+	Milestones []Milestone `json:"milestones,omitempty"`
 }
 
 type ExamItem struct {
-	Name     string
-	Date     string
-	Retakes  []string
-	Priority string
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Date     string   `json:"date"`
+	Retakes  []string `json:"retakes"`
+	Priority string   `json:"priority"`
+	// SourceUID is the UID of the calendar event the exam was imported
+	// from, used to update it when the same calendar is imported again.
+	SourceUID string `json:"source_uid,omitempty"`
+	// Weight is the exam's share of the subject grade in percent; 0 means
+	// an equal share of whatever the weighted exams leave.
+	Weight  float64      `json:"weight,omitempty"`
+	Results []ExamResult `json:"results,omitempty"`
 }
 
 type FlatExam struct {
@@ -29,9 +58,26 @@ type FlatExam struct {
 }
 
 type SubjectItem struct {
-	Code  string
-	Name  string
-	Exams []ExamItem
+	ID    string     `json:"id"`
+	Code  string     `json:"code"`
+	Name  string     `json:"name"`
+	Exams []ExamItem `json:"exams"`
+	// Credits are the ECTS or credit hours the subject is worth.
+	Credits float64 `json:"credits,omitempty"`
+	// Sessions are the subject's weekly lectures, labs and seminars.
+	Sessions []ClassSession `json:"sessions,omitempty"`
+	// Notes are free-form markdown: the syllabus, contacts, grading rules.
+	Notes string `json:"notes,omitempty"`
+	// Resources are links and files kept with the subject.
+	Resources []Resource `json:"resources,omitempty"`
+}
+
+// Resource is a link or file of a subject. Target is a URL or a path, opened
+// with the system's opener.
+type Resource struct {
+	ID     string `json:"id"`
+	Title  string `json:"title"`
+	Target string `json:"target"`
 }
 
 type LofiTrack struct {
@@ -40,3 +86,50 @@ type LofiTrack struct {
 }
 
 const LofiVisibleCap = 8
+
+// PriorityAuto marks an exam whose priority is derived from PriorityRules.
+const PriorityAuto = "AUTO"
+
+// PriorityRules are the thresholds used to derive the priority of exams set
+// to AUTO from how many days remain until the exam.
+type PriorityRules struct {
+	HighDays    int  `json:"high_days"`
+	MedDays     int  `json:"med_days"`
+	AutoDefault bool `json:"auto_default"`
+}
+
+// DefaultPriorityRules returns the thresholds used when none are configured.
+func DefaultPriorityRules() PriorityRules {
+	return PriorityRules{HighDays: 7, MedDays: 14}
+}
+
+// IsAutoPriority reports whether priority asks for rule-based derivation.
+func IsAutoPriority(priority string) bool {
+	return strings.EqualFold(strings.TrimSpace(priority), PriorityAuto)
+}
+
+// Resolve returns the effective priority level. Manual priorities are
+// returned unchanged; AUTO becomes HIGH, MED or LOW depending on how many
+// days remain until date. Exams without a usable date, and exams already in
+// the past, resolve to LOW.
+func (r PriorityRules) Resolve(priority string, date time.Time, hasDate bool, now time.Time) string {
+	if !IsAutoPriority(priority) {
+		return priority
+	}
+	if !hasDate {
+		return "LOW"
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, now.Location())
+	days := int(math.Round(day.Sub(today).Hours() / 24))
+	switch {
+	case days < 0:
+		return "LOW"
+	case days <= r.HighDays:
+		return "HIGH"
+	case days <= r.MedDays:
+		return "MED"
+	default:
+		return "LOW"
+	}
+}
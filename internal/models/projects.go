@@ -0,0 +1,75 @@
+package models
+
+// Project statuses. Status strings are stored upper-cased; anything else is
+// kept but sorts last.
+const (
+	StatusNotStarted = "NOT STARTED"
+	StatusInProgress = "IN PROGRESS"
+	StatusDone       = "DONE"
+)
+
+// StatusAuto is entered in place of a status to have it follow the subtasks.
+const StatusAuto = "AUTO"
+
+// Subtask is a checklist item belonging to a project.
+type Subtask struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// Milestone is a dated step towards a project's deadline.
+type Milestone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Date string `json:"date"`
+	Done bool   `json:"done"`
+}
+
+// Progress counts the finished subtasks.
+func (p ProjectItem) Progress() (done, total int) {
+	for _, s := range p.Subtasks {
+		if s.Done {
+			done++
+		}
+	}
+	return done, len(p.Subtasks)
+}
+
+// Percent is the share of finished subtasks, rounded down; ok is false for
+// projects without subtasks.
+func (p ProjectItem) Percent() (percent int, ok bool) {
+	done, total := p.Progress()
+	if total == 0 {
+		return 0, false
+	}
+	return done * 100 / total, true
+}
+
+// SubtaskStatus is the status the subtasks imply: NOT STARTED until one is
+// done, DONE once all are.
+func (p ProjectItem) SubtaskStatus() string {
+	done, total := p.Progress()
+	switch {
+	case done == 0:
+		return StatusNotStarted
+	case done == total:
+		return StatusDone
+	default:
+		return StatusInProgress
+	}
+}
+
+// SyncStatus updates the status of an AutoStatus project from its subtasks.
+// It reports whether the status changed.
+func (p *ProjectItem) SyncStatus() bool {
+	if !p.AutoStatus {
+		return false
+	}
+	status := p.SubtaskStatus()
+	if p.Status == status {
+		return false
+	}
+	p.Status = status
+	return true
+}
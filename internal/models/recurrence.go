@@ -0,0 +1,200 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is the repeat rule of a recurring todo. It is stored as an
+// iCalendar RRULE (RFC 5545) limited to what seman supports: FREQ=WEEKLY
+// with INTERVAL, BYDAY and either UNTIL or COUNT.
+type Recurrence struct {
+	Interval int            // weeks between repeats; 1 is every week
+	Days     []time.Weekday // empty: the weekday of the first occurrence
+	Until    string         // 2006-01-02, inclusive; empty: no end date
+	Count    int            // number of occurrences; 0: no limit
+}
+
+var rruleDays = []string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// weekdayOffset is the number of days from Monday to wd.
+func weekdayOffset(wd time.Weekday) int {
+	return (int(wd) + 6) % 7
+}
+
+// ParseRecurrence reads an RRULE such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+// with or without the "RRULE:" prefix.
+func ParseRecurrence(rule string) (Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	if len(rule) >= 6 && strings.EqualFold(rule[:6], "RRULE:") {
+		rule = rule[6:]
+	}
+	r := Recurrence{Interval: 1}
+	weekly := false
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return Recurrence{}, fmt.Errorf("malformed rule part %q", part)
+		}
+		value = strings.ToUpper(strings.TrimSpace(value))
+		switch strings.ToUpper(strings.TrimSpace(key)) {
+		case "FREQ":
+			if value != "WEEKLY" {
+				return Recurrence{}, fmt.Errorf("unsupported frequency %s", value)
+			}
+			weekly = true
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Recurrence{}, fmt.Errorf("bad interval %q", value)
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				i := indexOf(rruleDays, strings.TrimSpace(code))
+				if i < 0 {
+					return Recurrence{}, fmt.Errorf("bad day %q", code)
+				}
+				r.Days = append(r.Days, time.Weekday((i+1)%7))
+			}
+		case "UNTIL":
+			if len(value) < 8 {
+				return Recurrence{}, fmt.Errorf("bad end date %q", value)
+			}
+			until, err := time.Parse("20060102", value[:8])
+			if err != nil {
+				return Recurrence{}, fmt.Errorf("bad end date %q", value)
+			}
+			r.Until = until.Format("2006-01-02")
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Recurrence{}, fmt.Errorf("bad count %q", value)
+			}
+			r.Count = n
+		default:
+			return Recurrence{}, fmt.Errorf("unsupported rule part %s", key)
+		}
+	}
+	if !weekly {
+		return Recurrence{}, fmt.Errorf("rule has no FREQ=WEEKLY")
+	}
+	if r.Until != "" && r.Count > 0 {
+		return Recurrence{}, fmt.Errorf("rule has both UNTIL and COUNT")
+	}
+	r.Days = sortedDays(r.Days)
+	return r, nil
+}
+
+// String renders the rule as an RRULE without the "RRULE:" prefix.
+func (r Recurrence) String() string {
+	parts := []string{"FREQ=WEEKLY"}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Days) > 0 {
+		codes := make([]string, len(r.Days))
+		for i, wd := range sortedDays(r.Days) {
+			codes[i] = rruleDays[weekdayOffset(wd)]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Until != "" {
+		parts = append(parts, "UNTIL="+strings.ReplaceAll(r.Until, "-", ""))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Describe renders the rule for people, e.g. "every 2 weeks on Mon, Wed
+// until 2026-05-31".
+func (r Recurrence) Describe() string {
+	text := "weekly"
+	if r.Interval > 1 {
+		text = fmt.Sprintf("every %d weeks", r.Interval)
+	}
+	if len(r.Days) > 0 {
+		names := make([]string, len(r.Days))
+		for i, wd := range sortedDays(r.Days) {
+			names[i] = Weekdays[weekdayOffset(wd)]
+		}
+		text += " on " + strings.Join(names, ", ")
+	}
+	if r.Until != "" {
+		text += " until " + r.Until
+	}
+	if r.Count == 1 {
+		text += ", once"
+	} else if r.Count > 1 {
+		text += fmt.Sprintf(", %d times", r.Count)
+	}
+	return text
+}
+
+// Dates lists the occurrences before to and not before from of a series
+// whose first occurrence is first. Occurrences keep first's time of day;
+// Until and Count are counted from first.
+func (r Recurrence) Dates(first, from, to time.Time) []time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	days := sortedDays(r.Days)
+	if len(days) == 0 {
+		days = []time.Weekday{first.Weekday()}
+	}
+	firstDay := first.Format("2006-01-02")
+	week := first.AddDate(0, 0, -weekdayOffset(first.Weekday()))
+	var dates []time.Time
+	n := 0
+	for ; week.Before(to); week = week.AddDate(0, 0, 7*interval) {
+		for _, wd := range days {
+			date := week.AddDate(0, 0, weekdayOffset(wd))
+			day := date.Format("2006-01-02")
+			if day < firstDay {
+				continue
+			}
+			n++
+			if (r.Until != "" && day > r.Until) || (r.Count > 0 && n > r.Count) || !date.Before(to) {
+				return dates
+			}
+			if !date.Before(from) {
+				dates = append(dates, date)
+			}
+		}
+	}
+	return dates
+}
+
+// sortedDays returns days without duplicates, Monday first.
+func sortedDays(days []time.Weekday) []time.Weekday {
+	if len(days) == 0 {
+		return nil
+	}
+	seen := map[time.Weekday]bool{}
+	var out []time.Weekday
+	for _, wd := range days {
+		if !seen[wd] {
+			seen[wd] = true
+			out = append(out, wd)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return weekdayOffset(out[i]) < weekdayOffset(out[j])
+	})
+	return out
+}
+
+func indexOf(items []string, value string) int {
+	for i, item := range items {
+		if item == value {
+			return i
+		}
+	}
+	return -1
+}
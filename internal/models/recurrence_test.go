@@ -0,0 +1,261 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(t *testing.T, value string) time.Time {
+	t.Helper()
+	date, err := time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+	if err != nil {
+		date, err = time.ParseInLocation("2006-01-02", value, time.Local)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return date
+}
+
+func TestRecurrenceDates(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		first string
+		from  string // empty: from the start
+		to    string
+		want  []string
+	}{
+		{
+			name:  "weekly",
+			rule:  "FREQ=WEEKLY",
+			first: "2026-10-05",
+			to:    "2026-10-27",
+			want:  []string{"2026-10-05", "2026-10-12", "2026-10-19", "2026-10-26"},
+		},
+		{
+			name:  "to is exclusive",
+			rule:  "FREQ=WEEKLY",
+			first: "2026-10-05",
+			to:    "2026-10-19",
+			want:  []string{"2026-10-05", "2026-10-12"},
+		},
+		{
+			name:  "from leaves out earlier occurrences",
+			rule:  "FREQ=WEEKLY",
+			first: "2026-10-05",
+			from:  "2026-10-12",
+			to:    "2026-10-27",
+			want:  []string{"2026-10-12", "2026-10-19", "2026-10-26"},
+		},
+		{
+			name:  "biweekly",
+			rule:  "FREQ=WEEKLY;INTERVAL=2",
+			first: "2026-10-07",
+			to:    "2026-11-20",
+			want:  []string{"2026-10-07", "2026-10-21", "2026-11-04", "2026-11-18"},
+		},
+		{
+			name:  "weekdays from the middle of the week",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE,FR",
+			first: "2026-10-07",
+			to:    "2026-10-17",
+			want:  []string{"2026-10-07", "2026-10-09", "2026-10-12", "2026-10-14", "2026-10-16"},
+		},
+		{
+			name:  "weekdays every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH",
+			first: "2026-10-06",
+			to:    "2026-10-31",
+			want:  []string{"2026-10-06", "2026-10-08", "2026-10-20", "2026-10-22"},
+		},
+		{
+			name:  "BYDAY that leaves out the first day",
+			rule:  "FREQ=WEEKLY;BYDAY=MO",
+			first: "2026-10-07",
+			to:    "2026-10-20",
+			want:  []string{"2026-10-12", "2026-10-19"},
+		},
+		{
+			name:  "Sunday is the last day of the week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO",
+			first: "2026-10-05",
+			to:    "2026-10-26",
+			want:  []string{"2026-10-05", "2026-10-11", "2026-10-19", "2026-10-25"},
+		},
+		{
+			name:  "UNTIL is inclusive",
+			rule:  "FREQ=WEEKLY;UNTIL=20261019",
+			first: "2026-10-05",
+			to:    "2027-01-01",
+			want:  []string{"2026-10-05", "2026-10-12", "2026-10-19"},
+		},
+		{
+			name:  "UNTIL before the first occurrence",
+			rule:  "FREQ=WEEKLY;UNTIL=20261001",
+			first: "2026-10-05",
+			to:    "2027-01-01",
+			want:  nil,
+		},
+		{
+			name:  "COUNT",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,TH;COUNT=3",
+			first: "2026-10-05",
+			to:    "2027-01-01",
+			want:  []string{"2026-10-05", "2026-10-08", "2026-10-12"},
+		},
+		{
+			name:  "COUNT is counted from the first occurrence",
+			rule:  "FREQ=WEEKLY;COUNT=3",
+			first: "2026-10-05",
+			from:  "2026-10-12",
+			to:    "2027-01-01",
+			want:  []string{"2026-10-12", "2026-10-19"},
+		},
+		{
+			name:  "month ends",
+			rule:  "FREQ=WEEKLY",
+			first: "2026-01-31",
+			to:    "2026-03-01",
+			want:  []string{"2026-01-31", "2026-02-07", "2026-02-14", "2026-02-21", "2026-02-28"},
+		},
+		{
+			name:  "leap day",
+			rule:  "FREQ=WEEKLY;BYDAY=TU",
+			first: "2028-02-22",
+			to:    "2028-03-08",
+			want:  []string{"2028-02-22", "2028-02-29", "2028-03-07"},
+		},
+		{
+			name:  "from a leap day, UNTIL the next year",
+			rule:  "FREQ=WEEKLY;INTERVAL=52;UNTIL=20290301",
+			first: "2028-02-29",
+			to:    "2030-01-01",
+			want:  []string{"2028-02-29", "2029-02-27"},
+		},
+		{
+			name:  "the year turns",
+			rule:  "FREQ=WEEKLY;BYDAY=TH",
+			first: "2026-12-24",
+			to:    "2027-01-15",
+			want:  []string{"2026-12-24", "2026-12-31", "2027-01-07", "2027-01-14"},
+		},
+		{
+			name:  "time of day is kept",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=3",
+			first: "2026-10-05 09:30",
+			to:    "2027-01-01",
+			want:  []string{"2026-10-05 09:30", "2026-10-07 09:30", "2026-10-12 09:30"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrence(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRecurrence(%q): %v", tt.rule, err)
+			}
+			var from time.Time
+			if tt.from != "" {
+				from = day(t, tt.from)
+			}
+			var got []string
+			for _, date := range rule.Dates(day(t, tt.first), from, day(t, tt.to)) {
+				layout := "2006-01-02"
+				if strings.Contains(tt.first, " ") {
+					layout = "2006-01-02 15:04"
+				}
+				got = append(got, date.Format(layout))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dates = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRecurrenceDatesAcrossDST checks that occurrences keep their time of
+// day when the clocks change.
+func TestRecurrenceDatesAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Prague")
+	if err != nil {
+		t.Skip("no time zone database:", err)
+	}
+	saved := time.Local
+	time.Local = loc
+	defer func() { time.Local = saved }()
+
+	rule := Recurrence{Interval: 1}
+	got := rule.Dates(day(t, "2026-03-23 08:00"), time.Time{}, day(t, "2026-04-01"))
+	if len(got) != 2 {
+		t.Fatalf("Dates = %v, want 2 occurrences", got)
+	}
+	for _, date := range got {
+		if date.Hour() != 8 || date.Minute() != 0 {
+			t.Errorf("occurrence at %v, want 08:00", date)
+		}
+	}
+}
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		rule string
+		want Recurrence
+		text string // String of the parsed rule
+	}{
+		{"FREQ=WEEKLY", Recurrence{Interval: 1}, "FREQ=WEEKLY"},
+		{"RRULE:freq=weekly;interval=2", Recurrence{Interval: 2}, "FREQ=WEEKLY;INTERVAL=2"},
+		{
+			"FREQ=WEEKLY;BYDAY=SU,MO,MO",
+			Recurrence{Interval: 1, Days: []time.Weekday{time.Monday, time.Sunday}},
+			"FREQ=WEEKLY;BYDAY=MO,SU",
+		},
+		{
+			"FREQ=WEEKLY;UNTIL=20260630T235959Z",
+			Recurrence{Interval: 1, Until: "2026-06-30"},
+			"FREQ=WEEKLY;UNTIL=20260630",
+		},
+		{"FREQ=WEEKLY;COUNT=6", Recurrence{Interval: 1, Count: 6}, "FREQ=WEEKLY;COUNT=6"},
+	}
+	for _, tt := range tests {
+		got, err := ParseRecurrence(tt.rule)
+		if err != nil {
+			t.Errorf("ParseRecurrence(%q): %v", tt.rule, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRecurrence(%q) = %+v, want %+v", tt.rule, got, tt.want)
+		}
+		if text := got.String(); text != tt.text {
+			t.Errorf("String of %q = %q, want %q", tt.rule, text, tt.text)
+		}
+	}
+}
+
+func TestParseRecurrenceErrors(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+	}{
+		// Only weekly rules are supported: a monthly rule on the 31st or a
+		// yearly one on Feb 29 is refused rather than read as weekly.
+		{"FREQ=MONTHLY;BYMONTHDAY=31", "unsupported frequency MONTHLY"},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", "unsupported frequency YEARLY"},
+		{"INTERVAL=2", "rule has no FREQ=WEEKLY"},
+		{"FREQ=WEEKLY;INTERVAL=0", "bad interval"},
+		{"FREQ=WEEKLY;BYDAY=XX", "bad day"},
+		{"FREQ=WEEKLY;UNTIL=2026", "bad end date"},
+		{"FREQ=WEEKLY;UNTIL=20260231", "bad end date"},
+		{"FREQ=WEEKLY;COUNT=-1", "bad count"},
+		{"FREQ=WEEKLY;UNTIL=20260630;COUNT=3", "both UNTIL and COUNT"},
+		{"FREQ=WEEKLY;WKST=MO", "unsupported rule part WKST"},
+		{"FREQ", "malformed rule part"},
+	}
+	for _, tt := range tests {
+		_, err := ParseRecurrence(tt.rule)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseRecurrence(%q) = %v, want an error with %q", tt.rule, err, tt.want)
+		}
+	}
+}
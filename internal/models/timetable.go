@@ -0,0 +1,143 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// SessionTypes are the kinds of class the forms suggest; any other text is
+// kept as entered.
+var SessionTypes = []string{"Lecture", "Lab", "Seminar", "Tutorial"}
+
+// Weekdays are the day names sessions are stored with, Monday first.
+var Weekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// Week patterns of a session. The parity is that of the ISO week number of
+// the session's From date, counted on week by week from there (see
+// ClassSession.OccursOn); without a From date, that of the day's own week.
+const (
+	WeeksAll  = ""
+	WeeksOdd  = "odd"
+	WeeksEven = "even"
+)
+
+// ClassSession is a class that recurs every week, or every other week, on
+// one weekday, optionally only between two dates (usually the semester's).
+type ClassSession struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Day   string `json:"day"`   // one of Weekdays
+	Start string `json:"start"` // 15:04
+	End   string `json:"end"`
+	Room  string `json:"room,omitempty"`
+	Weeks string `json:"weeks,omitempty"`
+	From  string `json:"from,omitempty"` // 2006-01-02, inclusive
+	Until string `json:"until,omitempty"`
+}
+
+// Weekday returns the session's day of the week.
+func (s ClassSession) Weekday() (time.Weekday, bool) {
+	for i, name := range Weekdays {
+		if name == s.Day {
+			return time.Weekday((i + 1) % 7), true
+		}
+	}
+	return time.Sunday, false
+}
+
+// TimeRange renders the session's times, e.g. "09:00–10:30".
+func (s ClassSession) TimeRange() string {
+	return s.Start + "–" + s.End
+}
+
+// OccursOn reports whether the session takes place on the given day. Odd
+// and even weeks alternate from the week of From, so the pattern does not
+// break where an ISO year of 53 weeks ends.
+func (s ClassSession) OccursOn(day time.Time) bool {
+	if wd, ok := s.Weekday(); !ok || wd != day.Weekday() {
+		return false
+	}
+	date := civilDate(day)
+	from, hasFrom := parseCivilDate(s.From)
+	if hasFrom && date.Before(from) {
+		return false
+	}
+	if until, ok := parseCivilDate(s.Until); ok && date.After(until) {
+		return false
+	}
+	if s.Weeks != WeeksOdd && s.Weeks != WeeksEven {
+		return true
+	}
+	_, week := day.ISOWeek()
+	if hasFrom {
+		_, week = from.ISOWeek()
+		week += weeksBetween(from, date)
+	}
+	odd := week%2 != 0
+	return odd == (s.Weeks == WeeksOdd)
+}
+
+// civilDate is the calendar date of t, as midnight UTC, so that dates can be
+// compared and counted without time zones or daylight saving time.
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseCivilDate reads a 2006-01-02 date as civilDate would return it.
+func parseCivilDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02", value)
+	return t, err == nil
+}
+
+// weeksBetween counts the whole weeks from the Monday of from's week to the
+// Monday of to's; both are civil dates.
+func weeksBetween(from, to time.Time) int {
+	monday := func(t time.Time) time.Time {
+		return t.AddDate(0, 0, -weekdayOffset(t.Weekday()))
+	}
+	return int(monday(to).Sub(monday(from)).Hours()) / (24 * 7)
+}
+
+// TimetableEntry is one occurrence of a session on a given day.
+type TimetableEntry struct {
+	SubjectCode string
+	SubjectName string
+	SubjectIdx  int
+	SessionIdx  int
+	Session     ClassSession
+	Date        time.Time
+}
+
+// Timetable lists the sessions taking place in the given number of days from
+// start, by day and then by start time.
+func Timetable(subjects []SubjectItem, start time.Time, days int) []TimetableEntry {
+	var entries []TimetableEntry
+	for d := 0; d < days; d++ {
+		day := start.AddDate(0, 0, d)
+		for si, subject := range subjects {
+			for ci, session := range subject.Sessions {
+				if !session.OccursOn(day) {
+					continue
+				}
+				entries = append(entries, TimetableEntry{
+					SubjectCode: subject.Code,
+					SubjectName: subject.Name,
+					SubjectIdx:  si,
+					SessionIdx:  ci,
+					Session:     session,
+					Date:        day,
+				})
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].Date.Before(entries[j].Date)
+		}
+		return entries[i].Session.Start < entries[j].Session.Start
+	})
+	return entries
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccursOn(t *testing.T) {
+	// 2026 has 53 ISO weeks: Monday 2026-12-28 is in week 53 and Monday
+	// 2027-01-04 in week 1, both odd.
+	winter := ClassSession{Day: "Mon", Weeks: WeeksOdd, From: "2026-10-05", Until: "2027-01-31"}
+	tests := []struct {
+		name    string
+		session ClassSession
+		day     string
+		want    bool
+	}{
+		{"first week", winter, "2026-10-05", true},
+		{"second week", winter, "2026-10-12", false},
+		{"third week", winter, "2026-10-19", true},
+		{"week 53", winter, "2026-12-28", true},
+		{"after week 53", winter, "2027-01-04", false},
+		{"two weeks after week 53", winter, "2027-01-11", true},
+		{"last day", winter, "2027-01-25", true},
+		{"another weekday", winter, "2026-10-06", false},
+		{"before From", winter, "2026-09-28", false},
+		{"after Until", ClassSession{Day: "Mon", From: "2026-10-05", Until: "2027-01-24"}, "2027-01-25", false},
+		{"on Until", ClassSession{Day: "Mon", Until: "2027-01-25"}, "2027-01-25", true},
+		{
+			name:    "even weeks across the new year",
+			session: ClassSession{Day: "Wed", Weeks: WeeksEven, From: "2026-10-05"},
+			day:     "2027-01-06",
+			want:    true,
+		},
+		{
+			// The week of From sets the parity even when From is not the
+			// session's day.
+			name:    "From in the middle of an even week",
+			session: ClassSession{Day: "Mon", Weeks: WeeksEven, From: "2026-10-15"},
+			day:     "2026-10-19",
+			want:    false,
+		},
+		{
+			name:    "without From, the ISO week",
+			session: ClassSession{Day: "Mon", Weeks: WeeksOdd},
+			day:     "2027-01-04",
+			want:    true,
+		},
+		{"every week", ClassSession{Day: "Sun", From: "2026-10-05"}, "2026-10-11", true},
+		{"unknown day", ClassSession{Day: "Someday"}, "2026-10-05", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.session.OccursOn(day(t, tt.day)); got != tt.want {
+				t.Errorf("OccursOn(%s) = %v, want %v", tt.day, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestOccursOnAnyZone checks that the day's own date counts, whatever its
+// time and zone, also when the clocks change in between.
+func TestOccursOnAnyZone(t *testing.T) {
+	session := ClassSession{Day: "Mon", Weeks: WeeksEven, From: "2026-03-02", Until: "2026-04-06"}
+	zones := []*time.Location{time.UTC, time.FixedZone("NZDT", 13*60*60), time.FixedZone("HST", -10*60*60)}
+	if loc, err := time.LoadLocation("Europe/Prague"); err == nil {
+		zones = append(zones, loc)
+	}
+	for _, loc := range zones {
+		for _, tt := range []struct {
+			date time.Time
+			want bool
+		}{
+			{time.Date(2026, 3, 2, 0, 0, 0, 0, loc), true},
+			{time.Date(2026, 3, 30, 23, 59, 0, 0, loc), true},
+			{time.Date(2026, 4, 6, 23, 59, 0, 0, loc), false},
+			{time.Date(2026, 3, 1, 23, 59, 0, 0, loc), false},
+		} {
+			if got := session.OccursOn(tt.date); got != tt.want {
+				t.Errorf("%v: OccursOn(%v) = %v, want %v", loc, tt.date, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestTimetableAcrossTheNewYear(t *testing.T) {
+	subjects := []SubjectItem{{Code: "OS", Sessions: []ClassSession{
+		{Type: "Lab", Day: "Mon", Start: "10:00", End: "11:30", Weeks: WeeksOdd, From: "2026-10-05"},
+		{Type: "Lab", Day: "Mon", Start: "08:00", End: "09:30", Weeks: WeeksEven, From: "2026-10-05"},
+	}}}
+	var got []string
+	for _, e := range Timetable(subjects, day(t, "2026-12-21"), 28) {
+		got = append(got, e.Date.Format("2006-01-02")+" "+e.Session.Start)
+	}
+	want := []string{"2026-12-21 08:00", "2026-12-28 10:00", "2027-01-04 08:00", "2027-01-11 10:00"}
+	if len(got) != len(want) {
+		t.Fatalf("Timetable = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Timetable = %v, want %v", got, want)
+			break
+		}
+	}
+}
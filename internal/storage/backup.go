@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimeLayout stamps backup names to the millisecond; backups written
+// by earlier builds are stamped to the second (backupTimeLayoutSeconds).
+const (
+	backupTimeLayout        = "20060102-150405.000"
+	backupTimeLayoutSeconds = "20060102-150405"
+)
+
+// Backup describes a timestamped snapshot written next to the data file.
+type Backup struct {
+	Path    string
+	Created time.Time
+}
+
+// Backuper is implemented by stores that can keep timestamped snapshots of
+// their data alongside the primary file.
+type Backuper interface {
+	Backup(SemesterData) (string, error)
+	Backups() ([]Backup, error)
+}
+
+// Backup writes data to <name>-backup-<timestamp>.json in the store's
+// directory and returns the path of the new file.
+func (s *JSONStore) Backup(data SemesterData) (string, error) {
+	return writeBackup(s.path, data)
+}
+
+// Backups lists existing backups for the store, newest first.
+func (s *JSONStore) Backups() ([]Backup, error) {
+	return listBackups(s.path)
+}
+
+// writeBackup writes a JSON snapshot next to the data file at dataPath.
+// Backups are JSON whatever the store's own format, so they can be read back
+// with ReadFile and previewed like any import.
+func writeBackup(dataPath string, data SemesterData) (string, error) {
+	path := backupPath(dataPath)
+	if err := WriteFile(path, data); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+func listBackups(dataPath string) ([]Backup, error) {
+	prefix := backupPrefix(dataPath)
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(dataPath), prefix+"*.json"))
+	if err != nil {
+		return nil, err
+	}
+	backups := make([]Backup, 0, len(matches))
+	for _, path := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix), ".json")
+		created, err := parseBackupStamp(stamp)
+		if err != nil {
+			info, statErr := os.Stat(path)
+			if statErr != nil {
+				continue
+			}
+			created = info.ModTime()
+		}
+		backups = append(backups, Backup{Path: path, Created: created})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Created.After(backups[j].Created)
+	})
+	return backups, nil
+}
+
+// backupPath returns a new timestamped backup path for the data file. Should
+// a backup of the same millisecond exist, a counter keeps the two apart.
+func backupPath(dataPath string) string {
+	base := filepath.Join(filepath.Dir(dataPath), backupPrefix(dataPath)+time.Now().Format(backupTimeLayout))
+	path := base + ".json"
+	for n := 2; ; n++ {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d.json", base, n)
+	}
+}
+
+// parseBackupStamp reads the time a backup name was stamped with, ignoring
+// the counter of backupPath.
+func parseBackupStamp(stamp string) (time.Time, error) {
+	if i := strings.LastIndex(stamp, "-"); i > len(backupTimeLayoutSeconds) {
+		stamp = stamp[:i]
+	}
+	if created, err := time.ParseInLocation(backupTimeLayout, stamp, time.Local); err == nil {
+		return created, nil
+	}
+	return time.ParseInLocation(backupTimeLayoutSeconds, stamp, time.Local)
+}
+
+func backupPrefix(dataPath string) string {
+	base := strings.TrimSuffix(filepath.Base(dataPath), filepath.Ext(dataPath))
+	return base + "-backup-"
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupsInTheSameSecondAreKept(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "semester.json")
+	store := NewJSONStore(path)
+	var paths []string
+	for i := 0; i < 3; i++ {
+		p, err := store.Backup(SemesterData{WeekSpan: i + 1})
+		if err != nil {
+			t.Fatalf("Backup: %v", err)
+		}
+		paths = append(paths, p)
+	}
+	if paths[0] == paths[1] || paths[1] == paths[2] || paths[0] == paths[2] {
+		t.Fatalf("backups share a path: %v", paths)
+	}
+	for i, p := range paths {
+		data, err := ReadFile(p)
+		if err != nil || data.WeekSpan != i+1 {
+			t.Errorf("backup %s = week span %d, %v; want %d", filepath.Base(p), data.WeekSpan, err, i+1)
+		}
+	}
+	backups, err := store.Backups()
+	if err != nil || len(backups) != 3 {
+		t.Fatalf("Backups = %v, %v; want 3", backups, err)
+	}
+	for _, b := range backups {
+		if time.Since(b.Created) > time.Minute {
+			t.Errorf("%s: Created = %v, want the stamp of its name", filepath.Base(b.Path), b.Created)
+		}
+	}
+}
+
+func TestParseBackupStamp(t *testing.T) {
+	want := time.Date(2026, 10, 18, 9, 30, 5, 0, time.Local)
+	tests := []struct {
+		stamp string
+		want  time.Time
+	}{
+		{"20261018-093005", want},
+		{"20261018-093005.250", want.Add(250 * time.Millisecond)},
+		{"20261018-093005.250-3", want.Add(250 * time.Millisecond)},
+	}
+	for _, tt := range tests {
+		got, err := parseBackupStamp(tt.stamp)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("parseBackupStamp(%q) = %v, %v; want %v", tt.stamp, got, err, tt.want)
+		}
+	}
+	if _, err := parseBackupStamp("yesterday"); err == nil {
+		t.Error("parseBackupStamp(yesterday) succeeded")
+	}
+}
+
+func TestBackupsListsOldNames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "semester.json")
+	old := filepath.Join(dir, "semester-backup-20250101-120000.json")
+	if err := WriteFile(old, SemesterData{}); err != nil {
+		t.Fatal(err)
+	}
+	newer, err := NewJSONStore(path).Backup(SemesterData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backups, err := NewJSONStore(path).Backups()
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("Backups = %v, %v", backups, err)
+	}
+	if backups[0].Path != newer || backups[1].Path != old {
+		t.Errorf("Backups = %v, want the new one first", backups)
+	}
+	if want := time.Date(2025, 1, 1, 12, 0, 0, 0, time.Local); !backups[1].Created.Equal(want) {
+		t.Errorf("Created = %v, want %v", backups[1].Created, want)
+	}
+	if _, err := os.Stat(old); err != nil {
+		t.Error(err)
+	}
+}
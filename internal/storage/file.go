@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ReadFile decodes a SemesterData snapshot from an arbitrary JSON file, such
+// as an export or a backup. Files from older versions are migrated in memory.
+func ReadFile(path string) (SemesterData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return SemesterData{}, err
+	}
+	data, _, err := Decode(raw)
+	return data, err
+}
+
+// Decode parses JSON data of any schema version, upgrading it to the current
+// one and filling in missing IDs. It reports whether the data changed in the
+// process.
+func Decode(raw []byte) (SemesterData, bool, error) {
+	upgraded, _, changed, err := migrate(raw)
+	if err != nil {
+		return SemesterData{}, false, err
+	}
+	var data SemesterData
+	if err := json.Unmarshal(upgraded, &data); err != nil {
+		return SemesterData{}, false, err
+	}
+	if EnsureIDs(&data) {
+		changed = true
+	}
+	return data, changed, nil
+}
+
+// Encode renders data as indented JSON stamped with the current schema
+// version.
+func Encode(data SemesterData) ([]byte, error) {
+	data.SchemaVersion = SchemaVersion
+	payload, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(payload, '\n'), nil
+}
+
+// WriteFile writes data as indented JSON, replacing path atomically.
+func WriteFile(path string, data SemesterData) error {
+	payload, err := Encode(data)
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, payload)
+}
+
+func writeAtomic(path string, payload []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
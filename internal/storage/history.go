@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is one change in a semester's undo history. It records only
+// the items the change touched, as they were before and after it, so the
+// history is a log of operations rather than copies of the data.
+type HistoryEntry struct {
+	Label   string       `json:"label"`
+	Time    time.Time    `json:"time"`
+	Changes []ItemChange `json:"changes"`
+}
+
+// ItemChange is the change of one item: a subject, project or todo by ID,
+// or the settings or weekly exams, which have none. Before is absent for an
+// added item and After for a deleted one. Index is the item's position
+// before the change, or after it for an added item, so undoing a deletion
+// puts the item back where it was.
+type ItemChange struct {
+	Kind   string          `json:"kind"`
+	ID     string          `json:"id,omitempty"`
+	Index  int             `json:"index"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+// HistoryRecord is a line of the history log: a new change, which drops the
+// changes undone before it, or a move through the history by undo, redo or
+// a jump, to the number of changes then applied.
+type HistoryRecord struct {
+	Entry *HistoryEntry `json:"entry,omitempty"`
+	At    *int          `json:"at,omitempty"`
+}
+
+// Historian is implemented by stores that keep the undo history of their
+// data in a log next to the data file.
+type Historian interface {
+	// History replays the log: the changes and how many of them are
+	// applied.
+	History() ([]HistoryEntry, int, error)
+	AppendHistory(HistoryRecord) error
+}
+
+// History replays the history log next to the data file.
+func (s *JSONStore) History() ([]HistoryEntry, int, error) {
+	return readHistory(historyPath(s.path))
+}
+
+// AppendHistory adds a record to the history log next to the data file.
+func (s *JSONStore) AppendHistory(r HistoryRecord) error {
+	return appendHistory(historyPath(s.path), r)
+}
+
+// History replays the history log next to the database.
+func (s *SQLiteStore) History() ([]HistoryEntry, int, error) {
+	return readHistory(historyPath(s.path))
+}
+
+// AppendHistory adds a record to the history log next to the database.
+func (s *SQLiteStore) AppendHistory(r HistoryRecord) error {
+	return appendHistory(historyPath(s.path), r)
+}
+
+// historyPath is <name>-history.jsonl beside the data file, one record per
+// line.
+func historyPath(dataPath string) string {
+	base := strings.TrimSuffix(filepath.Base(dataPath), filepath.Ext(dataPath))
+	return filepath.Join(filepath.Dir(dataPath), base+"-history.jsonl")
+}
+
+func readHistory(path string) ([]HistoryEntry, int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	at := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var r HistoryRecord
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, 0, fmt.Errorf("%s line %d: %w", filepath.Base(path), n, err)
+		}
+		switch {
+		case r.Entry != nil:
+			entries = append(entries[:at], *r.Entry)
+			at = len(entries)
+		case r.At != nil && *r.At >= 0 && *r.At <= len(entries):
+			at = *r.At
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, at, nil
+}
+
+func appendHistory(path string, r HistoryRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
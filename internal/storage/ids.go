@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// newID makes the IDs of items that have none; tests make it predictable.
+var newID = models.NewID
+
+// EnsureIDs gives every subject, exam, class session, resource, project,
+// subtask, milestone and todo that has no ID, or one already taken by an
+// earlier item, a fresh ID. It reports whether anything changed.
+func EnsureIDs(data *SemesterData) bool {
+	seen := map[string]bool{}
+	changed := false
+	fix := func(id *string) {
+		if *id == "" || seen[*id] {
+			*id = newID()
+			changed = true
+		}
+		seen[*id] = true
+	}
+	for i := range data.Subjects {
+		fix(&data.Subjects[i].ID)
+		for j := range data.Subjects[i].Exams {
+			fix(&data.Subjects[i].Exams[j].ID)
+		}
+		for j := range data.Subjects[i].Sessions {
+			fix(&data.Subjects[i].Sessions[j].ID)
+		}
+		for j := range data.Subjects[i].Resources {
+			fix(&data.Subjects[i].Resources[j].ID)
+		}
+	}
+	for i := range data.Projects {
+		fix(&data.Projects[i].ID)
+		for j := range data.Projects[i].Subtasks {
+			fix(&data.Projects[i].Subtasks[j].ID)
+		}
+		for j := range data.Projects[i].Milestones {
+			fix(&data.Projects[i].Milestones[j].ID)
+		}
+	}
+	for i := range data.Checklist {
+		fix(&data.Checklist[i].ID)
+	}
+	return changed
+}
+
+// NormalizeDates rewrites exam, retake, project, milestone and todo dates
+// that are not yet stored as RFC 3339. It reports whether anything changed.
+func NormalizeDates(data *SemesterData) bool {
+	changed := false
+	fix := func(value *string) {
+		if normal := dates.Normalize(*value); normal != *value {
+			*value = normal
+			changed = true
+		}
+	}
+	for i := range data.Subjects {
+		for j := range data.Subjects[i].Exams {
+			exam := &data.Subjects[i].Exams[j]
+			fix(&exam.Date)
+			for k := range exam.Retakes {
+				fix(&exam.Retakes[k])
+			}
+		}
+	}
+	for i := range data.Projects {
+		fix(&data.Projects[i].Due)
+		fix(&data.Projects[i].Start)
+		for j := range data.Projects[i].Milestones {
+			fix(&data.Projects[i].Milestones[j].Date)
+		}
+	}
+	for i := range data.Checklist {
+		fix(&data.Checklist[i].Due)
+	}
+	return changed
+}
@@ -1,10 +1,9 @@
 package storage
 
 import (
-	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
-	"path/filepath"
 )
 
 type JSONStore struct {
@@ -15,38 +14,38 @@ func NewJSONStore(path string) *JSONStore {
 	return &JSONStore{path: path}
 }
 
+// Load reads the data file. A file written with an older schema is copied to
+// a backup, upgraded and written back before it is returned.
 func (s *JSONStore) Load() (SemesterData, bool, error) {
-	file, err := os.Open(s.path)
+	return s.load(true)
+}
+
+// load reads and upgrades the data file; with writeBack, an upgraded file is
+// backed up and saved as Load describes.
+func (s *JSONStore) load(writeBack bool) (SemesterData, bool, error) {
+	raw, err := os.ReadFile(s.path)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return SemesterData{}, false, nil
 		}
 		return SemesterData{}, false, err
 	}
-	defer file.Close()
 
-	var data SemesterData
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
+	data, migrated, err := Decode(raw)
+	if err != nil {
 		return SemesterData{}, true, err
 	}
+	if migrated && writeBack {
+		if err := writeAtomic(backupPath(s.path), raw); err != nil {
+			return SemesterData{}, true, fmt.Errorf("backing up before migration: %w", err)
+		}
+		if err := s.Save(data); err != nil {
+			return SemesterData{}, true, fmt.Errorf("saving migrated data: %w", err)
+		}
+	}
 	return data, true, nil
 }
 
 func (s *JSONStore) Save(data SemesterData) error {
-	dir := filepath.Dir(s.path)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return err
-	}
-
-	payload, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return err
-	}
-	payload = append(payload, '\n')
-
-	tmp := s.path + ".tmp"
-	if err := os.WriteFile(tmp, payload, 0o644); err != nil {
-		return err
-	}
-	return os.Rename(tmp, s.path)
+	return WriteFile(s.path, data)
 }
@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// ErrArchived is returned when saving to an archived semester.
+var ErrArchived = errors.New("semester is archived (read-only)")
+
+// Semester is one entry of the semester catalog. File is the data file's
+// path relative to the data directory, without extension; the backend adds
+// its own (.json, .db).
+type Semester struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Archived bool   `json:"archived"`
+	File     string `json:"file"`
+}
+
+// Opener opens the store for a data file path without extension. The
+// returned function releases the store.
+type Opener func(base string) (Store, func() error, error)
+
+// catalogFile is the on-disk form of the semester list, semesters.json.
+type catalogFile struct {
+	SchemaVersion int        `json:"schema_version"`
+	Current       string     `json:"current"`
+	Semesters     []Semester `json:"semesters"`
+}
+
+const catalogSchemaVersion = 1
+
+// defaultSemesterFile is where data lived before there were semesters; the
+// first semester keeps using it so existing data stays in place.
+const defaultSemesterFile = "semester"
+
+// Library is the set of semesters in a data directory. It keeps one semester
+// open at a time.
+type Library struct {
+	dir     string
+	open    Opener
+	catalog catalogFile
+	store   Store
+	close   func() error
+	opened  string
+}
+
+// OpenLibrary reads the semester catalog in dir, creating one for the
+// existing data file if there is none yet.
+func OpenLibrary(dir string, open Opener) (*Library, error) {
+	l := &Library{dir: dir, open: open}
+	raw, err := os.ReadFile(l.catalogPath())
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		s := Semester{ID: models.NewID(), Name: "Current semester", File: defaultSemesterFile}
+		l.catalog = catalogFile{Current: s.ID, Semesters: []Semester{s}}
+		if err := l.saveCatalog(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		if err := json.Unmarshal(raw, &l.catalog); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", l.catalogPath(), err)
+		}
+		if l.catalog.SchemaVersion > catalogSchemaVersion {
+			return nil, fmt.Errorf("%s was written by a newer seman; please upgrade", l.catalogPath())
+		}
+		if len(l.catalog.Semesters) == 0 {
+			return nil, fmt.Errorf("%s lists no semesters", l.catalogPath())
+		}
+	}
+	return l, nil
+}
+
+// Semesters returns the catalog in order of creation.
+func (l *Library) Semesters() []Semester {
+	return append([]Semester(nil), l.catalog.Semesters...)
+}
+
+// Current is the semester opened by default.
+func (l *Library) Current() Semester {
+	if i := l.index(l.catalog.Current); i >= 0 {
+		return l.catalog.Semesters[i]
+	}
+	return l.catalog.Semesters[0]
+}
+
+// Opened is the semester whose store is open, or the current one if none is.
+func (l *Library) Opened() Semester {
+	if i := l.index(l.opened); i >= 0 {
+		return l.catalog.Semesters[i]
+	}
+	return l.Current()
+}
+
+// Find returns the semester with the given ID or (case-insensitive) name.
+func (l *Library) Find(ref string) (Semester, bool) {
+	ref = strings.TrimSpace(ref)
+	for _, s := range l.catalog.Semesters {
+		if s.ID == ref || strings.EqualFold(s.Name, ref) {
+			return s, true
+		}
+	}
+	return Semester{}, false
+}
+
+// Names lists the semester names, for error messages.
+func (l *Library) Names() []string {
+	names := make([]string, len(l.catalog.Semesters))
+	for i, s := range l.catalog.Semesters {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Open closes the open semester, if any, and opens the one with the given ID.
+// Archived semesters are opened read-only: saving returns ErrArchived.
+func (l *Library) Open(id string) (Store, error) {
+	s, store, closeStore, err := l.openStore(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.swap(s, store, closeStore); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Load opens the semester with the given ID, like Open, and reads its data.
+// The open semester is closed only once the new one has loaded, so after an
+// error it stays open.
+func (l *Library) Load(id string) (Store, SemesterData, bool, error) {
+	s, store, closeStore, err := l.openStore(id)
+	if err != nil {
+		return nil, SemesterData{}, false, err
+	}
+	data, found, err := store.Load()
+	if err != nil {
+		closeStore()
+		return nil, SemesterData{}, false, fmt.Errorf("loading semester %s: %w", s.Name, err)
+	}
+	if err := l.swap(s, store, closeStore); err != nil {
+		return nil, SemesterData{}, false, err
+	}
+	return store, data, found, nil
+}
+
+// openStore opens the store of the semester with the given ID, read-only if
+// the semester is archived.
+func (l *Library) openStore(id string) (Semester, Store, func() error, error) {
+	i := l.index(id)
+	if i < 0 {
+		return Semester{}, nil, nil, fmt.Errorf("unknown semester %q", id)
+	}
+	s := l.catalog.Semesters[i]
+	store, closeStore, err := l.open(filepath.Join(l.dir, filepath.FromSlash(s.File)))
+	if err != nil {
+		return Semester{}, nil, nil, fmt.Errorf("opening semester %s: %w", s.Name, err)
+	}
+	if s.Archived {
+		store = readOnlyStore{store}
+	}
+	return s, store, closeStore, nil
+}
+
+// swap closes the open semester and makes store, of semester s, the open one.
+func (l *Library) swap(s Semester, store Store, closeStore func() error) error {
+	if err := l.Close(); err != nil {
+		closeStore()
+		return err
+	}
+	l.store, l.close, l.opened = store, closeStore, s.ID
+	return nil
+}
+
+// SetCurrent makes the semester with the given ID the one opened by default.
+func (l *Library) SetCurrent(id string) error {
+	if l.index(id) < 0 {
+		return fmt.Errorf("unknown semester %q", id)
+	}
+	l.catalog.Current = id
+	return l.saveCatalog()
+}
+
+// Create adds a semester holding data and returns it. The new semester is
+// neither opened nor made current.
+func (l *Library) Create(name, start, end string, data SemesterData) (Semester, error) {
+	s := Semester{ID: models.NewID(), Name: name, Start: start, End: end}
+	s.File = "semesters/" + s.ID
+	store, closeStore, err := l.open(filepath.Join(l.dir, "semesters", s.ID))
+	if err != nil {
+		return Semester{}, err
+	}
+	err = store.Save(data)
+	if closeErr := closeStore(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Semester{}, err
+	}
+	l.catalog.Semesters = append(l.catalog.Semesters, s)
+	if err := l.saveCatalog(); err != nil {
+		return Semester{}, err
+	}
+	return s, nil
+}
+
+// Update changes a semester's name, dates and archived flag. Archiving the
+// open semester takes effect the next time it is opened.
+func (l *Library) Update(s Semester) error {
+	i := l.index(s.ID)
+	if i < 0 {
+		return fmt.Errorf("unknown semester %q", s.ID)
+	}
+	s.File = l.catalog.Semesters[i].File
+	l.catalog.Semesters[i] = s
+	return l.saveCatalog()
+}
+
+// Close releases the open semester's store.
+func (l *Library) Close() error {
+	if l.close == nil {
+		return nil
+	}
+	err := l.close()
+	l.store, l.close, l.opened = nil, nil, ""
+	return err
+}
+
+func (l *Library) index(id string) int {
+	for i, s := range l.catalog.Semesters {
+		if s.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *Library) catalogPath() string {
+	return filepath.Join(l.dir, "semesters.json")
+}
+
+func (l *Library) saveCatalog() error {
+	l.catalog.SchemaVersion = catalogSchemaVersion
+	payload, err := json.MarshalIndent(l.catalog, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomic(l.catalogPath(), append(payload, '\n'))
+}
+
+// readOnlyStore refuses to save, for archived semesters. Backups and the
+// undo history can still be read, and backups taken, so it forwards
+// Backuper and Historian to the semester's store, which implements both.
+type readOnlyStore struct {
+	Store
+}
+
+// Load reads the semester without writing anything back: a file from an
+// older build is upgraded in memory each time instead of on disk.
+func (s readOnlyStore) Load() (SemesterData, bool, error) {
+	if js, ok := s.Store.(*JSONStore); ok {
+		return js.load(false)
+	}
+	return s.Store.Load()
+}
+
+func (readOnlyStore) Save(SemesterData) error {
+	return ErrArchived
+}
+
+func (s readOnlyStore) Backup(data SemesterData) (string, error) {
+	b, ok := s.Store.(Backuper)
+	if !ok {
+		return "", errors.New("this store keeps no backups")
+	}
+	return b.Backup(data)
+}
+
+func (s readOnlyStore) Backups() ([]Backup, error) {
+	b, ok := s.Store.(Backuper)
+	if !ok {
+		return nil, nil
+	}
+	return b.Backups()
+}
+
+func (s readOnlyStore) History() ([]HistoryEntry, int, error) {
+	h, ok := s.Store.(Historian)
+	if !ok {
+		return nil, 0, nil
+	}
+	return h.History()
+}
+
+func (readOnlyStore) AppendHistory(HistoryRecord) error {
+	return ErrArchived
+}
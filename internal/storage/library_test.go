@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func jsonOpener(base string) (Store, func() error, error) {
+	return NewJSONStore(base + ".json"), func() error { return nil }, nil
+}
+
+func TestArchivedSemesterIsReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	lib, err := OpenLibrary(dir, jsonOpener)
+	if err != nil {
+		t.Fatalf("OpenLibrary: %v", err)
+	}
+	s, err := lib.Create("Winter", "2025-10-01", "2026-02-15", SemesterData{SchemaVersion: SchemaVersion})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	// Some history from before the semester was archived.
+	live := NewJSONStore(filepath.Join(dir, filepath.FromSlash(s.File)) + ".json")
+	entry := HistoryEntry{Label: "Added todo", Time: time.Now()}
+	if err := live.AppendHistory(HistoryRecord{Entry: &entry}); err != nil {
+		t.Fatalf("AppendHistory: %v", err)
+	}
+	s.Archived = true
+	if err := lib.Update(s); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	store, err := lib.Open(s.ID)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := store.Save(SemesterData{}); !errors.Is(err, ErrArchived) {
+		t.Errorf("Save = %v, want ErrArchived", err)
+	}
+
+	h, ok := store.(Historian)
+	if !ok {
+		t.Fatal("an archived semester's store is not a Historian")
+	}
+	entries, at, err := h.History()
+	if err != nil || len(entries) != 1 || at != 1 {
+		t.Errorf("History = %d entries at %d, %v; want 1 at 1", len(entries), at, err)
+	}
+	if err := h.AppendHistory(HistoryRecord{Entry: &entry}); !errors.Is(err, ErrArchived) {
+		t.Errorf("AppendHistory = %v, want ErrArchived", err)
+	}
+
+	b, ok := store.(Backuper)
+	if !ok {
+		t.Fatal("an archived semester's store is not a Backuper")
+	}
+	if _, err := b.Backup(SemesterData{SchemaVersion: SchemaVersion}); err != nil {
+		t.Errorf("Backup: %v", err)
+	}
+	backups, err := b.Backups()
+	if err != nil || len(backups) != 1 {
+		t.Errorf("Backups = %v, %v; want one", backups, err)
+	}
+}
+
+// trackingOpener opens JSON stores and records which are still open.
+type trackingOpener map[string]bool
+
+func (o trackingOpener) open(base string) (Store, func() error, error) {
+	o[base] = true
+	return NewJSONStore(base + ".json"), func() error {
+		delete(o, base)
+		return nil
+	}, nil
+}
+
+func TestLoadFailureKeepsSemesterOpen(t *testing.T) {
+	dir := t.TempDir()
+	opened := trackingOpener{}
+	lib, err := OpenLibrary(dir, opened.open)
+	if err != nil {
+		t.Fatalf("OpenLibrary: %v", err)
+	}
+	first := lib.Current()
+	if _, err := lib.Open(first.ID); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	broken, err := lib.Create("Broken", "", "", SemesterData{SchemaVersion: SchemaVersion})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	brokenBase := filepath.Join(dir, filepath.FromSlash(broken.File))
+	if err := os.WriteFile(brokenBase+".json", []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := lib.Load(broken.ID); err == nil {
+		t.Fatal("Load of a broken semester succeeded")
+	}
+	if got := lib.Opened(); got.ID != first.ID {
+		t.Errorf("Opened = %s, want %s still open", got.Name, first.Name)
+	}
+	if !opened[filepath.Join(dir, first.File)] || opened[brokenBase] {
+		t.Errorf("open stores = %v, want only the first semester's", opened)
+	}
+
+	second, err := lib.Create("Second", "", "", SemesterData{SchemaVersion: SchemaVersion})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, _, found, err := lib.Load(second.ID); err != nil || !found {
+		t.Fatalf("Load = found %v, %v", found, err)
+	}
+	if lib.Opened().ID != second.ID || opened[filepath.Join(dir, first.File)] {
+		t.Errorf("the first semester was not closed once the second loaded: %v", opened)
+	}
+}
+
+func TestArchivedLoadDoesNotWrite(t *testing.T) {
+	dir := t.TempDir()
+	lib, err := OpenLibrary(dir, jsonOpener)
+	if err != nil {
+		t.Fatalf("OpenLibrary: %v", err)
+	}
+	s, err := lib.Create("Old", "", "", SemesterData{SchemaVersion: SchemaVersion})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	s.Archived = true
+	if err := lib.Update(s); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	path := filepath.Join(dir, filepath.FromSlash(s.File)) + ".json"
+	raw, err := os.ReadFile(filepath.Join("testdata", "migrate", "v0.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, data, found, err := lib.Load(s.ID)
+	if err != nil || !found {
+		t.Fatalf("Load = found %v, %v", found, err)
+	}
+	if data.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want the file upgraded in memory", data.SchemaVersion)
+	}
+	if after, err := os.ReadFile(path); err != nil || !bytes.Equal(after, raw) {
+		t.Errorf("the archived file was rewritten (%v)", err)
+	}
+	if backups, _ := store.(Backuper).Backups(); len(backups) != 0 {
+		t.Errorf("Load backed up the archived file: %v", backups)
+	}
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// document is a decoded JSON object that migrations rewrite in place.
+type document = map[string]any
+
+// migrations upgrade raw JSON documents one schema version at a time: entry i
+// turns a version i document into a version i+1 one. Append new entries and
+// bump SchemaVersion; never edit old ones, files written by every release
+// must keep loading.
+var migrations = []func(document) error{
+	migrateSnakeCaseItems,
+	migrateAddIDs,
+	migrateAddGrading,
+	migrateAddTimetable,
+	migrateAddRecurringTodos,
+	migrateRFC3339Dates,
+	migrateAddProjectPlans,
+	migrateAddProjectStart,
+	migrateAddSubjectNotes,
+}
+
+// migrate upgrades raw to the current schema. It returns the upgraded JSON,
+// the version the document had, and whether anything changed.
+func migrate(raw []byte) ([]byte, int, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc document
+	if err := dec.Decode(&doc); err != nil {
+		return nil, 0, false, err
+	}
+
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if version > SchemaVersion {
+		return nil, version, false, fmt.Errorf("data has schema version %d but this build of seman only understands up to %d; please upgrade seman", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return raw, version, false, nil
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, version, false, fmt.Errorf("migrating schema %d to %d: %w", v, v+1, err)
+		}
+	}
+	doc["schema_version"] = SchemaVersion
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, version, false, err
+	}
+	return upgraded, version, true, nil
+}
+
+func documentVersion(doc document) (int, error) {
+	raw, ok := doc["schema_version"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	n, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("schema_version must be a number")
+	}
+	v, err := n.Int64()
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid schema_version %s", n)
+	}
+	return int(v), nil
+}
+
+// migrateSnakeCaseItems renames the Go field names that subjects, exams,
+// projects and todos were written with before they had JSON tags.
+func migrateSnakeCaseItems(doc document) error {
+	for _, subject := range objects(doc["subjects"]) {
+		renameKeys(subject, map[string]string{"Code": "code", "Name": "name", "Exams": "exams"})
+		for _, exam := range objects(subject["exams"]) {
+			renameKeys(exam, map[string]string{
+				"Name":      "name",
+				"Date":      "date",
+				"Retakes":   "retakes",
+				"Priority":  "priority",
+				"SourceUID": "source_uid",
+			})
+		}
+	}
+	for _, project := range objects(doc["projects"]) {
+		renameKeys(project, map[string]string{"Name": "name", "Subject": "subject", "Due": "due", "Status": "status"})
+	}
+	for _, item := range objects(doc["checklist"]) {
+		renameKeys(item, map[string]string{"Text": "text", "Done": "done", "Due": "due", "Subject": "subject"})
+	}
+	return nil
+}
+
+// migrateAddIDs gives every subject, exam, project and todo an ID.
+func migrateAddIDs(doc document) error {
+	addID := func(obj document) {
+		if id, _ := obj["id"].(string); id == "" {
+			obj["id"] = newID()
+		}
+	}
+	for _, subject := range objects(doc["subjects"]) {
+		addID(subject)
+		for _, exam := range objects(subject["exams"]) {
+			addID(exam)
+		}
+	}
+	for _, project := range objects(doc["projects"]) {
+		addID(project)
+	}
+	for _, item := range objects(doc["checklist"]) {
+		addID(item)
+	}
+	return nil
+}
+
+// migrateAddGrading picks the default grading scale. Exam results, weights
+// and subject credits are optional, so items need no changes.
+func migrateAddGrading(doc document) error {
+	if scale, _ := doc["grading_scale"].(string); scale == "" {
+		doc["grading_scale"] = models.DefaultGradingScale
+	}
+	return nil
+}
+
+// migrateAddTimetable changes nothing: class sessions are optional. The
+// version still moves so that older builds, which would drop the sessions on
+// their next save, refuse the file instead.
+func migrateAddTimetable(doc document) error {
+	return nil
+}
+
+// migrateAddRecurringTodos changes nothing either: todos repeat only once a
+// rule is set. Older builds would save a recurring todo as a one-off.
+func migrateAddRecurringTodos(doc document) error {
+	return nil
+}
+
+// migrateRFC3339Dates rewrites exam, retake, project and todo dates, which
+// were stored as typed, as RFC 3339. Values that are not dates stay as they
+// are.
+func migrateRFC3339Dates(doc document) error {
+	normalize := func(obj document, key string) {
+		if value, ok := obj[key].(string); ok {
+			obj[key] = dates.Normalize(value)
+		}
+	}
+	for _, subject := range objects(doc["subjects"]) {
+		for _, exam := range objects(subject["exams"]) {
+			normalize(exam, "date")
+			retakes, _ := exam["retakes"].([]any)
+			for i, retake := range retakes {
+				if value, ok := retake.(string); ok {
+					retakes[i] = dates.Normalize(value)
+				}
+			}
+		}
+	}
+	for _, project := range objects(doc["projects"]) {
+		normalize(project, "due")
+	}
+	for _, item := range objects(doc["checklist"]) {
+		normalize(item, "due")
+	}
+	return nil
+}
+
+// migrateAddProjectPlans changes nothing: subtasks and milestones are
+// optional. Older builds would drop them on their next save.
+func migrateAddProjectPlans(doc document) error {
+	return nil
+}
+
+// migrateAddProjectStart changes nothing: the start date is optional.
+func migrateAddProjectStart(doc document) error {
+	return nil
+}
+
+// migrateAddSubjectNotes changes nothing: notes and resources are optional.
+func migrateAddSubjectNotes(doc document) error {
+	return nil
+}
+
+// objects returns the JSON objects in a decoded array, skipping anything else.
+func objects(v any) []document {
+	list, _ := v.([]any)
+	out := make([]document, 0, len(list))
+	for _, item := range list {
+		if obj, ok := item.(document); ok {
+			out = append(out, obj)
+		}
+	}
+	return out
+}
+
+func renameKeys(obj document, names map[string]string) {
+	for from, to := range names {
+		value, ok := obj[from]
+		if !ok {
+			continue
+		}
+		delete(obj, from)
+		if _, exists := obj[to]; !exists {
+			obj[to] = value
+		}
+	}
+}
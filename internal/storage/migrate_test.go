@@ -0,0 +1,211 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// withTestIDs makes the IDs given to items count up, so migrated files can
+// be compared.
+func withTestIDs(t *testing.T) {
+	saved := newID
+	n := 0
+	newID = func() string {
+		n++
+		return fmt.Sprintf("id-%d", n)
+	}
+	t.Cleanup(func() { newID = saved })
+}
+
+// withZone reads dates in a fixed time zone.
+func withZone(t *testing.T) {
+	saved := time.Local
+	time.Local = time.FixedZone("CET", 60*60)
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	raw, err := os.ReadFile(filepath.Join("testdata", "migrate", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func decodeDocument(t *testing.T, raw []byte) document {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc document
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+// assertGolden compares got with the golden file name, or rewrites the file
+// when the tests run with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "migrate", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to write it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs:\n%s\nwant\n%s", name, got, want)
+	}
+}
+
+// TestMigrationSteps runs each migration on a file of the version before it:
+// testdata/migrate/vN.json is upgraded to vN.golden.json.
+func TestMigrationSteps(t *testing.T) {
+	if len(migrations) != SchemaVersion {
+		t.Fatalf("%d migrations for schema version %d", len(migrations), SchemaVersion)
+	}
+	for v := 0; v < SchemaVersion; v++ {
+		t.Run(fmt.Sprintf("v%d", v), func(t *testing.T) {
+			withTestIDs(t)
+			withZone(t)
+			doc := decodeDocument(t, readTestdata(t, fmt.Sprintf("v%d.json", v)))
+			if got, err := documentVersion(doc); err != nil || got != v {
+				t.Fatalf("fixture has version %d, %v; want %d", got, err, v)
+			}
+			if err := migrations[v](doc); err != nil {
+				t.Fatalf("migration %d: %v", v, err)
+			}
+			doc["schema_version"] = v + 1
+			got, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, fmt.Sprintf("v%d.golden.json", v), append(got, '\n'))
+		})
+	}
+}
+
+// TestNoOpMigrations pins down the steps that only move the version, so
+// that older builds refuse files they would lose data from.
+func TestNoOpMigrations(t *testing.T) {
+	for _, v := range []int{3, 4, 6, 7, 8} {
+		raw := readTestdata(t, fmt.Sprintf("v%d.json", v))
+		doc := decodeDocument(t, raw)
+		if err := migrations[v](doc); err != nil {
+			t.Fatalf("migration %d: %v", v, err)
+		}
+		if want := decodeDocument(t, raw); !reflect.DeepEqual(doc, want) {
+			t.Errorf("migration %d changed the document:\n%v\nwant\n%v", v, doc, want)
+		}
+	}
+}
+
+// TestMigrateToCurrent upgrades the oldest file through every step.
+func TestMigrateToCurrent(t *testing.T) {
+	withTestIDs(t)
+	withZone(t)
+	data, changed, err := Decode(readTestdata(t, "v0.json"))
+	if err != nil || !changed {
+		t.Fatalf("Decode = changed %v, %v", changed, err)
+	}
+	got, err := Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "current.golden.json", got)
+
+	// A current file is left as it is.
+	if _, changed, err := Decode(got); err != nil || changed {
+		t.Errorf("Decode of a current file = changed %v, %v", changed, err)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{fmt.Sprintf(`{"schema_version": %d}`, SchemaVersion+1), "please upgrade seman"},
+		{`{"schema_version": "9"}`, "schema_version must be a number"},
+		{`{"schema_version": -1}`, "invalid schema_version -1"},
+		{`{"schema_version": 1.5}`, "invalid schema_version 1.5"},
+		{`[]`, "cannot unmarshal"},
+	}
+	for _, tt := range tests {
+		_, _, _, err := migrate([]byte(tt.raw))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("migrate(%s) = %v, want an error with %q", tt.raw, err, tt.want)
+		}
+	}
+}
+
+// TestJSONStoreLoadMigrates checks that loading an old file backs it up as
+// it was and writes the upgraded data back, once.
+func TestJSONStoreLoadMigrates(t *testing.T) {
+	withTestIDs(t)
+	withZone(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "semester.json")
+	raw := readTestdata(t, "v0.json")
+	if err := os.WriteFile(path, raw, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store := NewJSONStore(path)
+	data, found, err := store.Load()
+	if err != nil || !found {
+		t.Fatalf("Load = found %v, %v", found, err)
+	}
+
+	backups, err := store.Backups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Backups = %v, %v; want the pre-migration backup", backups, err)
+	}
+	if backup, err := os.ReadFile(backups[0].Path); err != nil || !bytes.Equal(backup, raw) {
+		t.Errorf("the backup is not the file as it was (%v)", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := Encode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(written, want) {
+		t.Errorf("the file was not written back:\n%s", written)
+	}
+
+	// Loading again finds a current file: nothing is backed up or written.
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameData(t, again, data)
+	if backups, _ := store.Backups(); len(backups) != 1 {
+		t.Errorf("%d backups after a second load, want 1", len(backups))
+	}
+	if after, err := os.Stat(path); err != nil || !after.ModTime().Equal(info.ModTime()) {
+		t.Errorf("the file was written again (%v)", err)
+	}
+}
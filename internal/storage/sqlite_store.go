@@ -0,0 +1,745 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/romanguyen/seman/internal/models"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registered as "sqlite"
+)
+
+// sqliteMigrations create and evolve the schema. Entry i moves the database
+// from user_version i to i+1; append new entries, never edit old ones.
+var sqliteMigrations = []string{
+	`CREATE TABLE settings (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	CREATE TABLE subjects (
+		id       INTEGER PRIMARY KEY,
+		position INTEGER NOT NULL,
+		code     TEXT NOT NULL,
+		name     TEXT NOT NULL
+	);
+	CREATE TABLE exams (
+		id         INTEGER PRIMARY KEY,
+		subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+		position   INTEGER NOT NULL,
+		name       TEXT NOT NULL,
+		date       TEXT NOT NULL,
+		priority   TEXT NOT NULL,
+		source_uid TEXT NOT NULL
+	);
+	CREATE TABLE retakes (
+		exam_id  INTEGER NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+		position INTEGER NOT NULL,
+		date     TEXT NOT NULL
+	);
+	CREATE TABLE projects (
+		id       INTEGER PRIMARY KEY,
+		position INTEGER NOT NULL,
+		name     TEXT NOT NULL,
+		subject  TEXT NOT NULL,
+		due      TEXT NOT NULL,
+		status   TEXT NOT NULL
+	);
+	CREATE TABLE todos (
+		id       INTEGER PRIMARY KEY,
+		position INTEGER NOT NULL,
+		text     TEXT NOT NULL,
+		done     INTEGER NOT NULL,
+		due      TEXT NOT NULL,
+		subject  TEXT NOT NULL
+	);
+	CREATE INDEX exams_subject ON exams(subject_id, position);
+	CREATE INDEX retakes_exam ON retakes(exam_id, position);`,
+
+	`ALTER TABLE subjects ADD COLUMN item_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE exams ADD COLUMN item_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE projects ADD COLUMN item_id TEXT NOT NULL DEFAULT '';
+	ALTER TABLE todos ADD COLUMN item_id TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE subjects ADD COLUMN credits REAL NOT NULL DEFAULT 0;
+	ALTER TABLE exams ADD COLUMN weight REAL NOT NULL DEFAULT 0;
+	CREATE TABLE results (
+		exam_id    INTEGER NOT NULL REFERENCES exams(id) ON DELETE CASCADE,
+		attempt    INTEGER NOT NULL,
+		grade      TEXT NOT NULL,
+		scale      TEXT NOT NULL,
+		points     REAL NOT NULL,
+		max_points REAL NOT NULL,
+		passed     INTEGER NOT NULL,
+		PRIMARY KEY (exam_id, attempt)
+	);`,
+
+	`CREATE TABLE sessions (
+		subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+		item_id    TEXT NOT NULL,
+		position   INTEGER NOT NULL,
+		type       TEXT NOT NULL,
+		day        TEXT NOT NULL,
+		start_time TEXT NOT NULL,
+		end_time   TEXT NOT NULL,
+		room       TEXT NOT NULL,
+		weeks      TEXT NOT NULL,
+		from_date  TEXT NOT NULL,
+		until_date TEXT NOT NULL
+	);
+	CREATE INDEX sessions_subject ON sessions(subject_id, position);`,
+
+	`ALTER TABLE todos ADD COLUMN repeat TEXT NOT NULL DEFAULT '';
+	CREATE TABLE occurrences (
+		todo_id INTEGER NOT NULL REFERENCES todos(id) ON DELETE CASCADE,
+		date    TEXT NOT NULL,
+		skipped INTEGER NOT NULL
+	);
+	CREATE INDEX occurrences_todo ON occurrences(todo_id);`,
+
+	`ALTER TABLE projects ADD COLUMN auto_status INTEGER NOT NULL DEFAULT 0;
+	CREATE TABLE subtasks (
+		project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		item_id    TEXT NOT NULL,
+		position   INTEGER NOT NULL,
+		text       TEXT NOT NULL,
+		done       INTEGER NOT NULL
+	);
+	CREATE TABLE milestones (
+		project_id INTEGER NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+		item_id    TEXT NOT NULL,
+		position   INTEGER NOT NULL,
+		name       TEXT NOT NULL,
+		date       TEXT NOT NULL,
+		done       INTEGER NOT NULL
+	);
+	CREATE INDEX subtasks_project ON subtasks(project_id, position);
+	CREATE INDEX milestones_project ON milestones(project_id, position);`,
+
+	`ALTER TABLE projects ADD COLUMN start TEXT NOT NULL DEFAULT '';`,
+
+	`ALTER TABLE subjects ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+	CREATE TABLE resources (
+		subject_id INTEGER NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+		item_id    TEXT NOT NULL,
+		position   INTEGER NOT NULL,
+		title      TEXT NOT NULL,
+		target     TEXT NOT NULL
+	);
+	CREATE INDEX resources_subject ON resources(subject_id, position);`,
+}
+
+// SQLiteStore keeps the semester in a SQLite database with a table per kind
+// of item. Settings that have no table of their own are stored as JSON
+// values in the settings table, keyed by their JSON field name.
+//
+// Save only rewrites the tables whose contents changed since the last Load
+// or Save, so toggling a todo does not touch subjects or exams.
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+	last *SemesterData
+}
+
+// OpenSQLite opens (creating if needed) the database at path and brings its
+// schema up to date.
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	s := &SQLiteStore{db: db, path: path}
+	if err := s.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrating %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteStore) migrate() error {
+	var version int
+	if err := s.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	for ; version < len(sqliteMigrations); version++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(sqliteMigrations[version]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, version+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load reads the semester. It reports found == false until the first Save.
+func (s *SQLiteStore) Load() (SemesterData, bool, error) {
+	settings, err := s.loadSettings()
+	if err != nil {
+		return SemesterData{}, false, err
+	}
+	if len(settings) == 0 {
+		return SemesterData{}, false, nil
+	}
+
+	var data SemesterData
+	payload, err := json.Marshal(settings)
+	if err != nil {
+		return SemesterData{}, true, err
+	}
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return SemesterData{}, true, err
+	}
+	if data.Subjects, err = s.loadSubjects(); err != nil {
+		return SemesterData{}, true, err
+	}
+	if data.Projects, err = s.loadProjects(); err != nil {
+		return SemesterData{}, true, err
+	}
+	if data.Checklist, err = s.loadTodos(); err != nil {
+		return SemesterData{}, true, err
+	}
+	data.SchemaVersion = SchemaVersion
+	// Databases have no document to migrate: missing IDs and dates in the
+	// layouts of older builds are fixed up in memory instead. Loading never
+	// writes; the rows are remembered as read, so the next save stores the
+	// fixes along with the change that prompted it.
+	s.remember(data)
+	EnsureIDs(&data)
+	NormalizeDates(&data)
+	return data, true, nil
+}
+
+// Save writes data in a single transaction.
+func (s *SQLiteStore) Save(data SemesterData) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var last SemesterData
+	if s.last != nil {
+		last = *s.last
+	}
+	if s.last == nil || !reflect.DeepEqual(last.Subjects, data.Subjects) {
+		if err := saveSubjects(tx, data.Subjects); err != nil {
+			return err
+		}
+	}
+	if s.last == nil || !reflect.DeepEqual(last.Projects, data.Projects) {
+		if err := saveProjects(tx, data.Projects); err != nil {
+			return err
+		}
+	}
+	if s.last == nil || !reflect.DeepEqual(last.Checklist, data.Checklist) {
+		if err := saveTodos(tx, data.Checklist); err != nil {
+			return err
+		}
+	}
+	if err := saveSettings(tx, data); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.remember(data)
+	return nil
+}
+
+// Backup writes a JSON snapshot next to the database.
+func (s *SQLiteStore) Backup(data SemesterData) (string, error) {
+	return writeBackup(s.path, data)
+}
+
+// Backups lists the JSON snapshots next to the database, newest first.
+func (s *SQLiteStore) Backups() ([]Backup, error) {
+	return listBackups(s.path)
+}
+
+// remember keeps a deep copy of what the database holds, so later saves can
+// skip unchanged tables even though callers reuse their slices.
+func (s *SQLiteStore) remember(data SemesterData) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		s.last = nil
+		return
+	}
+	var copied SemesterData
+	if err := json.Unmarshal(payload, &copied); err != nil {
+		s.last = nil
+		return
+	}
+	s.last = &copied
+}
+
+// settingsOf returns every JSON field of data that has no table (or, like the
+// schema version, no meaning) of its own.
+func settingsOf(data SemesterData) (map[string]json.RawMessage, error) {
+	data.Subjects = nil
+	data.Projects = nil
+	data.Checklist = nil
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	// The database tracks its own schema through PRAGMA user_version.
+	for _, key := range []string{"subjects", "projects", "checklist", "schema_version"} {
+		delete(fields, key)
+	}
+	return fields, nil
+}
+
+func (s *SQLiteStore) loadSettings() (map[string]json.RawMessage, error) {
+	rows, err := s.db.Query(`SELECT key, value FROM settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	settings := map[string]json.RawMessage{}
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = json.RawMessage(value)
+	}
+	return settings, rows.Err()
+}
+
+func saveSettings(tx *sql.Tx, data SemesterData) error {
+	fields, err := settingsOf(data)
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(`INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value WHERE value != excluded.value`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for key, value := range fields {
+		if _, err := stmt.Exec(key, string(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SQLiteStore) loadSubjects() ([]models.SubjectItem, error) {
+	rows, err := s.db.Query(`SELECT id, item_id, code, name, credits, notes FROM subjects ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	var subjects []models.SubjectItem
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var subject models.SubjectItem
+		if err := rows.Scan(&id, &subject.ID, &subject.Code, &subject.Name, &subject.Credits, &subject.Notes); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		subjects = append(subjects, subject)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		exams, err := s.loadExams(id)
+		if err != nil {
+			return nil, err
+		}
+		subjects[i].Exams = exams
+		sessions, err := s.loadSessions(id)
+		if err != nil {
+			return nil, err
+		}
+		subjects[i].Sessions = sessions
+		resources, err := s.loadResources(id)
+		if err != nil {
+			return nil, err
+		}
+		subjects[i].Resources = resources
+	}
+	return subjects, nil
+}
+
+func (s *SQLiteStore) loadResources(subjectID int64) ([]models.Resource, error) {
+	rows, err := s.db.Query(`SELECT item_id, title, target FROM resources WHERE subject_id = ? ORDER BY position`, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var resources []models.Resource
+	for rows.Next() {
+		var r models.Resource
+		if err := rows.Scan(&r.ID, &r.Title, &r.Target); err != nil {
+			return nil, err
+		}
+		resources = append(resources, r)
+	}
+	return resources, rows.Err()
+}
+
+func (s *SQLiteStore) loadSessions(subjectID int64) ([]models.ClassSession, error) {
+	rows, err := s.db.Query(`SELECT item_id, type, day, start_time, end_time, room, weeks, from_date, until_date
+		FROM sessions WHERE subject_id = ? ORDER BY position`, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []models.ClassSession
+	for rows.Next() {
+		var c models.ClassSession
+		if err := rows.Scan(&c.ID, &c.Type, &c.Day, &c.Start, &c.End, &c.Room, &c.Weeks, &c.From, &c.Until); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, c)
+	}
+	return sessions, rows.Err()
+}
+
+func (s *SQLiteStore) loadExams(subjectID int64) ([]models.ExamItem, error) {
+	rows, err := s.db.Query(`SELECT id, item_id, name, date, priority, source_uid, weight FROM exams
+		WHERE subject_id = ? ORDER BY position`, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	var exams []models.ExamItem
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		var exam models.ExamItem
+		if err := rows.Scan(&id, &exam.ID, &exam.Name, &exam.Date, &exam.Priority, &exam.SourceUID, &exam.Weight); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+		exams = append(exams, exam)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, id := range ids {
+		retakes, err := s.loadRetakes(id)
+		if err != nil {
+			return nil, err
+		}
+		exams[i].Retakes = retakes
+		results, err := s.loadResults(id)
+		if err != nil {
+			return nil, err
+		}
+		exams[i].Results = results
+	}
+	return exams, nil
+}
+
+func (s *SQLiteStore) loadRetakes(examID int64) ([]string, error) {
+	rows, err := s.db.Query(`SELECT date FROM retakes WHERE exam_id = ? ORDER BY position`, examID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var retakes []string
+	for rows.Next() {
+		var date string
+		if err := rows.Scan(&date); err != nil {
+			return nil, err
+		}
+		retakes = append(retakes, date)
+	}
+	return retakes, rows.Err()
+}
+
+func (s *SQLiteStore) loadResults(examID int64) ([]models.ExamResult, error) {
+	rows, err := s.db.Query(`SELECT attempt, grade, scale, points, max_points, passed FROM results
+		WHERE exam_id = ? ORDER BY attempt`, examID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []models.ExamResult
+	for rows.Next() {
+		var r models.ExamResult
+		if err := rows.Scan(&r.Attempt, &r.Grade, &r.Scale, &r.Points, &r.MaxPoints, &r.Passed); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+func (s *SQLiteStore) loadProjects() ([]models.ProjectItem, error) {
+	rows, err := s.db.Query(`SELECT id, item_id, name, subject, due, start, status, auto_status FROM projects ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var projects []models.ProjectItem
+	index := map[int64]int{}
+	for rows.Next() {
+		var rowID int64
+		var p models.ProjectItem
+		if err := rows.Scan(&rowID, &p.ID, &p.Name, &p.Subject, &p.Due, &p.Start, &p.Status, &p.AutoStatus); err != nil {
+			return nil, err
+		}
+		index[rowID] = len(projects)
+		projects = append(projects, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	subtasks, err := s.db.Query(`SELECT project_id, item_id, text, done FROM subtasks ORDER BY project_id, position`)
+	if err != nil {
+		return nil, err
+	}
+	defer subtasks.Close()
+	for subtasks.Next() {
+		var projectID int64
+		var t models.Subtask
+		if err := subtasks.Scan(&projectID, &t.ID, &t.Text, &t.Done); err != nil {
+			return nil, err
+		}
+		if i, ok := index[projectID]; ok {
+			projects[i].Subtasks = append(projects[i].Subtasks, t)
+		}
+	}
+	if err := subtasks.Err(); err != nil {
+		return nil, err
+	}
+
+	milestones, err := s.db.Query(`SELECT project_id, item_id, name, date, done FROM milestones ORDER BY project_id, position`)
+	if err != nil {
+		return nil, err
+	}
+	defer milestones.Close()
+	for milestones.Next() {
+		var projectID int64
+		var ms models.Milestone
+		if err := milestones.Scan(&projectID, &ms.ID, &ms.Name, &ms.Date, &ms.Done); err != nil {
+			return nil, err
+		}
+		if i, ok := index[projectID]; ok {
+			projects[i].Milestones = append(projects[i].Milestones, ms)
+		}
+	}
+	return projects, milestones.Err()
+}
+
+func (s *SQLiteStore) loadTodos() ([]models.ChecklistItem, error) {
+	rows, err := s.db.Query(`SELECT id, item_id, text, done, due, subject, repeat FROM todos ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var todos []models.ChecklistItem
+	index := map[int64]int{}
+	for rows.Next() {
+		var rowID int64
+		var item models.ChecklistItem
+		if err := rows.Scan(&rowID, &item.ID, &item.Text, &item.Done, &item.Due, &item.Subject, &item.Repeat); err != nil {
+			return nil, err
+		}
+		index[rowID] = len(todos)
+		todos = append(todos, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	occ, err := s.db.Query(`SELECT todo_id, date, skipped FROM occurrences ORDER BY todo_id, date`)
+	if err != nil {
+		return nil, err
+	}
+	defer occ.Close()
+	for occ.Next() {
+		var todoID int64
+		var date string
+		var skipped bool
+		if err := occ.Scan(&todoID, &date, &skipped); err != nil {
+			return nil, err
+		}
+		i, ok := index[todoID]
+		if !ok {
+			continue
+		}
+		if skipped {
+			todos[i].Skipped = append(todos[i].Skipped, date)
+		} else {
+			todos[i].DoneDates = append(todos[i].DoneDates, date)
+		}
+	}
+	return todos, occ.Err()
+}
+
+// saveSubjects replaces all subjects, exams, retakes, results, class
+// sessions and resources; the foreign keys cascade the delete to everything
+// below them.
+func saveSubjects(tx *sql.Tx, subjects []models.SubjectItem) error {
+	if _, err := tx.Exec(`DELETE FROM subjects`); err != nil {
+		return err
+	}
+	for i, subject := range subjects {
+		res, err := tx.Exec(`INSERT INTO subjects (item_id, position, code, name, credits, notes) VALUES (?, ?, ?, ?, ?, ?)`,
+			subject.ID, i, subject.Code, subject.Name, subject.Credits, subject.Notes)
+		if err != nil {
+			return err
+		}
+		subjectID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for j, c := range subject.Sessions {
+			if _, err := tx.Exec(`INSERT INTO sessions (subject_id, item_id, position, type, day, start_time, end_time, room, weeks, from_date, until_date)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+				subjectID, c.ID, j, c.Type, c.Day, c.Start, c.End, c.Room, c.Weeks, c.From, c.Until); err != nil {
+				return err
+			}
+		}
+		for j, r := range subject.Resources {
+			if _, err := tx.Exec(`INSERT INTO resources (subject_id, item_id, position, title, target) VALUES (?, ?, ?, ?, ?)`,
+				subjectID, r.ID, j, r.Title, r.Target); err != nil {
+				return err
+			}
+		}
+		for j, exam := range subject.Exams {
+			res, err := tx.Exec(`INSERT INTO exams (subject_id, item_id, position, name, date, priority, source_uid, weight)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+				subjectID, exam.ID, j, exam.Name, exam.Date, exam.Priority, exam.SourceUID, exam.Weight)
+			if err != nil {
+				return err
+			}
+			examID, err := res.LastInsertId()
+			if err != nil {
+				return err
+			}
+			for k, date := range exam.Retakes {
+				if _, err := tx.Exec(`INSERT INTO retakes (exam_id, position, date) VALUES (?, ?, ?)`,
+					examID, k, date); err != nil {
+					return err
+				}
+			}
+			for _, r := range exam.Results {
+				if _, err := tx.Exec(`INSERT INTO results (exam_id, attempt, grade, scale, points, max_points, passed)
+					VALUES (?, ?, ?, ?, ?, ?, ?)`,
+					examID, r.Attempt, r.Grade, r.Scale, r.Points, r.MaxPoints, r.Passed); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// saveProjects replaces all projects; the delete cascades to their subtasks
+// and milestones.
+func saveProjects(tx *sql.Tx, projects []models.ProjectItem) error {
+	if _, err := tx.Exec(`DELETE FROM projects`); err != nil {
+		return err
+	}
+	for i, p := range projects {
+		res, err := tx.Exec(`INSERT INTO projects (item_id, position, name, subject, due, start, status, auto_status) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			p.ID, i, p.Name, p.Subject, p.Due, p.Start, p.Status, p.AutoStatus)
+		if err != nil {
+			return err
+		}
+		if len(p.Subtasks) == 0 && len(p.Milestones) == 0 {
+			continue
+		}
+		projectID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for j, t := range p.Subtasks {
+			if _, err := tx.Exec(`INSERT INTO subtasks (project_id, item_id, position, text, done) VALUES (?, ?, ?, ?, ?)`,
+				projectID, t.ID, j, t.Text, t.Done); err != nil {
+				return err
+			}
+		}
+		for j, ms := range p.Milestones {
+			if _, err := tx.Exec(`INSERT INTO milestones (project_id, item_id, position, name, date, done) VALUES (?, ?, ?, ?, ?, ?)`,
+				projectID, ms.ID, j, ms.Name, ms.Date, ms.Done); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// saveTodos replaces all todos; the delete cascades to the occurrences of
+// recurring ones.
+func saveTodos(tx *sql.Tx, todos []models.ChecklistItem) error {
+	if _, err := tx.Exec(`DELETE FROM todos`); err != nil {
+		return err
+	}
+	for i, item := range todos {
+		res, err := tx.Exec(`INSERT INTO todos (item_id, position, text, done, due, subject, repeat) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			item.ID, i, item.Text, item.Done, item.Due, item.Subject, item.Repeat)
+		if err != nil {
+			return err
+		}
+		if len(item.DoneDates) == 0 && len(item.Skipped) == 0 {
+			continue
+		}
+		todoID, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for _, date := range item.DoneDates {
+			if _, err := tx.Exec(`INSERT INTO occurrences (todo_id, date, skipped) VALUES (?, ?, 0)`, todoID, date); err != nil {
+				return err
+			}
+		}
+		for _, date := range item.Skipped {
+			if _, err := tx.Exec(`INSERT INTO occurrences (todo_id, date, skipped) VALUES (?, ?, 1)`, todoID, date); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// MigrateFromJSON copies the JSON data file at jsonPath into the database
+// when the database is still empty. It reports whether anything was copied;
+// the JSON file is left in place as a backup.
+func (s *SQLiteStore) MigrateFromJSON(jsonPath string) (bool, error) {
+	if _, found, err := s.Load(); err != nil || found {
+		return false, err
+	}
+	data, found, err := NewJSONStore(jsonPath).Load()
+	if err != nil || !found {
+		return false, err
+	}
+	if err := s.Save(data); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/romanguyen/seman/internal/models"
+)
+
+// openTestSQLite opens a new database in a temporary directory.
+func openTestSQLite(t *testing.T) (*SQLiteStore, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "semester.db")
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store, path
+}
+
+// column reads a single text value from the database.
+func column(t *testing.T, s *SQLiteStore, query string) string {
+	t.Helper()
+	var value string
+	if err := s.db.QueryRow(query).Scan(&value); err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return value
+}
+
+func TestSQLiteLoadDoesNotWrite(t *testing.T) {
+	store, path := openTestSQLite(t)
+	data := SemesterData{
+		SchemaVersion: SchemaVersion,
+		Subjects: []models.SubjectItem{{
+			ID: "s1", Code: "DB", Name: "Databases",
+			Exams: []models.ExamItem{{ID: "e1", Name: "Final", Date: "2026-01-14T00:00:00+09:00"}},
+		}},
+		Checklist: []models.ChecklistItem{{ID: "t1", Text: "Read", Due: "Jan 14, 2026"}},
+	}
+	if err := store.Save(data); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Rows as an older build left them: no ID, a legacy date.
+	if _, err := store.db.Exec(`UPDATE todos SET item_id = ''`); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer reopened.Close()
+	loaded, _, err := reopened.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := loaded.Subjects[0].Exams[0].Date; got != "2026-01-14T00:00:00+09:00" {
+		t.Errorf("exam date = %q, want the stored value", got)
+	}
+	if got := loaded.Checklist[0]; got.Due != "2026-01-14" || got.ID == "" {
+		t.Errorf("todo = %+v, want a normalized date and an ID", got)
+	}
+	if got := column(t, reopened, `SELECT due FROM todos`); got != "Jan 14, 2026" {
+		t.Errorf("Load rewrote the todo's date to %q", got)
+	}
+	if got := column(t, reopened, `SELECT item_id FROM todos`); got != "" {
+		t.Errorf("Load wrote the todo's ID %q", got)
+	}
+
+	// The fixes are stored with the next save.
+	if err := reopened.Save(loaded); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got := column(t, reopened, `SELECT due FROM todos`); got != "2026-01-14" {
+		t.Errorf("saved todo date = %q, want 2026-01-14", got)
+	}
+	if got := column(t, reopened, `SELECT item_id FROM todos`); got != loaded.Checklist[0].ID {
+		t.Errorf("saved todo ID = %q, want %q", got, loaded.Checklist[0].ID)
+	}
+}
+
+// assertSameData compares data as it is written to a JSON file, where an
+// empty list and no list are the same.
+func assertSameData(t *testing.T, got, want SemesterData) {
+	t.Helper()
+	gotJSON, err := json.MarshalIndent(got, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON, err := json.MarshalIndent(want, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("data =\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
+
+// fullData has an item of every kind the database stores, with every field
+// set.
+func fullData() SemesterData {
+	return SemesterData{
+		SchemaVersion: SchemaVersion,
+		Subjects: []models.SubjectItem{
+			{
+				ID: "s1", Code: "DB", Name: "Databases", Credits: 6,
+				Notes: "# Syllabus\n- SQL",
+				Exams: []models.ExamItem{{
+					ID: "e1", Name: "Final", Date: "2026-01-14T10:00:00+01:00",
+					Retakes:  []string{"2026-02-01", "2026-02-15T09:00:00+01:00"},
+					Priority: "HIGH", SourceUID: "uid-1", Weight: 60,
+					Results: []models.ExamResult{
+						{Attempt: 1, Grade: "F", Scale: "ects", Points: 20, MaxPoints: 100},
+						{Attempt: 2, Grade: "B", Scale: "ects", Points: 85, MaxPoints: 100, Passed: true},
+					},
+				}},
+				Sessions: []models.ClassSession{
+					{ID: "c1", Type: "Lecture", Day: "Mon", Start: "10:00", End: "11:30", Room: "A1", Weeks: "odd", From: "2025-10-01", Until: "2026-01-10"},
+				},
+				Resources: []models.Resource{{ID: "r1", Title: "Slides", Target: "https://example.com/db"}},
+			},
+			{ID: "s2", Code: "OS", Name: "Operating Systems"},
+		},
+		Projects: []models.ProjectItem{{
+			ID: "p1", Name: "Shell", Subject: "OS", Due: "2026-01-20", Status: "IN PROGRESS",
+			Start: "2025-12-01", AutoStatus: true,
+			Subtasks:   []models.Subtask{{ID: "st1", Text: "Parser", Done: true}, {ID: "st2", Text: "Pipes"}},
+			Milestones: []models.Milestone{{ID: "m1", Name: "Demo", Date: "2026-01-10", Done: true}},
+		}},
+		Checklist: []models.ChecklistItem{
+			{ID: "t1", Text: "Read ch. 3", Due: "2026-01-12", Subject: "DB", Done: true},
+			{ID: "t2", Text: "Gym", Due: "2026-01-05T18:00:00+01:00", Repeat: "FREQ=WEEKLY;BYDAY=MO,TH", DoneDates: []string{"2026-01-05"}, Skipped: []string{"2026-01-08"}},
+		},
+		WeeklyExams:       []string{"Quiz"},
+		ConfirmOn:         true,
+		WeekStart:         "2026-01-12",
+		WeekSpan:          2,
+		LofiEnabled:       true,
+		LofiURL:           "https://example.com/lofi.m3u",
+		Theme:             "light",
+		PriorityRules:     models.PriorityRules{HighDays: 5, MedDays: 10, AutoDefault: true},
+		ICSSubjectPattern: `[A-Z]{2}`,
+		GradingScale:      "ects",
+		DateFormat:        "iso",
+	}
+}
+
+func TestSQLiteRoundTrip(t *testing.T) {
+	store, path := openTestSQLite(t)
+	if _, found, err := store.Load(); err != nil || found {
+		t.Fatalf("Load of a new database = found %v, %v; want nothing", found, err)
+	}
+	want := fullData()
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	store.Close()
+
+	reopened, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer reopened.Close()
+	got, found, err := reopened.Load()
+	if err != nil || !found {
+		t.Fatalf("Load = found %v, %v", found, err)
+	}
+	assertSameData(t, got, want)
+}
+
+func TestSQLiteMigratesFromVersionZero(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "semester.db")
+	// A database as the first release left it: the first schema only,
+	// with a subject, an exam, a retake, a project and a todo.
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stmt := range []string{
+		sqliteMigrations[0],
+		`PRAGMA user_version = 1`,
+		`INSERT INTO settings (key, value) VALUES ('theme', '"light"'), ('weekly_exams', '[]')`,
+		`INSERT INTO subjects (id, position, code, name) VALUES (1, 0, 'DB', 'Databases')`,
+		`INSERT INTO exams (id, subject_id, position, name, date, priority, source_uid) VALUES (1, 1, 0, 'Final', 'Jan 14, 2026 @ 10:00', 'HIGH', '')`,
+		`INSERT INTO retakes (exam_id, position, date) VALUES (1, 0, '2026-02-01')`,
+		`INSERT INTO projects (id, position, name, subject, due, status) VALUES (1, 0, 'Shell', 'DB', '2026-01-20', 'TODO')`,
+		`INSERT INTO todos (id, position, text, done, due, subject) VALUES (1, 0, 'Read', 1, '', 'DB')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	store, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatalf("OpenSQLite: %v", err)
+	}
+	defer store.Close()
+	var version int
+	if err := store.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		t.Fatal(err)
+	}
+	if version != len(sqliteMigrations) {
+		t.Errorf("user_version = %d, want %d", version, len(sqliteMigrations))
+	}
+
+	data, found, err := store.Load()
+	if err != nil || !found {
+		t.Fatalf("Load = found %v, %v", found, err)
+	}
+	if data.Theme != "light" || data.SchemaVersion != SchemaVersion {
+		t.Errorf("Theme, SchemaVersion = %q, %d", data.Theme, data.SchemaVersion)
+	}
+	subject := data.Subjects[0]
+	if subject.ID == "" || subject.Code != "DB" || subject.Notes != "" || subject.Credits != 0 {
+		t.Errorf("subject = %+v", subject)
+	}
+	exam := subject.Exams[0]
+	if exam.ID == "" || exam.Date != "2026-01-14T10:00:00"+time.Date(2026, 1, 14, 10, 0, 0, 0, time.Local).Format("Z07:00") ||
+		!reflect.DeepEqual(exam.Retakes, []string{"2026-02-01"}) || exam.Weight != 0 || len(exam.Results) != 0 {
+		t.Errorf("exam = %+v", exam)
+	}
+	if p := data.Projects[0]; p.ID == "" || p.Name != "Shell" || p.AutoStatus || len(p.Subtasks) != 0 || p.Start != "" {
+		t.Errorf("project = %+v", p)
+	}
+	if todo := data.Checklist[0]; todo.ID == "" || !todo.Done || todo.Repeat != "" {
+		t.Errorf("todo = %+v", todo)
+	}
+
+	// A new, empty database gets the whole schema.
+	empty, _ := openTestSQLite(t)
+	if err := empty.db.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil || version != len(sqliteMigrations) {
+		t.Errorf("user_version of a new database = %d, %v", version, err)
+	}
+}
+
+func TestMigrateFromJSON(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "semester.json")
+	store, _ := openTestSQLite(t)
+
+	// Nothing to copy without a JSON file.
+	if copied, err := store.MigrateFromJSON(jsonPath); err != nil || copied {
+		t.Fatalf("MigrateFromJSON without a file = %v, %v", copied, err)
+	}
+
+	want := fullData()
+	if err := NewJSONStore(jsonPath).Save(want); err != nil {
+		t.Fatal(err)
+	}
+	copied, err := store.MigrateFromJSON(jsonPath)
+	if err != nil || !copied {
+		t.Fatalf("MigrateFromJSON = %v, %v; want a copy", copied, err)
+	}
+	got, _, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameData(t, got, want)
+
+	// Once the database has data, the JSON file is left alone.
+	changed := want
+	changed.Theme = "green"
+	if err := NewJSONStore(jsonPath).Save(changed); err != nil {
+		t.Fatal(err)
+	}
+	if copied, err := store.MigrateFromJSON(jsonPath); err != nil || copied {
+		t.Errorf("second MigrateFromJSON = %v, %v; want nothing copied", copied, err)
+	}
+	if got, _, _ := store.Load(); got.Theme != want.Theme {
+		t.Errorf("Theme = %q, want %q", got.Theme, want.Theme)
+	}
+}
+
+func TestSQLiteSaveSkipsUnchangedTables(t *testing.T) {
+	store, _ := openTestSQLite(t)
+	data := fullData()
+	if err := store.Save(data); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	// Mark every table, to tell which ones the next save rewrites.
+	for _, stmt := range []string{
+		`UPDATE subjects SET name = 'marked'`,
+		`UPDATE projects SET name = 'marked'`,
+		`UPDATE todos SET text = 'marked'`,
+	} {
+		if _, err := store.db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := func() [3]string {
+		return [3]string{
+			column(t, store, `SELECT name FROM subjects ORDER BY position LIMIT 1`),
+			column(t, store, `SELECT name FROM projects ORDER BY position LIMIT 1`),
+			column(t, store, `SELECT text FROM todos ORDER BY position LIMIT 1`),
+		}
+	}
+
+	// The caller changes its own slices in place; the store must not
+	// mistake them for what it saved.
+	data.Checklist[0].Done = false
+	data.Theme = "green"
+	if err := store.Save(data); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, want := names(), [3]string{"marked", "marked", "Read ch. 3"}; got != want {
+		t.Errorf("after a todo change the names are %q, want %q", got, want)
+	}
+	if got := column(t, store, `SELECT value FROM settings WHERE key = 'theme'`); got != `"green"` {
+		t.Errorf("theme = %s, want the settings saved", got)
+	}
+
+	data.Subjects[0].Exams[0].Retakes[0] = "2026-02-02"
+	if err := store.Save(data); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, want := names(), [3]string{"Databases", "marked", "Read ch. 3"}; got != want {
+		t.Errorf("after an exam change the names are %q, want %q", got, want)
+	}
+
+	// Loading remembers what the database holds too.
+	if _, _, err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.db.Exec(`UPDATE subjects SET name = 'marked'`); err != nil {
+		t.Fatal(err)
+	}
+	data.Projects[0].Milestones[0].Done = false
+	if err := store.Save(data); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got, want := names(), [3]string{"marked", "Shell", "Read ch. 3"}; got != want {
+		t.Errorf("after a project change the names are %q, want %q", got, want)
+	}
+}
@@ -2,17 +2,32 @@ package storage
 
 import "github.com/romanguyen/seman/internal/models"
 
+// SchemaVersion is the version of the JSON layout written by this build.
+// Files with an older version are upgraded by the migrations in migrate.go.
+const SchemaVersion = 9
+
 type SemesterData struct {
-	Subjects    []models.SubjectItem   `json:"subjects"`
-	Projects    []models.ProjectItem   `json:"projects"`
-	Checklist   []models.ChecklistItem `json:"checklist"`
-	WeeklyExams []string               `json:"weekly_exams"`
-	ConfirmOn   bool                   `json:"confirm_on"`
-	WeekStart   string                 `json:"week_start"`
-	WeekSpan    int                    `json:"week_span"`
-	LofiEnabled bool                   `json:"lofi_enabled"`
-	LofiURL     string                 `json:"lofi_url"`
-	Theme       string                 `json:"theme"`
+	SchemaVersion int                    `json:"schema_version"`
+	Subjects      []models.SubjectItem   `json:"subjects"`
+	Projects      []models.ProjectItem   `json:"projects"`
+	Checklist     []models.ChecklistItem `json:"checklist"`
+	WeeklyExams   []string               `json:"weekly_exams"`
+	ConfirmOn     bool                   `json:"confirm_on"`
+	WeekStart     string                 `json:"week_start"`
+	WeekSpan      int                    `json:"week_span"`
+	LofiEnabled   bool                   `json:"lofi_enabled"`
+	LofiURL       string                 `json:"lofi_url"`
+	Theme         string                 `json:"theme"`
+	PriorityRules models.PriorityRules   `json:"priority_rules"`
+	// ICSSubjectPattern is the regex that finds subject codes in imported
+	// calendar events; empty means the built-in default.
+	ICSSubjectPattern string `json:"ics_subject_pattern"`
+	// GradingScale names the scale grades and the GPA are shown in; see
+	// models.GradingScaleNames.
+	GradingScale string `json:"grading_scale"`
+	// DateFormat names the way dates are shown; see dates.Formats. Dates are
+	// stored as RFC 3339 whatever it is.
+	DateFormat string `json:"date_format"`
 }
 
 type Store interface {
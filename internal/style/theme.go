@@ -2,8 +2,8 @@ package style
 
 import "github.com/charmbracelet/lipgloss"
 
-// ThemeNames is the ordered list of available theme names.
-var ThemeNames = []string{"green", "dracula", "tokyo-night", "one-dark", "nord", "gruvbox", "monokai", "catppuccin"}
+// ThemeNames is the ordered list of built-in theme names.
+var ThemeNames = []string{"green", "dracula", "tokyo-night", "one-dark", "nord", "gruvbox", "monokai", "catppuccin", "paper", "solarized-light"}
 
 type Theme struct {
 	Accent        lipgloss.Color
@@ -22,6 +22,10 @@ type Theme struct {
 	StatusDone    lipgloss.Style
 	StatusInProg  lipgloss.Style
 	StatusNotStr  lipgloss.Style
+	PriorityHigh  lipgloss.Style
+	PriorityMed   lipgloss.Style
+	PriorityLow   lipgloss.Style
+	PriorityNone  lipgloss.Style
 	RowActive     lipgloss.Style
 	ModalBorder   lipgloss.Style
 	ModalTitle    lipgloss.Style
@@ -31,12 +35,36 @@ type Theme struct {
 	InputHint     lipgloss.Style
 	InputCursor   lipgloss.Style
 	SubjectTag    lipgloss.Style
+	Overdue       lipgloss.Style
 }
 
+// themeSpec is what a theme is built from. Only accent, border and rowBg are
+// required; the other colours default to those of dark or light themes, and
+// styles overrides the built styles by field name (see Theme.styleFields).
 type themeSpec struct {
+	light  bool
 	accent lipgloss.Color
 	border lipgloss.Color
 	rowBg  lipgloss.Color
+	// errColor marks errors and overdue items; badgeText is the text on
+	// status and priority badges.
+	errColor   lipgloss.Color
+	badgeText  lipgloss.Color
+	high       lipgloss.Color
+	med        lipgloss.Color
+	low        lipgloss.Color
+	none       lipgloss.Color
+	done       lipgloss.Color
+	inProgress lipgloss.Color
+	notStarted lipgloss.Color
+	styles     map[string]styleOverride
+}
+
+// styleOverride changes some attributes of a built style; unset ones keep
+// their value.
+type styleOverride struct {
+	fg, bg, border                 lipgloss.Color
+	bold, italic, underline, faint *bool
 }
 
 var themeSpecs = map[string]themeSpec{
@@ -56,15 +84,30 @@ var themeSpecs = map[string]themeSpec{
 	"monokai": {accent: "#a6e22e", border: "#75715e", rowBg: "#272822"},
 	// Catppuccin Mocha — lavender mauve on dark base
 	"catppuccin": {accent: "#cba6f7", border: "#585b70", rowBg: "#181825"},
+	// Paper — ink blue on a light background
+	"paper": {light: true, accent: "#005f87", border: "#8a8a8a", rowBg: "#dadada"},
+	// Solarized Light — teal on warm cream
+	"solarized-light": {light: true, accent: "#268bd2", border: "#93a1a1", rowBg: "#eee8d5"},
 }
 
-// ThemeOf returns the Theme for the given name, defaulting to "green" if unknown.
-func ThemeOf(name string) Theme {
-	spec, ok := themeSpecs[name]
-	if !ok {
-		spec = themeSpecs["green"]
+// darkColors and lightColors fill in the colours a spec leaves out.
+var (
+	darkColors = themeSpec{
+		errColor: "#ff5f5f", badgeText: "#0c0c0c",
+		high: "#ff5f5f", med: "#d4a017", low: "#3a7f3a", none: "#39ff14",
+		done: "#3a7f3a", inProgress: "#d4a017", notStarted: "#1e5f1e",
+	}
+	lightColors = themeSpec{
+		errColor: "#d70000", badgeText: "#ffffff",
+		high: "#d70000", med: "#af8700", low: "#5f8700", none: "#0087af",
+		done: "#5f8700", inProgress: "#af8700", notStarted: "#808080",
 	}
-	return buildTheme(spec.accent, spec.border, spec.rowBg)
+)
+
+// ThemeOf returns the built-in Theme for the given name, defaulting to
+// "green" if unknown.
+func ThemeOf(name string) Theme {
+	return Themes{}.Of(name)
 }
 
 // NewTheme returns the default green theme (backwards compat).
@@ -72,10 +115,36 @@ func NewTheme() Theme {
 	return ThemeOf("green")
 }
 
-func buildTheme(accent, border, rowBg lipgloss.Color) Theme {
+// withDefaults fills in the colours spec leaves out.
+func (spec themeSpec) withDefaults() themeSpec {
+	def := darkColors
+	if spec.light {
+		def = lightColors
+	}
+	for _, c := range []struct{ v, d *lipgloss.Color }{
+		{&spec.errColor, &def.errColor}, {&spec.badgeText, &def.badgeText},
+		{&spec.high, &def.high}, {&spec.med, &def.med}, {&spec.low, &def.low}, {&spec.none, &def.none},
+		{&spec.done, &def.done}, {&spec.inProgress, &def.inProgress}, {&spec.notStarted, &def.notStarted},
+	} {
+		if *c.v == "" {
+			*c.v = *c.d
+		}
+	}
+	return spec
+}
+
+func buildTheme(spec themeSpec) Theme {
+	spec = spec.withDefaults()
+	accent, border := spec.accent, spec.border
 	text := lipgloss.NewStyle().Foreground(accent)
 	dim := lipgloss.NewStyle().Foreground(border)
-	return Theme{
+	badge := func(bg lipgloss.Color) lipgloss.Style {
+		return lipgloss.NewStyle().Foreground(spec.badgeText).Background(bg).Padding(0, 1)
+	}
+	priority := func(bg lipgloss.Color) lipgloss.Style {
+		return lipgloss.NewStyle().Bold(true).Foreground(spec.badgeText).Background(bg)
+	}
+	t := Theme{
 		Accent:        accent,
 		Border:        border,
 		Text:          text,
@@ -89,17 +158,88 @@ func buildTheme(accent, border, rowBg lipgloss.Color) Theme {
 		ProjectDetail: dim.Copy(),
 		SubjectActive: text.Copy().Bold(true),
 		SubjectDim:    dim.Copy(),
-		StatusDone:    lipgloss.NewStyle().Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#3a7f3a")).Padding(0, 1),
-		StatusInProg:  lipgloss.NewStyle().Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#d4a017")).Padding(0, 1),
-		StatusNotStr:  lipgloss.NewStyle().Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#1e5f1e")).Padding(0, 1),
-		RowActive:     lipgloss.NewStyle().Foreground(accent).Background(rowBg).Bold(true),
+		StatusDone:    badge(spec.done),
+		StatusInProg:  badge(spec.inProgress),
+		StatusNotStr:  badge(spec.notStarted),
+		PriorityHigh:  priority(spec.high),
+		PriorityMed:   priority(spec.med),
+		PriorityLow:   priority(spec.low),
+		PriorityNone:  priority(spec.none),
+		RowActive:     lipgloss.NewStyle().Foreground(accent).Background(spec.rowBg).Bold(true),
 		ModalBorder:   lipgloss.NewStyle().Border(lipgloss.NormalBorder()).BorderForeground(accent),
 		ModalTitle:    text.Copy().Bold(true),
 		ModalHint:     dim.Copy(),
-		ModalError:    lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5f5f")),
+		ModalError:    lipgloss.NewStyle().Foreground(spec.errColor),
 		InputText:     text.Copy(),
 		InputHint:     dim.Copy(),
 		InputCursor:   text.Copy(),
 		SubjectTag:    lipgloss.NewStyle().Foreground(accent).Background(border).Padding(0, 1).Bold(true),
+		Overdue:       lipgloss.NewStyle().Foreground(spec.errColor),
+	}
+	fields := t.styleFields()
+	for name, o := range spec.styles {
+		if s, ok := fields[name]; ok {
+			*s = o.apply(*s)
+		}
+	}
+	return t
+}
+
+// styleFields maps the names theme files use for the styles of t to them.
+func (t *Theme) styleFields() map[string]*lipgloss.Style {
+	return map[string]*lipgloss.Style{
+		"text":           &t.Text,
+		"dim":            &t.Dim,
+		"title":          &t.Title,
+		"tab_active":     &t.TabActive,
+		"tab_inactive":   &t.TabInactive,
+		"checkbox_done":  &t.CheckboxDone,
+		"checkbox_todo":  &t.CheckboxTodo,
+		"footer_hint":    &t.FooterHint,
+		"project_detail": &t.ProjectDetail,
+		"subject_active": &t.SubjectActive,
+		"subject_dim":    &t.SubjectDim,
+		"status_done":    &t.StatusDone,
+		"status_in_prog": &t.StatusInProg,
+		"status_not_str": &t.StatusNotStr,
+		"priority_high":  &t.PriorityHigh,
+		"priority_med":   &t.PriorityMed,
+		"priority_low":   &t.PriorityLow,
+		"priority_none":  &t.PriorityNone,
+		"row_active":     &t.RowActive,
+		"modal_border":   &t.ModalBorder,
+		"modal_title":    &t.ModalTitle,
+		"modal_hint":     &t.ModalHint,
+		"modal_error":    &t.ModalError,
+		"input_text":     &t.InputText,
+		"input_hint":     &t.InputHint,
+		"input_cursor":   &t.InputCursor,
+		"subject_tag":    &t.SubjectTag,
+		"overdue":        &t.Overdue,
+	}
+}
+
+func (o styleOverride) apply(s lipgloss.Style) lipgloss.Style {
+	if o.fg != "" {
+		s = s.Foreground(o.fg)
+	}
+	if o.bg != "" {
+		s = s.Background(o.bg)
+	}
+	if o.border != "" {
+		s = s.BorderForeground(o.border)
+	}
+	if o.bold != nil {
+		s = s.Bold(*o.bold)
+	}
+	if o.italic != nil {
+		s = s.Italic(*o.italic)
+	}
+	if o.underline != nil {
+		s = s.Underline(*o.underline)
+	}
+	if o.faint != nil {
+		s = s.Faint(*o.faint)
 	}
+	return s
 }
@@ -0,0 +1,263 @@
+package style
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/config"
+)
+
+// Themes is the set of themes to choose from: the built-in ones and those of
+// theme files. The zero value holds the built-in ones.
+type Themes struct {
+	names []string
+	specs map[string]themeSpec
+}
+
+// Names lists the themes in the order Settings cycles through them: the
+// built-in ones, then those of theme files.
+func (ts Themes) Names() []string {
+	if ts.names == nil {
+		return ThemeNames
+	}
+	return ts.names
+}
+
+// Has reports whether there is a theme with the name.
+func (ts Themes) Has(name string) bool {
+	_, ok := ts.spec(name)
+	return ok
+}
+
+// Of returns the Theme for the given name, defaulting to "green" if unknown.
+func (ts Themes) Of(name string) Theme {
+	spec, ok := ts.spec(name)
+	if !ok {
+		spec = themeSpecs["green"]
+	}
+	return buildTheme(spec)
+}
+
+func (ts Themes) spec(name string) (themeSpec, bool) {
+	if ts.specs == nil {
+		spec, ok := themeSpecs[name]
+		return spec, ok
+	}
+	spec, ok := ts.specs[name]
+	return spec, ok
+}
+
+// LoadThemes returns the built-in themes and those of the .toml and .json
+// files in dir, each named after its file; a file may replace a built-in
+// theme by taking its name. Files that cannot be read are left out and
+// reported, so one mistake does not cost the others.
+func LoadThemes(dir string) (Themes, []error) {
+	ts := Themes{names: append([]string(nil), ThemeNames...), specs: map[string]themeSpec{}}
+	for name, spec := range themeSpecs {
+		ts.specs[name] = spec
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return ts, nil
+	}
+	if err != nil {
+		return ts, []error{err}
+	}
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || ext != ".toml" && ext != ".json" {
+			continue
+		}
+		spec, err := readThemeFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		name := strings.ToLower(strings.TrimSuffix(entry.Name(), ext))
+		if _, ok := ts.specs[name]; !ok {
+			ts.names = append(ts.names, name)
+		}
+		ts.specs[name] = spec
+	}
+	return ts, errs
+}
+
+func readThemeFile(path string) (themeSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return themeSpec{}, err
+	}
+	var doc config.Document
+	if filepath.Ext(path) == ".json" {
+		doc, err = config.ParseJSON(data)
+	} else {
+		doc, err = config.ParseTOML(string(data))
+	}
+	if err != nil {
+		return themeSpec{}, err
+	}
+	return parseTheme(doc)
+}
+
+// parseTheme reads a theme file: an optional built-in base theme and light
+// flag, colours in [colors], and tables named after Theme styles that
+// override them, e.g.
+//
+//	base = "paper"
+//
+//	[colors]
+//	accent = "#6c3483"
+//	high = "#c0392b"
+//
+//	[row_active]
+//	fg = "#ffffff"
+//	bg = "#6c3483"
+//
+// Without a base, a theme starts from "green", or "paper" when it is light.
+func parseTheme(doc config.Document) (themeSpec, error) {
+	top := doc[""]
+	if err := knownKeys(top, "base", "light"); err != nil {
+		return themeSpec{}, err
+	}
+	light, hasLight := top["light"].(bool)
+	if _, ok := top["light"]; ok && !hasLight {
+		return themeSpec{}, fmt.Errorf("light must be true or false")
+	}
+	base, ok := top["base"].(string)
+	if _, set := top["base"]; set && !ok {
+		return themeSpec{}, fmt.Errorf("base must be a theme name")
+	}
+	if base == "" {
+		base = "green"
+		if light {
+			base = "paper"
+		}
+	}
+	spec, ok := themeSpecs[base]
+	if !ok {
+		return themeSpec{}, fmt.Errorf("unknown base theme %q (one of %s)", base, strings.Join(ThemeNames, ", "))
+	}
+	if hasLight {
+		spec.light = light
+	}
+	styles := map[string]styleOverride{}
+	for name, o := range spec.styles {
+		styles[name] = o
+	}
+	spec.styles = styles
+
+	colors := map[string]*lipgloss.Color{
+		"accent": &spec.accent, "border": &spec.border, "row_bg": &spec.rowBg,
+		"error": &spec.errColor, "badge_text": &spec.badgeText,
+		"high": &spec.high, "med": &spec.med, "low": &spec.low, "none": &spec.none,
+		"done": &spec.done, "in_progress": &spec.inProgress, "not_started": &spec.notStarted,
+	}
+	fields := (&Theme{}).styleFields()
+	tables := make([]string, 0, len(doc))
+	for name := range doc {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+	for _, name := range tables {
+		table := doc[name]
+		switch _, isStyle := fields[name]; {
+		case name == "":
+		case name == "colors":
+			for _, key := range sortedKeys(table) {
+				dst, ok := colors[key]
+				if !ok {
+					return themeSpec{}, fmt.Errorf("[colors]: unknown colour %s", key)
+				}
+				c, err := parseColor(table[key])
+				if err != nil {
+					return themeSpec{}, fmt.Errorf("[colors]: %s: %v", key, err)
+				}
+				*dst = c
+			}
+		case isStyle:
+			o, err := parseStyleOverride(styles[name], table)
+			if err != nil {
+				return themeSpec{}, fmt.Errorf("[%s]: %v", name, err)
+			}
+			styles[name] = o
+		default:
+			return themeSpec{}, fmt.Errorf("unknown table [%s]", name)
+		}
+	}
+	return spec, nil
+}
+
+func parseStyleOverride(o styleOverride, table config.Table) (styleOverride, error) {
+	colors := map[string]*lipgloss.Color{"fg": &o.fg, "bg": &o.bg, "border": &o.border}
+	flags := map[string]**bool{"bold": &o.bold, "italic": &o.italic, "underline": &o.underline, "faint": &o.faint}
+	for _, key := range sortedKeys(table) {
+		if dst, ok := colors[key]; ok {
+			c, err := parseColor(table[key])
+			if err != nil {
+				return o, fmt.Errorf("%s: %v", key, err)
+			}
+			*dst = c
+			continue
+		}
+		dst, ok := flags[key]
+		if !ok {
+			return o, fmt.Errorf("unknown attribute %s (fg, bg, border, bold, italic, underline or faint)", key)
+		}
+		on, ok := table[key].(bool)
+		if !ok {
+			return o, fmt.Errorf("%s must be true or false", key)
+		}
+		*dst = &on
+	}
+	return o, nil
+}
+
+// parseColor accepts a hex colour ("#rgb" or "#rrggbb") or an ANSI colour
+// number from 0 to 255.
+func parseColor(v any) (lipgloss.Color, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = strings.TrimSpace(v)
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return "", fmt.Errorf("a colour is a string like \"#1e5f1e\" or a number from 0 to 255")
+	}
+	if strings.HasPrefix(s, "#") {
+		if _, err := strconv.ParseUint(s[1:], 16, 32); err == nil && (len(s) == 4 || len(s) == 7) {
+			return lipgloss.Color(strings.ToLower(s)), nil
+		}
+	} else if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(s), nil
+	}
+	return "", fmt.Errorf("malformed colour %q (use \"#rrggbb\" or 0-255)", s)
+}
+
+func knownKeys(table config.Table, keys ...string) error {
+	for _, key := range sortedKeys(table) {
+		known := false
+		for _, k := range keys {
+			known = known || k == key
+		}
+		if !known {
+			return fmt.Errorf("unknown key %s", key)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(table config.Table) []string {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
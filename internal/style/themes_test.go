@@ -0,0 +1,203 @@
+package style
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/config"
+)
+
+func parseThemeText(t *testing.T, text string) (themeSpec, error) {
+	t.Helper()
+	doc, err := config.ParseTOML(text)
+	if err != nil {
+		t.Fatalf("ParseTOML(%q): %v", text, err)
+	}
+	return parseTheme(doc)
+}
+
+func TestParseThemeBase(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		base   string // the built-in theme the colours come from
+		light  bool
+		accent lipgloss.Color
+	}{
+		{"empty", "", "green", false, "#39ff14"},
+		{"light without base", "light = true", "paper", true, "#005f87"},
+		{"dark without base", "light = false", "green", false, "#39ff14"},
+		{"base", `base = "nord"`, "nord", false, "#88c0d0"},
+		{"light base", `base = "solarized-light"`, "solarized-light", true, themeSpecs["solarized-light"].accent},
+		{"dark base made light", "base = \"dracula\"\nlight = true", "dracula", true, "#ff79c6"},
+		{"light base made dark", "base = \"paper\"\nlight = false", "paper", false, "#005f87"},
+		{"colour over the base", "base = \"nord\"\n[colors]\naccent = \"#ABC\"", "nord", false, "#abc"},
+	}
+	for _, tt := range tests {
+		spec, err := parseThemeText(t, tt.text)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if spec.light != tt.light || spec.accent != tt.accent {
+			t.Errorf("%s: light = %v, accent = %q; want %v, %q", tt.name, spec.light, spec.accent, tt.light, tt.accent)
+		}
+		if base := themeSpecs[tt.base]; spec.border != base.border || spec.rowBg != base.rowBg {
+			t.Errorf("%s: border %q and row %q, want those of %s", tt.name, spec.border, spec.rowBg, tt.base)
+		}
+	}
+}
+
+func TestParseThemeOverrides(t *testing.T) {
+	spec, err := parseThemeText(t, `
+[colors]
+high = 196
+done = " #2E7D32 "
+badge_text = "0"
+
+[row_active]
+fg = "#ffffff"
+bg = 24
+bold = false
+
+[overdue]
+underline = true
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.high != "196" || spec.done != "#2e7d32" || spec.badgeText != "0" {
+		t.Errorf("colours = high %q, done %q, badge text %q", spec.high, spec.done, spec.badgeText)
+	}
+	row := spec.styles["row_active"]
+	if row.fg != "#ffffff" || row.bg != "24" || row.bold == nil || *row.bold || row.italic != nil {
+		t.Errorf("row_active = %+v", row)
+	}
+	if o := spec.styles["overdue"]; o.underline == nil || !*o.underline {
+		t.Errorf("overdue = %+v", o)
+	}
+	if len(themeSpecs["green"].styles) != 0 {
+		t.Error("the overrides changed the built-in theme")
+	}
+
+	theme := buildTheme(spec)
+	if got := theme.RowActive.GetForeground(); got != lipgloss.Color("#ffffff") {
+		t.Errorf("RowActive foreground = %v", got)
+	}
+	if theme.RowActive.GetBold() || !theme.Overdue.GetUnderline() {
+		t.Error("the style flags were not applied")
+	}
+}
+
+func TestParseThemeErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`name = "mine"`, "unknown key name"},
+		{`light = "yes"`, "light must be true or false"},
+		{`base = 3`, "base must be a theme name"},
+		{`base = "solarized"`, `unknown base theme "solarized" (one of green, dracula`},
+		{"[rows]\nfg = \"#fff\"", "unknown table [rows]"},
+		{"[colors]\nprimary = \"#fff\"", "[colors]: unknown colour primary"},
+		{"[colors]\naccent = \"#12345\"", `[colors]: accent: malformed colour "#12345"`},
+		{"[colors]\naccent = true", "[colors]: accent: a colour is a string"},
+		{"[title]\ncolour = \"#fff\"", "[title]: unknown attribute colour (fg, bg, border"},
+		{"[title]\nbold = \"yes\"", "[title]: bold must be true or false"},
+		{"[title]\nfg = 256", `[title]: fg: malformed colour "256"`},
+	}
+	for _, tt := range tests {
+		_, err := parseThemeText(t, tt.text)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseTheme(%q) = %v, want an error with %q", tt.text, err, tt.want)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		value any
+		want  lipgloss.Color // empty: an error
+	}{
+		{"#fff", "#fff"},
+		{"#1E5F1E", "#1e5f1e"},
+		{" #1e5f1e ", "#1e5f1e"},
+		{"0", "0"},
+		{"255", "255"},
+		{" 42 ", "42"},
+		{float64(196), "196"},
+		{"#ffff", ""},
+		{"#12345", ""},
+		{"#1234567", ""},
+		{"#ggg", ""},
+		{"#", ""},
+		{"fff", ""},
+		{"red", ""},
+		{"256", ""},
+		{"-1", ""},
+		{"", ""},
+		{float64(3.5), ""},
+		{float64(300), ""},
+		{true, ""},
+		{[]string{"#fff"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := parseColor(tt.value)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("parseColor(%#v) = %q, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseColor(%#v) = %q, %v; want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestLoadThemes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Mine.toml":  "base = \"nord\"\n[colors]\naccent = \"#ff0000\"",
+		"paper.json": `{"colors": {"accent": "#000080"}, "light": true}`,
+		"bad.toml":   "[colors]\naccent = \"pink\"",
+		"notes.txt":  "not a theme",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "dir.toml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ts, errs := LoadThemes(dir)
+	if len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "bad.toml: [colors]: accent") {
+		t.Errorf("errors = %v, want one for bad.toml", errs)
+	}
+	if want := append(append([]string(nil), ThemeNames...), "mine"); !reflect.DeepEqual(ts.Names(), want) {
+		t.Errorf("Names = %v, want %v", ts.Names(), want)
+	}
+	if ts.Has("bad") || ts.Has("notes") || ts.Has("dir") {
+		t.Error("a file that is not a theme was loaded")
+	}
+	if got := ts.Of("mine").Accent; got != lipgloss.Color("#ff0000") {
+		t.Errorf("mine accent = %q", got)
+	}
+	if got := ts.Of("paper").Accent; got != lipgloss.Color("#000080") {
+		t.Errorf("paper accent = %q, want the file to replace the built-in theme", got)
+	}
+	if got := ts.Of("unknown").Accent; got != themeSpecs["green"].accent {
+		t.Errorf("unknown theme accent = %q, want green's", got)
+	}
+
+	ts, errs = LoadThemes(filepath.Join(dir, "missing"))
+	if len(errs) != 0 || !reflect.DeepEqual(ts.Names(), ThemeNames) {
+		t.Errorf("without a directory: %v, %v", ts.Names(), errs)
+	}
+}
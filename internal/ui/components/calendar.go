@@ -0,0 +1,167 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// RenderCalendar draws the month of selected as a grid of weeks, Monday
+// first. Each day lists its exams in their priority colour, retakes, project
+// deadlines and milestones, and counts its todos; a day that runs out of
+// room says how many entries it could not show.
+func RenderCalendar(selected time.Time, entries []models.CalendarEntry, width, height int, t style.Theme) string {
+	first := time.Date(selected.Year(), selected.Month(), 1, 0, 0, 0, 0, selected.Location())
+	offset := (int(first.Weekday()) + 6) % 7
+	start := first.AddDate(0, 0, -offset)
+	weeks := (offset + first.AddDate(0, 1, -1).Day() + 6) / 7
+
+	gap := 1
+	colW := (width - gap*6) / 7
+	if colW < 1 {
+		colW = 1
+	}
+	cellH := (height - 1) / weeks
+	if cellH < 1 {
+		cellH = 1
+	}
+	cell := lipgloss.NewStyle().Width(colW).Height(cellH).MaxHeight(cellH)
+	spacer := strings.Repeat(" ", gap)
+
+	header := make([]string, 7)
+	for d := range header {
+		header[d] = lipgloss.NewStyle().Width(colW).Render(t.Dim.Render(TruncateString(models.Weekdays[d], colW)))
+	}
+	rows := []string{strings.Join(header, spacer)}
+
+	today := time.Now().Format("2006-01-02")
+	for w := 0; w < weeks; w++ {
+		cols := make([]string, 7)
+		for d := range cols {
+			day := start.AddDate(0, 0, w*7+d)
+			var dayEntries []models.CalendarEntry
+			for _, e := range entries {
+				if e.OnDay(day) {
+					dayEntries = append(dayEntries, e)
+				}
+			}
+			number := fmt.Sprintf("%d", day.Day())
+			switch {
+			case day.Format("2006-01-02") == selected.Format("2006-01-02"):
+				number = t.RowActive.Copy().Width(colW).Render(number)
+			case day.Format("2006-01-02") == today:
+				number = t.Title.Render(number)
+			case day.Month() != first.Month():
+				number = t.Dim.Render(number)
+			default:
+				number = t.Text.Render(number)
+			}
+			lines := append([]string{number}, calendarDayLines(dayEntries, colW, cellH-1, t)...)
+			cols[d] = cell.Render(strings.Join(lines, "\n"))
+		}
+		parts := make([]string, 0, 13)
+		for d, col := range cols {
+			if d > 0 {
+				parts = append(parts, spacer)
+			}
+			parts = append(parts, col)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, parts...))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// calendarDayLines renders a day's entries in at most room lines.
+func calendarDayLines(entries []models.CalendarEntry, width, room int, t style.Theme) []string {
+	var lines []string
+	todos, open := 0, 0
+	for _, e := range entries {
+		label := strings.TrimSpace(e.SubjectCode + " " + e.Title)
+		switch e.Kind {
+		case models.CalendarExam:
+			lines = append(lines, priorityStyle(e.Priority, t).Render(TruncateString(label, width)))
+		case models.CalendarRetake:
+			lines = append(lines, t.Text.Render(TruncateString("↺ "+label, width)))
+		case models.CalendarProject:
+			lineStyle := t.Text
+			if e.Done {
+				lineStyle = t.Dim
+			}
+			lines = append(lines, lineStyle.Render(TruncateString("◆ "+e.Title, width)))
+		case models.CalendarMilestone:
+			lineStyle := t.Text
+			if e.Done {
+				lineStyle = t.Dim
+			}
+			lines = append(lines, lineStyle.Render(TruncateString("◇ "+e.Title, width)))
+		case models.CalendarTodo:
+			todos++
+			if !e.Done {
+				open++
+			}
+		}
+	}
+	if todos > 0 {
+		label := fmt.Sprintf("%d todo", open)
+		if open != 1 {
+			label += "s"
+		}
+		if open == 0 {
+			label = fmt.Sprintf("✓ %d done", todos)
+		}
+		lines = append(lines, t.Dim.Render(TruncateString(label, width)))
+	}
+	if room < 1 {
+		return nil
+	}
+	if len(lines) > room {
+		hidden := len(lines) - room + 1
+		lines = append(lines[:room-1], t.Dim.Render(TruncateString(fmt.Sprintf("+%d more", hidden), width)))
+	}
+	return lines
+}
+
+// RenderAgenda lists a day's entries for the agenda panel beside the
+// calendar. cursor indexes entries.
+func RenderAgenda(entries []models.CalendarEntry, cursor, width int, t style.Theme) string {
+	if len(entries) == 0 {
+		return t.Dim.Render("Nothing on this day\n\n[A] Exam  [P] Project  [N] Todo")
+	}
+	kinds := map[models.CalendarKind]string{
+		models.CalendarExam:      "Exam",
+		models.CalendarRetake:    "Retake",
+		models.CalendarProject:   "Project",
+		models.CalendarMilestone: "Milestone",
+		models.CalendarTodo:      "Todo",
+	}
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		when := "all day"
+		if e.HasTime {
+			when = e.Date.Format("15:04")
+		}
+		kind := kinds[e.Kind]
+		if e.Kind == models.CalendarTodo && e.Done {
+			kind = "Done"
+		}
+		text := fmt.Sprintf("%-7s %-9s %s", when, kind, strings.TrimSpace(e.SubjectCode+" "+e.Title))
+		badge := ""
+		if e.Kind == models.CalendarExam && e.Priority != "" {
+			badge = " " + RenderPriority(e.Priority, t)
+		}
+		room := width - 2 - lipgloss.Width(badge)
+		switch {
+		case i == cursor:
+			lines[i] = t.RowActive.Render("> "+TruncateString(text, room)) + badge
+		case e.Done:
+			lines[i] = t.Dim.Render("  "+TruncateString(text, room)) + badge
+		default:
+			lines[i] = t.Text.Render("  "+TruncateString(text, room)) + badge
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -7,44 +7,23 @@ import (
 	"github.com/romanguyen/seman/internal/style"
 )
 
-// Tab ID constants kept in sync with app/model.go.
-const (
-	footerTabDashboard = 0
-	footerTabExams     = 1
-	footerTabTodos     = 2
-	footerTabProjects  = 3
-	footerTabSettings  = 4
-	footerTabLofi      = 5
-	footerTabSubjects  = 6
-)
-
-func tabHint(activeTab int) string {
-	switch activeTab {
-	case footerTabSubjects:
-		return "[S] Add subject  [E] Edit  [D] Delete  [T] Today  [Q] Quit"
-	case footerTabExams:
-		return "[A] Add exam  [E] Edit  [D] Delete  [F] Filter  [R] Clear  [G] Global  [T] Today  [Q] Quit"
-	case footerTabTodos:
-		return "[N] Add  [B] Bulk add  [E] Edit  [D] Delete  [Space] Toggle  [F] Filter  [R] Clear  [G] Global  [T] Today  [Q] Quit"
-	case footerTabProjects:
-		return "[P] Add project  [E] Edit  [D] Delete  [F] Filter  [R] Clear  [T] Today  [Q] Quit"
-	case footerTabSettings:
-		return "[T] Theme  [O] Confirm  [W] Week span  [L] Lofi  [U] Lofi URL  [Q] Quit"
-	case footerTabLofi:
-		return "[Enter] Play  [Space] Pause  [N] Next  [B] Prev  [X] Stop  [Q] Quit"
-	default: // Dashboard
-		return "[S] Subject  [A] Exam  [P] Project  [F] Filter  [R] Clear  [G] Global  [T] Today  [Q] Quit"
-	}
-}
-
-func RenderFooter(width, tabCount, activeTab int, t style.Theme) string {
+// RenderFooter draws the key hints of the active tab. A non-empty notice
+// (the result of the last action) replaces the hints until the next key press.
+func RenderFooter(width, tabCount int, hint, notice string, t style.Theme) string {
 	contentWidth := width - barBorderX - barPaddingX*2
 	if contentWidth < 1 {
 		contentWidth = 1
 	}
 
-	left := t.FooterHint.Render(tabHint(activeTab))
-	right := t.FooterHint.Render(fmt.Sprintf("[1-%d] Switch tabs", tabCount))
+	left := t.FooterHint.Render(hint)
+	if notice != "" {
+		left = t.Text.Render(notice)
+	}
+	keys := fmt.Sprintf("1-%d", tabCount)
+	if tabCount >= 10 {
+		keys = "0-9"
+	}
+	right := t.FooterHint.Render(fmt.Sprintf("[%s] Switch tabs", keys))
 	content := AlignLine(contentWidth, left, right)
 
 	styleWidth := width - barBorderX
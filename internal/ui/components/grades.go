@@ -0,0 +1,122 @@
+package components
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// RenderGradesSummary is the Grades tab's headline: scale, GPA and credits.
+func RenderGradesSummary(subjects []models.SubjectItem, scale models.GradingScale, t style.Theme) string {
+	gpa := "—"
+	if v, ok := models.GPA(subjects, scale); ok {
+		gpa = scale.FormatValue(v)
+	}
+	earned, total := 0.0, 0.0
+	for _, s := range subjects {
+		total += s.Credits
+		if g := models.GradeSubject(s, scale); g.Complete && g.Passed {
+			earned += s.Credits
+		}
+	}
+	line := fmt.Sprintf("Scale %s · GPA %s", scale.Name, gpa)
+	if total > 0 {
+		line += fmt.Sprintf(" · %s of %s credits earned", formatFloat(earned), formatFloat(total))
+	}
+	return t.Title.Render(line)
+}
+
+// RenderGrades lists each subject with its grade and, below it, its exams with
+// their weight and every recorded attempt. cursor counts exams across all
+// subjects.
+func RenderGrades(subjects []models.SubjectItem, scale models.GradingScale, cursor, width int, t style.Theme) string {
+	if len(subjects) == 0 {
+		return t.Dim.Render("No subjects yet — press [S] to add one")
+	}
+
+	var b strings.Builder
+	row := 0
+	for i, s := range subjects {
+		if i > 0 {
+			b.WriteString("\n\n")
+		}
+		left := s.Code + "  " + s.Name
+		if s.Credits > 0 {
+			left += " · " + formatFloat(s.Credits) + " cr"
+		}
+		b.WriteString(AlignLine(width, t.SubjectActive.Render(left), subjectGradeLabel(s, scale, t)))
+
+		if len(s.Exams) == 0 {
+			b.WriteString("\n")
+			b.WriteString(t.Dim.Render("    No exams"))
+			continue
+		}
+		weights := models.ExamWeights(s.Exams)
+		for j, exam := range s.Exams {
+			b.WriteString("\n")
+			prefix, nameStyle := "    ", t.Text
+			if row == cursor {
+				prefix, nameStyle = "  > ", t.RowActive
+			}
+			name := fmt.Sprintf("%s%s (%s%%)", prefix, exam.Name, formatFloat(roundTenth(weights[j])))
+			b.WriteString(AlignLine(width, nameStyle.Render(name), examResultsLabel(exam, t)))
+			row++
+		}
+	}
+	return b.String()
+}
+
+func subjectGradeLabel(s models.SubjectItem, scale models.GradingScale, t style.Theme) string {
+	g := models.GradeSubject(s, scale)
+	var status string
+	switch {
+	case !g.Passed:
+		status = t.StatusNotStr.Render("failed")
+	case g.Complete:
+		status = t.StatusDone.Render("passed")
+	default:
+		status = t.Dim.Render("in progress")
+	}
+	if !g.Graded {
+		return status
+	}
+	grade := scale.Grade(g.Percent)
+	if len(scale.Bands) > 0 {
+		grade += fmt.Sprintf(" · %.1f%%", g.Percent)
+	}
+	return t.Text.Render(grade+" · ") + status
+}
+
+// examResultsLabel shows every attempt with a result, e.g. "1: F ✗  2: C 72/100 ✓".
+func examResultsLabel(exam models.ExamItem, t style.Theme) string {
+	if len(exam.Results) == 0 {
+		return t.Dim.Render("—")
+	}
+	parts := make([]string, len(exam.Results))
+	for i, r := range exam.Results {
+		text := strconv.Itoa(r.Number()) + ":"
+		if r.Grade != "" {
+			text += " " + r.Grade
+		}
+		if r.MaxPoints > 0 {
+			text += " " + formatFloat(r.Points) + "/" + formatFloat(r.MaxPoints)
+		}
+		if r.Passed {
+			parts[i] = t.Text.Render(text + " ✓")
+		} else {
+			parts[i] = t.StatusNotStr.Render(text + " ✗")
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func roundTenth(v float64) float64 {
+	return float64(int(v*10+0.5)) / 10
+}
@@ -7,13 +7,18 @@ import (
 	"github.com/romanguyen/seman/internal/style"
 )
 
-func RenderHeader(width int, t style.Theme) string {
+// RenderHeader draws the title bar. A non-empty semester is shown after the
+// title.
+func RenderHeader(width int, semester string, t style.Theme) string {
 	contentWidth := width - barBorderX - barPaddingX*2
 	if contentWidth < 1 {
 		contentWidth = 1
 	}
 
 	title := t.Title.Render("Student Manager")
+	if semester != "" {
+		title += t.Dim.Render("  ·  " + semester)
+	}
 	date := t.Dim.Render(time.Now().Format("01-02-2006"))
 	content := AlignLine(contentWidth, title, date)
 
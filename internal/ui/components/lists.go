@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/romanguyen/seman/internal/dates"
 	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/style"
 )
@@ -15,9 +16,10 @@ type upcomingExam struct {
 	Name     string
 	Priority string
 	Date     time.Time
+	Stored   string
 }
 
-func RenderUpcomingExams(subjects []models.SubjectItem, limit int, start, end time.Time, all bool, filter string, t style.Theme) string {
+func RenderUpcomingExams(subjects []models.SubjectItem, limit int, start, end time.Time, all bool, filter string, rules models.PriorityRules, df dates.Format, t style.Theme) string {
 	exams := collectUpcomingExams(subjects, start, end, all, filter)
 
 	if len(exams) == 0 {
@@ -39,10 +41,10 @@ func RenderUpcomingExams(subjects []models.SubjectItem, limit int, start, end ti
 		item := exams[i]
 		b.WriteString(t.Text.Render(fmt.Sprintf("- %s (%s)", item.Name, item.Subject)))
 		b.WriteString("\n")
-		dateStr := item.Date.Format("Jan 2, 2006 @ 15:04")
+		dateStr := df.ShowStored(item.Stored)
 		b.WriteString(t.Dim.Render("  " + dateStr + " "))
 		if item.Priority != "" {
-			b.WriteString(RenderPriority(item.Priority))
+			b.WriteString(RenderExamPriority(item.Priority, rules, item.Date, true, t))
 		}
 	}
 
@@ -61,7 +63,7 @@ func collectUpcomingExams(subjects []models.SubjectItem, start, end time.Time, a
 			continue
 		}
 		for _, exam := range subject.Exams {
-			date, ok := parseExamDate(exam.Date)
+			date, ok := dates.Parse(exam.Date)
 			if !ok {
 				continue
 			}
@@ -73,6 +75,7 @@ func collectUpcomingExams(subjects []models.SubjectItem, start, end time.Time, a
 				Name:     exam.Name,
 				Priority: exam.Priority,
 				Date:     date,
+				Stored:   exam.Date,
 			})
 		}
 	}
@@ -83,38 +86,32 @@ func collectUpcomingExams(subjects []models.SubjectItem, start, end time.Time, a
 	return list
 }
 
-func parseExamDate(value string) (time.Time, bool) {
-	value = strings.TrimSpace(value)
-	if value == "" {
-		return time.Time{}, false
-	}
-	layouts := []string{
-		"Jan 2, 2006 @ 15:04",
-		"Jan 2, 2006",
-		"2006-01-02 15:04",
-		"2006-01-02",
-		"2.1.2006 15:04",
-		"2.1.2006",
-		"02.01.2006 15:04",
-		"02.01.2006",
-		"2/1/2006 15:04",
-		"2/1/2006",
-		"02/01/2006 15:04",
-		"02/01/2006",
-	}
-	for _, layout := range layouts {
-		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
-			return t, true
-		}
-	}
-	return time.Time{}, false
+// TodoRow is a todo as the checklist shows it.
+type TodoRow struct {
+	Item    models.ChecklistItem
+	Group   string // day heading on the Todos tab
+	Due     time.Time
+	HasDue  bool
+	Overdue bool
 }
 
-func RenderChecklist(items []models.ChecklistItem, selected int, showCursor bool, t style.Theme) string {
-	var b strings.Builder
-	for i, item := range items {
-		if i > 0 {
-			b.WriteString("\n")
+// RenderChecklist draws the todos. On the Todos tab (grouped) they get a
+// heading per day and a cursor on selected; elsewhere each row shows its due
+// date instead. It also returns the line the selected todo is on.
+func RenderChecklist(rows []TodoRow, selected int, grouped bool, t style.Theme) (string, int) {
+	var lines []string
+	cursorLine := 0
+	for i, row := range rows {
+		item := row.Item
+		if grouped && (i == 0 || rows[i-1].Group != row.Group) {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			heading := t.Title
+			if row.Overdue {
+				heading = t.Overdue.Copy().Bold(true)
+			}
+			lines = append(lines, heading.Render(row.Group))
 		}
 		box := "[ ]"
 		rowStyle := t.CheckboxTodo
@@ -122,26 +119,63 @@ func RenderChecklist(items []models.ChecklistItem, selected int, showCursor bool
 			box = "[x]"
 			rowStyle = t.CheckboxDone
 		}
-		if showCursor && i == selected {
-			rowStyle = t.RowActive
+		if row.Overdue {
+			rowStyle = t.Overdue
 		}
+		if i == selected {
+			cursorLine = len(lines)
+			if grouped {
+				rowStyle = t.RowActive
+			}
+		}
+		line := rowStyle.Render(box) + " "
 		if item.Subject != "" {
-			b.WriteString(rowStyle.Render(box) + " " + t.SubjectTag.Render(item.Subject) + "  " + rowStyle.Render(item.Text))
-		} else {
-			b.WriteString(rowStyle.Render(fmt.Sprintf("%s %s", box, item.Text)))
+			line += t.SubjectTag.Render(item.Subject) + "  "
+		}
+		line += rowStyle.Render(item.Text)
+		if item.Repeat != "" {
+			line += t.Dim.Render(" ↻")
+		}
+		if due := todoDueText(row, grouped); due != "" {
+			line += "  " + t.Dim.Render(due)
 		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), cursorLine
+}
+
+// todoDueText is the due date next to a todo: just the time under a day
+// heading, the date (and time) everywhere else.
+func todoDueText(row TodoRow, grouped bool) string {
+	if !row.HasDue {
+		return ""
+	}
+	timed := row.Due.Hour() != 0 || row.Due.Minute() != 0
+	switch {
+	case grouped && !row.Overdue:
+		if timed {
+			return row.Due.Format("15:04")
+		}
+		return ""
+	case timed:
+		return row.Due.Format("Jan 2 15:04")
+	default:
+		return row.Due.Format("Jan 2")
 	}
-	return b.String()
 }
 
-func RenderProjects(items []models.ProjectItem, t style.Theme) string {
+func RenderProjects(items []models.ProjectItem, df dates.Format, t style.Theme) string {
 	var b strings.Builder
 	for i, item := range items {
 		if i > 0 {
 			b.WriteString("\n")
 		}
 		name := t.Text.Render("- " + item.Name)
-		meta := t.ProjectDetail.Render("  " + item.Subject + " - Due: " + item.Due)
+		detail := "  " + item.Subject + " - Due: " + df.ShowStored(item.Due)
+		if done, total := item.Progress(); total > 0 {
+			detail += fmt.Sprintf(" - %d/%d done", done, total)
+		}
+		meta := t.ProjectDetail.Render(detail)
 		b.WriteString(name)
 		b.WriteString("\n")
 		b.WriteString(meta)
@@ -15,11 +15,15 @@ const (
 	ModalForm
 	ModalConfirm
 	ModalSubjectSelect
+	ModalPicker
+	ModalSearch // a query field over grouped results
 )
 
 type ModalField struct {
-	Label string
-	Value string
+	Label      string
+	Value      string
+	Preview    string // shown under the field, e.g. the date a date field reads as
+	PreviewBad bool   // Preview is an error
 }
 
 type ModalState struct {
@@ -35,10 +39,13 @@ type ModalState struct {
 	SelectItems      []string
 	SelectActive     []bool
 	SelectCursor     int
+	SelectGroups     []string // group of each SelectItems entry (ModalSearch)
+	SelectDimmed     []bool   // SelectItems entries shown dimmed (ModalPicker)
 }
 
 const dropdownPanelWidth = 18
 const dropdownMaxVisible = 8
+const pickerMaxVisible = 10
 
 // RenderModalContent returns just the modal box (+ dropdown panel if active),
 // without padding it to fill the full area. Use with PlaceOverlay.
@@ -113,6 +120,10 @@ func RenderModal(state ModalState, width int, t style.Theme) string {
 
 	if state.Mode == ModalSubjectSelect {
 		b.WriteString("\n")
+		if state.Message != "" {
+			b.WriteString(t.Dim.Render(state.Message))
+			b.WriteString("\n\n")
+		}
 		if len(state.SelectItems) == 0 {
 			b.WriteString(t.Dim.Render("No subjects available."))
 		} else {
@@ -139,12 +150,47 @@ func RenderModal(state ModalState, width int, t style.Theme) string {
 		return box.Render(b.String())
 	}
 
+	if state.Mode == ModalPicker {
+		b.WriteString("\n")
+		b.WriteString(renderPickerItems(state, t))
+		if state.Hint != "" {
+			b.WriteString("\n\n")
+			b.WriteString(t.ModalHint.Render(state.Hint))
+		}
+		return box.Render(b.String())
+	}
+
+	if state.Mode == ModalSearch {
+		b.WriteString("\n")
+		for _, field := range state.Fields {
+			b.WriteString(t.Dim.Render(fmt.Sprintf("%-12s", field.Label+":")))
+			b.WriteString(" ")
+			b.WriteString(field.Value)
+		}
+		b.WriteString("\n\n")
+		b.WriteString(renderSearchResults(state, modalW-4, t))
+		if state.Hint != "" {
+			b.WriteString("\n\n")
+			b.WriteString(t.ModalHint.Render(state.Hint))
+		}
+		return box.Render(b.String())
+	}
+
 	b.WriteString("\n")
 	for i, field := range state.Fields {
 		label := fmt.Sprintf("%-12s", field.Label+":")
 		b.WriteString(t.Dim.Render(label))
 		b.WriteString(" ")
 		b.WriteString(field.Value)
+		if field.Preview != "" {
+			previewStyle := t.Dim
+			if field.PreviewBad {
+				previewStyle = t.ModalError
+			}
+			b.WriteString("\n")
+			b.WriteString(strings.Repeat(" ", 13))
+			b.WriteString(previewStyle.Render("→ " + field.Preview))
+		}
 		if i < len(state.Fields)-1 {
 			b.WriteString("\n")
 		}
@@ -161,6 +207,104 @@ func RenderModal(state ModalState, width int, t style.Theme) string {
 	return box.Render(b.String())
 }
 
+// renderPickerItems draws a single-choice list, scrolling so the cursor stays
+// visible. Message is shown above the list, or in its place when it is empty.
+func renderPickerItems(state ModalState, t style.Theme) string {
+	items := state.SelectItems
+	if len(items) == 0 {
+		if state.Message != "" {
+			return t.Dim.Render(state.Message)
+		}
+		return t.Dim.Render("Nothing to choose from.")
+	}
+
+	start, end := pickerWindow(state.SelectCursor, len(items))
+
+	var b strings.Builder
+	if state.Message != "" {
+		b.WriteString(t.Text.Render(state.Message))
+		b.WriteString("\n\n")
+	}
+	for i := start; i < end; i++ {
+		if i > start {
+			b.WriteString("\n")
+		}
+		switch {
+		case i == state.SelectCursor:
+			b.WriteString(t.RowActive.Render("> " + items[i]))
+		case i < len(state.SelectDimmed) && state.SelectDimmed[i]:
+			b.WriteString(t.Dim.Render("  " + items[i]))
+		default:
+			b.WriteString(t.Text.Render("  " + items[i]))
+		}
+	}
+	if remaining := len(items) - end; remaining > 0 {
+		b.WriteString("\n")
+		b.WriteString(t.Dim.Render(fmt.Sprintf("  +%d more", remaining)))
+	}
+	return b.String()
+}
+
+// pickerWindow returns the range of the n items to show so that the cursor
+// stays visible.
+func pickerWindow(cursor, n int) (int, int) {
+	start := cursor - pickerMaxVisible/2
+	if start < 0 {
+		start = 0
+	}
+	end := start + pickerMaxVisible
+	if end > n {
+		end = n
+		start = end - pickerMaxVisible
+		if start < 0 {
+			start = 0
+		}
+	}
+	return start, end
+}
+
+// renderSearchResults lists the results of the search overlay under a
+// heading per group, scrolling so the cursor stays visible. Message stands
+// in for an empty list.
+func renderSearchResults(state ModalState, width int, t style.Theme) string {
+	items := state.SelectItems
+	if len(items) == 0 {
+		return t.Dim.Render(state.Message)
+	}
+	start, end := pickerWindow(state.SelectCursor, len(items))
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(t.Dim.Render(fmt.Sprintf("  +%d above", start)))
+		b.WriteString("\n")
+	}
+	group := ""
+	for i := start; i < end; i++ {
+		if i < len(state.SelectGroups) && (i == start || state.SelectGroups[i] != group) {
+			group = state.SelectGroups[i]
+			if i > start {
+				b.WriteString("\n")
+			}
+			b.WriteString(t.Title.Render(group))
+			b.WriteString("\n")
+		}
+		line := TruncateString(items[i], width-2)
+		if i == state.SelectCursor {
+			b.WriteString(t.RowActive.Render("> " + line))
+		} else {
+			b.WriteString(t.Text.Render("  " + line))
+		}
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	if remaining := len(items) - end; remaining > 0 {
+		b.WriteString("\n")
+		b.WriteString(t.Dim.Render(fmt.Sprintf("  +%d more", remaining)))
+	}
+	return b.String()
+}
+
 func renderDropdownPanel(state ModalState, t style.Theme) string {
 	items := state.DropdownItems
 	cursor := state.DropdownCursor
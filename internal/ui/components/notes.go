@@ -0,0 +1,115 @@
+package components
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/muesli/reflow/wordwrap"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// RenderSubjectDetail shows a subject's resources and then its notes, cut
+// to height lines. cursor indexes the resources and is shown only while
+// focused.
+func RenderSubjectDetail(subject models.SubjectItem, focused bool, cursor, width, height int, t style.Theme) string {
+	var lines []string
+	if subject.Credits > 0 {
+		lines = append(lines, t.Dim.Render(fmt.Sprintf("%s credits", formatFloat(subject.Credits))), "")
+	}
+
+	lines = append(lines, t.Title.Render("Resources"))
+	if len(subject.Resources) == 0 {
+		lines = append(lines, t.Dim.Render("  No resources  [L] Add"))
+	}
+	for i, r := range subject.Resources {
+		text := r.Title
+		if r.Target != r.Title {
+			text += "  " + r.Target
+		}
+		text = TruncateString(text, width-2)
+		if focused && i == cursor {
+			lines = append(lines, t.RowActive.Render("> "+text))
+		} else {
+			lines = append(lines, t.Text.Render("  "+text))
+		}
+	}
+
+	lines = append(lines, "", t.Title.Render("Notes"))
+	if strings.TrimSpace(subject.Notes) == "" {
+		lines = append(lines, t.Dim.Render("  No notes  [N] Edit"))
+	} else {
+		lines = append(lines, RenderMarkdown(subject.Notes, width, t)...)
+	}
+
+	if height > 0 && len(lines) > height {
+		more := len(lines) - height + 1
+		lines = append(lines[:height-1], t.Dim.Render(fmt.Sprintf("… %d more lines  [N] Edit", more)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^#{1,6}\s+`)
+	markdownBullet  = regexp.MustCompile(`^[-*+]\s+`)
+	markdownNumber  = regexp.MustCompile(`^\d+[.)]\s+`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	markdownMarks   = strings.NewReplacer("**", "", "__", "", "`", "")
+)
+
+// RenderMarkdown renders the markdown of notes as styled lines wrapped to
+// width: headings, bullet and numbered lists, quotes and code blocks. Inline
+// emphasis is dropped and links read as "text (url)".
+func RenderMarkdown(text string, width int, t style.Theme) []string {
+	var lines []string
+	wrap := func(s, first, rest string, render func(...string) string) {
+		w := width - len([]rune(first))
+		if w < 8 {
+			w = 8
+		}
+		for i, line := range strings.Split(wordwrap.String(s, w), "\n") {
+			prefix := rest
+			if i == 0 {
+				prefix = first
+			}
+			lines = append(lines, render(prefix+line))
+		}
+	}
+	inline := func(s string) string {
+		s = markdownLink.ReplaceAllString(s, "$1 ($2)")
+		return markdownMarks.Replace(s)
+	}
+
+	code := false
+	for _, raw := range strings.Split(text, "\n") {
+		line := strings.TrimRight(raw, " \t")
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			code = !code
+			continue
+		}
+		if code {
+			lines = append(lines, t.Dim.Render(TruncateString("  "+line, width)))
+			continue
+		}
+		indent := strings.Repeat(" ", (len(line)-len(strings.TrimLeft(line, " \t")))/2*2)
+		switch {
+		case trimmed == "":
+			lines = append(lines, "")
+		case markdownHeading.MatchString(trimmed):
+			wrap(inline(markdownHeading.ReplaceAllString(trimmed, "")), "", "", t.Title.Render)
+		case markdownBullet.MatchString(trimmed):
+			wrap(inline(markdownBullet.ReplaceAllString(trimmed, "")), indent+"• ", indent+"  ", t.Text.Render)
+		case markdownNumber.MatchString(trimmed):
+			number := strings.TrimSpace(markdownNumber.FindString(trimmed))
+			pad := strings.Repeat(" ", len(number)+1)
+			wrap(inline(trimmed[len(markdownNumber.FindString(trimmed)):]), indent+number+" ", indent+pad, t.Text.Render)
+		case strings.HasPrefix(trimmed, ">"):
+			wrap(inline(strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))), "│ ", "│ ", t.Dim.Render)
+		default:
+			wrap(inline(trimmed), indent, indent, t.Text.Render)
+		}
+	}
+	return lines
+}
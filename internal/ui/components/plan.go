@@ -0,0 +1,70 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// RenderProjectPlan lists a project's subtasks and then its milestones.
+// cursor indexes them in that order and is shown only while focused.
+// Milestones that passed without being done are highlighted.
+func RenderProjectPlan(project models.ProjectItem, focused bool, cursor, width int, df dates.Format, t style.Theme) string {
+	var lines []string
+	row := func(i int, text string, rowStyle func(...string) string) {
+		text = TruncateString(text, width-2)
+		if focused && i == cursor {
+			lines = append(lines, t.RowActive.Render("> "+text))
+			return
+		}
+		lines = append(lines, rowStyle("  "+text))
+	}
+	box := func(done bool) string {
+		if done {
+			return "[x] "
+		}
+		return "[ ] "
+	}
+
+	done, total := project.Progress()
+	heading := "Subtasks"
+	if total > 0 {
+		heading = fmt.Sprintf("Subtasks %d/%d", done, total)
+	}
+	lines = append(lines, t.Title.Render(heading))
+	if total == 0 {
+		lines = append(lines, t.Dim.Render("  No subtasks  [N] Add"))
+	}
+	for i, s := range project.Subtasks {
+		rowStyle := t.CheckboxTodo.Render
+		if s.Done {
+			rowStyle = t.CheckboxDone.Render
+		}
+		row(i, box(s.Done)+s.Text, rowStyle)
+	}
+
+	lines = append(lines, "", t.Title.Render("Milestones"))
+	if len(project.Milestones) == 0 {
+		lines = append(lines, t.Dim.Render("  No milestones  [L] Add"))
+	}
+	now := time.Now()
+	for i, ms := range project.Milestones {
+		rowStyle := t.Text.Render
+		end, ok := dates.Parse(ms.Date)
+		if dates.IsDay(ms.Date) {
+			end = end.AddDate(0, 0, 1)
+		}
+		switch {
+		case ms.Done:
+			rowStyle = t.CheckboxDone.Render
+		case ok && end.Before(now):
+			rowStyle = t.Overdue.Render
+		}
+		row(total+i, box(ms.Done)+df.ShowStored(ms.Date)+"  "+ms.Name, rowStyle)
+	}
+	return strings.Join(lines, "\n")
+}
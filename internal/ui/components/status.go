@@ -2,40 +2,79 @@ package components
 
 import (
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/style"
 )
 
 func RenderStatusBadge(status string, width int, t style.Theme) string {
-	label := strings.ToUpper(status)
-	style := t.StatusNotStr
-	switch label {
+	return fitBadge(statusBadge(status, t), width)
+}
+
+// RenderProjectStatus is the status badge of a project, marked when the
+// status follows the subtasks.
+func RenderProjectStatus(project models.ProjectItem, width int, t style.Theme) string {
+	badge := statusBadge(project.Status, t)
+	if project.AutoStatus {
+		badge += t.Dim.Render(" auto")
+	}
+	return fitBadge(badge, width)
+}
+
+func statusBadge(status string, t style.Theme) string {
+	return statusStyle(status, t).Render(strings.ToUpper(status))
+}
+
+func statusStyle(status string, t style.Theme) lipgloss.Style {
+	switch strings.ToUpper(status) {
 	case "DONE":
-		style = t.StatusDone
+		return t.StatusDone
 	case "IN PROGRESS":
-		style = t.StatusInProg
-	case "NOT STARTED":
-		style = t.StatusNotStr
+		return t.StatusInProg
+	default:
+		return t.StatusNotStr
 	}
-	badge := style.Render(label)
+}
+
+// fitBadge pads or cuts a rendered badge to width.
+func fitBadge(badge string, width int) string {
 	if lipgloss.Width(badge) < width {
 		return badge + strings.Repeat(" ", width-lipgloss.Width(badge))
 	}
 	return TruncateString(badge, width)
 }
 
-func RenderPriority(priority string) string {
-	style := lipgloss.NewStyle().Padding(0, 1).Bold(true)
+func RenderPriority(priority string, t style.Theme) string {
+	return priorityStyle(priority, t).Padding(0, 1).Render(priority)
+}
+
+// priorityStyle is the colouring of a priority badge, also used for exams
+// on the calendar.
+func priorityStyle(priority string, t style.Theme) lipgloss.Style {
 	switch strings.ToUpper(priority) {
 	case "HIGH":
-		style = style.Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#ff5f5f"))
+		return t.PriorityHigh
 	case "MED":
-		style = style.Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#d4a017"))
+		return t.PriorityMed
 	case "LOW":
-		style = style.Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#3a7f3a"))
+		return t.PriorityLow
 	default:
-		style = style.Foreground(lipgloss.Color("#0c0c0c")).Background(lipgloss.Color("#39ff14"))
+		return t.PriorityNone
+	}
+}
+
+// RenderExamPriority renders the effective priority badge for an exam. AUTO
+// priorities are resolved against rules and marked as derived.
+func RenderExamPriority(priority string, rules models.PriorityRules, date time.Time, hasDate bool, t style.Theme) string {
+	level := rules.Resolve(priority, date, hasDate, time.Now())
+	if level == "" {
+		return ""
+	}
+	badge := RenderPriority(level, t)
+	if models.IsAutoPriority(priority) {
+		badge += t.Dim.Render(" auto")
 	}
-	return style.Render(priority)
+	return badge
 }
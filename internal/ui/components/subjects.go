@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/romanguyen/seman/internal/dates"
 	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/style"
 )
@@ -36,7 +37,7 @@ func RenderSubjects(items []models.SubjectItem, selected int, t style.Theme) str
 	return b.String()
 }
 
-func RenderFlatExams(exams []models.FlatExam, cursor int, filter string, t style.Theme) string {
+func RenderFlatExams(exams []models.FlatExam, cursor int, filter string, rules models.PriorityRules, df dates.Format, t style.Theme) string {
 	if len(exams) == 0 {
 		if filter != "" {
 			return t.Dim.Render("No exams for " + filter + " in this period")
@@ -64,8 +65,9 @@ func RenderFlatExams(exams []models.FlatExam, cursor int, filter string, t style
 			nameLine += "  (" + flat.SubjectCode + ")"
 		}
 		if flat.Exam.Priority != "" {
+			date, ok := dates.Parse(flat.Exam.Date)
 			b.WriteString(nameStyle.Render(nameLine) + "  ")
-			b.WriteString(RenderPriority(flat.Exam.Priority))
+			b.WriteString(RenderExamPriority(flat.Exam.Priority, rules, date, ok, t))
 		} else {
 			b.WriteString(nameStyle.Render(nameLine))
 		}
@@ -73,8 +75,8 @@ func RenderFlatExams(exams []models.FlatExam, cursor int, filter string, t style
 		// Line 2: date + countdown
 		if flat.Exam.Date != "" {
 			b.WriteString("\n")
-			dateInfo := "  " + flat.Exam.Date
-			if date, ok := parseExamDate(flat.Exam.Date); ok {
+			dateInfo := "  " + df.ShowStored(flat.Exam.Date)
+			if date, ok := dates.Parse(flat.Exam.Date); ok {
 				days := int(date.Sub(now).Hours() / 24)
 				var countdown string
 				switch {
@@ -99,7 +101,7 @@ func RenderFlatExams(exams []models.FlatExam, cursor int, filter string, t style
 		// Retakes (compact, single line)
 		if len(flat.Exam.Retakes) > 0 {
 			b.WriteString("\n")
-			b.WriteString(t.Dim.Render("  Retakes: " + strings.Join(flat.Exam.Retakes, ", ")))
+			b.WriteString(t.Dim.Render("  Retakes: " + df.ShowList(flat.Exam.Retakes)))
 		}
 	}
 	return b.String()
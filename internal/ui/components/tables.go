@@ -4,30 +4,34 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/dates"
 	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/style"
 )
 
-func RenderProjectsTable(items []models.ProjectItem, selected, width int, t style.Theme) string {
+func RenderProjectsTable(items []models.ProjectItem, selected, width int, df dates.Format, t style.Theme) string {
 	if width <= 0 {
 		return ""
 	}
-	header := []string{"Project Name", "Subject", "Deadline", "Status"}
+	header := []string{"Project Name", "Subject", "Deadline", "Progress", "Status"}
 
 	colSubject := 10
 	colDeadline := 16
-	colStatus := 14
-	colName := width - (colSubject + colDeadline + colStatus + 3)
+	colProgress := 14
+	colStatus := 18
+	colName := width - (colSubject + colDeadline + colProgress + colStatus + 4)
 	if colName < 16 {
 		colName = 16
 	}
 
 	line := fmt.Sprintf(
-		"%-*s %-*s %-*s %-*s",
+		"%-*s %-*s %-*s %-*s %-*s",
 		colName, header[0],
 		colSubject, header[1],
 		colDeadline, header[2],
-		colStatus, header[3],
+		colProgress, header[3],
+		colStatus, header[4],
 	)
 
 	var b strings.Builder
@@ -37,13 +41,15 @@ func RenderProjectsTable(items []models.ProjectItem, selected, width int, t styl
 		b.WriteString("\n")
 		name := TruncateString(item.Name, colName)
 		subject := TruncateString(item.Subject, colSubject)
-		due := TruncateString(item.Due, colDeadline)
-		status := RenderStatusBadge(item.Status, colStatus, t)
+		due := TruncateString(df.ShowStored(item.Due), colDeadline)
+		progress := fitBadge(RenderProgress(item, colProgress, t), colProgress)
+		status := RenderProjectStatus(item, colStatus, t)
 		row := fmt.Sprintf(
-			"%-*s %-*s %-*s %-*s",
+			"%-*s %-*s %-*s %s %-*s",
 			colName, name,
 			colSubject, subject,
 			colDeadline, due,
+			progress,
 			colStatus, status,
 		)
 		if i == selected {
@@ -54,3 +60,22 @@ func RenderProjectsTable(items []models.ProjectItem, selected, width int, t styl
 	}
 	return b.String()
 }
+
+// RenderProgress draws the share of a project's finished subtasks as a bar
+// followed by the percentage, e.g. "███░░░░░  37%". Projects without
+// subtasks show a dash.
+func RenderProgress(project models.ProjectItem, width int, t style.Theme) string {
+	percent, ok := project.Percent()
+	if !ok {
+		return t.Dim.Render("-")
+	}
+	label := fmt.Sprintf(" %3d%%", percent)
+	barW := width - len(label)
+	if barW < 1 {
+		return label[1:]
+	}
+	filled := barW * percent / 100
+	bar := lipgloss.NewStyle().Foreground(t.Accent).Render(strings.Repeat("█", filled)) +
+		t.Dim.Render(strings.Repeat("░", barW-filled))
+	return bar + label
+}
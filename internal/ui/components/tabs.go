@@ -16,7 +16,8 @@ type TabItem struct {
 func RenderTabs(active, width int, weekLabel string, items []TabItem, t style.Theme) string {
 	tabs := make([]string, 0, len(items))
 	for i, item := range items {
-		text := fmt.Sprintf("[%d] %s", i+1, item.Label)
+		// The tenth tab is on the 0 key.
+		text := fmt.Sprintf("[%d] %s", (i+1)%10, item.Label)
 		if item.ID == active {
 			tabs = append(tabs, t.TabActive.Render(text))
 		} else {
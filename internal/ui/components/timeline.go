@@ -0,0 +1,142 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// timelineCell is one day of a timeline row.
+type timelineCell struct {
+	ch    string
+	style lipgloss.Style
+}
+
+// RenderTimeline draws each project as a bar from its start to its deadline
+// (◆), one column per day from the Monday from. Milestones (◇) and the exams
+// of the project's subject (●, in their priority colour) are marked on its
+// row and today's column is highlighted. The Load row below shades each day
+// by how many open projects run and exams fall on it, to show crunch
+// periods.
+func RenderTimeline(projects []models.ProjectItem, exams []models.CalendarEntry, selected int, from time.Time, width int, t style.Theme) string {
+	nameW := minInt(22, width/4)
+	days := width - nameW - 1
+	if days < 7 {
+		days = 7
+	}
+	dayIndex := func(d time.Time) int {
+		d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, from.Location())
+		return int(math.Round(d.Sub(from).Hours() / 24))
+	}
+	today := dayIndex(time.Now())
+	accent := lipgloss.NewStyle().Foreground(t.Accent)
+
+	header := []rune(strings.Repeat(" ", days))
+	for i := 0; i < days; i += 7 {
+		label := from.AddDate(0, 0, i).Format("Jan 2")
+		if i+len(label) <= days {
+			copy(header[i:], []rune(label))
+		}
+	}
+	headerLine := t.Dim.Render(string(header))
+	if today >= 0 && today < days {
+		headerLine = t.Dim.Render(string(header[:today])) + accent.Render("▼") + t.Dim.Render(string(header[today+1:]))
+	}
+	lines := []string{strings.Repeat(" ", nameW+1) + headerLine}
+
+	load := make([]int, days)
+	for _, e := range exams {
+		if i := dayIndex(e.Date); i >= 0 && i < days {
+			load[i]++
+		}
+	}
+
+	for pi, project := range projects {
+		row := make([]timelineCell, days)
+		for i := range row {
+			row[i] = timelineCell{" ", t.Text}
+		}
+		set := func(i int, ch string, s lipgloss.Style) {
+			if i >= 0 && i < days {
+				row[i] = timelineCell{ch, s}
+			}
+		}
+		done := strings.EqualFold(project.Status, models.StatusDone)
+		barStyle := lipgloss.NewStyle().Foreground(statusStyle(project.Status, t).GetBackground())
+		if done {
+			barStyle = t.Dim
+		}
+		if due, ok := dates.Parse(project.Due); ok {
+			end := dayIndex(due)
+			if start, ok := dates.Parse(project.Start); ok {
+				for i := dayIndex(start); i < end && i < days; i++ {
+					if i < 0 {
+						continue
+					}
+					set(i, "█", barStyle)
+					if !done {
+						load[i]++
+					}
+				}
+			}
+			set(end, "◆", barStyle)
+			if !done && end >= 0 && end < days {
+				load[end]++
+			}
+		}
+		for _, ms := range project.Milestones {
+			if date, ok := dates.Parse(ms.Date); ok {
+				msStyle := accent
+				if ms.Done {
+					msStyle = t.Dim
+				}
+				set(dayIndex(date), "◇", msStyle)
+			}
+		}
+		for _, e := range exams {
+			if strings.EqualFold(e.SubjectCode, project.Subject) {
+				set(dayIndex(e.Date), "●", lipgloss.NewStyle().Foreground(priorityStyle(e.Priority, t).GetBackground()))
+			}
+		}
+		if today >= 0 && today < days && row[today].ch == " " {
+			row[today] = timelineCell{"│", accent}
+		}
+
+		name := fitBadge(TruncateString(project.Name, nameW), nameW)
+		if pi == selected {
+			name = t.RowActive.Render(name)
+		} else {
+			name = t.Text.Render(name)
+		}
+		lines = append(lines, name+" "+renderCells(row))
+	}
+
+	shades := []string{" ", "░", "▒", "▓", "█"}
+	loadRow := make([]timelineCell, days)
+	for i, n := range load {
+		loadRow[i] = timelineCell{shades[minInt(n, len(shades)-1)], accent}
+		if i == today && n == 0 {
+			loadRow[i] = timelineCell{"│", accent}
+		}
+	}
+	lines = append(lines,
+		t.Dim.Render(fitBadge("Load", nameW))+" "+renderCells(loadRow),
+		"",
+		t.Dim.Render(TruncateString(fmt.Sprintf("█ start → ◆ deadline  ◇ milestone  ● exam  │ today   from %s, [←→] to scroll", from.Format("Jan 2, 2006")), width)),
+	)
+	return strings.Join(lines, "\n")
+}
+
+func renderCells(cells []timelineCell) string {
+	var b strings.Builder
+	for _, c := range cells {
+		b.WriteString(c.style.Render(c.ch))
+	}
+	return b.String()
+}
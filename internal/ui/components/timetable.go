@@ -0,0 +1,121 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+)
+
+// RenderTimetable draws the week starting at weekStart as one column per day,
+// each listing its classes in order. Weekends only get a column when they
+// have classes. cursor indexes entries.
+func RenderTimetable(entries []models.TimetableEntry, weekStart time.Time, cursor, width, height int, t style.Theme) string {
+	if len(entries) == 0 {
+		return t.Dim.Render("No classes this week — press [N] to add one")
+	}
+
+	days := 5
+	for _, e := range entries {
+		if wd := e.Date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			days = 7
+		}
+	}
+	gap := 1
+	colW := (width - gap*(days-1)) / days
+	if colW < 1 {
+		colW = 1
+	}
+
+	today := time.Now().Format("2006-01-02")
+	cols := make([]string, days)
+	for d := 0; d < days; d++ {
+		day := weekStart.AddDate(0, 0, d)
+		headerStyle := t.Dim
+		if day.Format("2006-01-02") == today {
+			headerStyle = t.Title
+		}
+		lines := []string{
+			headerStyle.Render(TruncateString(day.Format("Mon Jan 2"), colW)),
+			headerStyle.Render(strings.Repeat("-", colW)),
+		}
+		var blocks [][]string
+		for i, e := range entries {
+			if e.Date.Format("2006-01-02") != day.Format("2006-01-02") {
+				continue
+			}
+			blocks = append(blocks, sessionBlock(e, i == cursor, colW, t))
+		}
+		for i, block := range blocks {
+			if len(lines)+len(block) > height {
+				lines = append(lines, t.Dim.Render(TruncateString(fmt.Sprintf("+%d more", len(blocks)-i), colW)))
+				break
+			}
+			lines = append(lines, block...)
+		}
+		cols[d] = lipgloss.NewStyle().Width(colW).Render(strings.Join(lines, "\n"))
+	}
+
+	parts := make([]string, 0, days*2-1)
+	for d, col := range cols {
+		if d > 0 {
+			parts = append(parts, strings.Repeat(" ", gap))
+		}
+		parts = append(parts, col)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, parts...)
+}
+
+// sessionBlock is one class in a day column: time, subject and type, room,
+// and a blank line.
+func sessionBlock(e models.TimetableEntry, selected bool, width int, t style.Theme) []string {
+	main, detail := t.Text, t.Dim
+	prefix := ""
+	if selected {
+		main, detail = t.RowActive, t.RowActive
+		prefix = "> "
+	}
+	block := []string{
+		main.Render(TruncateString(prefix+e.Session.TimeRange(), width)),
+		main.Render(TruncateString(e.SubjectCode+" "+e.Session.Type, width)),
+	}
+	var meta []string
+	if e.Session.Room != "" {
+		meta = append(meta, e.Session.Room)
+	}
+	if e.Session.Weeks != models.WeeksAll {
+		meta = append(meta, e.Session.Weeks+" weeks")
+	}
+	if len(meta) > 0 {
+		block = append(block, detail.Render(TruncateString(strings.Join(meta, " · "), width)))
+	}
+	return append(block, "")
+}
+
+// RenderTodaySessions lists today's classes for the Dashboard: finished ones
+// dimmed, the one in progress highlighted.
+func RenderTodaySessions(entries []models.TimetableEntry, now time.Time, t style.Theme) string {
+	if len(entries) == 0 {
+		return t.Dim.Render("No classes today")
+	}
+	clock := now.Format("15:04")
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		text := e.Session.TimeRange() + "  " + e.SubjectCode + " " + e.Session.Type
+		if e.Session.Room != "" {
+			text += " · " + e.Session.Room
+		}
+		switch {
+		case e.Session.End <= clock:
+			lines[i] = t.Dim.Render(text)
+		case e.Session.Start <= clock:
+			lines[i] = t.RowActive.Render(text + " (now)")
+		default:
+			lines[i] = t.Text.Render(text)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
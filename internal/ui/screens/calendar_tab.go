@@ -0,0 +1,32 @@
+package screens
+
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/style"
+	"github.com/romanguyen/seman/internal/ui/components"
+)
+
+func RenderCalendarTab(state State, width, height int, t style.Theme) string {
+	title := state.CalendarDay.Format("January 2006")
+	if !state.AgendaOpen {
+		contentW, contentH := components.PanelContentSize(width, height)
+		body := components.RenderCalendar(state.CalendarDay, state.CalendarEntries, contentW, contentH-1, t)
+		return components.RenderPanel(width, height, title, body, t)
+	}
+
+	agendaW := width / 3
+	if agendaW < 32 {
+		agendaW = 32
+	}
+	gridW := width - agendaW - 1
+	gridContentW, gridContentH := components.PanelContentSize(gridW, height)
+	grid := components.RenderCalendar(state.CalendarDay, state.CalendarEntries, gridContentW, gridContentH-1, t)
+	agendaContentW, _ := components.PanelContentSize(agendaW, height)
+	agenda := components.RenderAgenda(state.AgendaEntries, state.AgendaCursor, agendaContentW, t)
+	dayTitle := state.CalendarDay.Format("Mon") + " " + state.DateFormat.Show(state.CalendarDay)
+	return lipgloss.JoinHorizontal(lipgloss.Top,
+		components.RenderPanel(gridW, height, title, grid, t),
+		" ",
+		components.RenderPanel(agendaW, height, dayTitle, agenda, t),
+	)
+}
@@ -1,6 +1,8 @@
 package screens
 
 import (
+	"time"
+
 	"github.com/charmbracelet/lipgloss"
 	"github.com/romanguyen/seman/internal/style"
 	"github.com/romanguyen/seman/internal/ui/components"
@@ -18,8 +20,21 @@ func RenderDashboard(state State, width, height int, t style.Theme) string {
 		projectTitle = "Projects · " + state.SubjectFilter
 	}
 
-	leftBody := components.RenderUpcomingExams(state.Subjects, 5, state.FilterStart, state.FilterEnd, state.FilterAll, state.SubjectFilter, t)
-	leftPanel := components.RenderPanel(layout.LeftWidth, layout.PanelHeight, examTitle, leftBody, t)
+	leftBody := components.RenderUpcomingExams(state.Subjects, 5, state.FilterStart, state.FilterEnd, state.FilterAll, state.SubjectFilter, state.PriorityRules, state.DateFormat, t)
+	// Today's classes sit above the exams when there is room for both.
+	todayLines := len(state.TodaySessions)
+	if todayLines == 0 {
+		todayLines = 1
+	}
+	todayHeight := components.PanelHeightForLines(todayLines + 1)
+	var leftPanel string
+	if todayHeight <= layout.PanelHeight/2 {
+		todayPanel := components.RenderPanel(layout.LeftWidth, todayHeight, "Today", components.RenderTodaySessions(state.TodaySessions, time.Now(), t), t)
+		examPanel := components.RenderPanel(layout.LeftWidth, layout.PanelHeight-todayHeight, examTitle, leftBody, t)
+		leftPanel = lipgloss.JoinVertical(lipgloss.Left, todayPanel, examPanel)
+	} else {
+		leftPanel = components.RenderPanel(layout.LeftWidth, layout.PanelHeight, examTitle, leftBody, t)
+	}
 
 	if layout.MiddleWidth <= 0 {
 		return leftPanel
@@ -30,6 +45,6 @@ func RenderDashboard(state State, width, height int, t style.Theme) string {
 		return lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, " ", middlePanel)
 	}
 
-	rightPanel := components.RenderPanel(layout.RightWidth, layout.PanelHeight, projectTitle, components.RenderProjects(state.Projects, t), t)
+	rightPanel := components.RenderPanel(layout.RightWidth, layout.PanelHeight, projectTitle, components.RenderProjects(state.Projects, state.DateFormat, t), t)
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftPanel, " ", middlePanel, " ", rightPanel)
 }
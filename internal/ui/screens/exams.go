@@ -12,6 +12,6 @@ func RenderExams(state State, width, height int, t style.Theme) string {
 		header += "\n\n"
 	}
 
-	body := header + components.RenderFlatExams(state.FlatExams, state.ExamCursor, state.SubjectFilter, t)
+	body := header + components.RenderFlatExams(state.FlatExams, state.ExamCursor, state.SubjectFilter, state.PriorityRules, state.DateFormat, t)
 	return components.RenderPanel(width, height, "Exams", body, t)
 }
@@ -0,0 +1,15 @@
+package screens
+
+import (
+	"github.com/romanguyen/seman/internal/models"
+	"github.com/romanguyen/seman/internal/style"
+	"github.com/romanguyen/seman/internal/ui/components"
+)
+
+func RenderGradesTab(state State, width, height int, t style.Theme) string {
+	scale := models.GradingScaleOf(state.GradingScale)
+	contentW, _ := components.PanelContentSize(width, height)
+	body := components.RenderGradesSummary(state.Subjects, scale, t) + "\n\n" +
+		components.RenderGrades(state.Subjects, scale, state.GradeCursor, contentW, t)
+	return components.RenderPanel(width, height, "Grades", body, t)
+}
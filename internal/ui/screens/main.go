@@ -17,11 +17,17 @@ func RenderMain(state State, width, height int, t style.Theme) string {
 	case 3: // tabProjects
 		return RenderProjectsTab(state, width, height, t)
 	case 4: // tabSettings
-		return RenderSettingsTab(width, height, state.ConfirmOn, state.WeekSpan, state.LofiEnabled, state.LofiURL, state.ThemeName, t)
+		return RenderSettingsTab(width, height, state.ConfirmOn, state.WeekSpan, state.LofiEnabled, state.LofiURL, state.ThemeName, state.DateFormat, state.PriorityRules, t)
 	case 5: // tabLofi
 		return RenderLofi(state, width, height, t)
 	case 6: // tabSubjects
 		return RenderSubjectsTab(state, width, height, t)
+	case 7: // tabGrades
+		return RenderGradesTab(state, width, height, t)
+	case 8: // tabTimetable
+		return RenderTimetableTab(state, width, height, t)
+	case 9: // tabCalendar
+		return RenderCalendarTab(state, width, height, t)
 	default:
 		return RenderPlaceholder(width, height, t)
 	}
@@ -1,20 +1,44 @@
 package screens
 
 import (
+	"github.com/charmbracelet/lipgloss"
 	"github.com/romanguyen/seman/internal/style"
 	"github.com/romanguyen/seman/internal/ui/components"
 )
 
 func RenderProjectsTab(state State, width, height int, t style.Theme) string {
-	layout := components.ComputeProjectsLayout(width, height)
+	planW := width / 3
+	if planW < 34 {
+		planW = 34
+	}
+	tableW := width - planW - 1
+	layout := components.ComputeProjectsLayout(tableW, height)
 
 	title := "Projects Overview"
+	if state.Timeline {
+		title = "Projects Timeline"
+	}
 	var header string
 	if state.SubjectFilter != "" {
 		title = "Projects · " + state.SubjectFilter
 		header = t.Title.Render("Subject: "+state.SubjectFilter) + "  " + t.Dim.Render("[R] clear filter") + "\n\n"
 	}
 
-	body := header + components.RenderProjectsTable(state.Projects, state.ProjectCursor, layout.TableWidth, t)
-	return components.RenderPanel(width, height, title, body, t)
+	var body string
+	if state.Timeline {
+		body = header + components.RenderTimeline(state.Projects, state.TimelineExams, state.ProjectCursor, state.WeekStart, layout.TableWidth, t)
+	} else {
+		body = header + components.RenderProjectsTable(state.Projects, state.ProjectCursor, layout.TableWidth, state.DateFormat, t)
+	}
+	table := components.RenderPanel(tableW, height, title, body, t)
+
+	planTitle := "Plan"
+	planBody := t.Dim.Render("No project selected")
+	if state.ProjectCursor >= 0 && state.ProjectCursor < len(state.Projects) {
+		project := state.Projects[state.ProjectCursor]
+		planTitle = project.Name
+		planContentW, _ := components.PanelContentSize(planW, height)
+		planBody = components.RenderProjectPlan(project, state.ProjectFocus, state.PlanCursor, planContentW, state.DateFormat, t)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, table, " ", components.RenderPanel(planW, height, planTitle, planBody, t))
 }
@@ -3,13 +3,16 @@ package screens
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/romanguyen/seman/internal/dates"
+	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/style"
 	"github.com/romanguyen/seman/internal/ui/components"
 )
 
-func RenderSettingsTab(width, height int, confirmOn bool, weekSpan int, lofiEnabled bool, lofiURL string, themeName string, t style.Theme) string {
+func RenderSettingsTab(width, height int, confirmOn bool, weekSpan int, lofiEnabled bool, lofiURL string, themeName string, dateFormat dates.Format, rules models.PriorityRules, t style.Theme) string {
 	gap := 1
 	leftWidth := (width - gap) / 2
 	rightWidth := width - leftWidth - gap
@@ -19,9 +22,10 @@ func RenderSettingsTab(width, height int, confirmOn bool, weekSpan int, lofiEnab
 	}
 
 	dataBody := strings.Join([]string{
-		t.Text.Render("[X] Export data to JSON"),
+		t.Text.Render("[X] Export data (JSON or .ics calendar)"),
 		t.Text.Render("[I] Import data from file"),
 		t.Text.Render("[B] Backup current semester"),
+		t.Text.Render("[V] Restore from backup"),
 		t.Text.Render("[C] Clear all data (CAUTION)"),
 	}, "\n")
 
@@ -29,6 +33,7 @@ func RenderSettingsTab(width, height int, confirmOn bool, weekSpan int, lofiEnab
 	themeLabel := lipgloss.NewStyle().Foreground(t.Accent).Bold(true).Render("■") + " " + themeName
 	displayBody := strings.Join([]string{
 		components.AlignLine(displayContentW, t.Text.Render("Theme: "+themeLabel), t.Text.Render("[T] Cycle")),
+		components.AlignLine(displayContentW, t.Text.Render("Date format: "+dateFormat.Show(dateFormatSample)), t.Text.Render("[F] Cycle")),
 		components.AlignLine(displayContentW, t.Text.Render("Weeks visible: "+weekSpanLabel(weekSpan)), t.Text.Render("[W] Change")),
 		components.AlignLine(displayContentW, t.Text.Render(fmt.Sprintf("Confirm deletions: %s", components.YesNo(confirmOn))), t.Text.Render("[O] Toggle")),
 		components.AlignLine(displayContentW, t.Text.Render(fmt.Sprintf("Lofi tab: %s", components.YesNo(lofiEnabled))), t.Text.Render("[L] Toggle")),
@@ -38,10 +43,15 @@ func RenderSettingsTab(width, height int, confirmOn bool, weekSpan int, lofiEnab
 	dataLines := strings.Count(dataBody, "\n") + 1 + 1
 	dataHeight := components.PanelHeightForLines(dataLines)
 
+	newExams := "manual"
+	if rules.AutoDefault {
+		newExams = "AUTO"
+	}
 	priorityBody := strings.Join([]string{
-		t.Text.Render("HIGH priority if exam is within: 7 days"),
-		t.Text.Render("MEDIUM priority if exam is within: 14 days"),
+		t.Text.Render("HIGH priority if exam is within: " + daysLabel(rules.HighDays)),
+		t.Text.Render("MEDIUM priority if exam is within: " + daysLabel(rules.MedDays)),
 		t.Text.Render("LOW priority otherwise"),
+		t.Dim.Render("Applies to exams with priority AUTO · new exams: " + newExams),
 		t.Dim.Render("[R] Configure rules"),
 	}, "\n")
 
@@ -69,6 +79,9 @@ func RenderSettingsTab(width, height int, confirmOn bool, weekSpan int, lofiEnab
 	return lipgloss.JoinHorizontal(lipgloss.Top, displayPanel, " ", rightColumn)
 }
 
+// dateFormatSample is the date the Settings tab shows in the chosen format.
+var dateFormatSample = time.Date(2026, time.January, 13, 13, 0, 0, 0, time.Local)
+
 func weekSpanLabel(span int) string {
 	switch span {
 	case -1:
@@ -82,6 +95,13 @@ func weekSpanLabel(span int) string {
 	}
 }
 
+func daysLabel(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
 func lofiURLLabel(url string, width int) string {
 	label := strings.TrimSpace(url)
 	if label == "" {
@@ -3,6 +3,7 @@ package screens
 import (
 	"time"
 
+	"github.com/romanguyen/seman/internal/dates"
 	"github.com/romanguyen/seman/internal/models"
 	"github.com/romanguyen/seman/internal/ui/components"
 )
@@ -11,11 +12,14 @@ type State struct {
 	ActiveTab          int
 	ConfirmOn          bool
 	ThemeName          string
+	PriorityRules      models.PriorityRules
 	SubjectFilter      string
 	ChecklistView      string
 	Projects           []models.ProjectItem
 	Subjects           []models.SubjectItem
 	SelectedSubj       int
+	SubjectFocus       bool
+	ResourceCursor     int
 	ExamCursor         int
 	FlatExams          []models.FlatExam
 	WeekLabel          string
@@ -25,6 +29,10 @@ type State struct {
 	WeekSpan           int
 	WeeklyExams        []string
 	ProjectCursor      int
+	ProjectFocus       bool
+	PlanCursor         int
+	Timeline           bool
+	TimelineExams      []models.CalendarEntry
 	LofiEnabled        bool
 	LofiURL            string
 	LofiStatus         string
@@ -33,5 +41,17 @@ type State struct {
 	LofiCursor         int
 	LofiOffset         int
 	LofiNow            int
+	GradingScale       string
+	GradeCursor        int
+	DateFormat         dates.Format
+	WeekStart          time.Time
+	WeekSessions       []models.TimetableEntry
+	SessionCursor      int
+	TodaySessions      []models.TimetableEntry
+	CalendarDay        time.Time
+	CalendarEntries    []models.CalendarEntry
+	AgendaOpen         bool
+	AgendaEntries      []models.CalendarEntry
+	AgendaCursor       int
 	Modal              components.ModalState
 }
@@ -1,16 +1,37 @@
 package screens
 
 import (
+	"github.com/charmbracelet/lipgloss"
 	"github.com/romanguyen/seman/internal/style"
 	"github.com/romanguyen/seman/internal/ui/components"
 )
 
 func RenderSubjectsTab(state State, width, height int, t style.Theme) string {
+	listW := width / 3
+	if listW < 24 {
+		listW = 24
+	}
+	detailW := width - listW - 1
+
 	var body string
 	if len(state.Subjects) == 0 {
 		body = t.Dim.Render("No subjects yet — press [A] to add one")
 	} else {
 		body = components.RenderSubjects(state.Subjects, state.SelectedSubj, t)
 	}
-	return components.RenderPanel(width, height, "Subjects", body, t)
+	list := components.RenderPanel(listW, height, "Subjects", body, t)
+
+	detailTitle := "Details"
+	detailBody := t.Dim.Render("No subject selected")
+	if state.SelectedSubj >= 0 && state.SelectedSubj < len(state.Subjects) {
+		subject := state.Subjects[state.SelectedSubj]
+		detailTitle = subject.Code
+		if subject.Name != "" {
+			detailTitle += " · " + subject.Name
+		}
+		contentW, contentH := components.PanelContentSize(detailW, height)
+		// The panel title takes the first line.
+		detailBody = components.RenderSubjectDetail(subject, state.SubjectFocus, state.ResourceCursor, contentW, contentH-1, t)
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, list, " ", components.RenderPanel(detailW, height, detailTitle, detailBody, t))
 }
@@ -0,0 +1,12 @@
+package screens
+
+import (
+	"github.com/romanguyen/seman/internal/style"
+	"github.com/romanguyen/seman/internal/ui/components"
+)
+
+func RenderTimetableTab(state State, width, height int, t style.Theme) string {
+	contentW, contentH := components.PanelContentSize(width, height)
+	body := components.RenderTimetable(state.WeekSessions, state.WeekStart, state.SessionCursor, contentW, contentH-1, t)
+	return components.RenderPanel(width, height, "Timetable", body, t)
+}